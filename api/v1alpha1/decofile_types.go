@@ -17,10 +17,17 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"fmt"
+	"io/fs"
+	"net/url"
+	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -30,6 +37,25 @@ import (
 const (
 	SourceInline = "inline"
 	SourceGitHub = "github"
+	SourceFile   = "file"
+	// SourceS3 lists and reads the objects under a key prefix of an S3 (or
+	// S3-compatible) bucket.
+	SourceS3 = "s3"
+	// SourcePVC reads files from a PersistentVolumeClaim mounted into the
+	// operator.
+	SourcePVC = "pvc"
+	// SourceComposite merges the outputs of an ordered list of the other
+	// sources.
+	SourceComposite = "composite"
+)
+
+// Inline value formats (InlineSource.Format).
+const (
+	// InlineFormatJSON takes each value entry as JSON (default).
+	InlineFormatJSON = "json"
+	// InlineFormatYAML takes each value entry as a string of YAML, converted
+	// to JSON before assembly.
+	InlineFormatYAML = "yaml"
 )
 
 // Decofile delivery targets (DecofileSpec.Target) — selects the FastDeployment
@@ -46,13 +72,87 @@ const (
 	TargetS3 = "s3"
 )
 
+// Reload transports (DecofileSpec.NotifyTransport).
+const (
+	// NotifyTransportHTTP sends reloads as HTTP requests (default).
+	NotifyTransportHTTP = "http"
+	// NotifyTransportGRPC calls the runtime's DecofileRuntime.Reload RPC.
+	NotifyTransportGRPC = "grpc"
+	// NotifyTransportSSE pushes reloads as Server-Sent Events to pods
+	// subscribed to the operator's event stream.
+	NotifyTransportSSE = "sse"
+)
+
+// Decofile rollout strategies (DecofileSpec.RolloutStrategy) — how running
+// pods pick up changed content.
+const (
+	// RolloutReload pushes the new content to the running pods (default).
+	RolloutReload = "reload"
+	// RolloutKnativeRevision stamps the owning Knative Service's pod template,
+	// so Knative rolls a fresh Revision instead of pods being reloaded.
+	RolloutKnativeRevision = "knative-revision"
+)
+
+// Pod notification strategies (DecofileSpec.NotificationStrategy).
+const (
+	// NotifyAll reloads all pods in parallel (default).
+	NotifyAll = "all"
+	// NotifyCanary reloads one pod first and only reloads the rest once it
+	// answered 2xx and passed spec.canary's health check.
+	NotifyCanary = "canary"
+)
+
+// Decofile destination types (DestinationSpec.Type).
+const (
+	// DestinationConfigMap mirrors the rendered ConfigMap data to another
+	// ConfigMap in the Decofile's namespace.
+	DestinationConfigMap = "configMap"
+	// DestinationHTTP POSTs the rendered decofile JSON to a URL.
+	DestinationHTTP = "http"
+)
+
+// Decofile deletion policies (DecofileSpec.DeletionPolicy).
+const (
+	// DeletionBlock rejects deleting a Decofile that Services still inject
+	// (default).
+	DeletionBlock = "block"
+	// DeletionAllow deletes the Decofile and its ConfigMap even when in use.
+	DeletionAllow = "allow"
+	// DeletionOrphan deletes the Decofile but keeps its ConfigMap, so
+	// consuming Services keep working.
+	DeletionOrphan = "orphan"
+)
+
+// ConfigMap ownership modes (DecofileSpec.ConfigMapOwnership).
+const (
+	// ConfigMapOwned makes the Decofile the ConfigMap's controller, so the
+	// ConfigMap is garbage collected with the Decofile (default).
+	ConfigMapOwned = "owned"
+	// ConfigMapOrphan keeps the ConfigMap free of the Decofile's
+	// ownerReference, so deleting the Decofile leaves it for its consumers.
+	ConfigMapOrphan = "orphan"
+)
+
+// Decofile ConfigMap codecs. A Service declares the codecs its runtime can
+// read with the deco.sites/decofile-codecs annotation; the ConfigMap carries
+// one key per codec selected by any consumer of the deploymentId.
+const (
+	// CodecBrotli is base64-encoded Brotli (decofile.bin), always rendered.
+	CodecBrotli = "br"
+	// CodecGzip is base64-encoded gzip (decofile.gz).
+	CodecGzip = "gzip"
+	// CodecIdentity is the plain JSON (decofile.json), for runtimes that
+	// can't decompress. Counts fully against the ~1MB ConfigMap limit.
+	CodecIdentity = "identity"
+)
+
 // DecofileSpec defines the desired state of Decofile.
 // +kubebuilder:validation:XValidation:rule="self.target != 'tanstack-kv' || has(self.tanstackKV)",message="spec.tanstackKV is required when target is tanstack-kv"
 // +kubebuilder:validation:XValidation:rule="self.target != 'tanstack-kv' || self.source == 'github'",message="source must be 'github' when target is tanstack-kv"
 type DecofileSpec struct {
 	// Source specifies where to get the configuration data
 	// +kubebuilder:validation:Required
-	// +kubebuilder:validation:Enum=inline;github
+	// +kubebuilder:validation:Enum=inline;github;file;s3;pvc;composite
 	Source string `json:"source"`
 
 	// Inline contains direct JSON values (used when source=inline)
@@ -63,8 +163,25 @@ type DecofileSpec struct {
 	// +optional
 	GitHub *GitHubSource `json:"github,omitempty"`
 
+	// File lists files baked into the operator image (used when source=file)
+	// +optional
+	File *FileSource `json:"file,omitempty"`
+
+	// S3 names the bucket and key prefix read from (used when source=s3)
+	// +optional
+	S3 *S3Source `json:"s3,omitempty"`
+
+	// PVC names a claim mounted into the operator (used when source=pvc)
+	// +optional
+	PVC *PVCSource `json:"pvc,omitempty"`
+
+	// Composite lists the sources merged together (used when source=composite)
+	// +optional
+	Composite *CompositeSource `json:"composite,omitempty"`
+
 	// DeploymentId is used for pod label matching (defaults to metadata.name if absent)
-	// Pods are queried using the app.deco/deploymentId label
+	// Pods are queried using the app.deco/deploymentId label (or the
+	// operator's --deployment-id-label)
 	// +optional
 	DeploymentId string `json:"deploymentId,omitempty"`
 
@@ -83,6 +200,566 @@ type DecofileSpec struct {
 	// The repo/commit to sync come from spec.github (source=github).
 	// +optional
 	TanstackKV *TanstackKVTarget `json:"tanstackKV,omitempty"`
+
+	// NotifyRevisionTag scopes pod notifications to the Knative Revision behind
+	// this traffic tag (resolved via the Service's traffic), e.g. to reload only
+	// the canary during a progressive rollout. Unset notifies all pods.
+	// +optional
+	NotifyRevisionTag string `json:"notifyRevisionTag,omitempty"`
+
+	// PodSelector further scopes pod notifications to the pods matching it,
+	// ANDed with the deploymentId label (or the extra Decofile label), e.g.
+	// to reload only the pods of the current version. Unset notifies all of
+	// the Decofile's pods.
+	// +optional
+	PodSelector *metav1.LabelSelector `json:"podSelector,omitempty"`
+
+	// ProbeReloadEndpoint checks that the injected runtime implements the
+	// reload contract: on each reconcile the controller sends a HEAD request
+	// to /.decofile/reload on the newest ready pod and reports the outcome in
+	// the ReloadEndpointReachable condition.
+	// +optional
+	ProbeReloadEndpoint bool `json:"probeReloadEndpoint,omitempty"`
+
+	// PublishContent serves the rendered content from the operator API at
+	// /decofile/{namespace}/{name} and records that URL in
+	// status.contentURL, for consumers that are not pods and should not need
+	// ConfigMap read access. Only the configmap target can be published.
+	// +optional
+	PublishContent bool `json:"publishContent,omitempty"`
+
+	// ReloadMethod is the HTTP method of the reload request sent to pods:
+	// POST (default) or PUT carry the decofile in the body, GET sends none and
+	// leaves the runtime to read the mounted file.
+	// +kubebuilder:validation:Enum=POST;PUT;GET
+	// +optional
+	ReloadMethod string `json:"reloadMethod,omitempty"`
+
+	// ReloadPath is the path of the reload request sent to pods (and of the
+	// spec.probeReloadEndpoint probe), for runtimes that serve their reload
+	// hook elsewhere, e.g. /internal/reload. Defaults to /.decofile/reload.
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^/`
+	// +optional
+	ReloadPath string `json:"reloadPath,omitempty"`
+
+	// NotifyTransport is how pods are told to reload: http (default) sends
+	// the reload request to /.decofile/reload, grpc calls the
+	// DecofileRuntime.Reload RPC of docs/reload.proto instead, and sse
+	// pushes it as an event to pods subscribed to the operator's event
+	// stream (--notify-events-bind-address).
+	// +kubebuilder:validation:Enum=http;grpc;sse
+	// +optional
+	NotifyTransport string `json:"notifyTransport,omitempty"`
+
+	// NotifyGracePeriod debounces pod notifications: a content change is
+	// delivered to the ConfigMap at once, but pods are only notified when the
+	// grace period after the first change has passed, with whatever content
+	// is current by then. Bursts of edits thus cause a single reload.
+	// Unset notifies every change immediately.
+	// +optional
+	NotifyGracePeriod *metav1.Duration `json:"notifyGracePeriod,omitempty"`
+
+	// TimestampKey is the ConfigMap data key (and so the mounted file name)
+	// holding the Unix timestamp of the last content change, for runtimes that
+	// key their reload logic off a differently named file. Defaults to
+	// "timestamp.txt".
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[-._a-zA-Z0-9]+$`
+	// +optional
+	TimestampKey string `json:"timestampKey,omitempty"`
+
+	// Compression is the codec consuming Services read when they don't list
+	// their own in the deco.sites/decofile-codecs annotation: brotli
+	// (decofile.bin, default), gzip (decofile.gz) or none (decofile.json).
+	// decofile.bin is rendered whatever is chosen.
+	// +kubebuilder:validation:Enum=brotli;gzip;none
+	// +optional
+	Compression string `json:"compression,omitempty"`
+
+	// NotificationPriority orders this Decofile's reconciles in the controller
+	// queue: when it is backed up, Decofiles with a higher priority are
+	// reconciled, and their pods notified, before routine ones. Defaults to 0.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	NotificationPriority int32 `json:"notificationPriority,omitempty"`
+
+	// NotificationStrategy selects how pods are reloaded on a change: "all"
+	// (default) notifies them in parallel, "canary" notifies a single pod
+	// first and stops, with PodsNotified reason CanaryFailed, unless it
+	// accepts the reload and passes the spec.canary health check.
+	// +kubebuilder:validation:Enum=all;canary
+	// +optional
+	NotificationStrategy string `json:"notificationStrategy,omitempty"`
+
+	// Canary configures the health check of notificationStrategy=canary.
+	// +optional
+	Canary *CanaryNotification `json:"canary,omitempty"`
+
+	// DeletionPolicy controls deleting this Decofile: "block" (default)
+	// rejects it while Services inject it, "allow" skips that check, and
+	// "orphan" skips it and keeps the ConfigMap (released from the Decofile)
+	// so consuming Services keep working.
+	// +kubebuilder:validation:Enum=block;allow;orphan
+	// +optional
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
+
+	// ConfigMapOwnership controls the ownerReference on the rendered
+	// ConfigMap: "owned" (default) makes this Decofile its controller, so
+	// deleting the Decofile deletes the ConfigMap, while "orphan" leaves it
+	// without one (marked orphaned) so it outlives the Decofile, e.g. for a
+	// ConfigMap consumers keep mounting. Switching reconciles the reference.
+	// +kubebuilder:validation:Enum=owned;orphan
+	// +optional
+	ConfigMapOwnership string `json:"configMapOwnership,omitempty"`
+
+	// RolloutStrategy selects how running pods pick up changed content:
+	// "reload" (default) pushes it to the pods, "knative-revision" instead
+	// patches the pod template of the Knative Services with this deploymentId
+	// so Knative rolls out a new Revision.
+	// +kubebuilder:validation:Enum=reload;knative-revision
+	// +optional
+	RolloutStrategy string `json:"rolloutStrategy,omitempty"`
+
+	// Transforms lists post-processing steps applied, in order, to the retrieved
+	// content before it is stored. Built-ins: "minify", "substitute" (expands
+	// ${DECOFILE_NAME}, ${DECOFILE_NAMESPACE}, ${DECOFILE_DEPLOYMENT_ID} and
+	// ${DECOFILE_COMMIT}) and "validate-schema".
+	// +optional
+	Transforms []string `json:"transforms,omitempty"`
+
+	// RequiredKeys must all be top-level keys of the rendered content (after
+	// transforms), e.g. "site". Content missing any of them is not
+	// stored, and Ready turns False with reason MissingRequiredKeys listing
+	// them, which catches an emptied source or a path that drops sections.
+	// +kubebuilder:validation:MaxItems=64
+	// +listType=set
+	// +optional
+	RequiredKeys []string `json:"requiredKeys,omitempty"`
+
+	// Destinations are further places the rendered content is delivered to
+	// whenever it changes, in addition to the Decofile's own ConfigMap, e.g.
+	// a mirror for a consumer outside Kubernetes.
+	// +kubebuilder:validation:MaxItems=8
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Destinations []DestinationSpec `json:"destinations,omitempty"`
+
+	// StripExtensions trims the ".json" extension from file names when they
+	// become keys of the assembled decofile ("pages/home.json" is stored as
+	// "pages/home"). Set to false to keep the names as they are in the source.
+	// +kubebuilder:default=true
+	// +optional
+	StripExtensions *bool `json:"stripExtensions,omitempty"`
+
+	// PauseUntil freezes the Decofile until the given time, e.g. for a
+	// change-freeze window: while it is in the future the controller neither
+	// updates the delivered content nor notifies pods, and resumes on its own
+	// once it passes.
+	// +optional
+	PauseUntil *metav1.Time `json:"pauseUntil,omitempty"`
+
+	// ValidateOnly makes the controller retrieve, transform and check the
+	// content and report the outcome in the Validated condition, without
+	// writing a ConfigMap, uploading to S3 or notifying pods.
+	// +optional
+	ValidateOnly bool `json:"validateOnly,omitempty"`
+}
+
+// DestinationSpec is an additional place the rendered content is delivered to.
+type DestinationSpec struct {
+	// Name identifies the destination in conditions and logs
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// Type is the kind of destination: "configMap" or "http"
+	// +kubebuilder:validation:Enum=configMap;http
+	Type string `json:"type"`
+
+	// ConfigMap configures a type=configMap destination.
+	// +optional
+	ConfigMap *ConfigMapDestination `json:"configMap,omitempty"`
+
+	// HTTP configures a type=http destination.
+	// +optional
+	HTTP *HTTPDestination `json:"http,omitempty"`
+}
+
+// ConfigMapDestination mirrors the rendered ConfigMap data (codec keys and
+// timestamp) to another ConfigMap owned by the Decofile.
+type ConfigMapDestination struct {
+	// Name of the ConfigMap, in the Decofile's namespace. It must not be the
+	// Decofile's own ConfigMap.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	Name string `json:"name"`
+}
+
+// HTTPDestination POSTs the rendered decofile to an endpoint as JSON:
+// {"decofile", "namespace", "timestamp", "content"}.
+type HTTPDestination struct {
+	// URL is the http(s) endpoint the content is POSTed to
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	URL string `json:"url"`
+
+	// Secret is the name of a Secret in the Decofile's namespace whose
+	// "token" key is sent as a bearer token.
+	// +optional
+	Secret string `json:"secret,omitempty"`
+}
+
+// Validate checks the source/target combination and the required sub-fields
+// of the selected source. The CRD schema covers most of this for objects
+// admitted by the API server; Validate is shared by the validating webhook and
+// the controller so both reject an invalid spec with the same message.
+func (s *DecofileSpec) Validate() error {
+	if s.Composite != nil && s.Source != SourceComposite {
+		return fmt.Errorf("spec.composite must not be set when source is %q", s.Source)
+	}
+	if s.S3 != nil && s.Source != SourceS3 {
+		return fmt.Errorf("spec.s3 must not be set when source is %q", s.Source)
+	}
+	if s.PVC != nil && s.Source != SourcePVC {
+		return fmt.Errorf("spec.pvc must not be set when source is %q", s.Source)
+	}
+	switch s.Source {
+	case SourceInline:
+		if s.Inline == nil {
+			return fmt.Errorf("spec.inline is required when source is %q", SourceInline)
+		}
+		for i, from := range s.Inline.ValueFrom {
+			if from.ConfigMapRef.Name == "" {
+				return fmt.Errorf("spec.inline.valueFrom[%d].configMapRef.name is required", i)
+			}
+		}
+		switch s.Inline.Format {
+		case "", InlineFormatJSON, InlineFormatYAML:
+		default:
+			return fmt.Errorf("unknown spec.inline.format %q (must be %q or %q)", s.Inline.Format, InlineFormatJSON, InlineFormatYAML)
+		}
+		if s.GitHub != nil {
+			return fmt.Errorf("spec.github must not be set when source is %q", SourceInline)
+		}
+		if s.File != nil {
+			return fmt.Errorf("spec.file must not be set when source is %q", SourceInline)
+		}
+	case SourceGitHub:
+		if s.GitHub == nil {
+			return fmt.Errorf("spec.github is required when source is %q", SourceGitHub)
+		}
+		if s.Inline != nil {
+			return fmt.Errorf("spec.inline must not be set when source is %q", SourceGitHub)
+		}
+		if s.File != nil {
+			return fmt.Errorf("spec.file must not be set when source is %q", SourceGitHub)
+		}
+		var missing []string
+		for _, f := range []struct{ name, value string }{
+			{"org", s.GitHub.Org},
+			{"repo", s.GitHub.Repo},
+			{"commit", s.GitHub.Commit},
+		} {
+			if f.value == "" {
+				missing = append(missing, "spec.github."+f.name)
+			}
+		}
+		if s.GitHub.Path == "" && len(s.GitHub.Paths) == 0 {
+			missing = append(missing, "spec.github.path")
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("%s required when source is %q", strings.Join(missing, ", "), SourceGitHub)
+		}
+		for i, p := range s.GitHub.Paths {
+			if p == "" {
+				return fmt.Errorf("spec.github.paths[%d] must not be empty", i)
+			}
+		}
+		if !githubNamePattern.MatchString(s.GitHub.Org) {
+			return fmt.Errorf("spec.github.org %q is not a valid GitHub organization or user name", s.GitHub.Org)
+		}
+		if !githubNamePattern.MatchString(s.GitHub.Repo) {
+			return fmt.Errorf("spec.github.repo %q is not a valid GitHub repository name", s.GitHub.Repo)
+		}
+		if !githubRefPattern.MatchString(s.GitHub.Commit) {
+			return fmt.Errorf("spec.github.commit %q is not a valid commit SHA or ref", s.GitHub.Commit)
+		}
+		for i, layer := range s.GitHub.Layers {
+			if !githubRefPattern.MatchString(layer.Commit) {
+				return fmt.Errorf("spec.github.layers[%d].commit %q is not a valid commit SHA or ref", i, layer.Commit)
+			}
+		}
+		if s.GitHub.MaxDepth < 0 {
+			return fmt.Errorf("spec.github.maxDepth must not be negative")
+		}
+		if s.GitHub.Incremental && len(s.GitHub.Layers) > 0 {
+			return fmt.Errorf("spec.github.incremental cannot be combined with spec.github.layers")
+		}
+		if s.GitHub.RefreshInterval != nil && s.GitHub.RefreshInterval.Duration < MinGitHubRefreshInterval {
+			return fmt.Errorf("spec.github.refreshInterval must be at least %v", MinGitHubRefreshInterval)
+		}
+	case SourceFile:
+		if s.File == nil || len(s.File.Paths) == 0 {
+			return fmt.Errorf("spec.file.paths is required when source is %q", SourceFile)
+		}
+		if s.Inline != nil || s.GitHub != nil {
+			return fmt.Errorf("spec.inline and spec.github must not be set when source is %q", SourceFile)
+		}
+		for _, p := range s.File.Paths {
+			if !fs.ValidPath(p) || p == "." {
+				return fmt.Errorf("spec.file.paths entry %q must be a relative path without \"..\" elements", p)
+			}
+		}
+	case SourceS3:
+		if s.S3 == nil || s.S3.Bucket == "" {
+			return fmt.Errorf("spec.s3.bucket is required when source is %q", SourceS3)
+		}
+		if s.Inline != nil || s.GitHub != nil || s.File != nil {
+			return fmt.Errorf("spec.inline, spec.github and spec.file must not be set when source is %q", SourceS3)
+		}
+		if s.S3.Endpoint != "" {
+			if u, err := url.Parse(s.S3.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("spec.s3.endpoint %q must be an http(s) URL", s.S3.Endpoint)
+			}
+		}
+	case SourcePVC:
+		if s.PVC == nil || s.PVC.ClaimName == "" {
+			return fmt.Errorf("spec.pvc.claimName is required when source is %q", SourcePVC)
+		}
+		if s.Inline != nil || s.GitHub != nil || s.File != nil {
+			return fmt.Errorf("spec.inline, spec.github and spec.file must not be set when source is %q", SourcePVC)
+		}
+		if errs := validation.IsDNS1123Subdomain(s.PVC.ClaimName); len(errs) > 0 {
+			return fmt.Errorf("spec.pvc.claimName %q is not a valid claim name: %s", s.PVC.ClaimName, strings.Join(errs, "; "))
+		}
+		if s.PVC.Path != "" && !fs.ValidPath(s.PVC.Path) {
+			return fmt.Errorf("spec.pvc.path %q must be a relative path without \"..\" elements", s.PVC.Path)
+		}
+	case SourceComposite:
+		if s.Composite == nil || len(s.Composite.Sources) == 0 {
+			return fmt.Errorf("spec.composite.sources is required when source is %q", SourceComposite)
+		}
+		if s.Inline != nil || s.GitHub != nil || s.File != nil {
+			return fmt.Errorf("spec.inline, spec.github and spec.file must not be set when source is %q", SourceComposite)
+		}
+		for i, sub := range s.Composite.Sources {
+			if sub.Source == SourceComposite {
+				return fmt.Errorf("spec.composite.sources[%d]: composite sources cannot be nested", i)
+			}
+			if err := sub.Spec().Validate(); err != nil {
+				return fmt.Errorf("spec.composite.sources[%d]: %w", i, err)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown source %q (must be %q, %q, %q, %q, %q or %q)", s.Source, SourceInline, SourceGitHub, SourceFile, SourceS3, SourcePVC, SourceComposite)
+	}
+
+	switch s.Compression {
+	case "", CompressionBrotli, CompressionGzip, CompressionNone:
+	default:
+		return fmt.Errorf("unknown compression %q (must be %q, %q or %q)", s.Compression, CompressionBrotli, CompressionGzip, CompressionNone)
+	}
+
+	switch s.ReloadMethod {
+	case "", "POST", "PUT", "GET":
+	default:
+		return fmt.Errorf("unknown reloadMethod %q (must be POST, PUT or GET)", s.ReloadMethod)
+	}
+	if s.ReloadPath != "" && !strings.HasPrefix(s.ReloadPath, "/") {
+		return fmt.Errorf("spec.reloadPath %q must begin with /", s.ReloadPath)
+	}
+
+	switch s.NotifyTransport {
+	case "", NotifyTransportHTTP:
+	case NotifyTransportGRPC, NotifyTransportSSE:
+		if s.ReloadMethod != "" {
+			return fmt.Errorf("spec.reloadMethod only applies to notifyTransport %q", NotifyTransportHTTP)
+		}
+		if s.ReloadPath != "" {
+			return fmt.Errorf("spec.reloadPath only applies to notifyTransport %q", NotifyTransportHTTP)
+		}
+		if s.ProbeReloadEndpoint {
+			return fmt.Errorf("spec.probeReloadEndpoint requires notifyTransport %q", NotifyTransportHTTP)
+		}
+	default:
+		return fmt.Errorf("unknown notifyTransport %q (must be %q, %q or %q)", s.NotifyTransport,
+			NotifyTransportHTTP, NotifyTransportGRPC, NotifyTransportSSE)
+	}
+
+	if s.NotifyGracePeriod != nil && s.NotifyGracePeriod.Duration < 0 {
+		return fmt.Errorf("spec.notifyGracePeriod must not be negative")
+	}
+
+	for _, key := range s.RequiredKeys {
+		if key == "" {
+			return fmt.Errorf("spec.requiredKeys must not contain an empty key")
+		}
+	}
+
+	if s.NotificationPriority < 0 {
+		return fmt.Errorf("spec.notificationPriority must not be negative")
+	}
+
+	if s.PodSelector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(s.PodSelector); err != nil {
+			return fmt.Errorf("spec.podSelector: %w", err)
+		}
+	}
+
+	if s.TimestampKey != "" {
+		if !configMapKeyPattern.MatchString(s.TimestampKey) || s.TimestampKey == "." || s.TimestampKey == ".." {
+			return fmt.Errorf("spec.timestampKey %q is not a valid ConfigMap key", s.TimestampKey)
+		}
+		for _, codec := range []string{CodecBrotli, CodecGzip, CodecIdentity} {
+			if s.TimestampKey == DecofileCodecKey(codec) {
+				return fmt.Errorf("spec.timestampKey %q is the content key of codec %q", s.TimestampKey, codec)
+			}
+		}
+		if s.TimestampKey == DecofileMetaKey {
+			return fmt.Errorf("spec.timestampKey %q is the content metadata key", s.TimestampKey)
+		}
+		if s.TimestampKey == DecofileChecksumKey {
+			return fmt.Errorf("spec.timestampKey %q is the content checksum key", s.TimestampKey)
+		}
+	}
+
+	switch s.NotificationStrategy {
+	case "", NotifyAll, NotifyCanary:
+	default:
+		return fmt.Errorf("unknown notificationStrategy %q (must be %q or %q)", s.NotificationStrategy, NotifyAll, NotifyCanary)
+	}
+	if s.Canary != nil && s.Canary.HealthPath != "" && !strings.HasPrefix(s.Canary.HealthPath, "/") {
+		return fmt.Errorf("spec.canary.healthPath %q must start with /", s.Canary.HealthPath)
+	}
+
+	switch s.RolloutStrategy {
+	case "", RolloutReload:
+	case RolloutKnativeRevision:
+		if s.Target == TargetTanstackKV {
+			return fmt.Errorf("rolloutStrategy %q is not supported when target is %q", RolloutKnativeRevision, TargetTanstackKV)
+		}
+	default:
+		return fmt.Errorf("unknown rolloutStrategy %q (must be %q or %q)", s.RolloutStrategy, RolloutReload, RolloutKnativeRevision)
+	}
+
+	if err := validateDestinations(s.Destinations); err != nil {
+		return err
+	}
+
+	switch s.DeletionPolicy {
+	case "", DeletionBlock, DeletionAllow, DeletionOrphan:
+	default:
+		return fmt.Errorf("unknown deletionPolicy %q (must be %q, %q or %q)", s.DeletionPolicy, DeletionBlock, DeletionAllow, DeletionOrphan)
+	}
+
+	switch s.ConfigMapOwnership {
+	case "", ConfigMapOwned, ConfigMapOrphan:
+	default:
+		return fmt.Errorf("unknown configMapOwnership %q (must be %q or %q)", s.ConfigMapOwnership, ConfigMapOwned, ConfigMapOrphan)
+	}
+
+	if s.PublishContent && s.Target != "" && s.Target != TargetConfigMap {
+		return fmt.Errorf("spec.publishContent requires target %q", TargetConfigMap)
+	}
+
+	switch s.Target {
+	case "", TargetConfigMap, TargetS3:
+	case TargetTanstackKV:
+		if s.TanstackKV == nil {
+			return fmt.Errorf("spec.tanstackKV is required when target is %q", TargetTanstackKV)
+		}
+		if s.Source != SourceGitHub {
+			return fmt.Errorf("source must be %q when target is %q", SourceGitHub, TargetTanstackKV)
+		}
+	default:
+		return fmt.Errorf("unknown target %q (must be %q, %q or %q)", s.Target, TargetConfigMap, TargetTanstackKV, TargetS3)
+	}
+	return nil
+}
+
+// validateDestinations checks spec.destinations: unique names and the
+// sub-field matching each type.
+func validateDestinations(destinations []DestinationSpec) error {
+	seen := map[string]bool{}
+	for i, d := range destinations {
+		if d.Name == "" {
+			return fmt.Errorf("spec.destinations[%d].name is required", i)
+		}
+		if seen[d.Name] {
+			return fmt.Errorf("spec.destinations[%d]: duplicate name %q", i, d.Name)
+		}
+		seen[d.Name] = true
+		switch d.Type {
+		case DestinationConfigMap:
+			if d.ConfigMap == nil || d.ConfigMap.Name == "" || d.HTTP != nil {
+				return fmt.Errorf("spec.destinations[%d]: type %q requires configMap.name and no http", i, d.Type)
+			}
+		case DestinationHTTP:
+			if d.HTTP == nil || d.ConfigMap != nil {
+				return fmt.Errorf("spec.destinations[%d]: type %q requires http and no configMap", i, d.Type)
+			}
+			u, err := url.Parse(d.HTTP.URL)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("spec.destinations[%d].http.url %q must be an absolute http(s) URL", i, d.HTTP.URL)
+			}
+		default:
+			return fmt.Errorf("spec.destinations[%d]: unknown type %q (must be %q or %q)", i, d.Type, DestinationConfigMap, DestinationHTTP)
+		}
+	}
+	return nil
+}
+
+// ShouldStripExtensions reports whether ".json" is trimmed from keys; it
+// defaults to true when spec.stripExtensions is unset.
+func (s *DecofileSpec) ShouldStripExtensions() bool {
+	return s.StripExtensions == nil || *s.StripExtensions
+}
+
+// githubNamePattern matches a bare GitHub owner or repository name, which is
+// all BuildZipURL expects in spec.github.org / spec.github.repo.
+var githubNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]+$`)
+
+// configMapKeyPattern matches a ConfigMap data key; it mirrors the Pattern
+// marker on spec.timestampKey.
+var configMapKeyPattern = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+// githubRefPattern matches a commit SHA or a branch/tag ref; it mirrors the
+// Pattern marker on spec.github.commit.
+var githubRefPattern = regexp.MustCompile(`^[A-Za-z0-9._/-]+$`)
+
+// githubURLPrefixes are the copy-paste prefixes Normalize strips from org/repo.
+var githubURLPrefixes = []string{
+	"https://", "http://", "git@github.com:", "www.github.com/", "github.com/",
+}
+
+// Normalize fixes common copy-paste mistakes in org and repo: a pasted
+// repository URL, trailing slashes and a ".git" suffix. Of a path, org keeps
+// the first (owner) segment and repo the last one. Values that are still not
+// bare names are left for Validate to reject.
+func (g *GitHubSource) Normalize() {
+	org := strings.Trim(trimGitHubPrefix(strings.TrimSpace(g.Org)), "/")
+	g.Org, _, _ = strings.Cut(org, "/")
+
+	repo := strings.Trim(trimGitHubPrefix(strings.TrimSpace(g.Repo)), "/")
+	repo = strings.TrimSuffix(repo, ".git")
+	if i := strings.LastIndex(repo, "/"); i >= 0 {
+		repo = repo[i+1:]
+	}
+	g.Repo = repo
+}
+
+func trimGitHubPrefix(s string) string {
+	for _, prefix := range githubURLPrefixes {
+		if len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+			s = s[len(prefix):]
+		}
+	}
+	return s
 }
 
 // TanstackKVTarget configures Cloudflare KV fast-deploy for a TanStack/Workers site.
@@ -102,32 +779,339 @@ type TanstackKVTarget struct {
 type InlineSource struct {
 	// Value is a map where each key becomes a ConfigMap key,
 	// and each value is a JSON object that will be stringified
+	// +optional
+	Value map[string]runtime.RawExtension `json:"value,omitempty"`
+
+	// Format is how Value entries are written: json (default) takes them as
+	// JSON, yaml takes each one as a string of YAML and converts it to JSON,
+	// so the stored content is JSON either way. ValueFrom and Overlay
+	// entries are always JSON.
+	// +kubebuilder:validation:Enum=json;yaml
+	// +optional
+	Format string `json:"format,omitempty"`
+
+	// ValueFrom adds entries read from ConfigMaps in the Decofile's
+	// namespace, for bulky config kept out of the Decofile itself. Each
+	// selected ConfigMap key becomes an entry like a Value key, and its value
+	// must be JSON. A key (after .json trimming) that Value or another
+	// ValueFrom entry already provides is an error, never silently replaced;
+	// Overlay applies to these entries too.
+	// +kubebuilder:validation:MaxItems=16
+	// +optional
+	ValueFrom []InlineValueSource `json:"valueFrom,omitempty"`
+
+	// Overlay is deep-merged over Value, matching entries by key (after
+	// .json trimming): objects are merged recursively, while arrays, scalars
+	// and null replace the base value. An overlay key with no base entry is
+	// added as-is. Use it for per-environment overrides of a shared base.
+	// +optional
+	Overlay map[string]runtime.RawExtension `json:"overlay,omitempty"`
+}
+
+// InlineValueSource selects keys of a ConfigMap as inline entries.
+type InlineValueSource struct {
+	// ConfigMapRef names the ConfigMap, in the Decofile's namespace
+	// +kubebuilder:validation:Required
+	ConfigMapRef ConfigMapReference `json:"configMapRef"`
+
+	// Keys lists the ConfigMap keys to use. Empty uses every key.
+	// +optional
+	Keys []string `json:"keys,omitempty"`
+
+	// Optional tolerates a missing ConfigMap or key, which then contributes
+	// nothing, instead of failing the render.
+	// +optional
+	Optional bool `json:"optional,omitempty"`
+}
+
+// ConfigMapReference names a ConfigMap in the Decofile's namespace.
+type ConfigMapReference struct {
+	// Name of the ConfigMap
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	Name string `json:"name"`
+}
+
+// FileSource reads files from the operator's file source directory
+// (--decofile-file-source-dir), for config baked into the operator image in
+// air-gapped clusters.
+type FileSource struct {
+	// Paths are files or directories relative to the file source directory.
+	// A file is stored under its base name, the files of a directory under
+	// their path within it, like spec.github.path.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	Paths []string `json:"paths"`
+}
+
+// S3Source reads the objects under a key prefix of an S3 bucket, or of an
+// S3-compatible store such as MinIO.
+type S3Source struct {
+	// Bucket is the bucket name
 	// +kubebuilder:validation:Required
-	Value map[string]runtime.RawExtension `json:"value"`
+	// +kubebuilder:validation:MinLength=3
+	// +kubebuilder:validation:MaxLength=63
+	Bucket string `json:"bucket"`
+
+	// Region is the bucket's region. Defaults to us-east-1.
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// Prefix selects the objects read. An object is stored under its key
+	// with the prefix removed, like the files under spec.github.path.
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Endpoint overrides the S3 endpoint URL, e.g. http://minio.storage:9000.
+	// Requests then use path-style addressing.
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+
+	// Secret is the name of a Secret in the Decofile's namespace holding the
+	// AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY keys (and optionally
+	// AWS_SESSION_TOKEN). If omitted, the bucket is read anonymously.
+	// +optional
+	Secret string `json:"secret,omitempty"`
+}
+
+// PVCSource reads files from a PersistentVolumeClaim the operator has mounted
+// under its PVC source directory (--decofile-pvc-source-dir), e.g. a shared
+// ReadWriteMany volume large configs are copied onto out-of-band.
+type PVCSource struct {
+	// ClaimName is the claim, mounted at <pvc source dir>/<claimName> in the
+	// operator container
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	ClaimName string `json:"claimName"`
+
+	// Path is a file or directory relative to the claim's root, stored like
+	// an entry of spec.file.paths. Defaults to the whole volume.
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// CanaryNotification configures how the canary pod is checked before the
+// remaining pods are notified.
+type CanaryNotification struct {
+	// HealthPath is requested (GET) on the canary's reload port after it
+	// accepted the reload; any status other than 2xx fails the canary.
+	// Unset only requires the reload itself to succeed.
+	// +optional
+	HealthPath string `json:"healthPath,omitempty"`
+
+	// Delay is how long to wait after the canary's reload before the health
+	// check, e.g. to let caches warm up. Defaults to no wait.
+	// +optional
+	Delay *metav1.Duration `json:"delay,omitempty"`
+}
+
+// CompositeSource merges several sources into one decofile
+type CompositeSource struct {
+	// Sources are retrieved in order and their JSON deep-merged: for a key
+	// present in several sources, objects are merged recursively and any
+	// other value from the later source replaces the earlier one.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=8
+	Sources []CompositeSourceEntry `json:"sources"`
+}
+
+// CompositeSourceEntry is one source of a composite Decofile, configured
+// like the top-level spec.source and its block.
+type CompositeSourceEntry struct {
+	// Source is the kind of this entry
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=inline;github;file
+	Source string `json:"source"`
+
+	// Inline contains direct JSON values (used when source=inline)
+	// +optional
+	Inline *InlineSource `json:"inline,omitempty"`
+
+	// GitHub contains repository information (used when source=github)
+	// +optional
+	GitHub *GitHubSource `json:"github,omitempty"`
+
+	// File lists files baked into the operator image (used when source=file)
+	// +optional
+	File *FileSource `json:"file,omitempty"`
+}
+
+// Spec returns the entry as a standalone DecofileSpec, for validating and
+// retrieving it like a single-source Decofile.
+func (e *CompositeSourceEntry) Spec() *DecofileSpec {
+	return &DecofileSpec{Source: e.Source, Inline: e.Inline, GitHub: e.GitHub, File: e.File}
 }
 
 // GitHubSource contains GitHub repository information
 type GitHubSource struct {
 	// Org is the GitHub organization or user
 	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=39
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9._-]+$`
 	Org string `json:"org"`
 
 	// Repo is the repository name
 	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9._-]+$`
 	Repo string `json:"repo"`
 
 	// Commit is the commit SHA or ref to fetch
 	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=255
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9._/-]+$`
 	Commit string `json:"commit"`
 
-	// Path is the directory path within the repository
-	// +kubebuilder:validation:Required
-	Path string `json:"path"`
+	// Path is the directory path within the repository. Required unless
+	// Paths is set.
+	// +kubebuilder:validation:MaxLength=1024
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// Paths are further directory paths within the repository whose files
+	// are merged with Path's into one Decofile, e.g. "content" and
+	// "sections". Files are keyed by base name, so two paths holding files
+	// of the same name fail the reconcile instead of one replacing the
+	// other.
+	// +kubebuilder:validation:MaxItems=16
+	// +kubebuilder:validation:items:MinLength=1
+	// +kubebuilder:validation:items:MaxLength=1024
+	// +listType=set
+	// +optional
+	Paths []string `json:"paths,omitempty"`
 
 	// Secret is the name of the Kubernetes secret containing GitHub credentials.
-	// If omitted, the GITHUB_TOKEN environment variable will be used.
+	// If omitted, the operator's --github-default-secret is used, or else the
+	// GITHUB_TOKEN environment variable.
 	// +optional
 	Secret string `json:"secret,omitempty"`
+
+	// LFS fetches the real content of Git LFS-tracked files, which the
+	// repository archive only contains as pointer files. When false, a pointer
+	// file under Path fails the reconcile.
+	// +optional
+	LFS bool `json:"lfs,omitempty"`
+
+	// IncludeBinary keeps files under Path that are not valid JSON (images,
+	// fonts, ...) by storing their base64-encoded content as a JSON string
+	// under the key "base64:<file name>", extension included. When false they
+	// are skipped with a warning.
+	// +optional
+	IncludeBinary bool `json:"includeBinary,omitempty"`
+
+	// Layers are further commits (and paths) of the same repository whose
+	// files are composed over Commit/Path, in order: on a key collision the
+	// later layer's file replaces the earlier one whole (no deep merge).
+	// Layer commits are not tracked by the branch watcher, so pin them to a
+	// SHA or tag, or bump the Decofile to pick up a moved branch.
+	// +kubebuilder:validation:MaxItems=8
+	// +optional
+	Layers []GitHubLayer `json:"layers,omitempty"`
+
+	// MaxDepth only includes files at most this many directory levels below
+	// Path (1 = only files directly in it), skipping deeper ones, e.g. to
+	// leave out large nested trees when only top-level config matters.
+	// Omitted or 0 = unlimited.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxDepth int `json:"maxDepth,omitempty"`
+
+	// Incremental fetches only the files changed since the last rendered
+	// commit (GitHub compare API) and applies them to the previous content,
+	// instead of downloading the whole archive. It falls back to a full
+	// download when there is no previous render, the new commit does not
+	// descend from it, or the diff is too large. Files under Path must have
+	// distinct base names. Not supported with Layers.
+	// +optional
+	Incremental bool `json:"incremental,omitempty"`
+
+	// CacheBust forces a fresh download of the same commit when changed, e.g.
+	// after fixing a proxy that served a stale archive: the unchanged-tree
+	// reuse and incremental fetch are skipped once and the archive is
+	// requested with Cache-Control: no-cache. Any new value works; the one
+	// last rendered is recorded in status.githubCacheBust.
+	// +kubebuilder:validation:MaxLength=255
+	// +optional
+	CacheBust string `json:"cacheBust,omitempty"`
+
+	// RefreshInterval re-resolves Commit at this interval when it names a
+	// branch, and re-renders the content when the branch moved, without
+	// relying on the operator's branch watcher. Pods are only notified when
+	// the rendered content changed. At least 10s; unset only re-renders on
+	// spec changes (or branch watcher events).
+	// +optional
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
+}
+
+// AllPaths returns the directory paths the files are extracted from: Path,
+// if set, followed by Paths.
+func (g *GitHubSource) AllPaths() []string {
+	paths := make([]string, 0, 1+len(g.Paths))
+	if g.Path != "" {
+		paths = append(paths, g.Path)
+	}
+	for _, p := range g.Paths {
+		if !slices.Contains(paths, p) {
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// MinGitHubRefreshInterval is the shortest spec.github.refreshInterval, to
+// keep a Decofile from exhausting the GitHub API rate limit on its own.
+const MinGitHubRefreshInterval = 10 * time.Second
+
+// GitHubLayer is a commit/path of spec.github's repository composed over
+// the base content.
+type GitHubLayer struct {
+	// Commit is the commit SHA or ref to fetch
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=255
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9._/-]+$`
+	Commit string `json:"commit"`
+
+	// Path is the directory path within the repository. Defaults to
+	// spec.github.path.
+	// +kubebuilder:validation:MaxLength=1024
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// CompositeSourceStatus records what one source of a composite Decofile
+// contributed.
+type CompositeSourceStatus struct {
+	// Source is the entry's kind (inline, github or file)
+	Source string `json:"source"`
+
+	// Keys is the number of top-level keys the source returned
+	Keys int `json:"keys"`
+
+	// Overridden is how many of those keys a later source merged over
+	// +optional
+	Overridden int `json:"overridden,omitempty"`
+}
+
+// GitHubLayerStatus records which files of a GitHub layer made it into the
+// delivered content.
+type GitHubLayerStatus struct {
+	// Commit is the layer's commit SHA or ref
+	Commit string `json:"commit"`
+
+	// Path is the directory the layer was read from, or the comma-separated
+	// directories for a layer read from spec.github.paths
+	Path string `json:"path"`
+
+	// Keys is the number of keys of the delivered content that come from
+	// this layer, i.e. that no later layer overrides
+	Keys int `json:"keys"`
 }
 
 // DecofileStatus defines the observed state of Decofile.
@@ -148,10 +1132,58 @@ type DecofileStatus struct {
 	// +optional
 	SourceType string `json:"sourceType,omitempty"`
 
-	// GitHubCommit stores the commit SHA if using GitHub source
+	// GitHubCommit is the commit the current content was rendered at, if
+	// using GitHub source: the resolved SHA when spec.github.commit names a
+	// branch and its head is known, else spec.github.commit.
 	// +optional
 	GitHubCommit string `json:"githubCommit,omitempty"`
 
+	// GitHubRef is the spec.github.commit (SHA or branch) the current content
+	// was rendered for.
+	// +optional
+	GitHubRef string `json:"githubRef,omitempty"`
+
+	// GitHubHead is the SHA a tracked branch pointed at (the
+	// deco.sites/github-head value, or the head spec.github.refreshInterval
+	// resolved) that the current content was downloaded for.
+	// +optional
+	GitHubHead string `json:"githubHead,omitempty"`
+
+	// GitHubTree is the SHA of the git tree the current content was extracted
+	// from. While it is unchanged the controller reuses the ConfigMap content
+	// instead of downloading the archive again.
+	// +optional
+	GitHubTree string `json:"githubTree,omitempty"`
+
+	// GitHubSHA is the commit SHA the current content was rendered at, with
+	// spec.github.incremental. The next render fetches only the files changed
+	// since.
+	// +optional
+	GitHubSHA string `json:"githubSHA,omitempty"`
+
+	// GitHubRenderHash identifies the spec settings that decide how the
+	// files at GitHubSHA were rendered (repository, paths, maxDepth,
+	// includeBinary, lfs, layers, stripExtensions). An incremental render
+	// builds on the previous content only while it is unchanged.
+	// +optional
+	GitHubRenderHash string `json:"githubRenderHash,omitempty"`
+
+	// GitHubCacheBust is the spec.github.cacheBust value the current content
+	// was downloaded with.
+	// +optional
+	GitHubCacheBust string `json:"githubCacheBust,omitempty"`
+
+	// GitHubLayers records the provenance of content composed from
+	// spec.github.layers: the base commit/path first, then each layer, with
+	// the number of keys each contributes.
+	// +optional
+	GitHubLayers []GitHubLayerStatus `json:"githubLayers,omitempty"`
+
+	// CompositeSources records, for source=composite, the top-level keys each
+	// source contributed, in merge order.
+	// +optional
+	CompositeSources []CompositeSourceStatus `json:"compositeSources,omitempty"`
+
 	// JobName is the K8s Job name for the current tanstack-kv sync (target=tanstack-kv).
 	// +optional
 	JobName string `json:"jobName,omitempty"`
@@ -161,9 +1193,96 @@ type DecofileStatus struct {
 	// +optional
 	ContentHash string `json:"contentHash,omitempty"`
 
+	// PreviousContentHash is the ContentHash of the content the last change
+	// replaced, for rollback tooling to find the prior revision.
+	// +optional
+	PreviousContentHash string `json:"previousContentHash,omitempty"`
+
+	// PreviousGitHubCommit is the commit the replaced content was rendered
+	// at, for source=github: its resolved SHA when known, else the tracked
+	// branch head, else its spec.github.commit.
+	// +optional
+	PreviousGitHubCommit string `json:"previousGitHubCommit,omitempty"`
+
+	// DestinationsHash identifies the content and spec.destinations last
+	// delivered to every destination; delivery is retried until it matches.
+	// +optional
+	DestinationsHash string `json:"destinationsHash,omitempty"`
+
 	// S3URL is the HTTP URL the runtime reads from when target=s3.
 	// +optional
 	S3URL string `json:"s3URL,omitempty"`
+
+	// Revision is incremented every time the delivered content changes, so
+	// consumers can detect a new decofile with a single integer comparison
+	// (LastUpdated has one-second resolution and also moves on no-op syncs).
+	// +optional
+	Revision int64 `json:"revision,omitempty"`
+
+	// ContentURL is where the operator API serves the current content when
+	// spec.publishContent is set. Its rev query parameter is status.revision.
+	// +optional
+	ContentURL string `json:"contentURL,omitempty"`
+
+	// ContentETag is the ETag the operator API serves the current content
+	// with, so a poller can send it back in If-None-Match.
+	// +optional
+	ContentETag string `json:"contentETag,omitempty"`
+
+	// RenotifyNonce is the last deco.sites/renotify annotation value that was
+	// acted on. A different annotation value forces a re-notification of pods.
+	// +optional
+	RenotifyNonce string `json:"renotifyNonce,omitempty"`
+
+	// PendingNotification is set while a content change is held back by
+	// spec.notifyGracePeriod and cleared once pods were notified of it.
+	// +optional
+	PendingNotification *PendingNotification `json:"pendingNotification,omitempty"`
+
+	// History lists the last significant state changes, oldest first, so
+	// the sequence of events survives without log access. It holds at most
+	// MaxHistoryEntries entries.
+	// +kubebuilder:validation:MaxItems=16
+	// +optional
+	History []HistoryEntry `json:"history,omitempty"`
+}
+
+// History events (HistoryEntry.Event).
+const (
+	// HistoryCreated is recorded when the content was first delivered.
+	HistoryCreated = "Created"
+	// HistoryUpdated is recorded when the delivered content changed.
+	HistoryUpdated = "Updated"
+	// HistoryNotified is recorded when pods were notified of the content.
+	HistoryNotified = "Notified"
+	// HistoryFailed is recorded when rendering or notifying failed.
+	HistoryFailed = "Failed"
+)
+
+// MaxHistoryEntries caps status.history; older entries are dropped.
+const MaxHistoryEntries = 16
+
+// HistoryEntry is one state change of a Decofile
+type HistoryEntry struct {
+	// Time is when the change was recorded
+	Time metav1.Time `json:"time"`
+
+	// Event is Created, Updated, Notified or Failed
+	Event string `json:"event"`
+
+	// Message describes the change
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// PendingNotification is a pod notification waiting out spec.notifyGracePeriod
+type PendingNotification struct {
+	// Timestamp is the content timestamp pods will be notified with, i.e.
+	// that of the latest change within the grace period.
+	Timestamp string `json:"timestamp"`
+
+	// NotifyAt is when the grace period of the first held-back change ends.
+	NotifyAt metav1.Time `json:"notifyAt"`
 }
 
 // +kubebuilder:object:root=true
@@ -183,6 +1302,19 @@ func (d *Decofile) ConfigMapName() string {
 	return "decofile-" + d.Name
 }
 
+// DefaultTimestampKey is the ConfigMap key of the content timestamp when
+// spec.timestampKey is unset.
+const DefaultTimestampKey = "timestamp.txt"
+
+// TimestampKeyOrDefault returns spec.timestampKey, defaulting to
+// DefaultTimestampKey.
+func (d *Decofile) TimestampKeyOrDefault() string {
+	if d.Spec.TimestampKey != "" {
+		return d.Spec.TimestampKey
+	}
+	return DefaultTimestampKey
+}
+
 // DeploymentIdOrName returns spec.deploymentId, defaulting to the object name.
 func (d *Decofile) DeploymentIdOrName() string {
 	if d.Spec.DeploymentId != "" {
@@ -203,6 +1335,72 @@ func (d *Decofile) S3ObjectKey(prefix string) string {
 	return key
 }
 
+// Decofile compression settings (DecofileSpec.Compression), each naming the
+// codec its consumers default to.
+const (
+	CompressionBrotli = "brotli"
+	CompressionGzip   = "gzip"
+	CompressionNone   = "none"
+)
+
+// DefaultCodec returns the codec for consumers that don't declare theirs,
+// from spec.compression.
+func (s *DecofileSpec) DefaultCodec() string {
+	switch s.Compression {
+	case CompressionGzip:
+		return CodecGzip
+	case CompressionNone:
+		return CodecIdentity
+	default:
+		return CodecBrotli
+	}
+}
+
+// DecofileMetaKey is the ConfigMap key describing how the content keys were
+// encoded (codec, sizes, hash, timestamp), so runtimes need not infer it
+// from the key name.
+const DecofileMetaKey = "decofile.meta.json"
+
+// DecofileChecksumKey is the ConfigMap key holding the hex SHA-256 of the
+// decoded JSON content (the same value as status.contentHash), so a runtime
+// can verify what it loaded.
+const DecofileChecksumKey = "decofile.sha256"
+
+// DecofileCodecKey returns the ConfigMap key holding the content for codec.
+func DecofileCodecKey(codec string) string {
+	switch codec {
+	case CodecGzip:
+		return "decofile.gz"
+	case CodecIdentity:
+		return "decofile.json"
+	default:
+		return "decofile.bin"
+	}
+}
+
+// SelectDecofileCodec picks the codec for a consumer from its comma-separated
+// supported list (the deco.sites/decofile-codecs annotation), preferring br,
+// then gzip. An empty list means br (what every runtime has always read);
+// a list naming neither, e.g. "none", gets the uncompressed identity codec.
+func SelectDecofileCodec(supported string) string {
+	if strings.TrimSpace(supported) == "" {
+		return CodecBrotli
+	}
+	var gzip bool
+	for _, c := range strings.Split(supported, ",") {
+		switch strings.ToLower(strings.TrimSpace(c)) {
+		case CodecBrotli:
+			return CodecBrotli
+		case CodecGzip:
+			gzip = true
+		}
+	}
+	if gzip {
+		return CodecGzip
+	}
+	return CodecIdentity
+}
+
 // +kubebuilder:object:root=true
 
 // DecofileList contains a list of Decofile.
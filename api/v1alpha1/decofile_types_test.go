@@ -8,7 +8,10 @@ you may not use this file except in compliance with the License.
 package v1alpha1
 
 import (
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -39,3 +42,217 @@ func TestDecofileS3ObjectKey(t *testing.T) {
 		})
 	}
 }
+
+func TestDecofileSpecValidate(t *testing.T) {
+	gh := &GitHubSource{Org: "deco-sites", Repo: "storefront", Commit: "abc123", Path: ".deco/blocks"}
+	inline := &InlineSource{}
+	kv := &TanstackKVTarget{KVNamespaceID: "ns"}
+	cases := []struct {
+		name    string
+		spec    DecofileSpec
+		wantErr string
+	}{
+		{"inline", DecofileSpec{Source: SourceInline, Inline: inline}, ""},
+		{"github", DecofileSpec{Source: SourceGitHub, GitHub: gh}, ""},
+		{"github to s3", DecofileSpec{Source: SourceGitHub, GitHub: gh, Target: TargetS3}, ""},
+		{"inline to s3", DecofileSpec{Source: SourceInline, Inline: inline, Target: TargetS3}, ""},
+		{"github to tanstack-kv", DecofileSpec{Source: SourceGitHub, GitHub: gh, Target: TargetTanstackKV, TanstackKV: kv}, ""},
+		{"unknown source", DecofileSpec{Source: "gcs"}, `unknown source "gcs"`},
+		{"inline without block", DecofileSpec{Source: SourceInline}, "spec.inline is required"},
+		{"inline with github block", DecofileSpec{Source: SourceInline, Inline: inline, GitHub: gh}, "spec.github must not be set"},
+		{"github without block", DecofileSpec{Source: SourceGitHub}, "spec.github is required"},
+		{"github with inline block", DecofileSpec{Source: SourceGitHub, GitHub: gh, Inline: inline}, "spec.inline must not be set"},
+		{"github missing fields", DecofileSpec{Source: SourceGitHub, GitHub: &GitHubSource{Org: "deco-sites"}},
+			"spec.github.repo, spec.github.commit, spec.github.path required"},
+		{"github paths only", DecofileSpec{Source: SourceGitHub, GitHub: &GitHubSource{Org: "o", Repo: "r", Commit: "c",
+			Paths: []string{"content", "sections"}}}, ""},
+		{"github empty path entry", DecofileSpec{Source: SourceGitHub, GitHub: &GitHubSource{Org: "o", Repo: "r", Commit: "c",
+			Paths: []string{"content", ""}}}, "spec.github.paths[1] must not be empty"},
+		{"tanstack-kv without block", DecofileSpec{Source: SourceGitHub, GitHub: gh, Target: TargetTanstackKV}, "spec.tanstackKV is required"},
+		{"tanstack-kv from inline", DecofileSpec{Source: SourceInline, Inline: inline, Target: TargetTanstackKV, TanstackKV: kv},
+			`source must be "github"`},
+		{"org with spaces", DecofileSpec{Source: SourceGitHub, GitHub: &GitHubSource{Org: "deco sites", Repo: "r", Commit: "c", Path: "p"}},
+			"spec.github.org"},
+		{"repo with scheme", DecofileSpec{Source: SourceGitHub, GitHub: &GitHubSource{Org: "o", Repo: "https://github.com/o/r", Commit: "c", Path: "p"}},
+			"spec.github.repo"},
+		{"commit with spaces", DecofileSpec{Source: SourceGitHub, GitHub: &GitHubSource{Org: "o", Repo: "r", Commit: "main; rm", Path: "p"}},
+			"spec.github.commit"},
+		{"github layers", DecofileSpec{Source: SourceGitHub, GitHub: &GitHubSource{Org: "o", Repo: "r", Commit: "c", Path: "p",
+			Layers: []GitHubLayer{{Commit: "feat/x"}, {Commit: "abc123", Path: "overrides"}}}}, ""},
+		{"github layer with bad commit", DecofileSpec{Source: SourceGitHub, GitHub: &GitHubSource{Org: "o", Repo: "r", Commit: "c", Path: "p",
+			Layers: []GitHubLayer{{Commit: "feat x"}}}}, "spec.github.layers[0].commit"},
+		{"negative max depth", DecofileSpec{Source: SourceGitHub, GitHub: &GitHubSource{Org: "o", Repo: "r", Commit: "c", Path: "p",
+			MaxDepth: -1}}, "spec.github.maxDepth"},
+		{"incremental with layers", DecofileSpec{Source: SourceGitHub, GitHub: &GitHubSource{Org: "o", Repo: "r", Commit: "c", Path: "p",
+			Incremental: true, Layers: []GitHubLayer{{Commit: "feat/x"}}}}, "spec.github.incremental"},
+		{"github refresh interval", DecofileSpec{Source: SourceGitHub, GitHub: &GitHubSource{Org: "o", Repo: "r", Commit: "main", Path: "p",
+			RefreshInterval: &metav1.Duration{Duration: time.Minute}}}, ""},
+		{"github refresh interval too short", DecofileSpec{Source: SourceGitHub, GitHub: &GitHubSource{Org: "o", Repo: "r", Commit: "main", Path: "p",
+			RefreshInterval: &metav1.Duration{Duration: time.Second}}}, "spec.github.refreshInterval must be at least 10s"},
+		{"branch ref", DecofileSpec{Source: SourceGitHub, GitHub: &GitHubSource{Org: "o", Repo: "r", Commit: "refs/heads/feat/x", Path: "p"}}, ""},
+		{"custom timestamp key", DecofileSpec{Source: SourceInline, Inline: inline, TimestampKey: "version"}, ""},
+		{"timestamp key with a slash", DecofileSpec{Source: SourceInline, Inline: inline, TimestampKey: "meta/ts"}, "spec.timestampKey"},
+		{"timestamp key shadowing metadata", DecofileSpec{Source: SourceInline, Inline: inline, TimestampKey: "decofile.meta.json"}, "metadata key"},
+		{"timestamp key shadowing checksum", DecofileSpec{Source: SourceInline, Inline: inline, TimestampKey: "decofile.sha256"}, "checksum key"},
+		{"timestamp key shadowing content", DecofileSpec{Source: SourceInline, Inline: inline, TimestampKey: "decofile.bin"}, "content key"},
+		{"inline valueFrom", DecofileSpec{Source: SourceInline, Inline: &InlineSource{ValueFrom: []InlineValueSource{
+			{ConfigMapRef: ConfigMapReference{Name: "pages"}, Keys: []string{"home.json"}},
+		}}}, ""},
+		{"inline valueFrom without name", DecofileSpec{Source: SourceInline, Inline: &InlineSource{ValueFrom: []InlineValueSource{{}}}},
+			"spec.inline.valueFrom[0].configMapRef.name is required"},
+		{"published content", DecofileSpec{Source: SourceInline, Inline: inline, PublishContent: true}, ""},
+		{"published s3 content", DecofileSpec{Source: SourceInline, Inline: inline, Target: TargetS3, PublishContent: true},
+			"spec.publishContent requires target"},
+		{"orphan deletion policy", DecofileSpec{Source: SourceInline, Inline: inline, DeletionPolicy: DeletionOrphan}, ""},
+		{"configMap and http destinations", DecofileSpec{Source: SourceInline, Inline: inline, Destinations: []DestinationSpec{
+			{Name: "mirror", Type: DestinationConfigMap, ConfigMap: &ConfigMapDestination{Name: "mirror"}},
+			{Name: "redis", Type: DestinationHTTP, HTTP: &HTTPDestination{URL: "https://sync.example.com/decofile"}},
+		}}, ""},
+		{"duplicate destination name", DecofileSpec{Source: SourceInline, Inline: inline, Destinations: []DestinationSpec{
+			{Name: "a", Type: DestinationConfigMap, ConfigMap: &ConfigMapDestination{Name: "x"}},
+			{Name: "a", Type: DestinationConfigMap, ConfigMap: &ConfigMapDestination{Name: "y"}},
+		}}, `duplicate name "a"`},
+		{"http destination without url scheme", DecofileSpec{Source: SourceInline, Inline: inline, Destinations: []DestinationSpec{
+			{Name: "a", Type: DestinationHTTP, HTTP: &HTTPDestination{URL: "sync.example.com"}},
+		}}, "absolute http(s) URL"},
+		{"configMap destination without name", DecofileSpec{Source: SourceInline, Inline: inline, Destinations: []DestinationSpec{
+			{Name: "a", Type: DestinationConfigMap},
+		}}, "requires configMap.name"},
+		{"unknown deletion policy", DecofileSpec{Source: SourceInline, Inline: inline, DeletionPolicy: "cascade"}, `unknown deletionPolicy "cascade"`},
+		{"unknown configmap ownership", DecofileSpec{Source: SourceInline, Inline: inline, ConfigMapOwnership: "shared"}, `unknown configMapOwnership "shared"`},
+		{"reload via PUT", DecofileSpec{Source: SourceInline, Inline: inline, ReloadMethod: "PUT"}, ""},
+		{"grpc transport", DecofileSpec{Source: SourceInline, Inline: inline, NotifyTransport: NotifyTransportGRPC}, ""},
+		{"grpc transport with reload method", DecofileSpec{Source: SourceInline, Inline: inline, NotifyTransport: NotifyTransportGRPC, ReloadMethod: "PUT"},
+			"spec.reloadMethod only applies"},
+		{"grpc transport with reload probe", DecofileSpec{Source: SourceInline, Inline: inline, NotifyTransport: NotifyTransportGRPC, ProbeReloadEndpoint: true},
+			"spec.probeReloadEndpoint requires"},
+		{"yaml inline format", DecofileSpec{Source: SourceInline, Inline: &InlineSource{Format: InlineFormatYAML}}, ""},
+		{"unknown inline format", DecofileSpec{Source: SourceInline, Inline: &InlineSource{Format: "toml"}}, `unknown spec.inline.format "toml"`},
+		{"sse transport", DecofileSpec{Source: SourceInline, Inline: inline, NotifyTransport: NotifyTransportSSE}, ""},
+		{"sse transport with reload method", DecofileSpec{Source: SourceInline, Inline: inline, NotifyTransport: NotifyTransportSSE, ReloadMethod: "GET"},
+			"spec.reloadMethod only applies"},
+		{"unknown notify transport", DecofileSpec{Source: SourceInline, Inline: inline, NotifyTransport: "ws"}, `unknown notifyTransport "ws"`},
+		{"pod selector", DecofileSpec{Source: SourceInline, Inline: inline, PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"version": "v2"}}}, ""},
+		{"invalid pod selector", DecofileSpec{Source: SourceInline, Inline: inline, PodSelector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "version", Operator: "Near"}}}},
+			"spec.podSelector"},
+		{"negative notify grace period", DecofileSpec{Source: SourceInline, Inline: inline, NotifyGracePeriod: &metav1.Duration{Duration: -time.Second}}, "spec.notifyGracePeriod must not be negative"},
+		{"empty required key", DecofileSpec{Source: SourceInline, Inline: inline, RequiredKeys: []string{"site.json", ""}}, "spec.requiredKeys must not contain an empty key"},
+		{"negative notification priority", DecofileSpec{Source: SourceInline, Inline: inline, NotificationPriority: -1}, "spec.notificationPriority must not be negative"},
+		{"unknown reload method", DecofileSpec{Source: SourceInline, Inline: inline, ReloadMethod: "PATCH"}, `unknown reloadMethod "PATCH"`},
+		{"custom reload path", DecofileSpec{Source: SourceInline, Inline: inline, ReloadPath: "/internal/reload"}, ""},
+		{"relative reload path", DecofileSpec{Source: SourceInline, Inline: inline, ReloadPath: "internal/reload"}, "must begin with /"},
+		{"grpc transport with reload path", DecofileSpec{Source: SourceInline, Inline: inline, NotifyTransport: NotifyTransportGRPC, ReloadPath: "/reload"},
+			"spec.reloadPath only applies"},
+		{"unknown target", DecofileSpec{Source: SourceInline, Inline: inline, Target: "secret"}, `unknown target "secret"`},
+		{"canary notification", DecofileSpec{Source: SourceInline, Inline: inline, NotificationStrategy: NotifyCanary,
+			Canary: &CanaryNotification{HealthPath: "/live"}}, ""},
+		{"unknown notification strategy", DecofileSpec{Source: SourceInline, Inline: inline, NotificationStrategy: "rolling"},
+			`unknown notificationStrategy "rolling"`},
+		{"relative canary health path", DecofileSpec{Source: SourceInline, Inline: inline, NotificationStrategy: NotifyCanary,
+			Canary: &CanaryNotification{HealthPath: "live"}}, "spec.canary.healthPath"},
+		{"knative-revision rollout", DecofileSpec{Source: SourceInline, Inline: inline, RolloutStrategy: RolloutKnativeRevision}, ""},
+		{"unknown rollout strategy", DecofileSpec{Source: SourceInline, Inline: inline, RolloutStrategy: "recreate"}, `unknown rolloutStrategy "recreate"`},
+		{"knative-revision rollout to tanstack-kv", DecofileSpec{Source: SourceGitHub, GitHub: gh, Target: TargetTanstackKV, TanstackKV: kv, RolloutStrategy: RolloutKnativeRevision},
+			"not supported when target is"},
+		{"composite", DecofileSpec{Source: SourceComposite, Composite: &CompositeSource{Sources: []CompositeSourceEntry{
+			{Source: SourceGitHub, GitHub: gh}, {Source: SourceInline, Inline: inline}}}}, ""},
+		{"composite without sources", DecofileSpec{Source: SourceComposite, Composite: &CompositeSource{}}, "spec.composite.sources is required"},
+		{"composite with invalid entry", DecofileSpec{Source: SourceComposite, Composite: &CompositeSource{Sources: []CompositeSourceEntry{
+			{Source: SourceInline, Inline: inline}, {Source: SourceGitHub}}}}, "spec.composite.sources[1]: spec.github is required"},
+		{"nested composite", DecofileSpec{Source: SourceComposite, Composite: &CompositeSource{Sources: []CompositeSourceEntry{
+			{Source: SourceComposite}}}}, "cannot be nested"},
+		{"composite block on inline source", DecofileSpec{Source: SourceInline, Inline: inline, Composite: &CompositeSource{}},
+			"spec.composite must not be set"},
+		{"file", DecofileSpec{Source: SourceFile, File: &FileSource{Paths: []string{"blocks", "shared/site.json"}}}, ""},
+		{"file without paths", DecofileSpec{Source: SourceFile, File: &FileSource{}}, "spec.file.paths is required"},
+		{"file with inline block", DecofileSpec{Source: SourceFile, File: &FileSource{Paths: []string{"a"}}, Inline: inline},
+			"must not be set"},
+		{"file path escaping", DecofileSpec{Source: SourceFile, File: &FileSource{Paths: []string{"../etc"}}}, `"../etc"`},
+		{"file absolute path", DecofileSpec{Source: SourceFile, File: &FileSource{Paths: []string{"/etc/passwd"}}}, `"/etc/passwd"`},
+		{"s3", DecofileSpec{Source: SourceS3, S3: &S3Source{Bucket: "decofiles", Prefix: "sites/foo/", Endpoint: "http://minio:9000"}}, ""},
+		{"s3 without bucket", DecofileSpec{Source: SourceS3, S3: &S3Source{Prefix: "p"}}, "spec.s3.bucket is required"},
+		{"s3 with github block", DecofileSpec{Source: SourceS3, S3: &S3Source{Bucket: "b"}, GitHub: gh}, "must not be set"},
+		{"s3 bad endpoint", DecofileSpec{Source: SourceS3, S3: &S3Source{Bucket: "b", Endpoint: "minio:9000"}}, "must be an http(s) URL"},
+		{"pvc", DecofileSpec{Source: SourcePVC, PVC: &PVCSource{ClaimName: "decofiles", Path: "my-site/blocks"}}, ""},
+		{"pvc whole volume", DecofileSpec{Source: SourcePVC, PVC: &PVCSource{ClaimName: "decofiles"}}, ""},
+		{"pvc without claim", DecofileSpec{Source: SourcePVC, PVC: &PVCSource{Path: "a"}}, "spec.pvc.claimName is required"},
+		{"pvc claim escaping", DecofileSpec{Source: SourcePVC, PVC: &PVCSource{ClaimName: ".."}}, "not a valid claim name"},
+		{"pvc path escaping", DecofileSpec{Source: SourcePVC, PVC: &PVCSource{ClaimName: "c", Path: "../etc"}}, `"../etc"`},
+		{"gzip compression", DecofileSpec{Source: SourceInline, Inline: inline, Compression: CompressionGzip}, ""},
+		{"unknown compression", DecofileSpec{Source: SourceInline, Inline: inline, Compression: "zstd"}, `unknown compression "zstd"`},
+		{"s3 block on inline source", DecofileSpec{Source: SourceInline, Inline: inline, S3: &S3Source{Bucket: "b"}}, "spec.s3 must not be set"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.spec.Validate()
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("Validate() = %v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestGitHubSourceNormalize(t *testing.T) {
+	cases := []struct {
+		org, repo         string
+		wantOrg, wantRepo string
+	}{
+		{"deco-sites", "storefront", "deco-sites", "storefront"},
+		{"https://github.com/deco-sites", "storefront.git", "deco-sites", "storefront"},
+		{"https://github.com/deco-sites/", "https://github.com/deco-sites/storefront/", "deco-sites", "storefront"},
+		{"github.com/deco-sites", "deco-sites/storefront.git", "deco-sites", "storefront"},
+		{"git@github.com:deco-sites", "git@github.com:deco-sites/storefront.git", "deco-sites", "storefront"},
+		{" deco-sites ", "storefront/ ", "deco-sites", "storefront"},
+		{"HTTPS://WWW.GITHUB.COM/deco-sites", "storefront", "deco-sites", "storefront"},
+	}
+	for _, tc := range cases {
+		g := &GitHubSource{Org: tc.org, Repo: tc.repo}
+		g.Normalize()
+		if g.Org != tc.wantOrg || g.Repo != tc.wantRepo {
+			t.Errorf("Normalize(%q, %q) = (%q, %q), want (%q, %q)",
+				tc.org, tc.repo, g.Org, g.Repo, tc.wantOrg, tc.wantRepo)
+		}
+	}
+}
+
+func TestGitHubSourceAllPaths(t *testing.T) {
+	cases := []struct {
+		path  string
+		paths []string
+		want  []string
+	}{
+		{".deco/blocks", nil, []string{".deco/blocks"}},
+		{"", []string{"content", "sections"}, []string{"content", "sections"}},
+		{"content", []string{"sections", "content"}, []string{"content", "sections"}},
+	}
+	for _, tc := range cases {
+		g := &GitHubSource{Path: tc.path, Paths: tc.paths}
+		if got := g.AllPaths(); !slices.Equal(got, tc.want) {
+			t.Errorf("AllPaths(%q, %v) = %v, want %v", tc.path, tc.paths, got, tc.want)
+		}
+	}
+}
+
+func TestSelectDecofileCodec(t *testing.T) {
+	cases := map[string]string{
+		"":              CodecBrotli,
+		"br,gzip":       CodecBrotli,
+		"gzip, br":      CodecBrotli,
+		"gzip":          CodecGzip,
+		" GZIP ":        CodecGzip,
+		"none":          CodecIdentity,
+		"identity":      CodecIdentity,
+		"zstd,identity": CodecIdentity,
+	}
+	for supported, want := range cases {
+		if got := SelectDecofileCodec(supported); got != want {
+			t.Errorf("SelectDecofileCodec(%q) = %q, want %q", supported, got, want)
+		}
+	}
+}
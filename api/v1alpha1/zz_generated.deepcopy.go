@@ -26,6 +26,123 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryNotification) DeepCopyInto(out *CanaryNotification) {
+	*out = *in
+	if in.Delay != nil {
+		in, out := &in.Delay, &out.Delay
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryNotification.
+func (in *CanaryNotification) DeepCopy() *CanaryNotification {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryNotification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompositeSource) DeepCopyInto(out *CompositeSource) {
+	*out = *in
+	if in.Sources != nil {
+		in, out := &in.Sources, &out.Sources
+		*out = make([]CompositeSourceEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositeSource.
+func (in *CompositeSource) DeepCopy() *CompositeSource {
+	if in == nil {
+		return nil
+	}
+	out := new(CompositeSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompositeSourceEntry) DeepCopyInto(out *CompositeSourceEntry) {
+	*out = *in
+	if in.Inline != nil {
+		in, out := &in.Inline, &out.Inline
+		*out = new(InlineSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GitHub != nil {
+		in, out := &in.GitHub, &out.GitHub
+		*out = new(GitHubSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.File != nil {
+		in, out := &in.File, &out.File
+		*out = new(FileSource)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositeSourceEntry.
+func (in *CompositeSourceEntry) DeepCopy() *CompositeSourceEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(CompositeSourceEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CompositeSourceStatus) DeepCopyInto(out *CompositeSourceStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CompositeSourceStatus.
+func (in *CompositeSourceStatus) DeepCopy() *CompositeSourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CompositeSourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapDestination) DeepCopyInto(out *ConfigMapDestination) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapDestination.
+func (in *ConfigMapDestination) DeepCopy() *ConfigMapDestination {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapDestination)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConfigMapReference) DeepCopyInto(out *ConfigMapReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConfigMapReference.
+func (in *ConfigMapReference) DeepCopy() *ConfigMapReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ConfigMapReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Deco) DeepCopyInto(out *Deco) {
 	*out = *in
@@ -526,13 +643,74 @@ func (in *DecofileSpec) DeepCopyInto(out *DecofileSpec) {
 	if in.GitHub != nil {
 		in, out := &in.GitHub, &out.GitHub
 		*out = new(GitHubSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.File != nil {
+		in, out := &in.File, &out.File
+		*out = new(FileSource)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(S3Source)
 		**out = **in
 	}
+	if in.PVC != nil {
+		in, out := &in.PVC, &out.PVC
+		*out = new(PVCSource)
+		**out = **in
+	}
+	if in.Composite != nil {
+		in, out := &in.Composite, &out.Composite
+		*out = new(CompositeSource)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.TanstackKV != nil {
 		in, out := &in.TanstackKV, &out.TanstackKV
 		*out = new(TanstackKVTarget)
 		**out = **in
 	}
+	if in.PodSelector != nil {
+		in, out := &in.PodSelector, &out.PodSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NotifyGracePeriod != nil {
+		in, out := &in.NotifyGracePeriod, &out.NotifyGracePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(CanaryNotification)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Transforms != nil {
+		in, out := &in.Transforms, &out.Transforms
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RequiredKeys != nil {
+		in, out := &in.RequiredKeys, &out.RequiredKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Destinations != nil {
+		in, out := &in.Destinations, &out.Destinations
+		*out = make([]DestinationSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StripExtensions != nil {
+		in, out := &in.StripExtensions, &out.StripExtensions
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PauseUntil != nil {
+		in, out := &in.PauseUntil, &out.PauseUntil
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DecofileSpec.
@@ -556,6 +734,28 @@ func (in *DecofileStatus) DeepCopyInto(out *DecofileStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.GitHubLayers != nil {
+		in, out := &in.GitHubLayers, &out.GitHubLayers
+		*out = make([]GitHubLayerStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.CompositeSources != nil {
+		in, out := &in.CompositeSources, &out.CompositeSources
+		*out = make([]CompositeSourceStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.PendingNotification != nil {
+		in, out := &in.PendingNotification, &out.PendingNotification
+		*out = new(PendingNotification)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]HistoryEntry, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DecofileStatus.
@@ -568,9 +768,99 @@ func (in *DecofileStatus) DeepCopy() *DecofileStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DestinationSpec) DeepCopyInto(out *DestinationSpec) {
+	*out = *in
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(ConfigMapDestination)
+		**out = **in
+	}
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = new(HTTPDestination)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DestinationSpec.
+func (in *DestinationSpec) DeepCopy() *DestinationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DestinationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FileSource) DeepCopyInto(out *FileSource) {
+	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileSource.
+func (in *FileSource) DeepCopy() *FileSource {
+	if in == nil {
+		return nil
+	}
+	out := new(FileSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitHubLayer) DeepCopyInto(out *GitHubLayer) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitHubLayer.
+func (in *GitHubLayer) DeepCopy() *GitHubLayer {
+	if in == nil {
+		return nil
+	}
+	out := new(GitHubLayer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GitHubLayerStatus) DeepCopyInto(out *GitHubLayerStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitHubLayerStatus.
+func (in *GitHubLayerStatus) DeepCopy() *GitHubLayerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GitHubLayerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GitHubSource) DeepCopyInto(out *GitHubSource) {
 	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Layers != nil {
+		in, out := &in.Layers, &out.Layers
+		*out = make([]GitHubLayer, len(*in))
+		copy(*out, *in)
+	}
+	if in.RefreshInterval != nil {
+		in, out := &in.RefreshInterval, &out.RefreshInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitHubSource.
@@ -583,6 +873,37 @@ func (in *GitHubSource) DeepCopy() *GitHubSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HTTPDestination) DeepCopyInto(out *HTTPDestination) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HTTPDestination.
+func (in *HTTPDestination) DeepCopy() *HTTPDestination {
+	if in == nil {
+		return nil
+	}
+	out := new(HTTPDestination)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HistoryEntry) DeepCopyInto(out *HistoryEntry) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HistoryEntry.
+func (in *HistoryEntry) DeepCopy() *HistoryEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(HistoryEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InlineSource) DeepCopyInto(out *InlineSource) {
 	*out = *in
@@ -593,6 +914,20 @@ func (in *InlineSource) DeepCopyInto(out *InlineSource) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.ValueFrom != nil {
+		in, out := &in.ValueFrom, &out.ValueFrom
+		*out = make([]InlineValueSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Overlay != nil {
+		in, out := &in.Overlay, &out.Overlay
+		*out = make(map[string]runtime.RawExtension, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InlineSource.
@@ -605,6 +940,73 @@ func (in *InlineSource) DeepCopy() *InlineSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InlineValueSource) DeepCopyInto(out *InlineValueSource) {
+	*out = *in
+	out.ConfigMapRef = in.ConfigMapRef
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InlineValueSource.
+func (in *InlineValueSource) DeepCopy() *InlineValueSource {
+	if in == nil {
+		return nil
+	}
+	out := new(InlineValueSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PVCSource) DeepCopyInto(out *PVCSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVCSource.
+func (in *PVCSource) DeepCopy() *PVCSource {
+	if in == nil {
+		return nil
+	}
+	out := new(PVCSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PendingNotification) DeepCopyInto(out *PendingNotification) {
+	*out = *in
+	in.NotifyAt.DeepCopyInto(&out.NotifyAt)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PendingNotification.
+func (in *PendingNotification) DeepCopy() *PendingNotification {
+	if in == nil {
+		return nil
+	}
+	out := new(PendingNotification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3Source) DeepCopyInto(out *S3Source) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3Source.
+func (in *S3Source) DeepCopy() *S3Source {
+	if in == nil {
+		return nil
+	}
+	out := new(S3Source)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TanstackKVTarget) DeepCopyInto(out *TanstackKVTarget) {
 	*out = *in
@@ -22,8 +22,10 @@ import (
 	"flag"
 	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -33,6 +35,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
@@ -51,6 +54,7 @@ import (
 	"github.com/deco-sites/decofile-operator/internal/build"
 	"github.com/deco-sites/decofile-operator/internal/controller"
 	"github.com/deco-sites/decofile-operator/internal/deploy"
+	"github.com/deco-sites/decofile-operator/internal/github"
 	"github.com/deco-sites/decofile-operator/internal/githubapp"
 	"github.com/deco-sites/decofile-operator/internal/valkey"
 	webhookv1 "github.com/deco-sites/decofile-operator/internal/webhook/v1"
@@ -98,6 +102,14 @@ func main() {
 	flag.StringVar(&webhookCertPath, "webhook-cert-path", "", "The directory that contains the webhook certificate.")
 	flag.StringVar(&webhookCertName, "webhook-cert-name", "tls.crt", "The name of the webhook certificate file.")
 	flag.StringVar(&webhookCertKey, "webhook-cert-key", "tls.key", "The name of the webhook key file.")
+	var webhookServiceDNSName string
+	flag.StringVar(&webhookServiceDNSName, "webhook-service-dns-name", os.Getenv("WEBHOOK_SERVICE_DNS_NAME"),
+		"DNS name of the webhook Service (<service>.<namespace>.svc) the serving certificate must be valid for; "+
+			"checked by the webhook-cert ready check. Empty skips the name check.")
+	var webhookCertMinValidity time.Duration
+	flag.DurationVar(&webhookCertMinValidity, "webhook-cert-min-validity",
+		parseDuration(os.Getenv("WEBHOOK_CERT_MIN_VALIDITY"), webhookv1.DefaultCertMinValidity),
+		"Fail readiness when the webhook serving certificate expires within this duration.")
 	flag.StringVar(&metricsCertPath, "metrics-cert-path", "",
 		"The directory that contains the metrics server certificate.")
 	flag.StringVar(&metricsCertName, "metrics-cert-name", "tls.crt", "The name of the metrics server certificate file.")
@@ -142,6 +154,158 @@ func main() {
 	flag.StringVar(&redirectBlockedIPv6, "redirect-blocked-ipv6",
 		getEnvOrDefault("REDIRECT_BLOCKED_IPV6", ""),
 		"Comma-separated IPv6 CIDRs that block cert issuance when present in a domain's AAAA records (e.g. 2600:1901::/32).")
+	var githubCABundle string
+	flag.StringVar(&githubCABundle, "github-ca-bundle",
+		getEnvOrDefault("GITHUB_CA_BUNDLE", ""),
+		"Path to a PEM CA bundle trusted, in addition to the system roots, for GitHub archive and LFS downloads.")
+	var githubDiskExtractThreshold int64
+	flag.Int64Var(&githubDiskExtractThreshold, "github-disk-extract-threshold",
+		parseInt64(os.Getenv("GITHUB_DISK_EXTRACT_THRESHOLD"), 0),
+		"Archive size in bytes above which GitHub sources are extracted to a temp directory "+
+			"instead of memory. 0 keeps everything in memory.")
+	var githubMaxConcurrentDownloads int
+	flag.IntVar(&githubMaxConcurrentDownloads, "github-max-concurrent-downloads",
+		int(parseInt64(os.Getenv("GITHUB_MAX_CONCURRENT_DOWNLOADS"), github.DefaultMaxConcurrentDownloads)),
+		"Maximum number of GitHub archive downloads in flight across all Decofile reconciles.")
+	var githubRPS float64
+	flag.Float64Var(&githubRPS, "github-rps",
+		parseFloat64(os.Getenv("GITHUB_RPS"), 0),
+		"Maximum requests per second to each GitHub host (API, archive and LFS downloads) across all Decofile "+
+			"reconciles; requests above it wait their turn. 0 disables the limit.")
+	var githubDefaultSecret string
+	flag.StringVar(&githubDefaultSecret, "github-default-secret",
+		getEnvOrDefault("GITHUB_DEFAULT_SECRET", ""),
+		"Secret (namespace/name, or a name in each Decofile's namespace) whose \"token\" key is used by github-source "+
+			"Decofiles without spec.github.secret. Empty falls back to the GITHUB_TOKEN env var.")
+	var githubCheckTokenScopes bool
+	flag.BoolVar(&githubCheckTokenScopes, "github-check-token-scopes",
+		os.Getenv("GITHUB_CHECK_TOKEN_SCOPES") == "true",
+		"On the first reconcile of each github-source Decofile generation, ask GitHub whether the token can read "+
+			"the repository and report a missing repo scope in the GitHubTokenScopes condition.")
+	var notifyPodConcurrency, notifyMaxConnsPerHost int
+	flag.IntVar(&notifyPodConcurrency, "notify-pod-concurrency",
+		int(parseInt64(os.Getenv("NOTIFY_POD_CONCURRENCY"), controller.DefaultNotifyPodConcurrency)),
+		"Number of pods a content change reloads in parallel.")
+	flag.IntVar(&notifyMaxConnsPerHost, "notify-max-conns-per-host",
+		int(parseInt64(os.Getenv("NOTIFY_MAX_CONNS_PER_HOST"), 0)),
+		"Maximum concurrent reload connections to a single host:port, independent of --notify-pod-concurrency "+
+			"(e.g. when reloads reach pods through a shared gateway). 0 disables the cap.")
+	var notifyMaxInFlight int
+	flag.IntVar(&notifyMaxInFlight, "notify-max-in-flight",
+		int(parseInt64(os.Getenv("NOTIFY_MAX_IN_FLIGHT"), 0)),
+		"Maximum reload requests in flight across all Decofiles at once, so many Decofiles changing together "+
+			"can't add --notify-pod-concurrency up to thousands of connections. 0 disables the cap.")
+	var notifyScheme string
+	flag.StringVar(&notifyScheme, "notify-scheme", getEnvOrDefault("NOTIFY_SCHEME", controller.NotifySchemeHTTP),
+		"URL scheme of reload requests, reload probes and canary health checks sent to pods: http or https.")
+	var notifyTLSCABundle, notifyTLSServerName string
+	var notifyTLSInsecureSkipVerify bool
+	flag.StringVar(&notifyTLSCABundle, "notify-tls-ca-bundle", os.Getenv("NOTIFY_TLS_CA_BUNDLE"),
+		"Path to a PEM CA bundle trusted, in addition to the system roots, for pods' certificates with --notify-scheme=https.")
+	flag.StringVar(&notifyTLSServerName, "notify-tls-server-name", os.Getenv("NOTIFY_TLS_SERVER_NAME"),
+		"Name pods' certificates are verified for with --notify-scheme=https, instead of the pod IP.")
+	flag.BoolVar(&notifyTLSInsecureSkipVerify, "notify-tls-insecure-skip-verify",
+		os.Getenv("NOTIFY_TLS_INSECURE_SKIP_VERIFY") == "true",
+		"Don't verify pods' certificates with --notify-scheme=https.")
+	var notifyReadyPodsOnly bool
+	flag.BoolVar(&notifyReadyPodsOnly, "notify-ready-pods-only",
+		os.Getenv("NOTIFY_READY_PODS_ONLY") != "false",
+		"Skip pods that are running but not Ready when notifying content changes; they are notified once they "+
+			"become Ready. Set NOTIFY_READY_PODS_ONLY=false to notify every running pod.")
+	var decofileMaxJSONDepth, decofileMaxJSONKeys int
+	flag.IntVar(&decofileMaxJSONDepth, "decofile-max-json-depth",
+		int(parseInt64(os.Getenv("DECOFILE_MAX_JSON_DEPTH"), controller.DefaultMaxJSONDepth)),
+		"Maximum nesting depth of Decofile content; deeper content is rejected.")
+	flag.IntVar(&decofileMaxJSONKeys, "decofile-max-json-keys",
+		int(parseInt64(os.Getenv("DECOFILE_MAX_JSON_KEYS"), controller.DefaultMaxJSONKeys)),
+		"Maximum total number of object keys in Decofile content; larger content is rejected.")
+	var decofileMaxConfigMapKeys, decofileMaxConfigMapBytes int
+	flag.IntVar(&decofileMaxConfigMapKeys, "decofile-max-configmap-keys",
+		int(parseInt64(os.Getenv("DECOFILE_MAX_CONFIGMAP_KEYS"), controller.DefaultMaxConfigMapKeys)),
+		"Maximum number of data keys in a Decofile's ConfigMap; larger ConfigMaps are rejected before writing.")
+	flag.IntVar(&decofileMaxConfigMapBytes, "decofile-max-configmap-bytes",
+		int(parseInt64(os.Getenv("DECOFILE_MAX_CONFIGMAP_BYTES"), controller.DefaultMaxConfigMapBytes)),
+		"Maximum total size, in bytes, of a Decofile's ConfigMap data; larger ConfigMaps are rejected before writing.")
+	var decofileBinaryData bool
+	flag.BoolVar(&decofileBinaryData, "decofile-binary-data", os.Getenv("DECOFILE_BINARY_DATA") != "false",
+		"Store the compressed decofile keys (decofile.bin, decofile.gz) as raw bytes in the ConfigMap's binaryData "+
+			"instead of base64 in data, about 25% smaller. Set to false for runtimes that don't read the "+
+			"encoding from decofile.meta.json yet.")
+	var githubBranchWatchInterval, githubBranchWatchMinInterval time.Duration
+	flag.DurationVar(&githubBranchWatchInterval, "github-branch-watch-interval",
+		parseDuration(os.Getenv("GITHUB_BRANCH_WATCH_INTERVAL"), 0),
+		"Enable the GitHub events watcher for Decofiles that track a branch; also the polling interval "+
+			"used when the events feed fails. 0 disables the watcher.")
+	flag.DurationVar(&githubBranchWatchMinInterval, "github-branch-watch-min-interval",
+		parseDuration(os.Getenv("GITHUB_BRANCH_WATCH_MIN_INTERVAL"), controller.DefaultBranchWatchMinInterval),
+		"Shortest gap between two events polls of the same repository.")
+	var configMapGCInterval time.Duration
+	flag.DurationVar(&configMapGCInterval, "configmap-gc-interval",
+		parseDuration(os.Getenv("CONFIGMAP_GC_INTERVAL"), controller.DefaultConfigMapGCInterval),
+		"How often to sweep for decofile ConfigMaps whose Decofile no longer exists. 0 disables the sweep.")
+	var configMapGCDryRun bool
+	flag.BoolVar(&configMapGCDryRun, "configmap-gc-dry-run",
+		os.Getenv("CONFIGMAP_GC_DRY_RUN") == "true",
+		"Log and count orphaned decofile ConfigMaps without deleting them.")
+	var repairServiceInjection bool
+	flag.BoolVar(&repairServiceInjection, "repair-service-injection",
+		os.Getenv("REPAIR_SERVICE_INJECTION") == "true",
+		"Watch Services annotated deco.sites/decofile-inject and re-run the Service webhook on those whose "+
+			"injected volume, mount or env vars went missing. Patches Services.")
+	var decofileReconcileStaleAfter time.Duration
+	flag.DurationVar(&decofileReconcileStaleAfter, "decofile-reconcile-stale-after",
+		parseDuration(os.Getenv("DECOFILE_RECONCILE_STALE_AFTER"), 0),
+		"Fail readiness on the leader when no Decofile has been reconciled for this long. "+
+			"Should exceed the sync period. 0 disables the check.")
+	var fileSourceDir string
+	flag.StringVar(&fileSourceDir, "decofile-file-source-dir", os.Getenv("DECOFILE_FILE_SOURCE_DIR"),
+		"Directory in the operator container that source=file Decofiles read from, e.g. config baked into the "+
+			"image for air-gapped clusters. Empty disables the file source.")
+	var pvcSourceDir string
+	flag.StringVar(&pvcSourceDir, "decofile-pvc-source-dir", os.Getenv("DECOFILE_PVC_SOURCE_DIR"),
+		"Directory in the operator container that source=pvc claims are mounted under, each at <dir>/<claimName>. "+
+			"Empty disables the pvc source.")
+	var reconcileTimeout time.Duration
+	flag.DurationVar(&reconcileTimeout, "decofile-reconcile-timeout",
+		parseDuration(os.Getenv("DECOFILE_RECONCILE_TIMEOUT"), controller.DefaultReconcileTimeout),
+		"Maximum duration of a single Decofile reconcile, including source download and pod notifications; "+
+			"a reconcile that runs over is cancelled and requeued. 0 disables the limit.")
+	var rolloutEventsWebhookURL string
+	flag.StringVar(&rolloutEventsWebhookURL, "rollout-events-webhook-url", os.Getenv("ROLLOUT_EVENTS_WEBHOOK_URL"),
+		"URL that a JSON event is POSTed to (with retries) when a Decofile's changed content reached its pods. "+
+			"Empty disables rollout events.")
+	var cachePurgeURL, cachePurgeAuthorization string
+	flag.StringVar(&cachePurgeURL, "cache-purge-url", os.Getenv("CACHE_PURGE_URL"),
+		"URL that a JSON purge request is POSTed to (with retries) when a Decofile's changed content was rolled out, "+
+			"so an external cache such as a CDN drops stale copies. Empty disables purges.")
+	flag.StringVar(&cachePurgeAuthorization, "cache-purge-authorization", os.Getenv("CACHE_PURGE_AUTHORIZATION"),
+		"Authorization header value sent with cache purges, e.g. \"Bearer <token>\".")
+	var notifyEventsAddr string
+	flag.StringVar(&notifyEventsAddr, "notify-events-bind-address", os.Getenv("NOTIFY_EVENTS_BIND_ADDRESS"),
+		"The address the Server-Sent Events stream for notifyTransport sse binds to (e.g. :9091). "+
+			"Empty disables it, and sse Decofiles fail to notify.")
+	var enableDebugEndpoints bool
+	flag.BoolVar(&enableDebugEndpoints, "enable-debug-endpoints",
+		os.Getenv("ENABLE_DEBUG_ENDPOINTS") == "true",
+		"Serve GET /debug/decofile/{ns}/{name} on the operator API (basic auth), dumping a Decofile's rendered content.")
+	var contentBaseURL string
+	flag.StringVar(&contentBaseURL, "content-base-url", os.Getenv("CONTENT_BASE_URL"),
+		"External URL of the operator API (e.g. https://operator.example.com), prefixed to the status.contentURL of "+
+			"Decofiles with spec.publishContent. Empty records a path relative to the operator API.")
+	var decofileCreateQPS float64
+	var decofileCreateBurst int
+	flag.Float64Var(&decofileCreateQPS, "decofile-create-qps",
+		parseFloat64(os.Getenv("DECOFILE_CREATE_QPS"), 0),
+		"Rate of Decofile creates admitted per second by each webhook replica; bursts above it are "+
+			"held briefly, then rejected with 429. 0 disables throttling.")
+	flag.IntVar(&decofileCreateBurst, "decofile-create-burst",
+		int(parseInt64(os.Getenv("DECOFILE_CREATE_BURST"), webhookv1.DefaultDecofileCreateBurst)),
+		"Decofile creates admitted at once before --decofile-create-qps applies.")
+	var deploymentIdLabel string
+	flag.StringVar(&deploymentIdLabel, "deployment-id-label",
+		getEnvOrDefault("DEPLOYMENT_ID_LABEL", controller.DefaultDeploymentIdLabel),
+		"Label key holding a Service's deploymentId, stamped by the Service webhook on pod templates and used "+
+			"by the controller to select the pods to notify. Must be the same for the controller and the webhook.")
 	var controllersFlag string
 	flag.StringVar(&controllersFlag, "controllers", "*",
 		"Comma-separated list of controllers to enable. Use \"*\" to enable all. Valid values: "+
@@ -150,15 +314,72 @@ func main() {
 		Development: false,
 	}
 	opts.BindFlags(flag.CommandLine)
-	flag.Parse()
+	mode, args, err := parseMode(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	_ = flag.CommandLine.Parse(args)
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	github.SetMaxConcurrentDownloads(githubMaxConcurrentDownloads)
+	github.SetRequestsPerSecond(githubRPS)
+	if githubCABundle != "" {
+		bundle, err := os.ReadFile(githubCABundle)
+		if err == nil {
+			err = github.SetCABundle(bundle)
+		}
+		if err != nil {
+			setupLog.Error(err, "unable to load GitHub CA bundle", "path", githubCABundle)
+			os.Exit(1)
+		}
+	}
+
+	var notifyTLSConfig *tls.Config
+	switch notifyScheme {
+	case controller.NotifySchemeHTTP:
+	case controller.NotifySchemeHTTPS:
+		var bundle []byte
+		if notifyTLSCABundle != "" {
+			var err error
+			if bundle, err = os.ReadFile(notifyTLSCABundle); err != nil {
+				setupLog.Error(err, "unable to load notify CA bundle", "path", notifyTLSCABundle)
+				os.Exit(1)
+			}
+		}
+		tlsConfig, err := controller.NewNotifyTLSConfig(bundle, notifyTLSServerName, notifyTLSInsecureSkipVerify)
+		if err != nil {
+			setupLog.Error(err, "unable to load notify CA bundle", "path", notifyTLSCABundle)
+			os.Exit(1)
+		}
+		notifyTLSConfig = tlsConfig
+	default:
+		setupLog.Error(fmt.Errorf("unknown scheme %q (must be http or https)", notifyScheme), "invalid --notify-scheme")
+		os.Exit(1)
+	}
+
+	if githubDefaultSecret != "" {
+		if _, err := controller.ParseGitHubSecretRef(githubDefaultSecret, "default"); err != nil {
+			setupLog.Error(err, "invalid --github-default-secret")
+			os.Exit(1)
+		}
+	}
+
+	if errs := validation.IsQualifiedName(deploymentIdLabel); len(errs) > 0 {
+		setupLog.Error(fmt.Errorf("%s", strings.Join(errs, "; ")), "invalid --deployment-id-label", "label", deploymentIdLabel)
+		os.Exit(1)
+	}
+
 	enabled, err := parseControllers(controllersFlag)
 	if err != nil {
 		setupLog.Error(err, "invalid --controllers flag")
 		os.Exit(1)
 	}
+	runControllers := mode != modeWebhook
+	// nolint:goconst
+	runWebhooks := mode != modeController && os.Getenv("ENABLE_WEBHOOKS") != "false"
+	setupLog.Info("Run mode", "mode", mode, "controllers", runControllers, "webhooks", runWebhooks)
 
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
@@ -181,7 +402,7 @@ func main() {
 	// Initial webhook TLS options
 	webhookTLSOpts := tlsOpts
 
-	if len(webhookCertPath) > 0 {
+	if len(webhookCertPath) > 0 && runWebhooks {
 		setupLog.Info("Initializing webhook certificate watcher using provided certificates",
 			"webhook-cert-path", webhookCertPath, "webhook-cert-name", webhookCertName, "webhook-cert-key", webhookCertKey)
 
@@ -254,7 +475,7 @@ func main() {
 		Metrics:                metricsServerOptions,
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
-		LeaderElection:         enableLeaderElection,
+		LeaderElection:         enableLeaderElection && runControllers,
 		LeaderElectionID:       "1e708737.deco.sites",
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
 		// when the Manager ends. This requires the binary to immediately end when the
@@ -273,7 +494,7 @@ func main() {
 		os.Exit(1)
 	}
 
-	if enabled(controller.TenantControllerName) {
+	if runControllers && enabled(controller.TenantControllerName) {
 		var valkeyClient valkey.Client
 		switch {
 		case valkeyURL != "":
@@ -336,8 +557,25 @@ func main() {
 		}
 	}
 
-	if enabled(controller.DecofileControllerName) {
-		httpClient := controller.NewHTTPClient()
+	if runControllers && enabled(controller.DecofileControllerName) {
+		notifyOpts := controller.NotifyOptions{
+			DeploymentIdLabel: deploymentIdLabel,
+			ReadyPodsOnly:     notifyReadyPodsOnly,
+			PodConcurrency:    notifyPodConcurrency,
+			MaxConnsPerHost:   notifyMaxConnsPerHost,
+			Scheme:            notifyScheme,
+			TLSConfig:         notifyTLSConfig,
+		}
+		if notifyMaxInFlight > 0 {
+			notifyOpts.InFlight = make(chan struct{}, notifyMaxInFlight)
+		}
+		httpClient := controller.NewHTTPClient(notifyOpts)
+		sourceOpts := controller.SourceOptions{
+			FileDir:                    fileSourceDir,
+			PVCDir:                     pvcSourceDir,
+			DefaultGitHubSecret:        githubDefaultSecret,
+			GitHubDiskExtractThreshold: githubDiskExtractThreshold,
+		}
 		// Fast-deploy: pluggable FastDeployment strategies for non-configmap
 		// Decofile targets. tanstack-kv runs a self-cleaning Job that pushes the
 		// decofile to Cloudflare KV (config from env; inert unless a Decofile
@@ -365,30 +603,123 @@ func main() {
 		} else if s3Uploader != nil {
 			setupLog.Info("decofile s3 target enabled")
 		}
+		var rolloutEvents *controller.RolloutEventSink
+		if rolloutEventsWebhookURL != "" {
+			rolloutEvents = &controller.RolloutEventSink{
+				URL:        rolloutEventsWebhookURL,
+				HTTPClient: &http.Client{Timeout: 10 * time.Second},
+			}
+			setupLog.Info("Rollout events enabled")
+		}
+		var cachePurger *controller.CachePurger
+		if cachePurgeURL != "" {
+			cachePurger = &controller.CachePurger{
+				URL:           cachePurgeURL,
+				Authorization: cachePurgeAuthorization,
+				HTTPClient:    &http.Client{Timeout: 10 * time.Second},
+			}
+			setupLog.Info("Cache purges enabled")
+		}
+		var eventStream *controller.EventStream
+		if notifyEventsAddr != "" {
+			eventStream = controller.NewEventStream(mgr.GetClient(), notifyEventsAddr)
+			eventStream.DeploymentIdLabel = deploymentIdLabel
+			if err = mgr.Add(eventStream); err != nil {
+				setupLog.Error(err, "unable to add notify event stream")
+				os.Exit(1)
+			}
+			setupLog.Info("Notify event stream enabled", "addr", notifyEventsAddr)
+		}
 		if err = (&controller.DecofileReconciler{
+			Client:                 mgr.GetClient(),
+			Scheme:                 mgr.GetScheme(),
+			HTTPClient:             httpClient,
+			FastDeploy:             fastDeployRegistry,
+			S3:                     s3Uploader,
+			Transformers:           controller.NewDefaultTransformerRegistry(),
+			MaxJSONDepth:           decofileMaxJSONDepth,
+			MaxJSONKeys:            decofileMaxJSONKeys,
+			MaxConfigMapKeys:       decofileMaxConfigMapKeys,
+			MaxConfigMapBytes:      decofileMaxConfigMapBytes,
+			ReconcileTimeout:       reconcileTimeout,
+			RolloutEvents:          rolloutEvents,
+			CachePurger:            cachePurger,
+			Recorder:               mgr.GetEventRecorderFor("decofile-controller"),
+			EventStream:            eventStream,
+			Sources:                sourceOpts,
+			Notify:                 notifyOpts,
+			ContentBaseURL:         strings.TrimSuffix(contentBaseURL, "/"),
+			CheckGitHubTokenScopes: githubCheckTokenScopes,
+			Base64ConfigMapData:    !decofileBinaryData,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Decofile")
+			os.Exit(1)
+		}
+		if err = (&controller.DecofilePodReconciler{
 			Client:     mgr.GetClient(),
-			Scheme:     mgr.GetScheme(),
 			HTTPClient: httpClient,
-			FastDeploy: fastDeployRegistry,
-			S3:         s3Uploader,
+			Notify:     notifyOpts,
 		}).SetupWithManager(mgr); err != nil {
-			setupLog.Error(err, "unable to create controller", "controller", "Decofile")
+			setupLog.Error(err, "unable to create controller", "controller", "DecofilePod")
 			os.Exit(1)
 		}
-		// nolint:goconst
-		if os.Getenv("ENABLE_WEBHOOKS") != "false" {
-			if err = webhookv1.SetupServiceWebhookWithManager(mgr); err != nil {
-				setupLog.Error(err, "unable to create webhook", "webhook", "Service")
+		if configMapGCInterval > 0 {
+			if err = mgr.Add(&controller.ConfigMapGC{
+				Client:   mgr.GetClient(),
+				Interval: configMapGCInterval,
+				DryRun:   configMapGCDryRun,
+			}); err != nil {
+				setupLog.Error(err, "unable to add orphaned ConfigMap GC")
+				os.Exit(1)
+			}
+			setupLog.Info("Orphaned ConfigMap GC enabled", "interval", configMapGCInterval, "dryRun", configMapGCDryRun)
+		}
+		if githubBranchWatchInterval > 0 {
+			if err = mgr.Add(&controller.GitHubBranchWatcher{
+				Client:              mgr.GetClient(),
+				Interval:            githubBranchWatchInterval,
+				MinInterval:         githubBranchWatchMinInterval,
+				DefaultGitHubSecret: githubDefaultSecret,
+			}); err != nil {
+				setupLog.Error(err, "unable to add GitHub branch watcher")
 				os.Exit(1)
 			}
-			if err = webhookv1.SetupDecofileWebhookWithManager(mgr); err != nil {
-				setupLog.Error(err, "unable to create webhook", "webhook", "Decofile")
+			setupLog.Info("GitHub branch watcher enabled",
+				"interval", githubBranchWatchInterval, "minInterval", githubBranchWatchMinInterval)
+		}
+		if repairServiceInjection {
+			if err = (&controller.ServiceInjectionReconciler{
+				Client:            mgr.GetClient(),
+				DeploymentIdLabel: deploymentIdLabel,
+			}).SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create controller", "controller", "ServiceInjection")
 				os.Exit(1)
 			}
+			setupLog.Info("Service injection repair enabled")
 		}
 	}
 
-	if enabled(controller.DecoControllerName) {
+	if runWebhooks && enabled(controller.DecofileControllerName) {
+		if err = webhookv1.SetupServiceWebhookWithManager(mgr, deploymentIdLabel); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Service")
+			os.Exit(1)
+		}
+		if err = webhookv1.SetupDecofileWebhookWithManager(mgr, webhookv1.DecofileWebhookOptions{
+			DeploymentIdLabel:   deploymentIdLabel,
+			DefaultGitHubSecret: githubDefaultSecret,
+			CreateQPS:           decofileCreateQPS,
+			CreateBurst:         decofileCreateBurst,
+		}); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Decofile")
+			os.Exit(1)
+		}
+		if decofileCreateQPS > 0 {
+			setupLog.Info("Decofile create throttling enabled",
+				"qps", decofileCreateQPS, "burst", decofileCreateBurst)
+		}
+	}
+
+	if runControllers && enabled(controller.DecoControllerName) {
 		registry := build.NewBuilderRegistry()
 		registry.Register("cloudflare-worker", build.NewCloudflareFactory(build.CfWorkersConfigFromEnv()))
 		builderSAAnnotations := map[string]string{}
@@ -406,7 +737,7 @@ func main() {
 		}
 	}
 
-	if enabled(controller.DecoRedirectControllerName) {
+	if runControllers && enabled(controller.DecoRedirectControllerName) {
 		var blockedIPv6CIDRs []*net.IPNet
 		for _, cidr := range strings.Split(redirectBlockedIPv6, ",") {
 			cidr = strings.TrimSpace(cidr)
@@ -432,7 +763,7 @@ func main() {
 		}
 	}
 
-	if enabled(api.ControllerName) {
+	if runControllers && enabled(api.ControllerName) {
 		apiUser := os.Getenv("OPERATOR_API_USER")
 		apiPass := os.Getenv("OPERATOR_API_PASSWORD")
 
@@ -449,6 +780,13 @@ func main() {
 		// the webhook (secret) is configured.
 		if (apiUser != "" && apiPass != "") || webhookHandlers.Enabled() {
 			h := api.NewHandlers(mgr.GetClient(), redirectNamespace)
+			if enableDebugEndpoints {
+				if apiUser == "" || apiPass == "" {
+					setupLog.Info("--enable-debug-endpoints ignored: OPERATOR_API_USER/OPERATOR_API_PASSWORD are not set")
+				} else {
+					h.WithDebugEndpoints()
+				}
+			}
 			if err = mgr.Add(api.NewServer(operatorAPIAddr, apiUser, apiPass, h, webhookHandlers)); err != nil {
 				setupLog.Error(err, "unable to add operator API server")
 				os.Exit(1)
@@ -483,6 +821,20 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	if webhookCertWatcher != nil {
+		if err := mgr.AddReadyzCheck("webhook-cert",
+			webhookv1.CertificateReadyCheck(webhookCertWatcher.GetCertificate, webhookServiceDNSName, webhookCertMinValidity)); err != nil {
+			setupLog.Error(err, "unable to set up webhook certificate ready check")
+			os.Exit(1)
+		}
+	}
+	if decofileReconcileStaleAfter > 0 && runControllers && enabled(controller.DecofileControllerName) {
+		if err := mgr.AddReadyzCheck("decofile-reconcile",
+			controller.DecofileReconcileReadyCheck(decofileReconcileStaleAfter, mgr.Elected())); err != nil {
+			setupLog.Error(err, "unable to set up decofile reconcile ready check")
+			os.Exit(1)
+		}
+	}
 
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
@@ -517,6 +869,28 @@ func parseDuration(s string, fallback time.Duration) time.Duration {
 	return d
 }
 
+func parseInt64(s string, fallback int64) int64 {
+	if s == "" {
+		return fallback
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fallback
+	}
+	return n
+}
+
+func parseFloat64(s string, fallback float64) float64 {
+	if s == "" {
+		return fallback
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return fallback
+	}
+	return f
+}
+
 func getEnvOrDefault(key, defaultVal string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Run modes, chosen by the first argument. `manager controller` runs the
+// controllers and their runnables (including the operator API) but serves no
+// admission webhooks; `manager webhook` serves only the webhooks, without
+// leader election, so it can be scaled for latency on its own. Without a
+// subcommand both run in one process.
+const (
+	modeAll        = "all"
+	modeController = "controller"
+	modeWebhook    = "webhook"
+)
+
+// parseMode splits the optional subcommand off the command-line arguments
+// (without the program name) and returns the mode and the remaining flags.
+func parseMode(args []string) (string, []string, error) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return modeAll, args, nil
+	}
+	switch args[0] {
+	case modeAll, modeController, modeWebhook:
+		return args[0], args[1:], nil
+	}
+	return "", nil, fmt.Errorf("unknown subcommand %q; valid values: %s, %s, %s",
+		args[0], modeController, modeWebhook, modeAll)
+}
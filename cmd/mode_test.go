@@ -0,0 +1,36 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseMode(t *testing.T) {
+	cases := []struct {
+		args     []string
+		wantMode string
+		wantRest []string
+	}{
+		{nil, modeAll, nil},
+		{[]string{"--leader-elect"}, modeAll, []string{"--leader-elect"}},
+		{[]string{"controller", "--leader-elect"}, modeController, []string{"--leader-elect"}},
+		{[]string{"webhook"}, modeWebhook, []string{}},
+		{[]string{"all", "-zap-devel"}, modeAll, []string{"-zap-devel"}},
+	}
+	for _, tc := range cases {
+		mode, rest, err := parseMode(tc.args)
+		if err != nil {
+			t.Errorf("parseMode(%q): unexpected error: %v", tc.args, err)
+			continue
+		}
+		if mode != tc.wantMode || !slices.Equal(rest, tc.wantRest) {
+			t.Errorf("parseMode(%q) = %q, %q; want %q, %q", tc.args, mode, rest, tc.wantMode, tc.wantRest)
+		}
+	}
+}
+
+func TestParseMode_Unknown(t *testing.T) {
+	if _, _, err := parseMode([]string{"webhooks"}); err == nil {
+		t.Error("expected an error for an unknown subcommand")
+	}
+}
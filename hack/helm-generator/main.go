@@ -123,6 +123,9 @@ func main() {
 	if err := addControllersArg(templatesDir); err != nil {
 		fmt.Fprintf(os.Stderr, "Warning: Could not add controllers arg: %v\n", err)
 	}
+	if err := addWebhookDeployment(templatesDir); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: Could not add webhook deployment: %v\n", err)
+	}
 
 	fmt.Printf("✓ Generated %d Helm templates\n\n", fileCount)
 	fmt.Println("Test with:")
@@ -582,3 +585,149 @@ spec:
 `
 	return os.WriteFile(filepath.Join(templatesDir, "ingress-operator-api.yaml"), []byte(content), 0644)
 }
+
+// addWebhookDeployment splits the admission webhooks off the controller
+// manager when webhook.separateDeployment is set: the manager runs
+// `manager controller`, a second Deployment runs `manager webhook`, and the
+// webhook Service selects the latter.
+func addWebhookDeployment(templatesDir string) error {
+	managerFile := filepath.Join(templatesDir, "deployment-operator-controller-manager.yaml")
+	content, err := os.ReadFile(managerFile)
+	if err != nil {
+		return err
+	}
+	anchor := "      - args:\n"
+	if !strings.Contains(string(content), anchor) {
+		return fmt.Errorf("anchor %q not found in %s", anchor, managerFile)
+	}
+	modeArg := `        {{- if .Values.webhook.separateDeployment }}
+        - controller
+        {{- end }}
+`
+	contentStr := strings.Replace(string(content), anchor, anchor+modeArg, 1)
+	if err := os.WriteFile(managerFile, []byte(contentStr), 0644); err != nil {
+		return err
+	}
+
+	serviceFile := filepath.Join(templatesDir, "service-operator-webhook-service.yaml")
+	content, err = os.ReadFile(serviceFile)
+	if err != nil {
+		return err
+	}
+	selector := "  selector:\n    app.kubernetes.io/name: operator\n    control-plane: controller-manager"
+	if !strings.Contains(string(content), selector) {
+		return fmt.Errorf("selector not found in %s", serviceFile)
+	}
+	contentStr = strings.Replace(string(content), selector, `  selector:
+    app.kubernetes.io/name: operator
+    {{- if .Values.webhook.separateDeployment }}
+    control-plane: webhook
+    {{- else }}
+    control-plane: controller-manager
+    {{- end }}`, 1)
+	if err := os.WriteFile(serviceFile, []byte(contentStr), 0644); err != nil {
+		return err
+	}
+
+	deployment := `{{- if and .Values.webhook.enabled .Values.webhook.separateDeployment }}
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  labels:
+    app.kubernetes.io/name: operator
+    control-plane: webhook
+  name: {{ .Release.Name }}-webhook
+  namespace: {{ .Release.Namespace }}
+spec:
+  replicas: {{ .Values.webhook.replicaCount }}
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: operator
+      control-plane: webhook
+  template:
+    metadata:
+      annotations:
+        kubectl.kubernetes.io/default-container: manager
+        {{- with .Values.podAnnotations }}
+        {{- toYaml . | nindent 8 }}
+        {{- end }}
+      labels:
+        app.kubernetes.io/name: operator
+        control-plane: webhook
+    spec:
+      containers:
+      - args:
+        - webhook
+        - --metrics-bind-address=:8080
+        - --metrics-secure=false
+        - --health-probe-bind-address=:8081
+        - --webhook-cert-path=/tmp/k8s-webhook-server/serving-certs
+        {{- if .Values.webhook.decofileCreateQPS }}
+        - --decofile-create-qps={{ .Values.webhook.decofileCreateQPS }}
+        - --decofile-create-burst={{ .Values.webhook.decofileCreateBurst | default 10 }}
+        {{- end }}
+        command:
+        - /manager
+        image: "{{ .Values.image.repository }}:{{ .Values.image.tag }}"
+        {{- if or (and .Values.decofileSidecar .Values.decofileSidecar.image) (and .Values.decofileS3 .Values.decofileS3.bucket) }}
+        env:
+        {{- if and .Values.decofileSidecar .Values.decofileSidecar.image }}
+        - name: DECOFILE_SIDECAR_IMAGE
+          value: {{ .Values.decofileSidecar.image | quote }}
+        {{- if .Values.decofileSidecar.port }}
+        - name: DECOFILE_SIDECAR_PORT
+          value: {{ .Values.decofileSidecar.port | quote }}
+        {{- end }}
+        {{- end }}
+        {{- if and .Values.decofileS3 .Values.decofileS3.bucket }}
+        - name: DECOFILE_S3_PUBLIC_HOST
+          value: {{ .Values.decofileS3.publicHost | quote }}
+        - name: DECOFILE_S3_PREFIX
+          value: {{ .Values.decofileS3.prefix | quote }}
+        {{- end }}
+        {{- end }}
+        livenessProbe:
+          httpGet:
+            path: /healthz
+            port: 8081
+          initialDelaySeconds: 15
+          periodSeconds: 20
+        name: manager
+        ports:
+        - containerPort: 8080
+          name: metrics
+          protocol: TCP
+        - containerPort: 9443
+          name: webhook-server
+          protocol: TCP
+        readinessProbe:
+          httpGet:
+            path: /readyz
+            port: 8081
+          initialDelaySeconds: 5
+          periodSeconds: 10
+        resources:
+          {{- toYaml .Values.webhook.resources | nindent 10 }}
+        securityContext:
+          allowPrivilegeEscalation: false
+          capabilities:
+            drop:
+            - ALL
+        volumeMounts:
+        - mountPath: /tmp/k8s-webhook-server/serving-certs
+          name: webhook-certs
+          readOnly: true
+      securityContext:
+        runAsNonRoot: true
+        seccompProfile:
+          type: RuntimeDefault
+      serviceAccountName: {{ .Release.Name }}-controller-manager
+      terminationGracePeriodSeconds: 10
+      volumes:
+      - name: webhook-certs
+        secret:
+          secretName: webhook-server-cert
+{{- end }}
+`
+	return os.WriteFile(filepath.Join(templatesDir, "deployment-operator-webhook.yaml"), []byte(deployment), 0644)
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+	"github.com/deco-sites/decofile-operator/internal/controller"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// decofileContent serves GET /decofile/{ns}/{name}: the rendered content of
+// a Decofile with spec.publishContent, read back from its ConfigMap so any
+// replica can answer. An optional rev query parameter must name the current
+// status.revision; an older one gets 410 Gone rather than newer content.
+func (h *Handlers) decofileContent(w http.ResponseWriter, r *http.Request) {
+	key := client.ObjectKey{Namespace: r.PathValue("ns"), Name: r.PathValue("name")}
+	df := &decositesv1alpha1.Decofile{}
+	if err := h.client.Get(r.Context(), key, df); err != nil {
+		status := http.StatusInternalServerError
+		if apierrors.IsNotFound(err) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	// Unpublished Decofiles are indistinguishable from missing ones
+	if !df.Spec.PublishContent {
+		http.Error(w, "decofile "+key.String()+" does not publish its content", http.StatusNotFound)
+		return
+	}
+	if rev := r.URL.Query().Get("rev"); rev != "" {
+		n, err := strconv.ParseInt(rev, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid rev "+strconv.Quote(rev), http.StatusBadRequest)
+			return
+		}
+		if n != df.Status.Revision {
+			http.Error(w, "revision "+rev+" is not the current revision "+strconv.FormatInt(df.Status.Revision, 10), http.StatusGone)
+			return
+		}
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := h.client.Get(r.Context(), client.ObjectKey{Namespace: key.Namespace, Name: df.ConfigMapName()}, cm); err != nil {
+		status := http.StatusInternalServerError
+		if apierrors.IsNotFound(err) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	content, err := controller.DecodeDecofileConfigMap(cm)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	etag := controller.ContentETag(content)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("X-Decofile-Revision", strconv.FormatInt(df.Status.Revision, 10))
+	w.Header().Set("Cache-Control", "no-cache")
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(content)
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+	"github.com/deco-sites/decofile-operator/internal/controller"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// decofileDump is the response of GET /debug/decofile/{ns}/{name}: the content
+// the operator last rendered into the Decofile's ConfigMap, decompressed.
+type decofileDump struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	ConfigMap string `json:"configMap"`
+	Timestamp string `json:"timestamp,omitempty"`
+	Commit    string `json:"commit,omitempty"`
+	Revision  int64  `json:"revision,omitempty"`
+	// Codecs lists the codecs rendered into the ConfigMap.
+	Codecs []string `json:"codecs"`
+	// CompressedBytes is the size of the Brotli key as stored: base64 in
+	// data, or raw bytes in binaryData.
+	CompressedBytes int             `json:"compressedBytes"`
+	Size            int             `json:"size"`
+	Files           int             `json:"files"`
+	Content         json.RawMessage `json:"content"`
+}
+
+// WithDebugEndpoints mounts the debug endpoints behind the API's basic auth.
+func (h *Handlers) WithDebugEndpoints() *Handlers {
+	h.debug = true
+	return h
+}
+
+func (h *Handlers) debugDecofile(w http.ResponseWriter, r *http.Request) {
+	key := client.ObjectKey{Namespace: r.PathValue("ns"), Name: r.PathValue("name")}
+	df := &decositesv1alpha1.Decofile{}
+	if err := h.client.Get(r.Context(), key, df); err != nil {
+		status := http.StatusInternalServerError
+		if apierrors.IsNotFound(err) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	if df.Spec.Target != "" && df.Spec.Target != decositesv1alpha1.TargetConfigMap {
+		http.Error(w, "decofile target "+df.Spec.Target+" is not stored in a ConfigMap", http.StatusUnprocessableEntity)
+		return
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := h.client.Get(r.Context(), client.ObjectKey{Namespace: key.Namespace, Name: df.ConfigMapName()}, cm); err != nil {
+		status := http.StatusInternalServerError
+		if apierrors.IsNotFound(err) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	content, err := controller.DecodeDecofileConfigMap(cm)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var files map[string]json.RawMessage
+	if err := json.Unmarshal(content, &files); err != nil {
+		http.Error(w, "stored decofile is not a JSON object: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var codecs []string
+	for _, codec := range []string{decositesv1alpha1.CodecBrotli, decositesv1alpha1.CodecGzip, decositesv1alpha1.CodecIdentity} {
+		key := decositesv1alpha1.DecofileCodecKey(codec)
+		_, inData := cm.Data[key]
+		_, inBinaryData := cm.BinaryData[key]
+		if inData || inBinaryData {
+			codecs = append(codecs, codec)
+		}
+	}
+
+	brKey := decositesv1alpha1.DecofileCodecKey(decositesv1alpha1.CodecBrotli)
+	compressedBytes := len(cm.Data[brKey]) + len(cm.BinaryData[brKey])
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(decofileDump{
+		Namespace:       key.Namespace,
+		Name:            key.Name,
+		ConfigMap:       cm.Name,
+		Timestamp:       strings.TrimSpace(cm.Data[df.TimestampKeyOrDefault()]),
+		Commit:          df.Status.GitHubCommit,
+		Revision:        df.Status.Revision,
+		Codecs:          codecs,
+		CompressedBytes: compressedBytes,
+		Size:            len(content),
+		Files:           len(files),
+		Content:         content,
+	})
+}
@@ -17,6 +17,7 @@ var domainRe = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?
 type Handlers struct {
 	client           client.Client
 	defaultNamespace string
+	debug            bool
 }
 
 func NewHandlers(c client.Client, defaultNamespace string) *Handlers {
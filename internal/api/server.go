@@ -24,6 +24,11 @@ func NewServer(addr, user, pass string, h *Handlers, wh *WebhookHandlers) *Serve
 	redirects.HandleFunc("POST /redirects", h.create)
 	redirects.HandleFunc("GET /redirects/{domain}", h.get)
 	redirects.HandleFunc("DELETE /redirects/{domain}", h.delete)
+	// Published Decofile content; each Decofile opts in with spec.publishContent.
+	redirects.HandleFunc("GET /decofile/{ns}/{name}", h.decofileContent)
+	if h.debug {
+		redirects.HandleFunc("GET /debug/decofile/{ns}/{name}", h.debugDecofile)
+	}
 
 	root := http.NewServeMux()
 	// The git webhook authenticates via its HMAC signature, NOT basic auth, so
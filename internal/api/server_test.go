@@ -3,13 +3,16 @@ package api_test
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/andybalholm/brotli"
 	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
 	"github.com/deco-sites/decofile-operator/internal/api"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
@@ -234,3 +237,128 @@ func TestGet_IncludesRedirectCode(t *testing.T) {
 		t.Fatalf("expected redirectCode=301 in response, got %v", item.RedirectCode)
 	}
 }
+
+func TestDebugDecofile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = decositesv1alpha1.AddToScheme(scheme)
+
+	content := `{"pages/home":{"title":"Home"},"site":{}}`
+	var compressed bytes.Buffer
+	bw := brotli.NewWriter(&compressed)
+	_, _ = bw.Write([]byte(content))
+	_ = bw.Close()
+	df := &decositesv1alpha1.Decofile{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "sites-foo"}}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: df.ConfigMapName(), Namespace: "sites-foo"},
+		Data: map[string]string{
+			"decofile.bin":  base64.StdEncoding.EncodeToString(compressed.Bytes()),
+			"timestamp.txt": "2025-01-01T00:00:00Z",
+		},
+	}
+	fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(df, cm).Build()
+
+	req := httptest.NewRequest(http.MethodGet, "/debug/decofile/sites-foo/foo", nil)
+	req.SetBasicAuth("user", "pass")
+
+	// Not mounted unless enabled
+	rec := httptest.NewRecorder()
+	api.NewServer(":0", "user", "pass", api.NewHandlers(fc, ""), nil).ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 with debug endpoints disabled, got %d", rec.Code)
+	}
+
+	srv := api.NewServer(":0", "user", "pass", api.NewHandlers(fc, "").WithDebugEndpoints(), nil)
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var dump struct {
+		Codecs  []string        `json:"codecs"`
+		Size    int             `json:"size"`
+		Files   int             `json:"files"`
+		Content json.RawMessage `json:"content"`
+	}
+	_ = json.NewDecoder(rec.Body).Decode(&dump)
+	if dump.Files != 2 || dump.Size != len(content) || string(dump.Content) != content {
+		t.Fatalf("unexpected dump: %+v", dump)
+	}
+	if len(dump.Codecs) != 1 || dump.Codecs[0] != "br" {
+		t.Fatalf("expected codecs [br], got %v", dump.Codecs)
+	}
+
+	unauth := httptest.NewRequest(http.MethodGet, "/debug/decofile/sites-foo/foo", nil)
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, unauth)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without credentials, got %d", rec.Code)
+	}
+}
+
+func TestDecofileContent(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(scheme)
+	_ = decositesv1alpha1.AddToScheme(scheme)
+
+	content := `{"pages/home":{"title":"Home"},"site":{}}`
+	var compressed bytes.Buffer
+	bw := brotli.NewWriter(&compressed)
+	_, _ = bw.Write([]byte(content))
+	_ = bw.Close()
+	published := &decositesv1alpha1.Decofile{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "sites-foo"},
+		Spec:       decositesv1alpha1.DecofileSpec{PublishContent: true},
+		Status:     decositesv1alpha1.DecofileStatus{Revision: 3},
+	}
+	private := &decositesv1alpha1.Decofile{ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: "sites-foo"}}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: published.ConfigMapName(), Namespace: "sites-foo"},
+		Data:       map[string]string{"decofile.bin": base64.StdEncoding.EncodeToString(compressed.Bytes())},
+	}
+	fc := fake.NewClientBuilder().WithScheme(scheme).WithObjects(published, private, cm).Build()
+	srv := api.NewServer(":0", "user", "pass", api.NewHandlers(fc, ""), nil)
+
+	get := func(path, etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.SetBasicAuth("user", "pass")
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := get("/decofile/sites-foo/foo?rev=3", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec.Body.String() != content {
+		t.Fatalf("expected content %s, got %s", content, rec.Body.String())
+	}
+	etag := rec.Header().Get("ETag")
+	if etag == "" || rec.Header().Get("X-Decofile-Revision") != "3" {
+		t.Fatalf("expected ETag and revision headers, got %v", rec.Header())
+	}
+
+	if rec = get("/decofile/sites-foo/foo", etag); rec.Code != http.StatusNotModified {
+		t.Fatalf("expected 304 for a matching ETag, got %d", rec.Code)
+	}
+	if rec = get("/decofile/sites-foo/foo?rev=2", ""); rec.Code != http.StatusGone {
+		t.Fatalf("expected 410 for a superseded revision, got %d", rec.Code)
+	}
+	if rec = get("/decofile/sites-foo/foo?rev=x", ""); rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for an invalid revision, got %d", rec.Code)
+	}
+	if rec = get("/decofile/sites-foo/bar", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("expected 404 for an unpublished decofile, got %d", rec.Code)
+	}
+
+	unauth := httptest.NewRequest(http.MethodGet, "/decofile/sites-foo/foo", nil)
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, unauth)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without credentials, got %d", rec.Code)
+	}
+}
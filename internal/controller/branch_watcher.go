@@ -0,0 +1,188 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+	"github.com/deco-sites/decofile-operator/internal/github"
+)
+
+// DefaultBranchWatchMinInterval is the shortest gap between two events API
+// polls of the same repository. GitHub asks for 60s (X-Poll-Interval).
+const DefaultBranchWatchMinInterval = time.Minute
+
+// GitHubBranchWatcher keeps Decofiles whose spec.github.commit is a branch in
+// sync without inbound webhooks. It polls each tracked repository's events
+// feed and, on a push to a tracked branch, sets deco.sites/github-head on the
+// Decofiles tracking it, which makes the controller re-download the branch.
+// When the feed fails it resolves the tracked branches directly and backs off
+// to Interval. Runs on the leader only.
+type GitHubBranchWatcher struct {
+	client.Client
+	// Interval between polls of a repository after a failed events poll.
+	Interval time.Duration
+	// MinInterval bounds how often a repository's events feed is polled; a
+	// larger X-Poll-Interval from GitHub wins.
+	MinInterval time.Duration
+	// DefaultGitHubSecret is SourceOptions.DefaultGitHubSecret, for the
+	// tokens repositories are polled with.
+	DefaultGitHubSecret string
+
+	repos map[repoKey]*watchedRepo
+}
+
+type repoKey struct{ org, repo string }
+
+type watchedRepo struct {
+	events   *github.RepoEvents
+	nextPoll time.Time
+}
+
+// Start polls every MinInterval until ctx is done.
+func (w *GitHubBranchWatcher) Start(ctx context.Context) error {
+	ticker := time.NewTicker(w.MinInterval)
+	defer ticker.Stop()
+	for {
+		w.poll(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// NeedLeaderElection restricts polling to the active leader.
+func (w *GitHubBranchWatcher) NeedLeaderElection() bool {
+	return true
+}
+
+// trackedBranch returns the branch a Decofile follows, or "" when it is
+// pinned to a commit SHA (or not a GitHub Decofile).
+func trackedBranch(df *decositesv1alpha1.Decofile) string {
+	if df.Spec.Source != SourceTypeGitHub || df.Spec.GitHub == nil || github.IsCommitSHA(df.Spec.GitHub.Commit) {
+		return ""
+	}
+	return strings.TrimPrefix(df.Spec.GitHub.Commit, "refs/heads/")
+}
+
+// poll runs one round: every repository that is due is polled, and the
+// Decofiles tracking a branch with a new head are annotated.
+func (w *GitHubBranchWatcher) poll(ctx context.Context, now time.Time) {
+	log := logf.FromContext(ctx).WithName("github-branch-watcher")
+
+	decofiles := &decositesv1alpha1.DecofileList{}
+	if err := w.List(ctx, decofiles); err != nil {
+		log.Error(err, "Failed to list Decofiles")
+		return
+	}
+	tracked := map[repoKey][]*decositesv1alpha1.Decofile{}
+	for i := range decofiles.Items {
+		df := &decofiles.Items[i]
+		if trackedBranch(df) == "" {
+			continue
+		}
+		key := repoKey{df.Spec.GitHub.Org, df.Spec.GitHub.Repo}
+		tracked[key] = append(tracked[key], df)
+	}
+
+	if w.repos == nil {
+		w.repos = map[repoKey]*watchedRepo{}
+	}
+	for key := range w.repos {
+		if _, ok := tracked[key]; !ok {
+			delete(w.repos, key)
+		}
+	}
+
+	for key, dfs := range tracked {
+		repo, ok := w.repos[key]
+		if !ok {
+			repo = &watchedRepo{events: &github.RepoEvents{Org: key.org, Repo: key.repo}}
+			w.repos[key] = repo
+		}
+		if now.Before(repo.nextPoll) {
+			continue
+		}
+
+		token, err := GitHubToken(ctx, w.Client, dfs[0].Namespace, dfs[0].Spec.GitHub.Secret, w.DefaultGitHubSecret)
+		if err != nil {
+			log.Error(err, "Failed to get GitHub token", "org", key.org, "repo", key.repo)
+			repo.nextPoll = now.Add(w.Interval)
+			continue
+		}
+
+		heads := map[string]string{}
+		pushes, interval, err := repo.events.Poll(token)
+		if err == nil {
+			// Newest first: the first push per branch is its current head
+			for _, p := range pushes {
+				if _, seen := heads[p.Branch]; !seen {
+					heads[p.Branch] = p.Head
+				}
+			}
+			repo.nextPoll = now.Add(max(interval, w.MinInterval))
+		} else {
+			log.Error(err, "GitHub events poll failed, resolving tracked branches directly",
+				"org", key.org, "repo", key.repo, "retryIn", w.Interval)
+			for _, df := range dfs {
+				branch := trackedBranch(df)
+				if _, done := heads[branch]; done {
+					continue
+				}
+				head, err := github.ResolveBranch(token, key.org, key.repo, branch)
+				if err != nil {
+					log.Error(err, "Failed to resolve branch", "org", key.org, "repo", key.repo, "branch", branch)
+					continue
+				}
+				heads[branch] = head
+			}
+			repo.nextPoll = now.Add(w.Interval)
+		}
+
+		for _, df := range dfs {
+			if head, ok := heads[trackedBranch(df)]; ok {
+				w.setHead(ctx, df, head)
+			}
+		}
+	}
+}
+
+// setHead records a branch's new head on a Decofile, unless already set.
+func (w *GitHubBranchWatcher) setHead(ctx context.Context, df *decositesv1alpha1.Decofile, head string) {
+	if df.Annotations[githubHeadAnnotation] == head {
+		return
+	}
+	patch := client.MergeFrom(df.DeepCopy())
+	if df.Annotations == nil {
+		df.Annotations = map[string]string{}
+	}
+	df.Annotations[githubHeadAnnotation] = head
+	if err := w.Patch(ctx, df, patch); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to record branch head", "decofile", client.ObjectKeyFromObject(df), "head", head)
+		return
+	}
+	logf.FromContext(ctx).Info("Tracked branch moved, re-syncing Decofile",
+		"decofile", client.ObjectKeyFromObject(df), "branch", trackedBranch(df), "head", head)
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+const testHead = "0123456789abcdef0123456789abcdef01234567"
+
+func makeGitHubDecofile(name, commit string) *decositesv1alpha1.Decofile {
+	df := makeDecofile(name, "")
+	df.Spec.Source = SourceTypeGitHub
+	df.Spec.GitHub = &decositesv1alpha1.GitHubSource{Org: "deco-sites", Repo: "storefront", Commit: commit, Path: ".deco/blocks"}
+	return df
+}
+
+func TestTrackedBranch(t *testing.T) {
+	cases := map[string]string{
+		"main":            "main",
+		"refs/heads/main": "main",
+		testHead:          "",
+	}
+	for commit, want := range cases {
+		if got := trackedBranch(makeGitHubDecofile("foo", commit)); got != want {
+			t.Errorf("trackedBranch(%q) = %q, want %q", commit, got, want)
+		}
+	}
+	if got := trackedBranch(makeDecofile("inline", "")); got != "" {
+		t.Errorf("trackedBranch(inline) = %q, want empty", got)
+	}
+}
+
+func TestGitHubUpToDate_FollowsHead(t *testing.T) {
+	df := makeGitHubDecofile("foo", "main")
+	df.Status.GitHubCommit = "main"
+	if !githubUpToDate(df) {
+		t.Fatal("githubUpToDate() = false for a delivered, unwatched branch")
+	}
+	df.Annotations = map[string]string{githubHeadAnnotation: testHead}
+	if githubUpToDate(df) {
+		t.Fatal("githubUpToDate() = true after the branch head moved")
+	}
+	df.Status.GitHubHead = testHead
+	if !githubUpToDate(df) {
+		t.Fatal("githubUpToDate() = false once the new head was delivered")
+	}
+}
+
+func TestGitHubUpToDate_CacheBust(t *testing.T) {
+	df := makeGitHubDecofile("foo", testHead)
+	df.Status.GitHubCommit = testHead
+	df.Spec.GitHub.CacheBust = "proxy-fixed"
+	if githubUpToDate(df) || !githubCacheBusted(df) {
+		t.Fatal("githubUpToDate() = true with a new spec.github.cacheBust")
+	}
+	df.Status.GitHubCacheBust = "proxy-fixed"
+	if !githubUpToDate(df) || githubCacheBusted(df) {
+		t.Fatal("githubUpToDate() = false once the cacheBust value was rendered")
+	}
+}
+
+func TestGitHubBranchWatcher_SetHead(t *testing.T) {
+	ctx := context.Background()
+	scheme := newOwnerTestScheme(t)
+	df := makeGitHubDecofile("foo", "main")
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(df).Build()
+	w := &GitHubBranchWatcher{Client: c}
+
+	w.setHead(ctx, df, testHead)
+
+	got := &decositesv1alpha1.Decofile{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(df), got); err != nil {
+		t.Fatalf("get decofile: %v", err)
+	}
+	if got.Annotations[githubHeadAnnotation] != testHead {
+		t.Errorf("annotations = %v, want %s=%s", got.Annotations, githubHeadAnnotation, testHead)
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+const (
+	defaultCachePurgeAttempts = 3
+	defaultCachePurgeBackoff  = time.Second
+	// cachePurgeTimeout bounds one purge, retries included.
+	cachePurgeTimeout = time.Minute
+
+	// eventReasonCachePurgeFailed is the reason of the Warning event recorded
+	// on a Decofile whose cache purge failed.
+	eventReasonCachePurgeFailed = "CachePurgeFailed"
+)
+
+// CachePurgeRequest is the JSON body POSTed to the cache purge URL.
+type CachePurgeRequest struct {
+	Decofile     string `json:"decofile"`
+	Namespace    string `json:"namespace"`
+	DeploymentId string `json:"deploymentId"`
+	// ContentHash is the SHA-256 of the new rendered content
+	ContentHash string `json:"contentHash"`
+	// URL is where the content is served from, for the s3 target
+	URL string `json:"url,omitempty"`
+}
+
+// CachePurger asks an external cache (e.g. a CDN in front of the rendered
+// config) to drop its copies of a Decofile's content once new content was
+// rolled out. A nil purger on the reconciler disables it.
+type CachePurger struct {
+	URL string
+	// Authorization, when set, is sent as the Authorization header, e.g.
+	// "Bearer <token>".
+	Authorization string
+	HTTPClient    *http.Client
+	// Attempts is the number of purges tried per change
+	// (0 = defaultCachePurgeAttempts).
+	Attempts int
+	// Backoff is the wait before the first retry, doubled for each further
+	// one (0 = defaultCachePurgeBackoff).
+	Backoff time.Duration
+}
+
+// Dispatch sends purge in the background, so a slow or unreachable purge
+// endpoint never holds up the rollout. Failures are logged, counted and
+// passed to onFailure.
+func (p *CachePurger) Dispatch(ctx context.Context, purge CachePurgeRequest, onFailure func(error)) {
+	log := logf.FromContext(ctx)
+	go func() {
+		sendCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cachePurgeTimeout)
+		defer cancel()
+		if err := p.Send(sendCtx, purge); err != nil {
+			cachePurges.WithLabelValues("failed").Inc()
+			log.Error(err, "Failed to purge cached decofile", "url", p.URL)
+			onFailure(err)
+			return
+		}
+		cachePurges.WithLabelValues("purged").Inc()
+		log.V(1).Info("Purged cached decofile", "url", p.URL)
+	}()
+}
+
+// Send POSTs purge, retrying on network errors, 5xx, 408 and 429 answers.
+func (p *CachePurger) Send(ctx context.Context, purge CachePurgeRequest) error {
+	body, err := json.Marshal(purge)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache purge: %w", err)
+	}
+	attempts := p.Attempts
+	if attempts <= 0 {
+		attempts = defaultCachePurgeAttempts
+	}
+	backoff := p.Backoff
+	if backoff <= 0 {
+		backoff = defaultCachePurgeBackoff
+	}
+
+	for attempt := 1; ; attempt++ {
+		retryable, err := p.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if !retryable || attempt >= attempts {
+			return fmt.Errorf("cache not purged after %d attempt(s): %w", attempt, err)
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return fmt.Errorf("cache not purged: %w", ctx.Err())
+		}
+		backoff *= 2
+	}
+}
+
+// post makes one purge attempt, reporting whether a failure is worth
+// retrying.
+func (p *CachePurger) post(ctx context.Context, body []byte) (retryable bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.Authorization != "" {
+		req.Header.Set("Authorization", p.Authorization)
+	}
+
+	resp, err := p.HTTPClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	_ = resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode >= 500, resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests:
+		return true, fmt.Errorf("purge endpoint returned status %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("purge endpoint returned status %d", resp.StatusCode)
+	}
+}
+
+// purgeCache asks the cache purger, if one is configured, to drop the
+// cached copies of decofile's content after a change was rolled out. A
+// failure only records a Warning event on the Decofile.
+func (r *DecofileReconciler) purgeCache(ctx context.Context, decofile *decositesv1alpha1.Decofile, deploymentId, contentHash, url string) {
+	if r.CachePurger == nil {
+		return
+	}
+	target := decofile.DeepCopy()
+	r.CachePurger.Dispatch(ctx, CachePurgeRequest{
+		Decofile:     decofile.Name,
+		Namespace:    decofile.Namespace,
+		DeploymentId: deploymentId,
+		ContentHash:  contentHash,
+		URL:          url,
+	}, func(err error) {
+		if r.Recorder != nil {
+			r.Recorder.Eventf(target, corev1.EventTypeWarning, eventReasonCachePurgeFailed,
+				"Failed to purge the cached decofile: %v", err)
+		}
+	})
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/tools/record"
+)
+
+func TestCachePurgerSend(t *testing.T) {
+	var calls atomic.Int32
+	var got CachePurgeRequest
+	var gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		gotAuth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode purge: %v", err)
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+	purger := &CachePurger{URL: srv.URL, Authorization: "Bearer secret", HTTPClient: srv.Client(), Backoff: time.Millisecond}
+
+	purge := CachePurgeRequest{Decofile: "foo", Namespace: testNamespace, DeploymentId: "dep-1", ContentHash: "abc"}
+	if err := purger.Send(context.Background(), purge); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("purge calls = %d, want 2 (a 429 is retried)", calls.Load())
+	}
+	if gotAuth != "Bearer secret" || got != purge {
+		t.Errorf("received purge = %+v with Authorization %q", got, gotAuth)
+	}
+}
+
+func TestPurgeCache_FailureRecordsWarning(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	recorder := record.NewFakeRecorder(1)
+	r := &DecofileReconciler{
+		CachePurger: &CachePurger{URL: srv.URL, HTTPClient: srv.Client(), Attempts: 2, Backoff: time.Millisecond},
+		Recorder:    recorder,
+	}
+
+	r.purgeCache(context.Background(), makeDecofile("foo", ""), "foo", "abc", "")
+	select {
+	case ev := <-recorder.Events:
+		if !strings.HasPrefix(ev, "Warning "+eventReasonCachePurgeFailed) {
+			t.Errorf("event = %q, want a %s warning", ev, eventReasonCachePurgeFailed)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no event recorded for the failed purge")
+	}
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+func TestCompositeSourceRetrieve(t *testing.T) {
+	inline := func(value map[string]string) decositesv1alpha1.CompositeSourceEntry {
+		raw := make(map[string]runtime.RawExtension, len(value))
+		for k, v := range value {
+			raw[k] = runtime.RawExtension{Raw: []byte(v)}
+		}
+		return decositesv1alpha1.CompositeSourceEntry{
+			Source: decositesv1alpha1.SourceInline,
+			Inline: &decositesv1alpha1.InlineSource{Value: raw},
+		}
+	}
+	df := &decositesv1alpha1.Decofile{Spec: decositesv1alpha1.DecofileSpec{
+		Source: decositesv1alpha1.SourceComposite,
+		Composite: &decositesv1alpha1.CompositeSource{Sources: []decositesv1alpha1.CompositeSourceEntry{
+			inline(map[string]string{
+				"site.json": `{"theme":{"color":"red","font":"serif"},"hosts":["a","b"]}`,
+				"home":      `{"title":"Home"}`,
+			}),
+			inline(map[string]string{
+				"site": `{"theme":{"color":"blue"},"hosts":["c"]}`,
+				"new":  `1`,
+			}),
+		}},
+	}}
+	src, err := NewSource(nil, df, SourceOptions{})
+	if err != nil {
+		t.Fatalf("NewSource: %v", err)
+	}
+
+	got, err := src.Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	want := `{"home":{"title":"Home"},"new":1,"site":{"hosts":["c"],"theme":{"color":"blue","font":"serif"}}}`
+	if got != want {
+		t.Errorf("Retrieve() = %s, want %s", got, want)
+	}
+
+	wantContributions := []decositesv1alpha1.CompositeSourceStatus{
+		{Source: SourceTypeInline, Keys: 2, Overridden: 1},
+		{Source: SourceTypeInline, Keys: 2},
+	}
+	if got := src.(*CompositeSource).Contributions(); !reflect.DeepEqual(got, wantContributions) {
+		t.Errorf("Contributions() = %+v, want %+v", got, wantContributions)
+	}
+}
+
+func TestCompositeSourceRetrieve_SourceError(t *testing.T) {
+	src := &CompositeSource{sources: []DecofileSource{
+		NewInlineSource(&decositesv1alpha1.InlineSource{Value: map[string]runtime.RawExtension{"a": {}}}),
+	}}
+	if _, err := src.Retrieve(context.Background()); err == nil {
+		t.Error("expected error from a failing sub-source, got nil")
+	}
+}
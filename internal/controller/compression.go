@@ -18,10 +18,19 @@ package controller
 
 import (
 	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"maps"
+	"strings"
 	"time"
 
 	"github.com/andybalholm/brotli"
+	corev1 "k8s.io/api/core/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
 )
 
 const (
@@ -34,6 +43,75 @@ const (
 
 var compressionLog = ctrl.Log.WithName("compression")
 
+// Encodings of the compressed codec keys (contentMeta.Encoding).
+const (
+	encodingBase64 = "base64"
+	encodingBinary = "binary"
+)
+
+// compressedCodecKey reports whether key holds compressed content, i.e. is
+// stored in binaryData unless the keys are kept base64. decofile.json stays in
+// data.
+func compressedCodecKey(key string) bool {
+	return key == decositesv1alpha1.DecofileCodecKey(decositesv1alpha1.CodecBrotli) ||
+		key == decositesv1alpha1.DecofileCodecKey(decositesv1alpha1.CodecGzip)
+}
+
+// storedLen is the number of bytes value takes up when stored under key:
+// its decoded length for a compressed key kept in binaryData (binary).
+func storedLen(key, value string, binary bool) int {
+	if !binary || !compressedCodecKey(key) {
+		return len(value)
+	}
+	return base64.StdEncoding.DecodedLen(len(value)) - (len(value) - len(strings.TrimRight(value, "=")))
+}
+
+// configMapData returns cm's data with its binaryData keys merged in,
+// base64-encoded: the form content is rendered and compared in, whichever
+// field it is stored in.
+func configMapData(cm *corev1.ConfigMap) map[string]string {
+	if len(cm.BinaryData) == 0 {
+		return cm.Data
+	}
+	data := make(map[string]string, len(cm.Data)+len(cm.BinaryData))
+	maps.Copy(data, cm.Data)
+	for k, v := range cm.BinaryData {
+		data[k] = base64.StdEncoding.EncodeToString(v)
+	}
+	return data
+}
+
+// setConfigMapData stores rendered data (compressed keys base64-encoded, as
+// from addCodecKeys) in cm, moving the compressed keys to binaryData when
+// binary is set. data itself is not modified.
+func setConfigMapData(cm *corev1.ConfigMap, data map[string]string, binary bool) error {
+	cm.Data = maps.Clone(data)
+	cm.BinaryData = nil
+	if !binary {
+		return nil
+	}
+	for k, v := range data {
+		if !compressedCodecKey(k) {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return fmt.Errorf("decode %s: %w", k, err)
+		}
+		if cm.BinaryData == nil {
+			cm.BinaryData = make(map[string][]byte, 2)
+		}
+		cm.BinaryData[k] = raw
+		delete(cm.Data, k)
+	}
+	return nil
+}
+
+// storedAsBinary reports whether cm keeps its compressed keys in binaryData.
+func storedAsBinary(cm *corev1.ConfigMap) bool {
+	return len(cm.BinaryData) > 0
+}
+
 // compressBrotli compresses data using Brotli compression at a balanced level.
 // Uses level 5 instead of 11 (BestCompression) for much faster compression.
 // Logs a warning if compression takes longer than 30 seconds.
@@ -65,3 +143,59 @@ func compressBrotli(data []byte) ([]byte, error) {
 
 	return buf.Bytes(), nil
 }
+
+// decompressBrotli reverses compressBrotli.
+func decompressBrotli(data []byte) ([]byte, error) {
+	return io.ReadAll(brotli.NewReader(bytes.NewReader(data)))
+}
+
+// DecodeDecofileConfigMap returns the decofile JSON stored in a ConfigMap
+// rendered by the operator, from its always-present Brotli key, in binaryData
+// or base64 in data.
+func DecodeDecofileConfigMap(cm *corev1.ConfigMap) ([]byte, error) {
+	key := decositesv1alpha1.DecofileCodecKey(decositesv1alpha1.CodecBrotli)
+	compressed, ok := cm.BinaryData[key]
+	if !ok {
+		var err error
+		if compressed, err = base64.StdEncoding.DecodeString(cm.Data[key]); err != nil {
+			return nil, fmt.Errorf("decode ConfigMap %s: %w", cm.Name, err)
+		}
+	}
+	content, err := decompressBrotli(compressed)
+	if err != nil {
+		return nil, fmt.Errorf("decompress ConfigMap %s: %w", cm.Name, err)
+	}
+	return content, nil
+}
+
+// compressGzip compresses data with gzip at the default level.
+func compressGzip(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		_ = writer.Close()
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// addCodecKeys adds the ConfigMap key for every codec other than Brotli
+// (decofile.bin, always present) that a consumer of the Decofile selected.
+func addCodecKeys(configData map[string]string, content []byte, codecs []string) error {
+	for _, codec := range codecs {
+		switch codec {
+		case decositesv1alpha1.CodecGzip:
+			compressed, err := compressGzip(content)
+			if err != nil {
+				return err
+			}
+			configData[decositesv1alpha1.DecofileCodecKey(codec)] = base64.StdEncoding.EncodeToString(compressed)
+		case decositesv1alpha1.CodecIdentity:
+			configData[decositesv1alpha1.DecofileCodecKey(codec)] = string(content)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,269 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+func TestConsumerCodecs(t *testing.T) {
+	scheme := newOwnerTestScheme(t)
+	df := makeDecofile("site", "")
+
+	plain := makeRevision("site-00001", "site", "uid-1")
+	gz := makeRevision("site-00002", "site", "uid-2")
+	gz.Annotations = map[string]string{codecAnnotation: decositesv1alpha1.CodecGzip}
+	gzAgain := makeRevision("site-00003", "site", "uid-3")
+	gzAgain.Annotations = map[string]string{codecAnnotation: decositesv1alpha1.CodecGzip}
+	identity := makeRevision("site-00004", "site", "uid-4")
+	identity.Annotations = map[string]string{codecAnnotation: decositesv1alpha1.CodecIdentity}
+	other := makeRevision("other-00001", "other", "uid-5")
+	other.Annotations = map[string]string{codecAnnotation: decositesv1alpha1.CodecIdentity}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(df, plain, gz, gzAgain, other).Build()
+	r := &DecofileReconciler{Client: c, Scheme: scheme}
+
+	got, err := r.consumerCodecs(context.Background(), df)
+	if err != nil {
+		t.Fatalf("consumerCodecs: %v", err)
+	}
+	if want := []string{decositesv1alpha1.CodecGzip}; !reflect.DeepEqual(got, want) {
+		t.Errorf("consumerCodecs() = %v, want %v", got, want)
+	}
+
+	if err := c.Create(context.Background(), identity); err != nil {
+		t.Fatalf("create revision: %v", err)
+	}
+	got, _ = r.consumerCodecs(context.Background(), df)
+	if want := []string{decositesv1alpha1.CodecGzip, decositesv1alpha1.CodecIdentity}; !reflect.DeepEqual(got, want) {
+		t.Errorf("consumerCodecs() = %v, want %v", got, want)
+	}
+
+	// spec.compression's codec is rendered even with no Revision reading it
+	df.Spec.Compression = decositesv1alpha1.CompressionNone
+	if err := c.Delete(context.Background(), identity); err != nil {
+		t.Fatalf("delete revision: %v", err)
+	}
+	got, _ = r.consumerCodecs(context.Background(), df)
+	if want := []string{decositesv1alpha1.CodecGzip, decositesv1alpha1.CodecIdentity}; !reflect.DeepEqual(got, want) {
+		t.Errorf("consumerCodecs() with compression none = %v, want %v", got, want)
+	}
+}
+
+func TestAddCodecKeys(t *testing.T) {
+	content := []byte(`{"a":{"b":1}}`)
+	data := map[string]string{"decofile.bin": "br"}
+	codecs := []string{decositesv1alpha1.CodecGzip, decositesv1alpha1.CodecIdentity}
+	if err := addCodecKeys(data, content, codecs); err != nil {
+		t.Fatalf("addCodecKeys: %v", err)
+	}
+
+	if data["decofile.json"] != string(content) {
+		t.Errorf("decofile.json = %q, want %q", data["decofile.json"], content)
+	}
+	raw, err := base64.StdEncoding.DecodeString(data["decofile.gz"])
+	if err != nil {
+		t.Fatalf("decode decofile.gz: %v", err)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("gzip reader: %v", err)
+	}
+	if got, _ := io.ReadAll(zr); !bytes.Equal(got, content) {
+		t.Errorf("decofile.gz decodes to %q, want %q", got, content)
+	}
+
+	existing := map[string]string{"decofile.bin": "old", "timestamp.txt": "1"}
+	if sameDataKeys(existing, data, "timestamp.txt") {
+		t.Error("sameDataKeys() = true with codec keys added")
+	}
+	if !sameDataKeys(data, data, "timestamp.txt") {
+		t.Error("sameDataKeys() = false for identical keys")
+	}
+	existing = map[string]string{"decofile.bin": "old", "timestamp.txt": "1"}
+	if sameDataKeys(existing, map[string]string{"decofile.bin": "old"}, "version") {
+		t.Error("sameDataKeys() = true with the timestamp key renamed")
+	}
+}
+
+func TestReconcile_TimestampKey(t *testing.T) {
+	ctx := context.Background()
+	scheme := newOwnerTestScheme(t)
+	df := makeDecofile("foo", "")
+	df.Spec.Source = SourceTypeInline
+	df.Spec.Inline = &decositesv1alpha1.InlineSource{Value: map[string]runtime.RawExtension{
+		"config.json": {Raw: []byte(`{"a":1}`)},
+	}}
+	df.Spec.TimestampKey = "version"
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(df).
+		WithStatusSubresource(&decositesv1alpha1.Decofile{}).Build()
+	r := &DecofileReconciler{Client: c, Scheme: scheme}
+	key := client.ObjectKeyFromObject(df)
+	cmKey := client.ObjectKey{Namespace: testNamespace, Name: df.ConfigMapName()}
+
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(ctx, cmKey, cm); err != nil {
+		t.Fatalf("get ConfigMap: %v", err)
+	}
+	if _, ok := cm.Data["timestamp.txt"]; ok || cm.Data["version"] == "" {
+		t.Fatalf("ConfigMap keys = %v, want the timestamp under \"version\" only", reflect.ValueOf(cm.Data).MapKeys())
+	}
+
+	// Renaming the key moves the timestamp without touching the content
+	if err := c.Get(ctx, key, df); err != nil {
+		t.Fatalf("get Decofile: %v", err)
+	}
+	df.Spec.TimestampKey = ""
+	if err := c.Update(ctx, df); err != nil {
+		t.Fatalf("update Decofile: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if err := c.Get(ctx, cmKey, cm); err != nil {
+		t.Fatalf("get ConfigMap: %v", err)
+	}
+	if _, ok := cm.Data["version"]; ok || cm.Data["timestamp.txt"] == "" {
+		t.Errorf("ConfigMap keys = %v, want the timestamp under \"timestamp.txt\" only", reflect.ValueOf(cm.Data).MapKeys())
+	}
+}
+
+func TestReconcile_BinaryData(t *testing.T) {
+	ctx := context.Background()
+	scheme := newOwnerTestScheme(t)
+	df := makeDecofile("foo", "")
+	df.Spec.Source = SourceTypeInline
+	df.Spec.Inline = &decositesv1alpha1.InlineSource{Value: map[string]runtime.RawExtension{
+		"config.json": {Raw: []byte(`{"a":1}`)},
+	}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(df).
+		WithStatusSubresource(&decositesv1alpha1.Decofile{}).Build()
+	r := &DecofileReconciler{Client: c, Scheme: scheme}
+	key := client.ObjectKeyFromObject(df)
+	cmKey := client.ObjectKey{Namespace: testNamespace, Name: df.ConfigMapName()}
+
+	reconcileWith := func(binary bool) *corev1.ConfigMap {
+		t.Helper()
+		r.Base64ConfigMapData = !binary
+		if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key}); err != nil {
+			t.Fatalf("Reconcile: %v", err)
+		}
+		cm := &corev1.ConfigMap{}
+		if err := c.Get(ctx, cmKey, cm); err != nil {
+			t.Fatalf("get ConfigMap: %v", err)
+		}
+		content, err := DecodeDecofileConfigMap(cm)
+		if err != nil {
+			t.Fatalf("DecodeDecofileConfigMap: %v", err)
+		}
+		if string(content) != `{"config":{"a":1}}` {
+			t.Errorf("decoded content = %s", content)
+		}
+		return cm
+	}
+
+	cm := reconcileWith(true)
+	if _, ok := cm.Data["decofile.bin"]; ok {
+		t.Error("decofile.bin is in data, want it only in binaryData")
+	}
+	raw := cm.BinaryData["decofile.bin"]
+	if got, err := decompressBrotli(raw); err != nil || string(got) != `{"config":{"a":1}}` {
+		t.Errorf("binaryData decofile.bin = %q (%v), want raw Brotli", got, err)
+	}
+	if !bytes.Contains([]byte(cm.Data[decositesv1alpha1.DecofileMetaKey]), []byte(`"encoding":"binary"`)) {
+		t.Errorf("meta = %s, want encoding binary", cm.Data[decositesv1alpha1.DecofileMetaKey])
+	}
+	timestamp := cm.Data["timestamp.txt"]
+
+	// Switching back moves the key to data without a new timestamp
+	cm = reconcileWith(false)
+	if len(cm.BinaryData) != 0 || cm.Data["decofile.bin"] != base64.StdEncoding.EncodeToString(raw) {
+		t.Errorf("ConfigMap = data %v binaryData %v, want decofile.bin base64 in data", cm.Data, cm.BinaryData)
+	}
+	if cm.Data["timestamp.txt"] != timestamp {
+		t.Errorf("timestamp = %s, want %s kept for unchanged content", cm.Data["timestamp.txt"], timestamp)
+	}
+	if !bytes.Contains([]byte(cm.Data[decositesv1alpha1.DecofileMetaKey]), []byte(`"encoding":"base64"`)) {
+		t.Errorf("meta = %s, want encoding base64", cm.Data[decositesv1alpha1.DecofileMetaKey])
+	}
+}
+
+// The operator has no size threshold: content of any size is compressed into
+// decofile.bin, never stored as plain decofile.json unless a runtime asks for
+// it. Payloads either side of 2.5MB, where a threshold was reported to exist,
+// both land in decofile.bin.
+func TestReconcile_ContentKeyIndependentOfSize(t *testing.T) {
+	ctx := context.Background()
+	scheme := newOwnerTestScheme(t)
+	// 2.5MB, the size below which content was believed to be stored as
+	// plain JSON
+	const reportedThreshold = 2_621_440
+	df := makeDecofile("foo", "")
+	df.Spec.Source = SourceTypeInline
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(df).
+		WithStatusSubresource(&decositesv1alpha1.Decofile{}).Build()
+	r := &DecofileReconciler{Client: c, Scheme: scheme}
+	key := client.ObjectKeyFromObject(df)
+	cmKey := client.ObjectKey{Namespace: testNamespace, Name: df.ConfigMapName()}
+
+	for _, size := range []int{reportedThreshold - 64, reportedThreshold + 64} {
+		if err := c.Get(ctx, key, df); err != nil {
+			t.Fatalf("get Decofile: %v", err)
+		}
+		df.Spec.Inline = &decositesv1alpha1.InlineSource{Value: map[string]runtime.RawExtension{
+			"config.json": {Raw: []byte(`{"s":"` + strings.Repeat("a", size) + `"}`)},
+		}}
+		if err := c.Update(ctx, df); err != nil {
+			t.Fatalf("update Decofile: %v", err)
+		}
+		if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key}); err != nil {
+			t.Fatalf("Reconcile (%d bytes): %v", size, err)
+		}
+		cm := &corev1.ConfigMap{}
+		if err := c.Get(ctx, cmKey, cm); err != nil {
+			t.Fatalf("get ConfigMap: %v", err)
+		}
+		if _, ok := cm.Data["decofile.json"]; ok {
+			t.Errorf("%d bytes: ConfigMap has decofile.json, want only decofile.bin", size)
+		}
+		content, err := DecodeDecofileConfigMap(cm)
+		if err != nil {
+			t.Fatalf("%d bytes: DecodeDecofileConfigMap: %v", size, err)
+		}
+		if want := len(`{"config":{"s":""}}`) + size; len(content) != want {
+			t.Errorf("%d bytes: decoded %d bytes from decofile.bin, want %d", size, len(content), want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+const (
+	// managedByLabel / managedByValue identify ConfigMaps written by the operator.
+	managedByLabel = "app.kubernetes.io/managed-by"
+	managedByValue = "decofile-operator"
+	// decofileLabel names the Decofile a ConfigMap was rendered from.
+	decofileLabel = "deco.sites/decofile"
+
+	// DefaultConfigMapGCInterval is how often orphaned ConfigMaps are swept.
+	DefaultConfigMapGCInterval = time.Hour
+	// orphanMinAge leaves recently created ConfigMaps alone, so a sweep never
+	// races a Decofile that isn't in the cache yet.
+	orphanMinAge = 10 * time.Minute
+)
+
+// ConfigMapGC periodically deletes decofile ConfigMaps whose Decofile no
+// longer exists. Owner-reference GC handles the common case; this catches
+// what it misses (e.g. a ConfigMap that lost or never had its ownerReference).
+// ConfigMaps released by a deletionPolicy orphan deletion are kept.
+// Runs on the leader only.
+type ConfigMapGC struct {
+	client.Client
+	// Interval between sweeps.
+	Interval time.Duration
+	// DryRun logs and counts orphans without deleting them.
+	DryRun bool
+}
+
+// Start runs a sweep every Interval until ctx is done.
+func (g *ConfigMapGC) Start(ctx context.Context) error {
+	log := logf.FromContext(ctx).WithName("configmap-gc")
+	ticker := time.NewTicker(g.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if _, err := g.Sweep(ctx); err != nil {
+				log.Error(err, "Orphaned ConfigMap sweep failed")
+			}
+		}
+	}
+}
+
+// NeedLeaderElection restricts the sweep to the active leader.
+func (g *ConfigMapGC) NeedLeaderElection() bool {
+	return true
+}
+
+// Sweep deletes (or, in dry-run, only reports) orphaned decofile ConfigMaps
+// and returns how many it found.
+func (g *ConfigMapGC) Sweep(ctx context.Context) (int, error) {
+	log := logf.FromContext(ctx).WithName("configmap-gc")
+
+	cms := &corev1.ConfigMapList{}
+	if err := g.List(ctx, cms); err != nil {
+		return 0, err
+	}
+
+	orphans := 0
+	for i := range cms.Items {
+		cm := &cms.Items[i]
+		name, ok := decofileOwnerName(cm)
+		if !ok || cm.DeletionTimestamp != nil || isOrphanedConfigMap(cm) || time.Since(cm.CreationTimestamp.Time) < orphanMinAge {
+			continue
+		}
+		err := g.Get(ctx, client.ObjectKey{Name: name, Namespace: cm.Namespace}, &decositesv1alpha1.Decofile{})
+		if err == nil {
+			continue
+		}
+		if !errors.IsNotFound(err) {
+			log.Error(err, "Failed to get Decofile, skipping ConfigMap", "configMap", client.ObjectKeyFromObject(cm))
+			continue
+		}
+
+		orphans++
+		if g.DryRun {
+			log.Info("Found orphaned ConfigMap (dry run)", "configMap", client.ObjectKeyFromObject(cm), "decofile", name)
+			orphanConfigMapsReclaimed.WithLabelValues("dry_run").Inc()
+			continue
+		}
+		if err := g.Delete(ctx, cm); err != nil && !errors.IsNotFound(err) {
+			log.Error(err, "Failed to delete orphaned ConfigMap", "configMap", client.ObjectKeyFromObject(cm))
+			continue
+		}
+		log.Info("Deleted orphaned ConfigMap", "configMap", client.ObjectKeyFromObject(cm), "decofile", name)
+		orphanConfigMapsReclaimed.WithLabelValues("deleted").Inc()
+	}
+	return orphans, nil
+}
+
+// decofileOwnerName returns the Decofile a ConfigMap belongs to, from its
+// Decofile controller ownerReference or, for operator-labelled ConfigMaps,
+// its decofile label.
+func decofileOwnerName(cm *corev1.ConfigMap) (string, bool) {
+	for _, ref := range cm.OwnerReferences {
+		if ref.Kind == "Decofile" && ref.APIVersion == decositesv1alpha1.GroupVersion.String() &&
+			ref.Controller != nil && *ref.Controller {
+			return ref.Name, true
+		}
+	}
+	if cm.Labels[managedByLabel] == managedByValue && cm.Labels[decofileLabel] != "" {
+		return cm.Labels[decofileLabel], true
+	}
+	return "", false
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+func makeOwnedConfigMap(name, decofile string, age time.Duration) *corev1.ConfigMap {
+	return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:              name,
+		Namespace:         testNamespace,
+		CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+		OwnerReferences: []metav1.OwnerReference{{
+			APIVersion: decositesv1alpha1.GroupVersion.String(),
+			Kind:       "Decofile",
+			Name:       decofile,
+			UID:        "uid-" + types.UID(decofile),
+			Controller: ptr.To(true),
+		}},
+	}}
+}
+
+func TestConfigMapGCSweep(t *testing.T) {
+	scheme := newOwnerTestScheme(t)
+
+	live := makeOwnedConfigMap("decofile-live", "live", time.Hour)
+	orphan := makeOwnedConfigMap("decofile-gone", "gone", time.Hour)
+	fresh := makeOwnedConfigMap("decofile-new", "new", time.Minute)
+	labelled := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:              "custom-name",
+		Namespace:         testNamespace,
+		CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+		Labels:            map[string]string{managedByLabel: managedByValue, decofileLabel: "gone-too"},
+	}}
+	unrelated := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:              "kube-root-ca.crt",
+		Namespace:         testNamespace,
+		CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+	}}
+	objs := []client.Object{makeDecofile("live", ""), live, orphan, fresh, labelled, unrelated}
+
+	t.Run("dry run keeps orphans", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+		gc := &ConfigMapGC{Client: c, DryRun: true}
+		n, err := gc.Sweep(context.Background())
+		if err != nil || n != 2 {
+			t.Fatalf("Sweep() = %d, %v; want 2 orphans", n, err)
+		}
+		if err := c.Get(context.Background(), client.ObjectKeyFromObject(orphan), &corev1.ConfigMap{}); err != nil {
+			t.Errorf("dry run deleted %s: %v", orphan.Name, err)
+		}
+	})
+
+	t.Run("deletes orphans only", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+		gc := &ConfigMapGC{Client: c}
+		if n, err := gc.Sweep(context.Background()); err != nil || n != 2 {
+			t.Fatalf("Sweep() = %d, %v; want 2 orphans", n, err)
+		}
+		for _, cm := range []*corev1.ConfigMap{orphan, labelled} {
+			err := c.Get(context.Background(), client.ObjectKeyFromObject(cm), &corev1.ConfigMap{})
+			if !errors.IsNotFound(err) {
+				t.Errorf("orphan %s still present (err=%v)", cm.Name, err)
+			}
+		}
+		for _, cm := range []*corev1.ConfigMap{live, fresh, unrelated} {
+			if err := c.Get(context.Background(), client.ObjectKeyFromObject(cm), &corev1.ConfigMap{}); err != nil {
+				t.Errorf("%s was deleted: %v", cm.Name, err)
+			}
+		}
+	})
+}
+
+func TestApplyConfigMapLabels(t *testing.T) {
+	df := makeDecofile("decofile-site-main", "")
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"team": "storefront"}}}
+	if !applyConfigMapLabels(cm, df) {
+		t.Fatal("applyConfigMapLabels() = false on an unlabelled ConfigMap")
+	}
+	if cm.Labels[managedByLabel] != managedByValue || cm.Labels[decofileLabel] != df.Name || cm.Labels["team"] != "storefront" {
+		t.Errorf("labels = %v", cm.Labels)
+	}
+	if applyConfigMapLabels(cm, df) {
+		t.Error("applyConfigMapLabels() = true with labels already in place")
+	}
+
+	cm.Labels[decofileLabel] = "edited"
+	if !applyConfigMapLabels(cm, df) || cm.Labels[decofileLabel] != df.Name {
+		t.Errorf("drifted label not restored: %v", cm.Labels)
+	}
+
+	long := makeDecofile("decofile-"+strings.Repeat("x", 64), "")
+	cm = &corev1.ConfigMap{}
+	applyConfigMapLabels(cm, long)
+	if _, ok := cm.Labels[decofileLabel]; ok || cm.Labels[managedByLabel] != managedByValue {
+		t.Errorf("labels for a long name = %v, want only %s", cm.Labels, managedByLabel)
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+const (
+	// DefaultMaxConfigMapKeys bounds the number of data keys in a Decofile's
+	// ConfigMap. A ConfigMap normally holds a handful: decofile.bin, one key
+	// per consumer codec, the timestamp, the metadata and the checksum.
+	DefaultMaxConfigMapKeys = 64
+	// DefaultMaxConfigMapBytes is the API server's limit on a ConfigMap's data.
+	DefaultMaxConfigMapBytes = 1 << 20
+)
+
+// ErrConfigMapLimitExceeded is returned when the rendered ConfigMap would
+// hold more keys or bytes than the configured limits allow, so it's rejected
+// with a clear condition rather than by the API server.
+var ErrConfigMapLimitExceeded = errors.New("rendered ConfigMap exceeds limits")
+
+// checkConfigMapLimits checks configData, plus the timestamp, metadata and
+// checksum keys added when it's written, against the reconciler's ConfigMap limits
+// (defaults when unset). Sizes are counted as stored, so binaryData keys
+// count their raw bytes.
+func (r *DecofileReconciler) checkConfigMapLimits(configData map[string]string, timestampKey string, meta contentMeta) error {
+	maxKeys, maxBytes := r.MaxConfigMapKeys, r.MaxConfigMapBytes
+	if maxKeys <= 0 {
+		maxKeys = DefaultMaxConfigMapKeys
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxConfigMapBytes
+	}
+
+	// Any Unix-seconds timestamp renders to the same length
+	const timestamp = "0000000000"
+	data := maps.Clone(configData)
+	data[timestampKey] = timestamp
+	data[decositesv1alpha1.DecofileMetaKey] = meta.render(timestamp)
+	data[decositesv1alpha1.DecofileChecksumKey] = meta.ContentHash
+
+	if len(data) > maxKeys {
+		return fmt.Errorf("%w: %d data keys, over the limit of %d; keys beyond decofile.bin come from the "+
+			"codecs requested by consuming Services (%s); request fewer to fall back to the single decofile.bin key",
+			ErrConfigMapLimitExceeded, len(data), maxKeys, strings.Join(slices.Sorted(maps.Keys(data)), ", "))
+	}
+	size := 0
+	for k, v := range data {
+		size += len(k) + storedLen(k, v, r.binaryData())
+	}
+	if size > maxBytes {
+		return fmt.Errorf("%w: data is %d bytes, over the limit of %d bytes; consider spec.target: s3",
+			ErrConfigMapLimitExceeded, size, maxBytes)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+func TestCheckConfigMapLimits(t *testing.T) {
+	data := map[string]string{
+		"decofile.bin": "abc",
+		decositesv1alpha1.DecofileCodecKey(decositesv1alpha1.CodecGzip): "def",
+	}
+	cm := newContentMeta(data, `{"a":1}`, SourceTypeInline, decositesv1alpha1.CodecBrotli, false)
+
+	r := &DecofileReconciler{Base64ConfigMapData: true}
+	if err := r.checkConfigMapLimits(data, "timestamp", cm); err != nil {
+		t.Fatalf("defaults: %v", err)
+	}
+
+	// decofile.bin, the gzip key, the timestamp and the metadata
+	r.MaxConfigMapKeys = 3
+	err := r.checkConfigMapLimits(data, "timestamp", cm)
+	if !errors.Is(err, ErrConfigMapLimitExceeded) || !strings.Contains(err.Error(), "4 data keys") ||
+		!strings.Contains(err.Error(), "single decofile.bin key") {
+		t.Errorf("key limit: err = %v, want a key count error suggesting decofile.bin alone", err)
+	}
+	if len(data) != 2 {
+		t.Errorf("checkConfigMapLimits modified its input: %v", data)
+	}
+
+	r.MaxConfigMapKeys = 0
+	r.MaxConfigMapBytes = 64
+	err = r.checkConfigMapLimits(data, "timestamp", cm)
+	if !errors.Is(err, ErrConfigMapLimitExceeded) || !strings.Contains(err.Error(), "over the limit of 64 bytes") {
+		t.Errorf("byte limit: err = %v, want a size error", err)
+	}
+}
+
+func TestReconcile_RejectsConfigMapOverLimits(t *testing.T) {
+	ctx := context.Background()
+	scheme := newOwnerTestScheme(t)
+	df := makeDecofile("foo", "")
+	df.Spec.Source = SourceTypeInline
+	df.Spec.Inline = &decositesv1alpha1.InlineSource{Value: map[string]runtime.RawExtension{
+		"config.json": {Raw: []byte(`{"v":1}`)},
+	}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(df).
+		WithStatusSubresource(&decositesv1alpha1.Decofile{}).Build()
+	r := &DecofileReconciler{Client: c, Scheme: scheme, MaxConfigMapBytes: 64}
+
+	key := client.ObjectKeyFromObject(df)
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+
+	got := &decositesv1alpha1.Decofile{}
+	if err := c.Get(ctx, key, got); err != nil {
+		t.Fatalf("get Decofile: %v", err)
+	}
+	cond := meta.FindStatusCondition(got.Status.Conditions, "Ready")
+	if cond == nil || cond.Status != metav1.ConditionFalse || cond.Reason != "ConfigMapTooLarge" {
+		t.Errorf("Ready = %+v, want False/ConfigMapTooLarge", cond)
+	}
+	err := c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: df.ConfigMapName()}, &corev1.ConfigMap{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("ConfigMap get = %v, want NotFound", err)
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+// condTypeOwnershipConflict reports that the Decofile's ConfigMap name is
+// taken by a ConfigMap the operator doesn't own.
+const condTypeOwnershipConflict = "OwnershipConflict"
+
+// ownershipConflictRequeue is how often a conflicting ConfigMap is checked
+// again. Its removal doesn't trigger a reconcile, as it isn't ours.
+const ownershipConflictRequeue = time.Minute
+
+// checkConfigMapOwnership decides whether the existing ConfigMap cm may be
+// written for decofile. It may when the Decofile controls it, or when it has
+// no controller but carries the operator's labels for this Decofile (its
+// ownerReferences were stripped, e.g. by a backup restore); adopt is then
+// true and the caller sets the controller reference. Any other ConfigMap is
+// left alone: conflict is true and the OwnershipConflict and Ready
+// conditions say why. A resolved conflict flips OwnershipConflict to False.
+func (r *DecofileReconciler) checkConfigMapOwnership(ctx context.Context, req ctrl.Request, decofile *decositesv1alpha1.Decofile, cm *corev1.ConfigMap) (adopt, conflict bool, err error) {
+	log := logf.FromContext(ctx)
+
+	controller := metav1.GetControllerOf(cm)
+	owned := metav1.IsControlledBy(cm, decofile)
+	if controller == nil && cm.Labels[managedByLabel] == managedByValue && cm.Labels[decofileLabel] == decofile.Name {
+		owned, adopt = true, true
+	}
+
+	wasConflict := meta.IsStatusConditionTrue(decofile.Status.Conditions, condTypeOwnershipConflict)
+	if owned && !wasConflict {
+		return adopt, false, nil
+	}
+
+	conds := []metav1.Condition{{
+		Type:               condTypeOwnershipConflict,
+		Status:             metav1.ConditionFalse,
+		Reason:             "ConfigMapOwned",
+		Message:            fmt.Sprintf("ConfigMap %s is owned by this Decofile", cm.Name),
+		LastTransitionTime: metav1.Now(),
+	}}
+	if !owned {
+		owner := "no controller"
+		if controller != nil {
+			owner = fmt.Sprintf("controller %s %s", controller.Kind, controller.Name)
+		}
+		msg := fmt.Sprintf("ConfigMap %s already exists and is not owned by this Decofile (%s); delete or rename it to let the operator manage it", cm.Name, owner)
+		conds = []metav1.Condition{{
+			Type:               condTypeOwnershipConflict,
+			Status:             metav1.ConditionTrue,
+			Reason:             "ConfigMapNotOwned",
+			Message:            msg,
+			LastTransitionTime: metav1.Now(),
+		}, {
+			Type:               "Ready",
+			Status:             metav1.ConditionFalse,
+			Reason:             "OwnershipConflict",
+			Message:            msg,
+			LastTransitionTime: metav1.Now(),
+		}}
+		log.Info("Refusing to overwrite a ConfigMap not owned by this Decofile", "ConfigMap.Name", cm.Name, "owner", owner)
+		if cur := meta.FindStatusCondition(decofile.Status.Conditions, condTypeOwnershipConflict); cur != nil &&
+			cur.Status == metav1.ConditionTrue && cur.Message == msg {
+			return false, true, nil
+		}
+	}
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &decositesv1alpha1.Decofile{}
+		if err := r.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		for _, cond := range conds {
+			updateCondition(fresh, cond)
+		}
+		return r.Status().Update(ctx, fresh)
+	})
+	if err != nil {
+		log.Error(err, "Failed to update OwnershipConflict condition")
+		return false, !owned, err
+	}
+	return adopt, !owned, nil
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+func TestReconcile_ConfigMapOwnership(t *testing.T) {
+	ctx := context.Background()
+	scheme := newOwnerTestScheme(t)
+	newDecofile := func() *decositesv1alpha1.Decofile {
+		df := makeDecofile("foo", "")
+		df.UID = "foo-uid"
+		df.Spec.Source = SourceTypeInline
+		df.Spec.Inline = &decositesv1alpha1.InlineSource{Value: map[string]runtime.RawExtension{
+			"config.json": {Raw: []byte(`{"a":1}`)},
+		}}
+		return df
+	}
+
+	t.Run("foreign ConfigMap is left alone", func(t *testing.T) {
+		df := newDecofile()
+		foreign := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: df.ConfigMapName(), Namespace: testNamespace},
+			Data:       map[string]string{"app.conf": "unrelated"},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(df, foreign).
+			WithStatusSubresource(&decositesv1alpha1.Decofile{}).Build()
+		r := &DecofileReconciler{Client: c, Scheme: scheme}
+		key := client.ObjectKeyFromObject(df)
+
+		result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		if err != nil {
+			t.Fatalf("Reconcile: %v", err)
+		}
+		if result.RequeueAfter != ownershipConflictRequeue {
+			t.Errorf("RequeueAfter = %v, want %v", result.RequeueAfter, ownershipConflictRequeue)
+		}
+		cm := &corev1.ConfigMap{}
+		if err := c.Get(ctx, client.ObjectKeyFromObject(foreign), cm); err != nil {
+			t.Fatalf("get ConfigMap: %v", err)
+		}
+		if len(cm.Data) != 1 || cm.Data["app.conf"] != "unrelated" || len(cm.OwnerReferences) != 0 {
+			t.Errorf("ConfigMap = data %v, owners %v; want it untouched", cm.Data, cm.OwnerReferences)
+		}
+		got := &decositesv1alpha1.Decofile{}
+		if err := c.Get(ctx, key, got); err != nil {
+			t.Fatalf("get Decofile: %v", err)
+		}
+		if !meta.IsStatusConditionTrue(got.Status.Conditions, condTypeOwnershipConflict) {
+			t.Errorf("conditions = %+v, want OwnershipConflict=True", got.Status.Conditions)
+		}
+		if ready := meta.FindStatusCondition(got.Status.Conditions, "Ready"); ready == nil || ready.Reason != "OwnershipConflict" {
+			t.Errorf("Ready = %+v, want reason OwnershipConflict", ready)
+		}
+	})
+
+	t.Run("labelled ConfigMap without a controller is adopted", func(t *testing.T) {
+		df := newDecofile()
+		orphan := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: df.ConfigMapName(), Namespace: testNamespace,
+				Labels: map[string]string{managedByLabel: managedByValue, decofileLabel: df.Name}},
+			Data: map[string]string{"decofile.bin": "stale"},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(df, orphan).
+			WithStatusSubresource(&decositesv1alpha1.Decofile{}).Build()
+		r := &DecofileReconciler{Client: c, Scheme: scheme}
+
+		if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(df)}); err != nil {
+			t.Fatalf("Reconcile: %v", err)
+		}
+		cm := &corev1.ConfigMap{}
+		if err := c.Get(ctx, client.ObjectKeyFromObject(orphan), cm); err != nil {
+			t.Fatalf("get ConfigMap: %v", err)
+		}
+		if !metav1.IsControlledBy(cm, df) {
+			t.Errorf("ConfigMap owners = %v, want controlled by the Decofile", cm.OwnerReferences)
+		}
+		if configMapData(cm)["decofile.bin"] == "stale" {
+			t.Error("adopted ConfigMap content was not updated")
+		}
+	})
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+// contentMeta is the decofile.meta.json ConfigMap key: how the content keys
+// were encoded, so a runtime decodes them without guessing from the key name.
+type contentMeta struct {
+	// Codec is the codec of decofile.bin, the key every runtime reads.
+	Codec string `json:"codec"`
+	// Codecs maps every content key in the ConfigMap to its codec.
+	Codecs map[string]string `json:"codecs"`
+	// DefaultCodec is the codec spec.compression selects for runtimes that
+	// don't declare theirs.
+	DefaultCodec string `json:"defaultCodec"`
+	// Encoding is how the compressed keys are stored: the raw bytes
+	// ("binary", from binaryData), or "base64" text under
+	// --decofile-binary-data=false.
+	// decofile.json is always plain JSON.
+	Encoding string `json:"encoding"`
+	// OriginalBytes is the size of the decoded JSON.
+	OriginalBytes int `json:"originalBytes"`
+	// StoredBytes is the size of decofile.bin as stored.
+	StoredBytes int    `json:"storedBytes"`
+	Timestamp   string `json:"timestamp"`
+	// ContentHash is the SHA-256 of the decoded JSON.
+	ContentHash string `json:"contentHash"`
+	SourceType  string `json:"sourceType"`
+}
+
+// newContentMeta describes the content keys of configData, rendered from
+// content in defaultCodec and stored in binaryData when binary is set. The
+// timestamp is filled in by render once it is known.
+func newContentMeta(configData map[string]string, content, sourceType, defaultCodec string, binary bool) contentMeta {
+	brKey := decositesv1alpha1.DecofileCodecKey(decositesv1alpha1.CodecBrotli)
+	meta := contentMeta{
+		Codec:         decositesv1alpha1.CodecBrotli,
+		Codecs:        map[string]string{},
+		DefaultCodec:  defaultCodec,
+		Encoding:      encodingBase64,
+		OriginalBytes: len(content),
+		StoredBytes:   storedLen(brKey, configData[brKey], binary),
+		ContentHash:   sha256hex(content),
+		SourceType:    sourceType,
+	}
+	if binary {
+		meta.Encoding = encodingBinary
+	}
+	for _, codec := range []string{decositesv1alpha1.CodecBrotli, decositesv1alpha1.CodecGzip, decositesv1alpha1.CodecIdentity} {
+		key := decositesv1alpha1.DecofileCodecKey(codec)
+		if _, ok := configData[key]; ok {
+			meta.Codecs[key] = codec
+		}
+	}
+	return meta
+}
+
+// render returns the decofile.meta.json value for the content stamped with
+// timestamp.
+func (m contentMeta) render(timestamp string) string {
+	m.Timestamp = timestamp
+	// Marshalling strings, ints and a string map cannot fail
+	data, _ := json.Marshal(m)
+	return string(data)
+}
+
+// storedContentHash returns the contentHash recorded in cm's
+// decofile.meta.json, or "" when it has none.
+func storedContentHash(cm *corev1.ConfigMap) string {
+	var stored contentMeta
+	if err := json.Unmarshal([]byte(cm.Data[decositesv1alpha1.DecofileMetaKey]), &stored); err != nil {
+		return ""
+	}
+	return stored.ContentHash
+}
@@ -0,0 +1,237 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/andybalholm/brotli"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+func TestContentMeta(t *testing.T) {
+	content := `{"a":1}`
+	data := map[string]string{"decofile.bin": "c3RvcmVk"}
+	if err := addCodecKeys(data, []byte(content), []string{decositesv1alpha1.CodecIdentity}); err != nil {
+		t.Fatalf("addCodecKeys: %v", err)
+	}
+
+	var got contentMeta
+	if err := json.Unmarshal([]byte(newContentMeta(data, content, "inline", decositesv1alpha1.CodecGzip, false).render("1700000000")), &got); err != nil {
+		t.Fatalf("unmarshal meta: %v", err)
+	}
+	if got.Codec != "br" || got.Codecs["decofile.bin"] != "br" || got.Codecs["decofile.json"] != "identity" || len(got.Codecs) != 2 {
+		t.Errorf("codecs = %s %v, want br and {decofile.bin: br, decofile.json: identity}", got.Codec, got.Codecs)
+	}
+	if got.OriginalBytes != len(content) || got.StoredBytes != len("c3RvcmVk") {
+		t.Errorf("sizes = %d/%d, want %d/%d", got.OriginalBytes, got.StoredBytes, len(content), len("c3RvcmVk"))
+	}
+	if got.Timestamp != "1700000000" || got.ContentHash != sha256hex(content) || got.SourceType != "inline" || got.DefaultCodec != "gzip" {
+		t.Errorf("meta = %+v", got)
+	}
+}
+
+func TestReconcile_ContentMetaKey(t *testing.T) {
+	ctx := context.Background()
+	scheme := newOwnerTestScheme(t)
+	df := makeDecofile("foo", "")
+	df.Spec.Source = SourceTypeInline
+	df.Spec.Inline = &decositesv1alpha1.InlineSource{Value: map[string]runtime.RawExtension{
+		"config.json": {Raw: []byte(`{"a":1}`)},
+	}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(df).
+		WithStatusSubresource(&decositesv1alpha1.Decofile{}).Build()
+	r := &DecofileReconciler{Client: c, Scheme: scheme}
+	key := client.ObjectKeyFromObject(df)
+	cmKey := client.ObjectKey{Namespace: testNamespace, Name: df.ConfigMapName()}
+
+	readMeta := func() (contentMeta, *corev1.ConfigMap) {
+		t.Helper()
+		cm := &corev1.ConfigMap{}
+		if err := c.Get(ctx, cmKey, cm); err != nil {
+			t.Fatalf("get ConfigMap: %v", err)
+		}
+		var meta contentMeta
+		if err := json.Unmarshal([]byte(cm.Data[decositesv1alpha1.DecofileMetaKey]), &meta); err != nil {
+			t.Fatalf("unmarshal %s: %v", decositesv1alpha1.DecofileMetaKey, err)
+		}
+		return meta, cm
+	}
+
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	meta, cm := readMeta()
+	timestamp := cm.Data["timestamp.txt"]
+	if meta.Timestamp != timestamp || meta.SourceType != SourceTypeInline || meta.ContentHash != sha256hex(`{"config":{"a":1}}`) {
+		t.Errorf("meta = %+v, want timestamp %s and the inline content hash", meta, timestamp)
+	}
+
+	// A ConfigMap written before the key existed gets it, keeping its timestamp
+	delete(cm.Data, decositesv1alpha1.DecofileMetaKey)
+	if err := c.Update(ctx, cm); err != nil {
+		t.Fatalf("update ConfigMap: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	meta, cm = readMeta()
+	if cm.Data["timestamp.txt"] != timestamp || meta.Timestamp != timestamp {
+		t.Errorf("timestamp = %s (meta %s), want %s kept", cm.Data["timestamp.txt"], meta.Timestamp, timestamp)
+	}
+}
+
+func TestReconcile_ChecksumKey(t *testing.T) {
+	ctx := context.Background()
+	scheme := newOwnerTestScheme(t)
+	df := makeDecofile("foo", "")
+	df.Spec.Source = SourceTypeInline
+	df.Spec.Inline = &decositesv1alpha1.InlineSource{Value: map[string]runtime.RawExtension{
+		"config.json": {Raw: []byte(`{"a":1}`)},
+	}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(df).
+		WithStatusSubresource(&decositesv1alpha1.Decofile{}).Build()
+	r := &DecofileReconciler{Client: c, Scheme: scheme}
+	key := client.ObjectKeyFromObject(df)
+	cmKey := client.ObjectKey{Namespace: testNamespace, Name: df.ConfigMapName()}
+
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(ctx, cmKey, cm); err != nil {
+		t.Fatalf("get ConfigMap: %v", err)
+	}
+	got := &decositesv1alpha1.Decofile{}
+	if err := c.Get(ctx, key, got); err != nil {
+		t.Fatalf("get Decofile: %v", err)
+	}
+	checksum := cm.Data[decositesv1alpha1.DecofileChecksumKey]
+	if checksum != sha256hex(`{"config":{"a":1}}`) || checksum != got.Status.ContentHash {
+		t.Errorf("%s = %q, want the content hash %q", decositesv1alpha1.DecofileChecksumKey, checksum, got.Status.ContentHash)
+	}
+
+	// A ConfigMap written before the key existed gets it, keeping its timestamp
+	timestamp := cm.Data["timestamp.txt"]
+	delete(cm.Data, decositesv1alpha1.DecofileChecksumKey)
+	if err := c.Update(ctx, cm); err != nil {
+		t.Fatalf("update ConfigMap: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if err := c.Get(ctx, cmKey, cm); err != nil {
+		t.Fatalf("get ConfigMap: %v", err)
+	}
+	if cm.Data[decositesv1alpha1.DecofileChecksumKey] != checksum || cm.Data["timestamp.txt"] != timestamp {
+		t.Errorf("checksum = %q, timestamp = %s; want %q restored and %s kept",
+			cm.Data[decositesv1alpha1.DecofileChecksumKey], cm.Data["timestamp.txt"], checksum, timestamp)
+	}
+}
+
+func TestReconcile_NotifiesOnlyOnContentHashChange(t *testing.T) {
+	ctx := context.Background()
+	scheme := newOwnerTestScheme(t)
+	var notified int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		notified++
+	}))
+	defer srv.Close()
+
+	value := `{"v":"` + strings.Repeat("abcdefgh", 256) + `"}`
+	df := makeDecofile("foo", "")
+	df.Spec.Source = SourceTypeInline
+	df.Spec.Inline = &decositesv1alpha1.InlineSource{Value: map[string]runtime.RawExtension{
+		"config.json": {Raw: []byte(value)},
+	}}
+	pod := makeNotifyPod(t, srv, "")
+	pod.Labels = map[string]string{DefaultDeploymentIdLabel: "foo"}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(df, pod).
+		WithStatusSubresource(&decositesv1alpha1.Decofile{}).Build()
+	r := &DecofileReconciler{Client: c, Scheme: scheme, HTTPClient: srv.Client()}
+	key := client.ObjectKeyFromObject(df)
+	cmKey := client.ObjectKey{Namespace: testNamespace, Name: df.ConfigMapName()}
+
+	reconcileCM := func() *corev1.ConfigMap {
+		t.Helper()
+		if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key}); err != nil {
+			t.Fatalf("Reconcile: %v", err)
+		}
+		cm := &corev1.ConfigMap{}
+		if err := c.Get(ctx, cmKey, cm); err != nil {
+			t.Fatalf("get ConfigMap: %v", err)
+		}
+		return cm
+	}
+	cm := reconcileCM()
+	timestamp, stored := cm.Data["timestamp.txt"], cm.BinaryData["decofile.bin"]
+
+	// Another actor labels the ConfigMap: the Owns watch re-runs Reconcile
+	cm.Labels["team"] = "storefront"
+	if err := c.Update(ctx, cm); err != nil {
+		t.Fatalf("label ConfigMap: %v", err)
+	}
+	cm = reconcileCM()
+	if notified != 0 {
+		t.Errorf("pods notified %d time(s) after a label change", notified)
+	}
+
+	// The same JSON encoded differently is rewritten, but not a change
+	var buf bytes.Buffer
+	w := brotli.NewWriterLevel(&buf, 0)
+	_, _ = w.Write([]byte(`{"config":` + value + `}`))
+	_ = w.Close()
+	if bytes.Equal(buf.Bytes(), stored) {
+		t.Fatal("test setup: re-encoding produced the same bytes")
+	}
+	cm.BinaryData["decofile.bin"] = buf.Bytes()
+	if err := c.Update(ctx, cm); err != nil {
+		t.Fatalf("re-encode ConfigMap: %v", err)
+	}
+	cm = reconcileCM()
+	if notified != 0 {
+		t.Errorf("pods notified %d time(s) for re-encoded unchanged content", notified)
+	}
+	if !bytes.Equal(cm.BinaryData["decofile.bin"], stored) || cm.Data["timestamp.txt"] != timestamp {
+		t.Errorf("ConfigMap not restored to the rendered bytes with timestamp %s kept (got %s)", timestamp, cm.Data["timestamp.txt"])
+	}
+
+	// A genuine content change notifies
+	if err := c.Get(ctx, key, df); err != nil {
+		t.Fatalf("get Decofile: %v", err)
+	}
+	df.Spec.Inline.Value["config.json"] = runtime.RawExtension{Raw: []byte(`{"v":2}`)}
+	if err := c.Update(ctx, df); err != nil {
+		t.Fatalf("update Decofile: %v", err)
+	}
+	reconcileCM()
+	if notified != 1 {
+		t.Errorf("pods notified %d time(s) for a content change, want 1", notified)
+	}
+}
@@ -0,0 +1,41 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"strconv"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+// ContentPath is the operator API path serving a Decofile's published
+// content.
+func ContentPath(namespace, name string) string {
+	return "/decofile/" + namespace + "/" + name
+}
+
+// ContentETag is the strong ETag of rendered content, shared by the status
+// and the operator API so a poller can compare either.
+func ContentETag(content []byte) string {
+	return `"` + sha256hex(string(content)) + `"`
+}
+
+// publishedContentURL is status.contentURL for decofile at revision, under
+// baseURL (see DecofileReconciler.ContentBaseURL).
+func publishedContentURL(baseURL string, decofile *decositesv1alpha1.Decofile, revision int64) string {
+	return baseURL + ContentPath(decofile.Namespace, decofile.Name) + "?rev=" + strconv.FormatInt(revision, 10)
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+func TestReconcile_PublishContent(t *testing.T) {
+	ctx := context.Background()
+	scheme := newOwnerTestScheme(t)
+	df := makeDecofile("foo", "")
+	df.Spec.Source = SourceTypeInline
+	df.Spec.Inline = &decositesv1alpha1.InlineSource{Value: map[string]runtime.RawExtension{
+		"config.json": {Raw: []byte(`{"a":1}`)},
+	}}
+	df.Spec.PublishContent = true
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(df).
+		WithStatusSubresource(&decositesv1alpha1.Decofile{}).Build()
+	r := &DecofileReconciler{Client: c, Scheme: scheme, ContentBaseURL: "https://operator.example.com"}
+
+	key := client.ObjectKeyFromObject(df)
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	got := &decositesv1alpha1.Decofile{}
+	if err := c.Get(ctx, key, got); err != nil {
+		t.Fatalf("get Decofile: %v", err)
+	}
+	if want := "https://operator.example.com/decofile/sites-foo/foo?rev=1"; got.Status.ContentURL != want {
+		t.Errorf("ContentURL = %q, want %q", got.Status.ContentURL, want)
+	}
+
+	// The ETag matches what the operator API computes from the ConfigMap
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: df.ConfigMapName()}, cm); err != nil {
+		t.Fatalf("get ConfigMap: %v", err)
+	}
+	content, err := DecodeDecofileConfigMap(cm)
+	if err != nil {
+		t.Fatalf("DecodeDecofileConfigMap: %v", err)
+	}
+	if want := ContentETag(content); got.Status.ContentETag != want {
+		t.Errorf("ContentETag = %q, want %q", got.Status.ContentETag, want)
+	}
+
+	// Turning publishing off clears the status
+	got.Spec.PublishContent = false
+	if err := c.Update(ctx, got); err != nil {
+		t.Fatalf("update Decofile: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if err := c.Get(ctx, key, got); err != nil {
+		t.Fatalf("get Decofile: %v", err)
+	}
+	if got.Status.ContentURL != "" || got.Status.ContentETag != "" {
+		t.Errorf("status = %q %q, want both cleared", got.Status.ContentURL, got.Status.ContentETag)
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func readyCondition(status metav1.ConditionStatus, message string, at time.Time) metav1.Condition {
+	return metav1.Condition{
+		Type:               "Ready",
+		Status:             status,
+		Reason:             "Test",
+		Message:            message,
+		LastTransitionTime: metav1.NewTime(at),
+	}
+}
+
+func TestUpdateCondition_LastTransitionTime(t *testing.T) {
+	df := makeDecofile("foo", "")
+	df.Generation = 3
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	updateCondition(df, readyCondition(metav1.ConditionTrue, "v1", t0))
+	// Repeated reconciles with the same status keep the original transition
+	// time, even when the message changes
+	updateCondition(df, readyCondition(metav1.ConditionTrue, "v1", t0.Add(time.Minute)))
+	updateCondition(df, readyCondition(metav1.ConditionTrue, "v2", t0.Add(2*time.Minute)))
+
+	cond := meta.FindStatusCondition(df.Status.Conditions, "Ready")
+	if cond == nil {
+		t.Fatal("Ready condition not set")
+	}
+	if !cond.LastTransitionTime.Time.Equal(t0) {
+		t.Errorf("LastTransitionTime = %v, want %v", cond.LastTransitionTime, t0)
+	}
+	if cond.Message != "v2" || cond.ObservedGeneration != 3 {
+		t.Errorf("condition = %+v, want message v2 and observedGeneration 3", cond)
+	}
+
+	flipped := t0.Add(3 * time.Minute)
+	updateCondition(df, readyCondition(metav1.ConditionFalse, "broken", flipped))
+	cond = meta.FindStatusCondition(df.Status.Conditions, "Ready")
+	if !cond.LastTransitionTime.Time.Equal(flipped) {
+		t.Errorf("LastTransitionTime after a status change = %v, want %v", cond.LastTransitionTime, flipped)
+	}
+	if len(df.Status.Conditions) != 1 {
+		t.Errorf("got %d conditions, want 1", len(df.Status.Conditions))
+	}
+}
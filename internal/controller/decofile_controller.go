@@ -17,26 +17,33 @@ limitations under the License.
 package controller
 
 import (
+	"cmp"
 	"context"
 	"encoding/base64"
 	stderrors "errors"
 	"fmt"
+	"maps"
 	"net/http"
+	"sort"
 	"time"
 
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/utils/ptr"
 	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -50,9 +57,22 @@ import (
 const (
 	condTypePodsNotified   = "PodsNotified"
 	DecofileControllerName = "decofile"
-)
 
-// deploymentIdLabel is declared in notifier.go (same package).
+	// renotifyAnnotation forces pods to be re-notified with the current
+	// content even when it hasn't changed. Any new value triggers exactly one
+	// re-notification; the handled value is recorded in status.renotifyNonce.
+	renotifyAnnotation = "deco.sites/renotify"
+
+	// githubHeadAnnotation is set by GitHubBranchWatcher on Decofiles whose
+	// spec.github.commit is a branch, to the SHA the branch points at. A new
+	// value re-downloads the branch once; the handled value is recorded in
+	// status.githubHead.
+	githubHeadAnnotation = "deco.sites/github-head"
+
+	// codecAnnotation is set on the pod template (and so the Revision) by the
+	// Service webhook when the runtime reads a codec other than Brotli.
+	codecAnnotation = "deco.sites/decofile-codec"
+)
 
 // DecofileReconciler reconciles a Decofile object
 type DecofileReconciler struct {
@@ -67,26 +87,109 @@ type DecofileReconciler struct {
 	// S3 delivers the decofile via S3+HTTP for target=s3 (content-heavy sites
 	// that would exceed the etcd ConfigMap limit). Nil = s3 target unavailable.
 	S3 *S3Uploader
+	// Transformers resolves spec.transforms post-processing steps. Nil = a
+	// Decofile that lists transforms fails to reconcile.
+	Transformers *TransformerRegistry
+	// MaxJSONDepth and MaxJSONKeys bound the nesting depth and total key count
+	// of retrieved content (0 = DefaultMaxJSONDepth / DefaultMaxJSONKeys).
+	MaxJSONDepth int
+	MaxJSONKeys  int
+	// MaxConfigMapKeys and MaxConfigMapBytes bound the rendered ConfigMap
+	// (0 = DefaultMaxConfigMapKeys / DefaultMaxConfigMapBytes).
+	MaxConfigMapKeys  int
+	MaxConfigMapBytes int
+	// ReconcileTimeout bounds a whole reconcile, from source retrieval to the
+	// last pod notification. 0 = unbounded.
+	ReconcileTimeout time.Duration
+	// RolloutEvents is notified when new content reached a Decofile's pods.
+	// Nil = no rollout events are sent.
+	RolloutEvents *RolloutEventSink
+	// CachePurger is asked to purge an external cache of the content when
+	// a change was rolled out. Nil = no purges are sent.
+	CachePurger *CachePurger
+	// Recorder records Kubernetes events on Decofiles. Nil = none.
+	Recorder record.EventRecorder
+	// EventStream serves reloads to pods subscribed for
+	// spec.notifyTransport=sse. Nil = sse Decofiles fail to notify.
+	EventStream *EventStream
+	// ContentBaseURL is the externally reachable address of the operator
+	// API (e.g. https://operator.example.com), without a trailing slash,
+	// prefixed to status.contentURL. Empty leaves the URL relative to the
+	// operator API.
+	ContentBaseURL string
+	// CheckGitHubTokenScopes asks GitHub, on the first reconcile of each
+	// Decofile generation, whether the token can read the repository
+	// (condTypeGitHubTokenScopes).
+	CheckGitHubTokenScopes bool
+	// Sources holds the operator-wide settings of Decofile sources.
+	Sources SourceOptions
+	// Notify holds the process-wide notification settings every Notifier
+	// this reconciler builds starts from.
+	Notify NotifyOptions
+	// Base64ConfigMapData keeps the compressed codec keys (decofile.bin,
+	// decofile.gz) base64 in the ConfigMap's data, for runtimes that don't
+	// read decofile.meta.json's encoding yet. By default they are stored as
+	// raw bytes in binaryData, saving the ~33% base64 adds against the etcd
+	// limit.
+	Base64ConfigMapData bool
 }
 
+// binaryData reports whether compressed keys are stored in binaryData.
+func (r *DecofileReconciler) binaryData() bool {
+	return !r.Base64ConfigMapData
+}
+
+// DefaultReconcileTimeout is the default --reconcile-timeout. It leaves room
+// for a slow archive download plus a full round of pod notifications.
+const DefaultReconcileTimeout = 5 * time.Minute
+
 // +kubebuilder:rbac:groups=deco.sites,resources=decofiles,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=deco.sites,resources=decofiles/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=deco.sites,resources=decofiles/finalizers,verbs=update
 // +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 // +kubebuilder:rbac:groups=serving.knative.dev,resources=revisions,verbs=get;list;watch
+// +kubebuilder:rbac:groups=serving.knative.dev,resources=services,verbs=get;list;watch;patch
 // +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
-// nolint:gocyclo
+//
+// The reconcile runs under ReconcileTimeout so a pathological one (a huge
+// archive, hundreds of slow pods) gives its worker back; source retrieval,
+// transforms and notifications all stop at the deadline, and the Decofile is
+// requeued with backoff.
 func (r *DecofileReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	timeout := r.ReconcileTimeout
+	if timeout <= 0 {
+		return r.reconcile(ctx, req)
+	}
+
+	reconcileCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	result, err := r.reconcile(reconcileCtx, req)
+	if stderrors.Is(reconcileCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+		decofileReconcileTimeouts.Inc()
+		logf.FromContext(ctx).Error(err, "Reconcile timed out, requeueing", "decofile", req.NamespacedName, "timeout", timeout)
+		return ctrl.Result{}, fmt.Errorf("reconcile of %s timed out after %v: %w", req.NamespacedName, timeout, context.DeadlineExceeded)
+	}
+	return result, err
+}
+
+// reconcile does the work of Reconcile.
+// nolint:gocyclo
+func (r *DecofileReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	reconcileStart := time.Now()
 	log := logf.FromContext(ctx)
 
 	log.Info("Starting reconciliation", "decofile", req.NamespacedName)
 
+	// Keep the pod notifier from pushing this Decofile's ConfigMap while it
+	// is being rewritten (see decofileLocks)
+	defer decofileLocks.Lock(req.NamespacedName)()
+
 	// Fetch the Decofile instance
 	fetchStart := time.Now()
 	decofile := &decositesv1alpha1.Decofile{}
@@ -95,6 +198,7 @@ func (r *DecofileReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		if errors.IsNotFound(err) {
 			// Decofile was deleted, nothing to do (ConfigMap will be garbage collected via owner reference)
 			log.Info("Decofile resource not found. Ignoring since object must be deleted")
+			decofileReconciles.Forget(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
@@ -103,12 +207,37 @@ func (r *DecofileReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	}
 
 	log.V(1).Info("Fetched Decofile", "duration", time.Since(fetchStart))
+	decofileReconciles.Observe(req.NamespacedName)
+
+	// spec.deletionPolicy: manage the orphan finalizer; a Decofile being
+	// deleted only needs its ConfigMap released
+	if done, err := r.handleDeletionPolicy(ctx, decofile); done || err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Validate-only: render and check, never write anything but status
+	if decofile.Spec.ValidateOnly {
+		return r.reconcileValidateOnly(ctx, req, decofile)
+	}
+
+	// spec.pauseUntil: hold back content changes until the pause expires
+	if result, paused, err := r.checkPause(ctx, req, decofile); paused || err != nil {
+		return result, err
+	}
+
+	// spec.github.refreshInterval: follow a branch by re-resolving it on every
+	// (requeued) reconcile
+	refresh := githubRefreshInterval(decofile)
+	if refresh > 0 {
+		r.refreshGitHubHead(ctx, decofile)
+	}
 
 	// s3 target: deliver over HTTP from S3 instead of a ConfigMap (escapes the
 	// etcd ConfigMap limit). Handled inline (not a FastDeployment) because it
 	// reuses this package's source retrieval + pod notifier.
 	if decofile.Spec.Target == decositesv1alpha1.TargetS3 {
-		return r.reconcileS3(ctx, req, decofile)
+		result, err := r.reconcileS3(ctx, req, decofile)
+		return requeueForRefresh(result, refresh), err
 	}
 
 	// Pluggable delivery: non-configmap targets (e.g. tanstack-kv KV sync) are
@@ -135,28 +264,46 @@ func (r *DecofileReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	// Define the ConfigMap name
 	configMapName := decofile.ConfigMapName()
 
+	// Codecs the consumers of this Decofile read; each gets its own key in
+	// the shared ConfigMap. Non-fatal: fall back to Brotli only.
+	codecs, err := r.consumerCodecs(ctx, decofile)
+	if err != nil {
+		log.Error(err, "Failed to resolve consumer codecs (non-fatal)")
+	}
+
+	// A new deco.sites/renotify nonce re-pushes the current content to pods
+	renotifyNonce := decofile.Annotations[renotifyAnnotation]
+	renotify := renotifyNonce != "" && renotifyNonce != decofile.Status.RenotifyNonce
+
 	// For GitHub source, check if we need to re-download based on commit
 	shouldRetrieve := true
 	if decofile.Spec.Source == SourceTypeGitHub && decofile.Spec.GitHub != nil {
 		// Check if commit changed
-		if decofile.Status.GitHubCommit == decofile.Spec.GitHub.Commit {
+		if githubUpToDate(decofile) && !renotify {
 			// Commit hasn't changed, check if ConfigMap exists
 			testCM := &corev1.ConfigMap{}
 			err := r.Get(ctx, client.ObjectKey{Name: configMapName, Namespace: decofile.Namespace}, testCM)
-			if err == nil {
+			if err == nil && !hasCodecKeys(testCM, codecs) {
+				log.Info("ConfigMap is missing a key for a consumer codec, re-rendering", "codecs", codecs)
+			} else if err == nil && configMapOwnershipDrifted(decofile, testCM) {
+				log.Info("ConfigMap ownership differs from spec.configMapOwnership, re-rendering", "configMapOwnership", decofile.Spec.ConfigMapOwnership)
+			} else if err == nil {
 				// Check if notification is in progress or failed
-				hasIncompleteNotification := false
-				for _, cond := range decofile.Status.Conditions {
-					if cond.Type == condTypePodsNotified &&
-						(cond.Status == metav1.ConditionUnknown || cond.Status == metav1.ConditionFalse) {
-						hasIncompleteNotification = true
-						break
-					}
-				}
+				notified := meta.FindStatusCondition(decofile.Status.Conditions, condTypePodsNotified)
+				hasIncompleteNotification := notified != nil &&
+					(notified.Status == metav1.ConditionUnknown || notified.Status == metav1.ConditionFalse) ||
+					decofile.Status.PendingNotification != nil
 
 				if !hasIncompleteNotification {
 					// ConfigMap exists, commit unchanged, and no incomplete notifications - skip download
 					shouldRetrieve = false
+					if applyConfigMapLabels(testCM, decofile) {
+						log.Info("Restoring ConfigMap labels", "ConfigMap.Name", testCM.Name)
+						if err := r.Update(ctx, testCM); err != nil {
+							log.Error(err, "Failed to restore ConfigMap labels", "ConfigMap.Name", testCM.Name)
+							return ctrl.Result{}, err
+						}
+					}
 					log.V(1).Info("GitHub commit unchanged, ConfigMap exists, and no incomplete notifications", "commit", decofile.Spec.GitHub.Commit)
 				} else {
 					log.Info("Incomplete notification detected, continuing reconciliation to retry notification")
@@ -168,30 +315,81 @@ func (r *DecofileReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	if !shouldRetrieve {
 		// Nothing changed - skip this reconciliation
 		log.V(1).Info("GitHub commit unchanged and ConfigMap exists, skipping reconciliation")
-		return ctrl.Result{}, nil
+		return requeueForRefresh(ctrl.Result{}, refresh), nil
 	}
 
 	// Get the appropriate source implementation
-	source, err := NewSource(r.Client, decofile)
+	source, err := NewSource(r.Client, decofile, r.Sources)
 	if err != nil {
 		log.Error(err, "Failed to create source")
 		return ctrl.Result{}, err
 	}
 
-	// Retrieve configuration data from source (single JSON string)
-	sourceRetrieveStart := time.Now()
-	log.Info("Starting source retrieval", "sourceType", source.SourceType())
-	jsonContent, err := source.Retrieve(ctx)
-	sourceRetrieveDuration := time.Since(sourceRetrieveStart)
-	if err != nil {
-		log.Error(err, "Failed to retrieve data from source", "duration", sourceRetrieveDuration)
-		return ctrl.Result{}, err
+	// Check once per generation that the GitHub token can see the repository
+	tokenCheck := r.checkGitHubToken(ctx, decofile)
+
+	// A GitHub tree that hasn't changed since the last render is served from
+	// the ConfigMap instead of downloading the archive again
+	var githubTree, githubSHA, jsonContent string
+	var githubLayers []decositesv1alpha1.GitHubLayerStatus
+	var compositeSources []decositesv1alpha1.CompositeSourceStatus
+	var reused bool
+	if decofile.Spec.Source == SourceTypeGitHub && decofile.Spec.GitHub != nil {
+		githubTree, jsonContent, reused = r.unchangedGitHubContent(ctx, decofile, configMapName)
+		githubSHA = decofile.Status.GitHubSHA
+	}
+	if gs, ok := source.(*GitHubSource); ok && !reused {
+		if githubCacheBusted(decofile) {
+			log.Info("spec.github.cacheBust changed, downloading afresh", "cacheBust", decofile.Spec.GitHub.CacheBust)
+			gs.WithoutCache()
+		} else if decofile.Spec.GitHub.Incremental {
+			gs.WithPrevious(r.incrementalBase(ctx, decofile, configMapName))
+		}
+	}
+
+	if !reused {
+		// Retrieve configuration data from source (single JSON string)
+		sourceRetrieveStart := time.Now()
+		log.Info("Starting source retrieval", "sourceType", source.SourceType())
+		jsonContent, err = source.Retrieve(ctx)
+		sourceRetrieveDuration := time.Since(sourceRetrieveStart)
+		if err != nil {
+			log.Error(err, "Failed to retrieve data from source", "duration", sourceRetrieveDuration)
+			if tokenCheck != nil && tokenCheck.Status == metav1.ConditionFalse {
+				// Name the likely cause instead of a bare 404
+				err = fmt.Errorf("%w (%s)", err, tokenCheck.Message)
+			}
+			r.recordFailure(ctx, req, fmt.Errorf("retrieving %s content: %w", source.SourceType(), err))
+			return ctrl.Result{}, err
+		}
+		log.Info("Source retrieval completed", "sourceType", source.SourceType(), "duration", sourceRetrieveDuration, "contentSize", len(jsonContent))
+		if gs, ok := source.(*GitHubSource); ok {
+			githubLayers = gs.Layers()
+			githubSHA = gs.SHA()
+		}
+		if cs, ok := source.(*CompositeSource); ok {
+			compositeSources = cs.Contributions()
+		}
+
+		jsonContent, err = r.applyTransforms(ctx, decofile, jsonContent)
+		if err != nil {
+			log.Error(err, "Failed to transform retrieved content")
+			r.recordFailure(ctx, req, err)
+			return ctrl.Result{}, err
+		}
+		if err := r.checkContentLimits(jsonContent); err != nil {
+			return r.rejectContent(ctx, req, err)
+		}
+		if err := checkRequiredKeys(jsonContent, decofile.Spec.RequiredKeys); err != nil {
+			return r.rejectContent(ctx, req, err)
+		}
 	}
-	log.Info("Source retrieval completed", "sourceType", source.SourceType(), "duration", sourceRetrieveDuration, "contentSize", len(jsonContent))
 
 	sourceType := source.SourceType()
 
-	// Always compress content with Brotli for consistency
+	// Always compress content with Brotli, whatever its size: runtimes read
+	// decofile.bin as Brotli, and the encoder already falls back to stored
+	// (uncompressed) blocks when compressing doesn't help
 	compressionStart := time.Now()
 	log.Info("Starting Brotli compression", "inputSize", len(jsonContent))
 	compressed, err := compressBrotli([]byte(jsonContent))
@@ -205,14 +403,28 @@ func (r *DecofileReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		"decofile.bin": base64.StdEncoding.EncodeToString(compressed),
 	}
 	contentKey := "decofile.bin"
+	if err := addCodecKeys(configData, []byte(jsonContent), codecs); err != nil {
+		log.Error(err, "Failed to encode config for consumer codecs", "codecs", codecs)
+		return ctrl.Result{}, fmt.Errorf("failed to encode config: %w", err)
+	}
 
 	compressionRatio := float64(len(compressed)) / float64(len(jsonContent)) * 100
 	log.Info("Compressed config with Brotli",
 		"originalSize", len(jsonContent),
 		"compressedSize", len(compressed),
+		"storedSize", storedLen("decofile.bin", configData["decofile.bin"], r.binaryData()),
 		"ratio", fmt.Sprintf("%.1f%%", compressionRatio),
 		"duration", compressionDuration)
 
+	timestampKey := decofile.TimestampKeyOrDefault()
+	decofileMeta := newContentMeta(configData, jsonContent, sourceType, decofile.Spec.DefaultCodec(), r.binaryData())
+	// decofile.sha256 lets runtimes verify the content they loaded; it is
+	// the same hash as status.contentHash
+	configData[decositesv1alpha1.DecofileChecksumKey] = decofileMeta.ContentHash
+	if err := r.checkConfigMapLimits(configData, timestampKey, decofileMeta); err != nil {
+		return r.rejectContent(ctx, req, err)
+	}
+
 	// Check if the ConfigMap already exists
 	configMapStart := time.Now()
 	found := &corev1.ConfigMap{}
@@ -220,25 +432,31 @@ func (r *DecofileReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	log.V(1).Info("ConfigMap lookup completed", "duration", time.Since(configMapStart))
 
 	var dataChanged bool
+	var created bool
 	var timestamp string
 
 	if err != nil && errors.IsNotFound(err) {
 		// New ConfigMap - create with new timestamp (Unix seconds)
 		timestamp = fmt.Sprintf("%d", time.Now().Unix())
 		dataChanged = false // New ConfigMap, no notification needed
+		created = true
 
 		// Add timestamp
-		configData["timestamp.txt"] = timestamp
+		configData[timestampKey] = timestamp
+		configData[decositesv1alpha1.DecofileMetaKey] = decofileMeta.render(timestamp)
 
 		configMap := &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      configMapName,
 				Namespace: decofile.Namespace,
 			},
-			Data: configData,
 		}
+		if err := setConfigMapData(configMap, configData, r.binaryData()); err != nil {
+			return ctrl.Result{}, err
+		}
+		applyConfigMapLabels(configMap, decofile)
 
-		if err := controllerutil.SetControllerReference(decofile, configMap, r.Scheme); err != nil {
+		if _, err := r.setConfigMapOwnership(decofile, configMap); err != nil {
 			log.Error(err, "Failed to set owner reference on ConfigMap")
 			return ctrl.Result{}, err
 		}
@@ -255,9 +473,41 @@ func (r *DecofileReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		log.Error(err, "Failed to get ConfigMap")
 		return ctrl.Result{}, err
 	} else {
-		// ConfigMap exists - check if content changed
-		contentChanged := found.Data[contentKey] != configData[contentKey]
+		// Never overwrite a ConfigMap someone else created under our name
+		adopt, conflict, err := r.checkConfigMapOwnership(ctx, req, decofile, found)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if conflict {
+			return ctrl.Result{RequeueAfter: ownershipConflictRequeue}, nil
+		}
+		// spec.configMapOwnership: adopt an operator-labelled ConfigMap
+		// without a controller (a recreated Decofile takes back one an orphan
+		// deletion released), or release it in the orphan mode
+		ownershipChanged, err := r.setConfigMapOwnership(decofile, found)
+		if err != nil {
+			log.Error(err, "Failed to set owner reference on ConfigMap")
+			return ctrl.Result{}, err
+		}
+		if ownershipChanged {
+			log.Info("Updating ConfigMap ownership", "ConfigMap.Name", found.Name, "adopt", adopt,
+				"configMapOwnership", decofile.Spec.ConfigMapOwnership)
+		}
+
+		// ConfigMap exists - check if content changed. Label and ownership
+		// drift is repaired by whichever update below runs.
+		existing := configMapData(found)
+		encodingChanged := existing[contentKey] != configData[contentKey]
+		// Different bytes for the JSON the stored contentHash already
+		// describes (e.g. a new Brotli encoder) is not a content change: the
+		// keys are rewritten below without notifying pods. A ConfigMap with
+		// no metadata falls back to the byte comparison.
+		contentChanged := encodingChanged && storedContentHash(found) != decofileMeta.ContentHash
 		dataChanged = contentChanged
+		labelsDrifted := applyConfigMapLabels(found, decofile) || ownershipChanged
+		// decofile.meta.json as it should read for the stored timestamp, so
+		// an outdated or missing one is rewritten without a new timestamp
+		configData[decositesv1alpha1.DecofileMetaKey] = decofileMeta.render(found.Data[timestampKey])
 
 		if dataChanged {
 			// Content changed - update with new timestamp (Unix seconds)
@@ -265,8 +515,11 @@ func (r *DecofileReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 			log.Info("ConfigMap content changed, updating", "ConfigMap.Name", found.Name, "newTimestamp", timestamp)
 
 			// Replace all data
-			found.Data = configData
-			found.Data["timestamp.txt"] = timestamp
+			configData[decositesv1alpha1.DecofileMetaKey] = decofileMeta.render(timestamp)
+			configData[timestampKey] = timestamp
+			if err := setConfigMapData(found, configData, r.binaryData()); err != nil {
+				return ctrl.Result{}, err
+			}
 
 			updateStart := time.Now()
 			err = r.Update(ctx, found)
@@ -275,21 +528,70 @@ func (r *DecofileReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 				return ctrl.Result{}, err
 			}
 			log.Info("Updated existing ConfigMap", "ConfigMap.Namespace", found.Namespace, "ConfigMap.Name", found.Name, "duration", time.Since(updateStart))
+		} else if encodingChanged || !sameDataKeys(existing, configData, timestampKey) ||
+			found.Data[decositesv1alpha1.DecofileMetaKey] != configData[decositesv1alpha1.DecofileMetaKey] ||
+			storedAsBinary(found) != r.binaryData() {
+			// Same content, differently encoded, or different consumer
+			// codecs, timestamp key, metadata or data/binaryData storage:
+			// re-render the keys but keep the timestamp, as pods already have
+			// this content
+			timestamp = found.Data[timestampKey]
+			if timestamp == "" {
+				// spec.timestampKey was renamed; stamp the new key
+				timestamp = fmt.Sprintf("%d", time.Now().Unix())
+			}
+			configData[decositesv1alpha1.DecofileMetaKey] = decofileMeta.render(timestamp)
+			log.Info("ConfigMap data keys changed, updating", "ConfigMap.Name", found.Name, "codecs", codecs, "timestampKey", timestampKey)
+
+			configData[timestampKey] = timestamp
+			if err := setConfigMapData(found, configData, r.binaryData()); err != nil {
+				return ctrl.Result{}, err
+			}
+			if err := r.Update(ctx, found); err != nil {
+				log.Error(err, "Failed to update ConfigMap codec keys", "ConfigMap.Namespace", found.Namespace, "ConfigMap.Name", found.Name)
+				return ctrl.Result{}, err
+			}
 		} else {
 			// Content unchanged - keep existing timestamp
-			timestamp = found.Data["timestamp.txt"]
+			timestamp = found.Data[timestampKey]
 			log.V(1).Info("ConfigMap content unchanged, keeping existing timestamp", "ConfigMap.Name", found.Name)
+			if labelsDrifted {
+				log.Info("Restoring ConfigMap labels", "ConfigMap.Name", found.Name)
+				if err := r.Update(ctx, found); err != nil {
+					log.Error(err, "Failed to restore ConfigMap labels", "ConfigMap.Namespace", found.Namespace, "ConfigMap.Name", found.Name)
+					return ctrl.Result{}, err
+				}
+			}
 		}
 	}
 
+	// spec.destinations: hand the same rendered content to the extra
+	// destinations; failures are recorded and retried without holding back
+	// pod notification
+	renderedData := maps.Clone(configData)
+	renderedData[timestampKey] = timestamp
+	destinationsHash, destinationsErr := r.deliverDestinations(ctx, decofile, &Rendered{JSON: jsonContent, Data: renderedData, Timestamp: timestamp})
+
 	// Determine deploymentId (default to decofile name if not specified)
 	deploymentId := decofile.Spec.DeploymentId
 	if deploymentId == "" {
 		deploymentId = decofile.Name
 	}
 
+	// Pods are notified on content change or on an explicit renotify request.
+	// spec.notifyGracePeriod holds content changes back and notifies once,
+	// with the latest content, when the grace period ends.
+	pendingNotification, notifyChange, graceWait := notifyGrace(decofile, dataChanged, timestamp, time.Now())
+	if graceWait > 0 {
+		log.Info("Holding back pod notification for the grace period", "timestamp", timestamp, "notifyAt", pendingNotification.NotifyAt)
+	}
+	shouldNotify := notifyChange || renotify
+	if renotify && !notifyChange {
+		log.Info("Renotify requested, re-pushing unchanged content to pods", "nonce", renotifyNonce)
+	}
+
 	// Reset PodsNotified condition when change is detected (before notifying)
-	if dataChanged {
+	if shouldNotify {
 		// Set condition to InProgress before attempting notification
 		tempDecofile := &decositesv1alpha1.Decofile{}
 		err = r.Get(ctx, req.NamespacedName, tempDecofile)
@@ -319,13 +621,28 @@ func (r *DecofileReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 	var podsNotified bool
 	var notificationError string
 	notificationReason := "NotificationFailed"
-
-	if dataChanged {
+	rollout := decofile.Spec.RolloutStrategy == decositesv1alpha1.RolloutKnativeRevision
+	var podStats NotifyStats
+
+	if shouldNotify && rollout {
+		// A new Revision mounts the new content on start, so pods aren't
+		// pushed to. A renotify has unchanged content, hence the nonce.
+		stamp := timestamp
+		if renotify && !notifyChange {
+			stamp = timestamp + "-" + renotifyNonce
+		}
+		if err := r.rolloutKnativeServices(ctx, decofile.Namespace, deploymentId, stamp); err != nil {
+			notificationError = err.Error()
+			notificationReason = "RolloutFailed"
+			log.Error(err, "Failed to roll out a new Knative Revision", "deploymentId", deploymentId)
+		} else {
+			podsNotified = true
+		}
+	} else if shouldNotify {
 		notifyStart := time.Now()
 		log.Info("ConfigMap data changed, notifying pods", "timestamp", timestamp, "deploymentId", deploymentId)
 
-		notifier := NewNotifier(r.Client, r.HTTPClient)
-		err = notifier.NotifyPodsForDecofile(ctx, decofile.Namespace, deploymentId, timestamp, jsonContent)
+		err = r.notifyPods(ctx, decofile, deploymentId, timestamp, jsonContent, &podStats)
 		notifyDuration := time.Since(notifyStart)
 		if err != nil {
 			notificationError = err.Error()
@@ -333,6 +650,12 @@ func (r *DecofileReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 			if stderrors.Is(err, ErrMissingReloadToken) {
 				notificationReason = "MissingReloadToken"
 				log.Error(err, "Pods are missing DECO_RELEASE_RELOAD_TOKEN; the running revision cannot be hot-reloaded and must be redeployed so the mutating webhook re-injects the token", "deploymentId", deploymentId, "duration", notifyDuration)
+			} else if stderrors.Is(err, ErrCanaryFailed) {
+				notificationReason = "CanaryFailed"
+				log.Error(err, "Canary pod failed, the remaining pods were not notified", "deploymentId", deploymentId, "duration", notifyDuration)
+			} else if stderrors.Is(err, ErrReloadAuthFailed) {
+				notificationReason = "AuthFailed"
+				log.Error(err, "Pods rejected the reload token; the token is out of sync with the running revision", "deploymentId", deploymentId, "duration", notifyDuration)
 			} else {
 				log.Error(err, "Failed to notify pods", "deploymentId", deploymentId, "duration", notifyDuration)
 			}
@@ -343,73 +666,208 @@ func (r *DecofileReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		}
 	}
 
-	// Re-fetch the Decofile to get the latest version before updating status
-	// This prevents conflicts if the object was modified during reconciliation
-	freshDecofile := &decositesv1alpha1.Decofile{}
-	err = r.Get(ctx, req.NamespacedName, freshDecofile)
-	if err != nil {
-		log.Error(err, "Failed to re-fetch Decofile for status update")
-		return ctrl.Result{}, err
+	// Tell external release tooling that the new content is live
+	if (notifyChange || pendingNotification != nil) && podsNotified {
+		var pods *NotifyStats
+		if !rollout {
+			pods = &podStats
+		}
+		r.sendRolloutEvent(ctx, decofile, deploymentId, timestamp, renderedCommit(decofile, githubSHA), pods)
 	}
 
-	// Update Decofile status
-	freshDecofile.Status.ConfigMapName = configMapName
-	freshDecofile.Status.LastUpdated = metav1.Time{Time: time.Now()}
-	freshDecofile.Status.SourceType = sourceType
-
-	// Store GitHub commit if using GitHub source
-	if freshDecofile.Spec.Source == SourceTypeGitHub && freshDecofile.Spec.GitHub != nil {
-		freshDecofile.Status.GitHubCommit = freshDecofile.Spec.GitHub.Commit
+	// Drop copies of the previous content from an external cache, once
+	// pods were told about the change (after any notifyGracePeriod)
+	if notifyChange {
+		r.purgeCache(ctx, decofile, deploymentId, decofileMeta.ContentHash, "")
 	}
 
-	// Update Ready condition
-	readyCondition := metav1.Condition{
-		Type:               "Ready",
-		Status:             metav1.ConditionTrue,
-		Reason:             "ConfigMapCreated",
-		Message:            fmt.Sprintf("ConfigMap %s created successfully from %s source", configMapName, sourceType),
-		LastTransitionTime: metav1.Now(),
+	// spec.probeReloadEndpoint: check the injected runtime answers at the
+	// reload endpoint, so a runtime without the reload contract shows up
+	// before a content change fails to reach it
+	var reloadProbe *metav1.Condition
+	if decofile.Spec.ProbeReloadEndpoint {
+		cond := r.reloadProbeCondition(ctx, decofile, deploymentId)
+		if cond.Status != metav1.ConditionTrue {
+			log.Info("Reload endpoint probe did not pass", "reason", cond.Reason, "message", cond.Message)
+		}
+		reloadProbe = &cond
 	}
-	updateCondition(freshDecofile, readyCondition)
 
-	// Update PodsNotified condition
-	if dataChanged {
-		var podsNotifiedCondition metav1.Condition
+	// Re-fetch the Decofile to get the latest version before updating status,
+	// reapplying the changes on conflict so a content change is never recorded
+	// without its Revision bump (the next reconcile would see it as unchanged)
+	statusUpdateStart := time.Now()
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		freshDecofile := &decositesv1alpha1.Decofile{}
+		if err := r.Get(ctx, req.NamespacedName, freshDecofile); err != nil {
+			return err
+		}
 
-		// Include commit or timestamp in message for matching
-		var updateIdentifier string
-		if freshDecofile.Spec.Source == SourceTypeGitHub && freshDecofile.Spec.GitHub != nil {
-			updateIdentifier = fmt.Sprintf("commit:%s", freshDecofile.Spec.GitHub.Commit)
+		// Update Decofile status
+		freshDecofile.Status.ConfigMapName = configMapName
+		freshDecofile.Status.LastUpdated = metav1.Time{Time: time.Now()}
+		freshDecofile.Status.SourceType = sourceType
+		freshDecofile.Status.CompositeSources = compositeSources
+		if created || dataChanged {
+			freshDecofile.Status.Revision++
+		}
+		if created {
+			recordHistory(freshDecofile, decositesv1alpha1.HistoryCreated,
+				fmt.Sprintf("Created ConfigMap %s from %s source (revision %d, timestamp %s)", configMapName, sourceType, freshDecofile.Status.Revision, timestamp))
+		} else if dataChanged {
+			recordHistory(freshDecofile, decositesv1alpha1.HistoryUpdated,
+				fmt.Sprintf("Content changed (revision %d, timestamp %s)", freshDecofile.Status.Revision, timestamp))
+		}
+
+		// spec.publishContent: point pollers at the operator API copy
+		if decofile.Spec.PublishContent {
+			freshDecofile.Status.ContentURL = publishedContentURL(r.ContentBaseURL, freshDecofile, freshDecofile.Status.Revision)
+			freshDecofile.Status.ContentETag = ContentETag([]byte(jsonContent))
 		} else {
-			updateIdentifier = fmt.Sprintf("timestamp:%s", timestamp)
+			freshDecofile.Status.ContentURL = ""
+			freshDecofile.Status.ContentETag = ""
 		}
 
-		if podsNotified {
-			podsNotifiedCondition = metav1.Condition{
-				Type:               condTypePodsNotified,
-				Status:             metav1.ConditionTrue,
-				Reason:             "NotificationSucceeded",
-				Message:            fmt.Sprintf("Successfully notified all pods for %s", updateIdentifier),
-				LastTransitionTime: metav1.Now(),
+		recordContentHash(freshDecofile, decofileMeta.ContentHash)
+		freshDecofile.Status.S3URL = ""
+
+		// Store GitHub commit if using GitHub source
+		if freshDecofile.Spec.Source == SourceTypeGitHub && freshDecofile.Spec.GitHub != nil {
+			freshDecofile.Status.GitHubRef = decofile.Spec.GitHub.Commit
+			freshDecofile.Status.GitHubCommit = renderedCommit(decofile, githubSHA)
+			freshDecofile.Status.GitHubHead = decofile.Annotations[githubHeadAnnotation]
+			freshDecofile.Status.GitHubTree = githubTree
+			freshDecofile.Status.GitHubSHA = githubSHA
+			freshDecofile.Status.GitHubRenderHash = ""
+			if githubSHA != "" {
+				freshDecofile.Status.GitHubRenderHash = githubRenderHash(decofile)
 			}
-		} else {
-			failMessage := fmt.Sprintf("Failed to notify pods for %s: %s", updateIdentifier, notificationError)
-			if notificationReason == "MissingReloadToken" {
-				failMessage = fmt.Sprintf("Pods for %s are missing DECO_RELEASE_RELOAD_TOKEN, so the fail-closed /.decofile/reload endpoint returns 401. Redeploy the site to create a revision with the token injected: %s", updateIdentifier, notificationError)
+			freshDecofile.Status.GitHubCacheBust = decofile.Spec.GitHub.CacheBust
+			if !reused {
+				freshDecofile.Status.GitHubLayers = githubLayers
 			}
-			podsNotifiedCondition = metav1.Condition{
-				Type:               condTypePodsNotified,
+		}
+
+		// Update DestinationsDelivered condition
+		switch {
+		case destinationsErr != nil:
+			updateCondition(freshDecofile, metav1.Condition{
+				Type:               condTypeDestinationsDelivered,
 				Status:             metav1.ConditionFalse,
-				Reason:             notificationReason,
-				Message:            failMessage,
+				Reason:             "DeliveryFailed",
+				Message:            destinationsErr.Error(),
+				LastTransitionTime: metav1.Now(),
+			})
+		case len(decofile.Spec.Destinations) > 0:
+			freshDecofile.Status.DestinationsHash = destinationsHash
+			updateCondition(freshDecofile, metav1.Condition{
+				Type:               condTypeDestinationsDelivered,
+				Status:             metav1.ConditionTrue,
+				Reason:             "Delivered",
+				Message:            fmt.Sprintf("Delivered to %d destination(s)", len(decofile.Spec.Destinations)),
 				LastTransitionTime: metav1.Now(),
+			})
+		default:
+			freshDecofile.Status.DestinationsHash = ""
+			meta.RemoveStatusCondition(&freshDecofile.Status.Conditions, condTypeDestinationsDelivered)
+		}
+
+		// Keep the marker until pods were notified, so a failed notification
+		// of a held-back change is retried
+		if shouldNotify && podsNotified {
+			pendingNotification = nil
+		}
+		freshDecofile.Status.PendingNotification = pendingNotification
+		if pendingNotification != nil && !shouldNotify {
+			updateCondition(freshDecofile, metav1.Condition{
+				Type:               condTypePodsNotified,
+				Status:             metav1.ConditionUnknown,
+				Reason:             "NotificationPending",
+				Message:            fmt.Sprintf("Held back by spec.notifyGracePeriod, notifying pods for timestamp:%s at %s", pendingNotification.Timestamp, pendingNotification.NotifyAt.UTC().Format(time.RFC3339)),
+				LastTransitionTime: metav1.Now(),
+			})
+		}
+
+		if tokenCheck == nil {
+			meta.RemoveStatusCondition(&freshDecofile.Status.Conditions, condTypeGitHubTokenScopes)
+		}
+
+		if reloadProbe != nil {
+			updateCondition(freshDecofile, *reloadProbe)
+		} else {
+			meta.RemoveStatusCondition(&freshDecofile.Status.Conditions, condTypeReloadEndpointReachable)
+		}
+
+		// Update Ready condition
+		readyCondition := metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionTrue,
+			Reason:             "ConfigMapCreated",
+			Message:            fmt.Sprintf("ConfigMap %s created successfully from %s source", configMapName, sourceType),
+			LastTransitionTime: metav1.Now(),
+		}
+		updateCondition(freshDecofile, readyCondition)
+
+		// Update PodsNotified condition
+		if shouldNotify {
+			var podsNotifiedCondition metav1.Condition
+
+			// Include commit or timestamp in message for matching
+			var updateIdentifier string
+			if freshDecofile.Spec.Source == SourceTypeGitHub && freshDecofile.Spec.GitHub != nil {
+				updateIdentifier = fmt.Sprintf("commit:%s", freshDecofile.Spec.GitHub.Commit)
+			} else {
+				updateIdentifier = fmt.Sprintf("timestamp:%s", timestamp)
+			}
+
+			if podsNotified && rollout {
+				podsNotifiedCondition = metav1.Condition{
+					Type:               condTypePodsNotified,
+					Status:             metav1.ConditionTrue,
+					Reason:             "RevisionRolledOut",
+					Message:            fmt.Sprintf("Rolled out a new Knative Revision for %s", updateIdentifier),
+					LastTransitionTime: metav1.Now(),
+				}
+			} else if podsNotified {
+				podsNotifiedCondition = metav1.Condition{
+					Type:               condTypePodsNotified,
+					Status:             metav1.ConditionTrue,
+					Reason:             "NotificationSucceeded",
+					Message:            fmt.Sprintf("Successfully notified all pods for %s", updateIdentifier),
+					LastTransitionTime: metav1.Now(),
+				}
+			} else {
+				failMessage := fmt.Sprintf("Failed to notify pods for %s: %s", updateIdentifier, notificationError)
+				if notificationReason == "MissingReloadToken" {
+					failMessage = fmt.Sprintf("Pods for %s are missing DECO_RELEASE_RELOAD_TOKEN, so the fail-closed /.decofile/reload endpoint returns 401. Redeploy the site to create a revision with the token injected: %s", updateIdentifier, notificationError)
+				} else if notificationReason == "CanaryFailed" {
+					failMessage = fmt.Sprintf("Canary pod failed for %s, the remaining pods keep the previous content: %s", updateIdentifier, notificationError)
+				} else if notificationReason == "AuthFailed" {
+					failMessage = fmt.Sprintf("Pods for %s rejected the reload token (401/403); the pod's DECO_RELEASE_RELOAD_TOKEN is out of sync (e.g. pod started before a token change): %s", updateIdentifier, notificationError)
+				}
+				podsNotifiedCondition = metav1.Condition{
+					Type:               condTypePodsNotified,
+					Status:             metav1.ConditionFalse,
+					Reason:             notificationReason,
+					Message:            failMessage,
+					LastTransitionTime: metav1.Now(),
+				}
+			}
+			updateCondition(freshDecofile, podsNotifiedCondition)
+			if podsNotified {
+				recordHistory(freshDecofile, decositesv1alpha1.HistoryNotified, podsNotifiedCondition.Message)
+			} else {
+				recordHistory(freshDecofile, decositesv1alpha1.HistoryFailed, podsNotifiedCondition.Message)
+			}
+
+			// Record the nonce only once pods were notified so failures are retried
+			if renotify && podsNotified {
+				freshDecofile.Status.RenotifyNonce = renotifyNonce
 			}
 		}
-		updateCondition(freshDecofile, podsNotifiedCondition)
-	}
 
-	statusUpdateStart := time.Now()
-	err = r.Status().Update(ctx, freshDecofile)
+		return r.Status().Update(ctx, freshDecofile)
+	})
 	if err != nil {
 		log.Error(err, "Failed to update Decofile status", "duration", time.Since(statusUpdateStart))
 		return ctrl.Result{}, err
@@ -424,26 +882,148 @@ func (r *DecofileReconciler) Reconcile(ctx context.Context, req ctrl.Request) (c
 		"dataChanged", dataChanged)
 
 	// Return error if notifications failed (will requeue)
-	if dataChanged && !podsNotified {
+	if shouldNotify && !podsNotified {
 		return ctrl.Result{}, fmt.Errorf("failed to notify pods: %s", notificationError)
 	}
+	if destinationsErr != nil {
+		return ctrl.Result{}, destinationsErr
+	}
+	if graceWait > 0 && (reloadProbe == nil || reloadProbe.Status == metav1.ConditionTrue || graceWait < reloadProbeRequeue) {
+		return requeueForRefresh(ctrl.Result{RequeueAfter: graceWait}, refresh), nil
+	}
+	if reloadProbe != nil && reloadProbe.Status != metav1.ConditionTrue {
+		return requeueForRefresh(ctrl.Result{RequeueAfter: reloadProbeRequeue}, refresh), nil
+	}
 
-	return ctrl.Result{}, nil
+	return requeueForRefresh(ctrl.Result{}, refresh), nil
 }
 
-// updateCondition updates or appends a condition, only if it changed
-func updateCondition(decofile *decositesv1alpha1.Decofile, newCondition metav1.Condition) {
-	for i, cond := range decofile.Status.Conditions {
-		if cond.Type == newCondition.Type {
-			// Only update if status or message changed (prevent unnecessary updates)
-			if cond.Status != newCondition.Status || cond.Message != newCondition.Message {
-				decofile.Status.Conditions[i] = newCondition
+// applyTransforms runs the Decofile's spec.transforms over retrieved content.
+func (r *DecofileReconciler) applyTransforms(ctx context.Context, decofile *decositesv1alpha1.Decofile, content string) (string, error) {
+	if len(decofile.Spec.Transforms) == 0 {
+		return content, nil
+	}
+	if r.Transformers == nil {
+		return "", fmt.Errorf("decofile lists transforms %v but no transformers are configured", decofile.Spec.Transforms)
+	}
+	out, err := r.Transformers.Apply(ctx, decofile, decofile.Spec.Transforms, content)
+	if err != nil {
+		return "", err
+	}
+	logf.FromContext(ctx).V(1).Info("Applied transforms", "transforms", decofile.Spec.Transforms,
+		"inputSize", len(content), "outputSize", len(out))
+	return out, nil
+}
+
+// notifyPods notifies the Decofile's pods, both those it is the DECO_RELEASE
+// of and those mounting it as an extra. The former are scoped to the
+// Revision behind spec.notifyRevisionTag when set; extra mounts run in other
+// Services' Revisions and are always notified.
+func (r *DecofileReconciler) notifyPods(ctx context.Context, decofile *decositesv1alpha1.Decofile, deploymentId, timestamp, content string, stats *NotifyStats) error {
+	if decofile.Spec.NotifyTransport == decositesv1alpha1.NotifyTransportSSE && r.EventStream == nil {
+		return errEventStreamDisabled
+	}
+	notifier := NewNotifier(r.Client, r.HTTPClient, r.Notify)
+	notifier.ReloadMethod = decofile.Spec.ReloadMethod
+	notifier.ReloadPath = decofile.Spec.ReloadPath
+	notifier.Transport = decofile.Spec.NotifyTransport
+	notifier.EventStream = r.EventStream
+	notifier.Stats = stats
+	podSelector, err := decofilePodSelector(decofile)
+	if err != nil {
+		return err
+	}
+	notifier.PodSelector = podSelector
+	if decofile.Spec.NotificationStrategy == decositesv1alpha1.NotifyCanary {
+		notifier.Canary = true
+		if c := decofile.Spec.Canary; c != nil {
+			notifier.CanaryHealthPath = c.HealthPath
+			if c.Delay != nil {
+				notifier.CanaryDelay = c.Delay.Duration
+			}
+		}
+	}
+	tag := decofile.Spec.NotifyRevisionTag
+	if tag == "" {
+		err := notifier.NotifyPodsForDecofile(ctx, decofile.Namespace, deploymentId, timestamp, content)
+		return stderrors.Join(err, notifier.NotifyPodsForExtraDecofile(ctx, decofile.Namespace, deploymentId, timestamp, content))
+	}
+
+	revision, err := r.resolveTaggedRevision(ctx, decofile.Namespace, deploymentId, tag)
+	if err != nil {
+		return err
+	}
+	logf.FromContext(ctx).Info("Scoping notification to tagged revision", "tag", tag, "revision", revision)
+	err = notifier.NotifyPodsForRevision(ctx, decofile.Namespace, deploymentId, revision, timestamp, content)
+	return stderrors.Join(err, notifier.NotifyPodsForExtraDecofile(ctx, decofile.Namespace, deploymentId, timestamp, content))
+}
+
+// decofilePodSelector returns the selector of spec.podSelector, or nil when
+// it is unset.
+func decofilePodSelector(decofile *decositesv1alpha1.Decofile) (labels.Selector, error) {
+	if decofile.Spec.PodSelector == nil {
+		return nil, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(decofile.Spec.PodSelector)
+	if err != nil {
+		return nil, fmt.Errorf("spec.podSelector: %w", err)
+	}
+	return selector, nil
+}
+
+// resolveTaggedRevision returns the Revision name behind a traffic tag of
+// the Knative Services in namespace serving deploymentId; other sites in the
+// namespace may use the same tag. The resolved status traffic is preferred; a
+// spec traffic entry pinned to a revisionName is used as a fallback.
+func (r *DecofileReconciler) resolveTaggedRevision(ctx context.Context, namespace, deploymentId, tag string) (string, error) {
+	svcs := &servingv1.ServiceList{}
+	if err := r.List(ctx, svcs, client.InNamespace(namespace)); err != nil {
+		return "", fmt.Errorf("list services to resolve traffic tag %q: %w", tag, err)
+	}
+	var sites []*servingv1.Service
+	for i := range svcs.Items {
+		if knativeServiceDeploymentId(&svcs.Items[i], r.Notify.deploymentIdLabel()) == deploymentId {
+			sites = append(sites, &svcs.Items[i])
+		}
+	}
+	for _, svc := range sites {
+		for _, t := range svc.Status.Traffic {
+			if t.Tag == tag && t.RevisionName != "" {
+				return t.RevisionName, nil
 			}
-			return
 		}
 	}
-	// Condition doesn't exist - append it
-	decofile.Status.Conditions = append(decofile.Status.Conditions, newCondition)
+	for _, svc := range sites {
+		for _, t := range svc.Spec.Traffic {
+			if t.Tag == tag && t.RevisionName != "" {
+				return t.RevisionName, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no revision found for traffic tag %q of deploymentId=%s in namespace %s", tag, deploymentId, namespace)
+}
+
+// githubUpToDate reports whether the delivered content is from the current
+// spec.github.commit and, for a watched branch, its latest known head.
+// Status from before githubRef was recorded has the ref in githubCommit.
+func githubUpToDate(decofile *decositesv1alpha1.Decofile) bool {
+	return cmp.Or(decofile.Status.GitHubRef, decofile.Status.GitHubCommit) == decofile.Spec.GitHub.Commit &&
+		decofile.Status.GitHubHead == decofile.Annotations[githubHeadAnnotation] &&
+		!githubCacheBusted(decofile)
+}
+
+// githubCacheBusted reports whether spec.github.cacheBust changed since the
+// last render, which must then download the archive afresh.
+func githubCacheBusted(decofile *decositesv1alpha1.Decofile) bool {
+	return decofile.Spec.GitHub.CacheBust != decofile.Status.GitHubCacheBust
+}
+
+// updateCondition sets a condition, stamped with the Decofile's generation.
+// LastTransitionTime only moves when the condition's status changes, so it
+// tells how long the Decofile has been in its current state.
+func updateCondition(decofile *decositesv1alpha1.Decofile, newCondition metav1.Condition) {
+	newCondition.ObservedGeneration = decofile.Generation
+	meta.SetStatusCondition(&decofile.Status.Conditions, newCondition)
 }
 
 // syncRevisionOwnerRefs ensures the Decofile carries an ownerReference for
@@ -468,7 +1048,7 @@ func (r *DecofileReconciler) syncRevisionOwnerRefs(ctx context.Context, decofile
 	revs := &servingv1.RevisionList{}
 	if err := r.List(ctx, revs,
 		client.InNamespace(decofile.Namespace),
-		client.MatchingLabels{deploymentIdLabel: deploymentId},
+		client.MatchingLabels{r.Notify.deploymentIdLabel(): deploymentId},
 	); err != nil {
 		return fmt.Errorf("list revisions for deploymentId=%s: %w", deploymentId, err)
 	}
@@ -535,6 +1115,95 @@ func (r *DecofileReconciler) syncRevisionOwnerRefs(ctx context.Context, decofile
 	return nil
 }
 
+// consumerCodecs returns the codecs selected by the Revisions that consume
+// this Decofile, directly or as an extra (the codecAnnotation the Service
+// webhook sets), plus the one spec.compression selects, sorted and without
+// duplicates. Brotli is always rendered and so never listed.
+func (r *DecofileReconciler) consumerCodecs(ctx context.Context, decofile *decositesv1alpha1.Decofile) ([]string, error) {
+	revs := &servingv1.RevisionList{}
+	if err := r.List(ctx, revs,
+		client.InNamespace(decofile.Namespace),
+		client.MatchingLabels{r.Notify.deploymentIdLabel(): decofile.DeploymentIdOrName()},
+	); err != nil {
+		return nil, fmt.Errorf("list revisions for deploymentId=%s: %w", decofile.DeploymentIdOrName(), err)
+	}
+	if label, ok := extraDecofileLabel(decofile.DeploymentIdOrName()); ok {
+		extra := &servingv1.RevisionList{}
+		if err := r.List(ctx, extra, client.InNamespace(decofile.Namespace), client.MatchingLabels{label: "true"}); err != nil {
+			return nil, fmt.Errorf("list revisions mounting deploymentId=%s: %w", decofile.DeploymentIdOrName(), err)
+		}
+		revs.Items = append(revs.Items, extra.Items...)
+	}
+	seen := map[string]bool{}
+	var codecs []string
+	// spec.compression's codec is what new consumers will read, so render it
+	// before their first Revision exists
+	if codec := decofile.Spec.DefaultCodec(); codec != decositesv1alpha1.CodecBrotli {
+		seen[codec] = true
+		codecs = append(codecs, codec)
+	}
+	for i := range revs.Items {
+		rev := &revs.Items[i]
+		codec := rev.Annotations[codecAnnotation]
+		if rev.DeletionTimestamp != nil || codec == "" || codec == decositesv1alpha1.CodecBrotli || seen[codec] {
+			continue
+		}
+		seen[codec] = true
+		codecs = append(codecs, codec)
+	}
+	sort.Strings(codecs)
+	return codecs, nil
+}
+
+// applyConfigMapLabels sets the labels identifying a ConfigMap rendered from
+// decofile and reports whether any was missing or different. The decofile
+// label is omitted for names that aren't valid label values (over 63 chars).
+func applyConfigMapLabels(cm *corev1.ConfigMap, decofile *decositesv1alpha1.Decofile) bool {
+	want := map[string]string{managedByLabel: managedByValue}
+	if len(validation.IsValidLabelValue(decofile.Name)) == 0 {
+		want[decofileLabel] = decofile.Name
+	}
+	changed := false
+	for k, v := range want {
+		if cm.Labels[k] != v {
+			if cm.Labels == nil {
+				cm.Labels = make(map[string]string, len(want))
+			}
+			cm.Labels[k] = v
+			changed = true
+		}
+	}
+	return changed
+}
+
+// hasCodecKeys reports whether cm has a key for every codec.
+func hasCodecKeys(cm *corev1.ConfigMap, codecs []string) bool {
+	data := configMapData(cm)
+	for _, codec := range codecs {
+		if _, ok := data[decositesv1alpha1.DecofileCodecKey(codec)]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// sameDataKeys reports whether existing has exactly the content keys of
+// desired plus timestampKey, i.e. whether the codec set and the timestamp key
+// are unchanged.
+func sameDataKeys(existing, desired map[string]string, timestampKey string) bool {
+	n := 0
+	for k := range existing {
+		if k == timestampKey {
+			continue
+		}
+		if _, ok := desired[k]; !ok {
+			return false
+		}
+		n++
+	}
+	return n == len(desired)
+}
+
 // mapRevisionToDecofile maps a Revision event to the Decofile that should be
 // reconciled because of it. Used by the Revision watch so that new Revisions
 // (created after their Decofile) still trigger ownerReference sync.
@@ -543,7 +1212,7 @@ func (r *DecofileReconciler) mapRevisionToDecofile(ctx context.Context, obj clie
 	if !ok {
 		return nil
 	}
-	deploymentId := rev.Labels[deploymentIdLabel]
+	deploymentId := rev.Labels[r.Notify.deploymentIdLabel()]
 	if deploymentId == "" {
 		return nil
 	}
@@ -558,7 +1227,8 @@ func (r *DecofileReconciler) mapRevisionToDecofile(ctx context.Context, obj clie
 		if depId == "" {
 			depId = df.Name
 		}
-		if depId == deploymentId {
+		// Extras matter for the codecs to render, not for ownership
+		if depId == deploymentId || rev.Labels[extraDecofileLabelPrefix+depId] == "true" {
 			reqs = append(reqs, reconcile.Request{
 				NamespacedName: client.ObjectKey{Namespace: df.Namespace, Name: df.Name},
 			})
@@ -567,6 +1237,49 @@ func (r *DecofileReconciler) mapRevisionToDecofile(ctx context.Context, obj clie
 	return reqs
 }
 
+// mapConfigMapToDecofiles maps a ConfigMap event to the Decofiles in its
+// namespace that read it through spec.inline.valueFrom, so editing it
+// re-renders them. ConfigMaps rendered by the operator are skipped.
+func (r *DecofileReconciler) mapConfigMapToDecofiles(ctx context.Context, obj client.Object) []reconcile.Request {
+	if obj.GetLabels()[managedByLabel] == managedByValue {
+		return nil
+	}
+	decofiles := &decositesv1alpha1.DecofileList{}
+	if err := r.List(ctx, decofiles, client.InNamespace(obj.GetNamespace())); err != nil {
+		return nil
+	}
+	var reqs []reconcile.Request
+	for i := range decofiles.Items {
+		df := &decofiles.Items[i]
+		if inlineReferencesConfigMap(&df.Spec, obj.GetName()) {
+			reqs = append(reqs, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(df)})
+		}
+	}
+	return reqs
+}
+
+// inlineReferencesConfigMap reports whether spec (or one of its composite
+// sources) reads the ConfigMap name through spec.inline.valueFrom.
+func inlineReferencesConfigMap(spec *decositesv1alpha1.DecofileSpec, name string) bool {
+	specs := []*decositesv1alpha1.DecofileSpec{spec}
+	if spec.Composite != nil {
+		for i := range spec.Composite.Sources {
+			specs = append(specs, spec.Composite.Sources[i].Spec())
+		}
+	}
+	for _, s := range specs {
+		if s.Inline == nil {
+			continue
+		}
+		for _, from := range s.Inline.ValueFrom {
+			if from.ConfigMapRef.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *DecofileReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	// Only react to Revision Create -- Updates and Deletes don't add new
@@ -584,14 +1297,23 @@ func (r *DecofileReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		For(&decositesv1alpha1.Decofile{}).
 		Owns(&corev1.ConfigMap{}).
 		Owns(&batchv1.Job{}).
+		Watches(
+			&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.mapConfigMapToDecofiles),
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
 		Watches(
 			&servingv1.Revision{},
 			handler.EnqueueRequestsFromMapFunc(r.mapRevisionToDecofile),
 			builder.WithPredicates(revisionCreateOnly),
 		).
+		// spec.notificationPriority: reconcile critical Decofiles first
+		// when the queue is backed up
+		Watches(&decositesv1alpha1.Decofile{}, notificationPriorityHandler{}).
 		Named("decofile").
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: 8, // Allow 8 parallel reconciliations
+			UsePriorityQueue:        ptr.To(true),
 		}).
 		Complete(r)
 }
@@ -21,7 +21,9 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -89,6 +91,32 @@ var _ = Describe("Decofile Controller", func() {
 			Expect(err).NotTo(HaveOccurred())
 			// TODO(user): Add more specific assertions depending on your controller's reconciliation logic.
 			// Example: If you expect a certain status condition after reconciliation, verify it here.
+
+			By("Bumping the status revision only when content changes")
+			reconciled := &decositesv1alpha1.Decofile{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, reconciled)).To(Succeed())
+			Expect(reconciled.Status.Revision).To(Equal(int64(1)))
+			ready := meta.FindStatusCondition(reconciled.Status.Conditions, "Ready")
+			Expect(ready).NotTo(BeNil())
+			readySince := ready.LastTransitionTime
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: typeNamespacedName,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, typeNamespacedName, reconciled)).To(Succeed())
+			Expect(reconciled.Status.Revision).To(Equal(int64(1)))
+
+			By("Keeping the Ready transition time across no-op reconciles")
+			ready = meta.FindStatusCondition(reconciled.Status.Conditions, "Ready")
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.LastTransitionTime).To(Equal(readySince))
+
+			By("Labelling the ConfigMap as operator-managed")
+			cm := &corev1.ConfigMap{}
+			Expect(k8sClient.Get(ctx, types.NamespacedName{Name: reconciled.ConfigMapName(), Namespace: "default"}, cm)).To(Succeed())
+			Expect(cm.Labels).To(HaveKeyWithValue("app.kubernetes.io/managed-by", "decofile-operator"))
+			Expect(cm.Labels).To(HaveKeyWithValue("deco.sites/decofile", resourceName))
 		})
 	})
 })
@@ -0,0 +1,75 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// decofileLocks serializes work on a single Decofile across controllers. The
+// workqueue already keeps two Decofile reconciles of the same key apart, but
+// DecofilePodReconciler reads the ConfigMap and notifies pods from its own
+// queue. Holding the Decofile's lock in both keeps a pod notification from
+// interleaving with a reconcile that is rewriting the ConfigMap and pushing
+// the new content itself.
+var decofileLocks = &keyedMutex{}
+
+// keyedMutex hands out one mutex per key and drops it once nobody holds or
+// waits for it, so memory tracks in-flight work rather than every key seen.
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[types.NamespacedName]*refMutex
+}
+
+type refMutex struct {
+	sync.Mutex
+	refs int
+}
+
+// Lock blocks until key is free and returns the function that releases it.
+func (k *keyedMutex) Lock(key types.NamespacedName) func() {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = map[types.NamespacedName]*refMutex{}
+	}
+	m, ok := k.locks[key]
+	if !ok {
+		m = &refMutex{}
+		k.locks[key] = m
+	}
+	m.refs++
+	k.mu.Unlock()
+
+	m.Lock()
+	return func() {
+		m.Unlock()
+		k.mu.Lock()
+		if m.refs--; m.refs == 0 {
+			delete(k.locks, key)
+		}
+		k.mu.Unlock()
+	}
+}
+
+// len returns the number of keys currently held or waited on.
+func (k *keyedMutex) len() int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return len(k.locks)
+}
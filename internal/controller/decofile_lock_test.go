@@ -0,0 +1,105 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestKeyedMutex_SerializesPerKey(t *testing.T) {
+	var k keyedMutex
+	foo := types.NamespacedName{Namespace: testNamespace, Name: "foo"}
+
+	var inside, overlaps atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := k.Lock(foo)
+			defer unlock()
+			if inside.Add(1) > 1 {
+				overlaps.Add(1)
+			}
+			time.Sleep(time.Millisecond)
+			inside.Add(-1)
+		}()
+	}
+	wg.Wait()
+
+	if n := overlaps.Load(); n != 0 {
+		t.Errorf("%d holders overlapped on the same key", n)
+	}
+	if n := k.len(); n != 0 {
+		t.Errorf("%d keys left after all holders released, want 0", n)
+	}
+}
+
+func TestKeyedMutex_IndependentKeys(t *testing.T) {
+	var k keyedMutex
+	unlock := k.Lock(types.NamespacedName{Namespace: testNamespace, Name: "foo"})
+	defer unlock()
+
+	done := make(chan struct{})
+	go func() {
+		k.Lock(types.NamespacedName{Namespace: testNamespace, Name: "bar"})()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("lock on another key blocked")
+	}
+}
+
+// A reconcile must wait for whoever holds the Decofile's lock (e.g. the pod
+// notifier) before touching the ConfigMap.
+func TestDecofileReconcile_WaitsForLock(t *testing.T) {
+	scheme := newOwnerTestScheme(t)
+	r := &DecofileReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).Build(), Scheme: scheme}
+	key := types.NamespacedName{Namespace: testNamespace, Name: "foo"}
+
+	unlock := decofileLocks.Lock(key)
+	done := make(chan error, 1)
+	go func() {
+		_, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: key})
+		done <- err
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("Reconcile ran while the Decofile was locked")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Reconcile: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Reconcile did not resume after the lock was released")
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"testing"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -40,6 +41,9 @@ func newOwnerTestScheme(t *testing.T) *runtime.Scheme {
 	if err := servingv1.AddToScheme(s); err != nil {
 		t.Fatalf("add servingv1 scheme: %v", err)
 	}
+	if err := corev1.AddToScheme(s); err != nil {
+		t.Fatalf("add corev1 scheme: %v", err)
+	}
 	return s
 }
 
@@ -51,7 +55,7 @@ func makeRevision(name, deploymentId string, uid types.UID) *servingv1.Revision
 			Name:      name,
 			Namespace: testNamespace,
 			UID:       uid,
-			Labels:    map[string]string{deploymentIdLabel: deploymentId},
+			Labels:    map[string]string{DefaultDeploymentIdLabel: deploymentId},
 		},
 	}
 }
@@ -269,7 +273,7 @@ func TestMapRevisionToDecofile_IgnoresRevisionWithoutLabel(t *testing.T) {
 			Name:      "no-label",
 			Namespace: "sites-foo",
 			UID:       "uid",
-			// Note: no deploymentIdLabel.
+			// Note: no deploymentId label.
 		},
 	}
 
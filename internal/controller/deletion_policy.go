@@ -0,0 +1,147 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+const (
+	// orphanConfigMapFinalizer holds a Decofile with deletionPolicy orphan
+	// until its ConfigMap has been released.
+	orphanConfigMapFinalizer = "deco.sites/orphan-configmap"
+	// orphanedAnnotation marks a ConfigMap released by an orphan deletion, so
+	// the ConfigMap GC leaves it for the Services still mounting it.
+	orphanedAnnotation = "deco.sites/orphaned"
+)
+
+// handleDeletionPolicy keeps the orphan finalizer in step with
+// spec.deletionPolicy and, once an orphan-policy Decofile is being deleted,
+// releases its ConfigMap (drops the ownerReference so garbage collection
+// skips it) before letting the deletion finish. done is true while the
+// Decofile is being deleted: nothing else should be reconciled then.
+func (r *DecofileReconciler) handleDeletionPolicy(ctx context.Context, decofile *decositesv1alpha1.Decofile) (done bool, err error) {
+	log := logf.FromContext(ctx)
+
+	if !decofile.DeletionTimestamp.IsZero() {
+		if !controllerutil.ContainsFinalizer(decofile, orphanConfigMapFinalizer) {
+			return true, nil
+		}
+		if err := r.orphanConfigMap(ctx, decofile); err != nil {
+			log.Error(err, "Failed to orphan ConfigMap, will retry")
+			return true, err
+		}
+		controllerutil.RemoveFinalizer(decofile, orphanConfigMapFinalizer)
+		return true, r.Update(ctx, decofile)
+	}
+
+	wantFinalizer := decofile.Spec.DeletionPolicy == decositesv1alpha1.DeletionOrphan
+	if wantFinalizer == controllerutil.ContainsFinalizer(decofile, orphanConfigMapFinalizer) {
+		return false, nil
+	}
+	if wantFinalizer {
+		controllerutil.AddFinalizer(decofile, orphanConfigMapFinalizer)
+	} else {
+		controllerutil.RemoveFinalizer(decofile, orphanConfigMapFinalizer)
+	}
+	return false, r.Update(ctx, decofile)
+}
+
+// orphanConfigMap removes decofile's ownerReference from its ConfigMap and
+// marks it orphaned. A missing ConfigMap is nothing to release.
+func (r *DecofileReconciler) orphanConfigMap(ctx context.Context, decofile *decositesv1alpha1.Decofile) error {
+	cm := &corev1.ConfigMap{}
+	err := r.Get(ctx, client.ObjectKey{Name: decofile.ConfigMapName(), Namespace: decofile.Namespace}, cm)
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	releaseConfigMap(decofile, cm)
+	if err := r.Update(ctx, cm); err != nil {
+		return err
+	}
+	logf.FromContext(ctx).Info("Orphaned ConfigMap per deletionPolicy", "ConfigMap.Name", cm.Name)
+	return nil
+}
+
+// releaseConfigMap drops decofile's ownerReference from cm and marks it
+// orphaned, reporting whether cm changed.
+func releaseConfigMap(decofile *decositesv1alpha1.Decofile, cm *corev1.ConfigMap) bool {
+	changed := false
+	refs := cm.OwnerReferences[:0]
+	for _, ref := range cm.OwnerReferences {
+		if ref.UID == decofile.UID {
+			changed = true
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	cm.OwnerReferences = refs
+	if !isOrphanedConfigMap(cm) {
+		if cm.Annotations == nil {
+			cm.Annotations = map[string]string{}
+		}
+		cm.Annotations[orphanedAnnotation] = "true"
+		changed = true
+	}
+	return changed
+}
+
+// setConfigMapOwnership applies spec.configMapOwnership to cm, which
+// checkConfigMapOwnership has found to be decofile's: "orphan" releases it,
+// anything else makes decofile its controller (taking back a ConfigMap an
+// orphan deletion or the orphan mode released). changed reports whether cm
+// needs writing.
+func (r *DecofileReconciler) setConfigMapOwnership(decofile *decositesv1alpha1.Decofile, cm *corev1.ConfigMap) (changed bool, err error) {
+	if decofile.Spec.ConfigMapOwnership == decositesv1alpha1.ConfigMapOrphan {
+		return releaseConfigMap(decofile, cm), nil
+	}
+	if isOrphanedConfigMap(cm) {
+		delete(cm.Annotations, orphanedAnnotation)
+		changed = true
+	}
+	if metav1.IsControlledBy(cm, decofile) {
+		return changed, nil
+	}
+	return true, controllerutil.SetControllerReference(decofile, cm, r.Scheme)
+}
+
+// configMapOwnershipDrifted reports whether cm's ownership doesn't match
+// decofile's spec.configMapOwnership yet.
+func configMapOwnershipDrifted(decofile *decositesv1alpha1.Decofile, cm *corev1.ConfigMap) bool {
+	if decofile.Spec.ConfigMapOwnership == decositesv1alpha1.ConfigMapOrphan {
+		return metav1.IsControlledBy(cm, decofile) || !isOrphanedConfigMap(cm)
+	}
+	return !metav1.IsControlledBy(cm, decofile) || isOrphanedConfigMap(cm)
+}
+
+// isOrphanedConfigMap reports whether cm was released by an orphan deletion.
+func isOrphanedConfigMap(cm *corev1.ConfigMap) bool {
+	return cm.Annotations[orphanedAnnotation] == "true"
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+func TestReconcile_DeletionPolicyOrphan(t *testing.T) {
+	ctx := context.Background()
+	scheme := newOwnerTestScheme(t)
+	df := makeDecofile("foo", "")
+	df.UID = "foo-uid"
+	df.Spec.Source = SourceTypeInline
+	df.Spec.Inline = &decositesv1alpha1.InlineSource{Value: map[string]runtime.RawExtension{
+		"config.json": {Raw: []byte(`{"a":1}`)},
+	}}
+	df.Spec.DeletionPolicy = decositesv1alpha1.DeletionOrphan
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(df).
+		WithStatusSubresource(&decositesv1alpha1.Decofile{}).Build()
+	r := &DecofileReconciler{Client: c, Scheme: scheme}
+	key := client.ObjectKeyFromObject(df)
+	cmKey := client.ObjectKey{Name: df.ConfigMapName(), Namespace: testNamespace}
+
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	got := &decositesv1alpha1.Decofile{}
+	if err := c.Get(ctx, key, got); err != nil {
+		t.Fatalf("get Decofile: %v", err)
+	}
+	if !controllerutil.ContainsFinalizer(got, orphanConfigMapFinalizer) {
+		t.Fatalf("finalizers = %v, want %s", got.Finalizers, orphanConfigMapFinalizer)
+	}
+	cm := &corev1.ConfigMap{}
+	if err := c.Get(ctx, cmKey, cm); err != nil {
+		t.Fatalf("get ConfigMap: %v", err)
+	}
+	if len(cm.OwnerReferences) != 1 {
+		t.Fatalf("ownerReferences = %v, want the Decofile", cm.OwnerReferences)
+	}
+
+	if err := c.Delete(ctx, got); err != nil {
+		t.Fatalf("delete Decofile: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile after delete: %v", err)
+	}
+	if err := c.Get(ctx, key, &decositesv1alpha1.Decofile{}); !errors.IsNotFound(err) {
+		t.Errorf("get Decofile after finalizer = %v, want NotFound", err)
+	}
+	cm = &corev1.ConfigMap{}
+	if err := c.Get(ctx, cmKey, cm); err != nil {
+		t.Fatalf("ConfigMap gone after orphan deletion: %v", err)
+	}
+	if len(cm.OwnerReferences) != 0 || !isOrphanedConfigMap(cm) {
+		t.Errorf("ConfigMap owners %v, annotations %v; want released and marked orphaned", cm.OwnerReferences, cm.Annotations)
+	}
+	if n, err := (&ConfigMapGC{Client: c}).Sweep(ctx); err != nil || n != 0 {
+		t.Errorf("Sweep() = %d, %v; want the orphaned ConfigMap kept", n, err)
+	}
+}
+
+func TestReconcile_DeletionPolicyFinalizerRemoved(t *testing.T) {
+	ctx := context.Background()
+	scheme := newOwnerTestScheme(t)
+	df := makeDecofile("foo", "")
+	df.Spec.DeletionPolicy = decositesv1alpha1.DeletionBlock
+	controllerutil.AddFinalizer(df, orphanConfigMapFinalizer)
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(df).Build()
+	r := &DecofileReconciler{Client: c, Scheme: scheme}
+
+	done, err := r.handleDeletionPolicy(ctx, df)
+	if done || err != nil {
+		t.Fatalf("handleDeletionPolicy() = %v, %v; want false, nil", done, err)
+	}
+	got := &decositesv1alpha1.Decofile{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(df), got); err != nil {
+		t.Fatalf("get Decofile: %v", err)
+	}
+	if controllerutil.ContainsFinalizer(got, orphanConfigMapFinalizer) {
+		t.Errorf("finalizers = %v, want %s removed", got.Finalizers, orphanConfigMapFinalizer)
+	}
+}
+
+func TestReconcile_ConfigMapOwnershipMode(t *testing.T) {
+	ctx := context.Background()
+	scheme := newOwnerTestScheme(t)
+	df := makeDecofile("foo", "")
+	df.UID = "foo-uid"
+	df.Spec.Source = SourceTypeInline
+	df.Spec.Inline = &decositesv1alpha1.InlineSource{Value: map[string]runtime.RawExtension{
+		"config.json": {Raw: []byte(`{"a":1}`)},
+	}}
+	df.Spec.ConfigMapOwnership = decositesv1alpha1.ConfigMapOrphan
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(df).
+		WithStatusSubresource(&decositesv1alpha1.Decofile{}).Build()
+	r := &DecofileReconciler{Client: c, Scheme: scheme}
+	key := client.ObjectKeyFromObject(df)
+	cmKey := client.ObjectKey{Name: df.ConfigMapName(), Namespace: testNamespace}
+
+	reconcileAndGet := func() *corev1.ConfigMap {
+		t.Helper()
+		if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key}); err != nil {
+			t.Fatalf("Reconcile: %v", err)
+		}
+		cm := &corev1.ConfigMap{}
+		if err := c.Get(ctx, cmKey, cm); err != nil {
+			t.Fatalf("get ConfigMap: %v", err)
+		}
+		return cm
+	}
+
+	cm := reconcileAndGet()
+	if len(cm.OwnerReferences) != 0 || !isOrphanedConfigMap(cm) {
+		t.Fatalf("ConfigMap owners %v, annotations %v; want no owner and marked orphaned", cm.OwnerReferences, cm.Annotations)
+	}
+	timestamp := cm.Data["timestamp.txt"]
+
+	// Switching to owned takes the ConfigMap back without a content change
+	got := &decositesv1alpha1.Decofile{}
+	if err := c.Get(ctx, key, got); err != nil {
+		t.Fatalf("get Decofile: %v", err)
+	}
+	got.Spec.ConfigMapOwnership = decositesv1alpha1.ConfigMapOwned
+	if err := c.Update(ctx, got); err != nil {
+		t.Fatalf("update Decofile: %v", err)
+	}
+	cm = reconcileAndGet()
+	if !metav1.IsControlledBy(cm, got) || isOrphanedConfigMap(cm) {
+		t.Errorf("ConfigMap owners %v, annotations %v; want controlled by the Decofile", cm.OwnerReferences, cm.Annotations)
+	}
+	if cm.Data["timestamp.txt"] != timestamp {
+		t.Errorf("timestamp = %s, want %s kept", cm.Data["timestamp.txt"], timestamp)
+	}
+
+	// And back to orphan releases it again
+	if err := c.Get(ctx, key, got); err != nil {
+		t.Fatalf("get Decofile: %v", err)
+	}
+	got.Spec.ConfigMapOwnership = decositesv1alpha1.ConfigMapOrphan
+	if err := c.Update(ctx, got); err != nil {
+		t.Fatalf("update Decofile: %v", err)
+	}
+	cm = reconcileAndGet()
+	if len(cm.OwnerReferences) != 0 || !isOrphanedConfigMap(cm) {
+		t.Errorf("ConfigMap owners %v, annotations %v; want released again", cm.OwnerReferences, cm.Annotations)
+	}
+}
+
+func TestConfigMapOwnershipDrifted(t *testing.T) {
+	df := makeDecofile("foo", "")
+	df.UID = "foo-uid"
+	owned := &corev1.ConfigMap{}
+	if err := controllerutil.SetControllerReference(df, owned, newOwnerTestScheme(t)); err != nil {
+		t.Fatalf("SetControllerReference: %v", err)
+	}
+	orphaned := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{orphanedAnnotation: "true"}}}
+
+	if configMapOwnershipDrifted(df, owned) || !configMapOwnershipDrifted(df, orphaned) {
+		t.Error("owned mode: want only the orphaned ConfigMap drifted")
+	}
+	df.Spec.ConfigMapOwnership = decositesv1alpha1.ConfigMapOrphan
+	if !configMapOwnershipDrifted(df, owned) || configMapOwnershipDrifted(df, orphaned) {
+		t.Error("orphan mode: want only the owned ConfigMap drifted")
+	}
+}
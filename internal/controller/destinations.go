@@ -0,0 +1,236 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+const (
+	condTypeDestinationsDelivered = "DestinationsDelivered"
+	// httpDestinationTimeout bounds one POST to an http destination.
+	httpDestinationTimeout = 30 * time.Second
+)
+
+// Rendered is a Decofile's rendered content as handed to a Destination.
+type Rendered struct {
+	// JSON is the merged decofile JSON.
+	JSON string
+	// Data is the ConfigMap data written for it: the codec keys and the
+	// timestamp key, compressed keys base64-encoded (see setConfigMapData).
+	Data map[string]string
+	// Timestamp is the content timestamp (Unix seconds) pods are notified with.
+	Timestamp string
+}
+
+// Destination receives a Decofile's rendered content whenever it changes.
+// The Decofile's own ConfigMap is always written (it drives pod
+// notification); Destinations are the extra ones listed in spec.destinations.
+type Destination interface {
+	Deliver(ctx context.Context, decofile *decositesv1alpha1.Decofile, content *Rendered) error
+}
+
+// NewDestination creates the Destination implementation for one
+// spec.destinations entry. base64Data keeps a ConfigMap destination's
+// compressed keys base64 in data (DecofileReconciler.Base64ConfigMapData).
+func NewDestination(k8sClient client.Client, scheme *runtime.Scheme, httpClient *http.Client, spec *decositesv1alpha1.DestinationSpec, base64Data bool) (Destination, error) {
+	switch spec.Type {
+	case decositesv1alpha1.DestinationConfigMap:
+		if spec.ConfigMap == nil {
+			return nil, fmt.Errorf("destination %q: configMap is required", spec.Name)
+		}
+		return &ConfigMapDestination{client: k8sClient, scheme: scheme, name: spec.ConfigMap.Name, base64Data: base64Data}, nil
+	case decositesv1alpha1.DestinationHTTP:
+		if spec.HTTP == nil {
+			return nil, fmt.Errorf("destination %q: http is required", spec.Name)
+		}
+		if httpClient == nil {
+			httpClient = http.DefaultClient
+		}
+		return &HTTPDestination{client: k8sClient, httpClient: httpClient, config: spec.HTTP}, nil
+	default:
+		return nil, fmt.Errorf("destination %q: unsupported type %q", spec.Name, spec.Type)
+	}
+}
+
+// ConfigMapDestination writes the rendered ConfigMap data to another
+// ConfigMap in the Decofile's namespace, owned by the Decofile.
+type ConfigMapDestination struct {
+	client client.Client
+	scheme *runtime.Scheme
+	name   string
+	// base64Data keeps the compressed keys base64 in data rather than in
+	// binaryData.
+	base64Data bool
+}
+
+// Deliver creates or replaces the ConfigMap's data. A ConfigMap of that name
+// the Decofile doesn't control is left alone.
+func (d *ConfigMapDestination) Deliver(ctx context.Context, decofile *decositesv1alpha1.Decofile, content *Rendered) error {
+	if d.name == decofile.ConfigMapName() {
+		return fmt.Errorf("configMap %s is the Decofile's own ConfigMap", d.name)
+	}
+	cm := &corev1.ConfigMap{}
+	err := d.client.Get(ctx, client.ObjectKey{Name: d.name, Namespace: decofile.Namespace}, cm)
+	if errors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: d.name, Namespace: decofile.Namespace},
+		}
+		if err := setConfigMapData(cm, content.Data, !d.base64Data); err != nil {
+			return err
+		}
+		applyConfigMapLabels(cm, decofile)
+		if err := controllerutil.SetControllerReference(decofile, cm, d.scheme); err != nil {
+			return err
+		}
+		return d.client.Create(ctx, cm)
+	}
+	if err != nil {
+		return err
+	}
+	if !metav1.IsControlledBy(cm, decofile) {
+		return fmt.Errorf("configMap %s exists and is not owned by Decofile %s", d.name, decofile.Name)
+	}
+	if err := setConfigMapData(cm, content.Data, !d.base64Data); err != nil {
+		return err
+	}
+	applyConfigMapLabels(cm, decofile)
+	return d.client.Update(ctx, cm)
+}
+
+// HTTPDestination POSTs the rendered decofile JSON to a URL.
+type HTTPDestination struct {
+	client     client.Client
+	httpClient *http.Client
+	config     *decositesv1alpha1.HTTPDestination
+}
+
+// httpDestinationPayload is the body POSTed by HTTPDestination.
+type httpDestinationPayload struct {
+	Decofile  string          `json:"decofile"`
+	Namespace string          `json:"namespace"`
+	Timestamp string          `json:"timestamp"`
+	Content   json.RawMessage `json:"content"`
+}
+
+// Deliver POSTs the content once; a failure is retried by the next reconcile.
+func (d *HTTPDestination) Deliver(ctx context.Context, decofile *decositesv1alpha1.Decofile, content *Rendered) error {
+	body, err := json.Marshal(httpDestinationPayload{
+		Decofile:  decofile.Name,
+		Namespace: decofile.Namespace,
+		Timestamp: content.Timestamp,
+		Content:   json.RawMessage(content.JSON),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal payload: %w", err)
+	}
+
+	var token string
+	if d.config.Secret != "" {
+		secret := &corev1.Secret{}
+		if err := d.client.Get(ctx, client.ObjectKey{Name: d.config.Secret, Namespace: decofile.Namespace}, secret); err != nil {
+			return fmt.Errorf("failed to get secret %s: %w", d.config.Secret, err)
+		}
+		tokenBytes, ok := secret.Data["token"]
+		if !ok {
+			return fmt.Errorf("secret %s does not contain 'token' key", d.config.Secret)
+		}
+		token = strings.TrimSpace(string(tokenBytes))
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx, httpDestinationTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, d.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("POST %s returned %d", d.config.URL, resp.StatusCode)
+	}
+	return nil
+}
+
+// destinationsHash identifies what deliverDestinations sends: the rendered
+// data and the destinations it goes to. Unchanged content is redelivered when
+// a destination is added or changed.
+func destinationsHash(destinations []decositesv1alpha1.DestinationSpec, content *Rendered) string {
+	spec, _ := json.Marshal(destinations)
+	data, _ := json.Marshal(content.Data)
+	return sha256hex(string(spec) + "\x00" + string(data))
+}
+
+// deliverDestinations hands content to every spec.destinations entry, unless
+// that was already done for this content (status.destinationsHash). It
+// returns the hash to record on success, and the joined delivery errors.
+func (r *DecofileReconciler) deliverDestinations(ctx context.Context, decofile *decositesv1alpha1.Decofile, content *Rendered) (string, error) {
+	if len(decofile.Spec.Destinations) == 0 {
+		return "", nil
+	}
+	hash := destinationsHash(decofile.Spec.Destinations, content)
+	if hash == decofile.Status.DestinationsHash {
+		return hash, nil
+	}
+
+	log := logf.FromContext(ctx)
+	var failed []string
+	for i := range decofile.Spec.Destinations {
+		spec := &decofile.Spec.Destinations[i]
+		dest, err := NewDestination(r.Client, r.Scheme, r.HTTPClient, spec, r.Base64ConfigMapData)
+		if err == nil {
+			err = dest.Deliver(ctx, decofile, content)
+		}
+		if err != nil {
+			destinationDeliveries.WithLabelValues(spec.Type, "failed").Inc()
+			log.Error(err, "Failed to deliver to destination", "destination", spec.Name, "type", spec.Type)
+			failed = append(failed, fmt.Sprintf("%s: %v", spec.Name, err))
+			continue
+		}
+		destinationDeliveries.WithLabelValues(spec.Type, "delivered").Inc()
+		log.Info("Delivered to destination", "destination", spec.Name, "type", spec.Type)
+	}
+	if len(failed) > 0 {
+		return "", fmt.Errorf("failed to deliver to destination(s): %s", strings.Join(failed, "; "))
+	}
+	return hash, nil
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+func TestReconcile_Destinations(t *testing.T) {
+	ctx := context.Background()
+	scheme := newOwnerTestScheme(t)
+
+	var posts atomic.Int32
+	var status atomic.Int32
+	status.Store(http.StatusOK)
+	var got httpDestinationPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts.Add(1)
+		if auth := r.Header.Get("Authorization"); auth != "Bearer s3cret" {
+			t.Errorf("Authorization = %q, want the secret's token", auth)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decode payload: %v", err)
+		}
+		w.WriteHeader(int(status.Load()))
+	}))
+	defer srv.Close()
+
+	df := makeDecofile("foo", "")
+	df.UID = "foo-uid"
+	df.Spec.Source = SourceTypeInline
+	df.Spec.Inline = &decositesv1alpha1.InlineSource{Value: map[string]runtime.RawExtension{
+		"config.json": {Raw: []byte(`{"a":1}`)},
+	}}
+	df.Spec.Destinations = []decositesv1alpha1.DestinationSpec{
+		{Name: "mirror", Type: decositesv1alpha1.DestinationConfigMap, ConfigMap: &decositesv1alpha1.ConfigMapDestination{Name: "foo-mirror"}},
+		{Name: "sync", Type: decositesv1alpha1.DestinationHTTP, HTTP: &decositesv1alpha1.HTTPDestination{URL: srv.URL, Secret: "sync-token"}},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "sync-token", Namespace: testNamespace},
+		Data:       map[string][]byte{"token": []byte("s3cret\n")},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(df, secret).
+		WithStatusSubresource(&decositesv1alpha1.Decofile{}).Build()
+	r := &DecofileReconciler{Client: c, Scheme: scheme, HTTPClient: srv.Client()}
+	key := client.ObjectKeyFromObject(df)
+
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	primary := &corev1.ConfigMap{}
+	if err := c.Get(ctx, client.ObjectKey{Name: df.ConfigMapName(), Namespace: testNamespace}, primary); err != nil {
+		t.Fatalf("get ConfigMap: %v", err)
+	}
+	mirror := &corev1.ConfigMap{}
+	if err := c.Get(ctx, client.ObjectKey{Name: "foo-mirror", Namespace: testNamespace}, mirror); err != nil {
+		t.Fatalf("get mirror ConfigMap: %v", err)
+	}
+	if !maps.Equal(configMapData(mirror), configMapData(primary)) {
+		t.Errorf("mirror data = %v, want %v", configMapData(mirror), configMapData(primary))
+	}
+	if !metav1.IsControlledBy(mirror, df) {
+		t.Errorf("mirror owners = %v, want the Decofile", mirror.OwnerReferences)
+	}
+	var content map[string]json.RawMessage
+	if err := json.Unmarshal(got.Content, &content); err != nil || string(content["config"]) != `{"a":1}` {
+		t.Errorf("payload content = %s (%v), want the rendered decofile", got.Content, err)
+	}
+	if posts.Load() != 1 || got.Decofile != "foo" || got.Namespace != testNamespace {
+		t.Errorf("posts = %d, payload = %+v", posts.Load(), got)
+	}
+	if got.Timestamp != primary.Data[decositesv1alpha1.DefaultTimestampKey] {
+		t.Errorf("payload timestamp = %q, want %q", got.Timestamp, primary.Data[decositesv1alpha1.DefaultTimestampKey])
+	}
+
+	// Unchanged content isn't delivered again
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("second Reconcile: %v", err)
+	}
+	if posts.Load() != 1 {
+		t.Errorf("posts = %d after an unchanged reconcile, want 1", posts.Load())
+	}
+
+	// A failing destination is reported and retried
+	status.Store(http.StatusInternalServerError)
+	fresh := &decositesv1alpha1.Decofile{}
+	if err := c.Get(ctx, key, fresh); err != nil {
+		t.Fatalf("get Decofile: %v", err)
+	}
+	fresh.Spec.Inline.Value["config.json"] = runtime.RawExtension{Raw: []byte(`{"a":2}`)}
+	if err := c.Update(ctx, fresh); err != nil {
+		t.Fatalf("update Decofile: %v", err)
+	}
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key}); err == nil {
+		t.Fatal("Reconcile with a failing destination succeeded, want an error")
+	}
+	if err := c.Get(ctx, key, fresh); err != nil {
+		t.Fatalf("get Decofile: %v", err)
+	}
+	if !meta.IsStatusConditionFalse(fresh.Status.Conditions, condTypeDestinationsDelivered) {
+		t.Errorf("conditions = %+v, want DestinationsDelivered=False", fresh.Status.Conditions)
+	}
+
+	status.Store(http.StatusOK)
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("retry Reconcile: %v", err)
+	}
+	if err := c.Get(ctx, key, fresh); err != nil {
+		t.Fatalf("get Decofile: %v", err)
+	}
+	if !meta.IsStatusConditionTrue(fresh.Status.Conditions, condTypeDestinationsDelivered) || posts.Load() != 3 {
+		t.Errorf("posts = %d, conditions = %+v; want the retry delivered", posts.Load(), fresh.Status.Conditions)
+	}
+}
+
+func TestConfigMapDestination_RefusesForeignConfigMap(t *testing.T) {
+	scheme := newOwnerTestScheme(t)
+	df := makeDecofile("foo", "")
+	foreign := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "taken", Namespace: testNamespace}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(df, foreign).Build()
+
+	for _, name := range []string{"taken", df.ConfigMapName()} {
+		d := &ConfigMapDestination{client: c, scheme: scheme, name: name}
+		if err := d.Deliver(context.Background(), df, &Rendered{Data: map[string]string{"k": "v"}}); err == nil {
+			t.Errorf("Deliver to %s succeeded, want an error", name)
+		}
+	}
+}
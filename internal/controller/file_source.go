@@ -0,0 +1,119 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+// FileSource handles retrieval of configuration data from files in the
+// operator's container, e.g. config baked into the image for air-gapped
+// clusters.
+type FileSource struct {
+	config *decositesv1alpha1.FileSource
+	dir    string
+	// keepExtensions disables stripping ".json" from keys
+	keepExtensions bool
+}
+
+// NewFileSource creates a new FileSource reading config.Paths under dir
+func NewFileSource(config *decositesv1alpha1.FileSource, dir string) *FileSource {
+	return &FileSource{config: config, dir: dir}
+}
+
+// Retrieve reads the listed files, and the files under the listed
+// directories, into a single JSON string. All access goes through an os.Root
+// on the base directory, so neither ".." nor a symlink can reach outside it.
+func (s *FileSource) Retrieve(ctx context.Context) (string, error) {
+	log := logf.FromContext(ctx)
+	if s.dir == "" {
+		return "", fmt.Errorf("source %q is disabled: the operator has no --decofile-file-source-dir", SourceTypeFile)
+	}
+	root, err := os.OpenRoot(s.dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file source directory: %w", err)
+	}
+	defer root.Close() //nolint:errcheck
+	fsys := root.FS()
+
+	type entry struct{ key, name string }
+	var entries []entry
+	for _, p := range s.config.Paths {
+		p = path.Clean(p)
+		info, err := fs.Stat(fsys, p)
+		if err != nil {
+			return "", fmt.Errorf("failed to read file source path %s: %w", p, err)
+		}
+		if !info.IsDir() {
+			entries = append(entries, entry{decofileKey(path.Base(p), s.keepExtensions), p})
+			continue
+		}
+		err = fs.WalkDir(fsys, p, func(name string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			rel := strings.TrimPrefix(name, p+"/")
+			entries = append(entries, entry{decofileKey(rel, s.keepExtensions), name})
+			return nil
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to walk file source path %s: %w", p, err)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+
+	w := newJSONObjectWriter(0)
+	for i, e := range entries {
+		// Duplicate keys across paths: the last one wins
+		if i+1 < len(entries) && entries[i+1].key == e.key {
+			continue
+		}
+		content, err := fs.ReadFile(fsys, e.name)
+		if err != nil {
+			return "", fmt.Errorf("failed to read file %s: %w", e.name, err)
+		}
+		if !json.Valid(content) {
+			log.Info("Skipping file that is not valid JSON", "filename", e.name)
+			continue
+		}
+		if err := w.WriteMember(e.key, content); err != nil {
+			return "", fmt.Errorf("failed to marshal files to JSON: %w", err)
+		}
+	}
+
+	log.V(1).Info("Read file source", "files", len(entries), "minifiedBytesSaved", w.BytesSaved())
+	return w.String(), nil
+}
+
+// SourceType returns the source type identifier
+func (s *FileSource) SourceType() string {
+	return SourceTypeFile
+}
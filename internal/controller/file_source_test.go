@@ -0,0 +1,74 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+func writeFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+}
+
+func TestFileSourceRetrieve(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "blocks", "home.json"), `{ "a": 1 }`)
+	writeFile(t, filepath.Join(dir, "blocks", "pages", "about.json"), `{"b":2}`)
+	writeFile(t, filepath.Join(dir, "blocks", "README.md"), `not json`)
+	writeFile(t, filepath.Join(dir, "shared", "site.json"), `{"c":3}`)
+
+	src := NewFileSource(&decositesv1alpha1.FileSource{Paths: []string{"blocks", "shared/site.json"}}, dir)
+	got, err := src.Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if want := `{"home":{"a":1},"pages/about":{"b":2},"site":{"c":3}}`; got != want {
+		t.Errorf("Retrieve() = %s, want %s", got, want)
+	}
+}
+
+func TestFileSourceRetrieve_StaysInBaseDir(t *testing.T) {
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "base")
+	writeFile(t, filepath.Join(parent, "secret.json"), `{"secret":true}`)
+	writeFile(t, filepath.Join(dir, "ok.json"), `{}`)
+	if err := os.Symlink(filepath.Join(parent, "secret.json"), filepath.Join(dir, "link.json")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+
+	for _, p := range []string{"../secret.json", "link.json"} {
+		src := NewFileSource(&decositesv1alpha1.FileSource{Paths: []string{p}}, dir)
+		if got, err := src.Retrieve(context.Background()); err == nil {
+			t.Errorf("Retrieve(%s) = %s, want error", p, got)
+		}
+	}
+
+	if _, err := NewFileSource(&decositesv1alpha1.FileSource{Paths: []string{"ok.json"}}, "").Retrieve(context.Background()); err == nil {
+		t.Error("Retrieve without a base directory succeeded, want error")
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+	"github.com/deco-sites/decofile-operator/internal/github"
+)
+
+// githubRefreshInterval returns spec.github.refreshInterval for a Decofile
+// tracking a branch, or 0 when it is pinned to a SHA or not refreshed.
+func githubRefreshInterval(decofile *decositesv1alpha1.Decofile) time.Duration {
+	if trackedBranch(decofile) == "" || decofile.Spec.GitHub.RefreshInterval == nil {
+		return 0
+	}
+	return decofile.Spec.GitHub.RefreshInterval.Duration
+}
+
+// refreshGitHubHead re-resolves the branch a Decofile with
+// spec.github.refreshInterval tracks and records its head on the in-memory
+// copy as the branch watcher would (githubHeadAnnotation), so a moved branch
+// fails githubUpToDate and is re-rendered while an unchanged one is skipped.
+// A failed lookup keeps the last known head; the next refresh retries it.
+func (r *DecofileReconciler) refreshGitHubHead(ctx context.Context, decofile *decositesv1alpha1.Decofile) {
+	log := logf.FromContext(ctx)
+	gh := decofile.Spec.GitHub
+	branch := trackedBranch(decofile)
+
+	token, err := GitHubToken(ctx, r.Client, decofile.Namespace, gh.Secret, r.Sources.DefaultGitHubSecret)
+	if err != nil {
+		log.Error(err, "Failed to get GitHub token for refresh (non-fatal)", "branch", branch)
+		return
+	}
+	head, err := github.ResolveBranch(token, gh.Org, gh.Repo, branch)
+	if err != nil {
+		log.Error(err, "Failed to resolve tracked branch for refresh (non-fatal)", "branch", branch)
+		return
+	}
+	if head != decofile.Annotations[githubHeadAnnotation] {
+		log.V(1).Info("Resolved tracked branch head", "branch", branch, "head", head)
+	}
+	if decofile.Annotations == nil {
+		decofile.Annotations = map[string]string{}
+	}
+	decofile.Annotations[githubHeadAnnotation] = head
+}
+
+// requeueForRefresh makes result requeue no later than interval, so a
+// refreshed Decofile is reconciled again even when nothing else changes.
+func requeueForRefresh(result ctrl.Result, interval time.Duration) ctrl.Result {
+	if interval > 0 && (result.RequeueAfter == 0 || interval < result.RequeueAfter) {
+		result.RequeueAfter = interval
+	}
+	return result
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestGitHubRefreshInterval(t *testing.T) {
+	branch := makeGitHubDecofile("foo", "main")
+	if got := githubRefreshInterval(branch); got != 0 {
+		t.Errorf("githubRefreshInterval() = %v without spec.github.refreshInterval, want 0", got)
+	}
+	branch.Spec.GitHub.RefreshInterval = &metav1.Duration{Duration: time.Minute}
+	if got := githubRefreshInterval(branch); got != time.Minute {
+		t.Errorf("githubRefreshInterval() = %v for a branch, want 1m", got)
+	}
+
+	pinned := makeGitHubDecofile("bar", testHead)
+	pinned.Spec.GitHub.RefreshInterval = &metav1.Duration{Duration: time.Minute}
+	if got := githubRefreshInterval(pinned); got != 0 {
+		t.Errorf("githubRefreshInterval() = %v for a pinned SHA, want 0", got)
+	}
+}
+
+func TestRequeueForRefresh(t *testing.T) {
+	cases := []struct {
+		result   ctrl.Result
+		interval time.Duration
+		want     time.Duration
+	}{
+		{ctrl.Result{}, 0, 0},
+		{ctrl.Result{}, time.Minute, time.Minute},
+		{ctrl.Result{RequeueAfter: 10 * time.Second}, time.Minute, 10 * time.Second},
+		{ctrl.Result{RequeueAfter: time.Hour}, time.Minute, time.Minute},
+	}
+	for _, tc := range cases {
+		if got := requeueForRefresh(tc.result, tc.interval).RequeueAfter; got != tc.want {
+			t.Errorf("requeueForRefresh(%v, %v) = %v, want %v", tc.result.RequeueAfter, tc.interval, got, tc.want)
+		}
+	}
+}
+
+func TestGitHubUpToDate_ResolvedCommit(t *testing.T) {
+	df := makeGitHubDecofile("foo", "main")
+	df.Annotations = map[string]string{githubHeadAnnotation: testHead}
+	// githubCommit holds the resolved SHA, githubRef the branch it came from
+	df.Status.GitHubRef = "main"
+	df.Status.GitHubCommit = renderedCommit(df, "")
+	df.Status.GitHubHead = testHead
+	if df.Status.GitHubCommit != testHead {
+		t.Fatalf("renderedCommit() = %q, want the branch head %q", df.Status.GitHubCommit, testHead)
+	}
+	if !githubUpToDate(df) {
+		t.Fatal("githubUpToDate() = false for a delivered branch head")
+	}
+	df.Spec.GitHub.Commit = "develop"
+	if githubUpToDate(df) {
+		t.Fatal("githubUpToDate() = true after spec.github.commit moved to another branch")
+	}
+}
@@ -17,12 +17,14 @@ limitations under the License.
 package controller
 
 import (
-	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/url"
 	"os"
+	"path"
+	"sort"
 	"strings"
 	"time"
 
@@ -35,11 +37,39 @@ import (
 	"github.com/deco-sites/decofile-operator/internal/github"
 )
 
+// ParseGitHubSecretRef resolves a SourceOptions.DefaultGitHubSecret value
+// for a Decofile in namespace.
+func ParseGitHubSecretRef(ref, namespace string) (types.NamespacedName, error) {
+	ns, name, qualified := strings.Cut(ref, "/")
+	if !qualified {
+		ns, name = namespace, ref
+	}
+	if ns == "" || name == "" || strings.Contains(name, "/") {
+		return types.NamespacedName{}, fmt.Errorf("invalid GitHub secret reference %q (must be name or namespace/name)", ref)
+	}
+	return types.NamespacedName{Namespace: ns, Name: name}, nil
+}
+
 // GitHubSource handles retrieval of configuration data from GitHub repositories
 type GitHubSource struct {
 	client    client.Client
 	config    *decositesv1alpha1.GitHubSource
 	namespace string
+	// keepExtensions disables stripping ".json" from keys
+	keepExtensions bool
+	// layers is the provenance of the last Retrieve with spec.github.layers
+	layers []decositesv1alpha1.GitHubLayerStatus
+	// previousSHA and previousContent are the last render, which
+	// spec.github.incremental applies changed files to
+	previousSHA, previousContent string
+	// sha is the commit the last Retrieve rendered, with spec.github.incremental
+	sha string
+	// noCache requests archives past any cache (spec.github.cacheBust)
+	noCache bool
+	// defaultSecret is SourceOptions.DefaultGitHubSecret
+	defaultSecret string
+	// diskExtractThreshold is SourceOptions.GitHubDiskExtractThreshold
+	diskExtractThreshold int64
 }
 
 // NewGitHubSource creates a new GitHubSource with the given configuration
@@ -51,94 +81,403 @@ func NewGitHubSource(k8sClient client.Client, config *decositesv1alpha1.GitHubSo
 	}
 }
 
-// Retrieve downloads files from GitHub and returns them as a single JSON string
+// Retrieve downloads files from GitHub and returns them as a single JSON
+// string. With spec.github.layers, each layer is downloaded too and its files
+// replace those of earlier layers under the same key. With
+// spec.github.incremental and a previous render, only changed files are
+// fetched.
 func (s *GitHubSource) Retrieve(ctx context.Context) (string, error) {
 	log := logf.FromContext(ctx)
 
-	var token string
+	token, err := s.token(ctx)
+	if err != nil {
+		return "", err
+	}
+	downloader := &github.Downloader{
+		Token:                token,
+		MaxDepth:             s.config.MaxDepth,
+		NoCache:              s.noCache,
+		DiskExtractThreshold: s.diskExtractThreshold,
+	}
+
+	s.sha = ""
+	commit := s.config.Commit
+	if s.config.Incremental {
+		sha, content, ok := s.changedContent(ctx, token)
+		if ok {
+			s.sha = sha
+			return content, nil
+		}
+		// Download the resolved commit, so the recorded SHA matches the content
+		if sha != "" {
+			commit, s.sha = sha, sha
+		}
+	}
+
+	paths := s.config.AllPaths()
+	refs := make([]githubRef, 0, 1+len(s.config.Layers))
+	refs = append(refs, githubRef{commit: commit, paths: paths})
+	for _, layer := range s.config.Layers {
+		ref := githubRef{commit: layer.Commit, paths: paths}
+		if layer.Path != "" {
+			ref.paths = []string{layer.Path}
+		}
+		refs = append(refs, ref)
+	}
+
+	// Store all files as a single JSON object to preserve original filenames
+	// (ConfigMap keys have strict character restrictions)
+	type entry struct {
+		key, decoded, filename string
+		layer                  int
+	}
+	var entries []entry
+	layerFiles := make([]*github.Files, len(refs))
+	for i, ref := range refs {
+		files, err := s.download(ctx, downloader, ref)
+		if err != nil {
+			return "", err
+		}
+		defer func() {
+			if closeErr := files.Close(); closeErr != nil {
+				log.Error(closeErr, "Failed to remove extracted GitHub files")
+			}
+		}()
+		layerFiles[i] = files
+
+		for _, filename := range files.Names() {
+			decodedFilename := decodeFileName(ctx, filename)
+			entries = append(entries, entry{decofileKey(decodedFilename, s.keepExtensions), decodedFilename, filename, i})
+		}
+	}
+	// Stable: within a key, layers (and a layer's files) keep their order
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+
+	// Stream each file into the output (read one at a time when on disk);
+	// keys are written without HTML escaping (preserves &, <, > characters)
+	w := newJSONObjectWriter(0)
+	layerKeys := make([]int, len(refs))
+	for i, e := range entries {
+		// Duplicate keys after decoding or across layers: the last file wins
+		if i+1 < len(entries) && entries[i+1].key == e.key {
+			continue
+		}
+		content, err := layerFiles[e.layer].ReadFile(e.filename)
+		if err != nil {
+			return "", fmt.Errorf("failed to read extracted file %s: %w", e.filename, err)
+		}
+
+		content, err = s.resolveLFS(ctx, downloader, e.filename, content)
+		if err != nil {
+			return "", err
+		}
+
+		key := e.key
+		// Validate that content is valid JSON before adding
+		if !json.Valid(content) {
+			if !s.config.IncludeBinary {
+				log.Info("Skipping file that is not valid JSON; set spec.github.includeBinary: true to keep it", "filename", e.key)
+				continue
+			}
+			key, content = binaryMember(e.decoded, content)
+		}
+
+		if err := w.WriteMember(key, content); err != nil {
+			return "", fmt.Errorf("failed to marshal files to JSON: %w", err)
+		}
+		layerKeys[e.layer]++
+	}
+
+	s.layers = nil
+	if len(s.config.Layers) > 0 {
+		for i, ref := range refs {
+			s.layers = append(s.layers, decositesv1alpha1.GitHubLayerStatus{Commit: ref.commit, Path: strings.Join(ref.paths, ","), Keys: layerKeys[i]})
+		}
+		log.Info("Composed GitHub layers", "layers", s.layers)
+	}
+
+	log.Info("Successfully downloaded from GitHub", "files", len(entries), "minifiedBytesSaved", w.BytesSaved())
+
+	return w.String(), nil
+}
+
+// resolveLFS returns the real content of a Git LFS pointer file, which is
+// all the archive and contents API hold for LFS-tracked files. Other content
+// is returned as is.
+func (s *GitHubSource) resolveLFS(ctx context.Context, downloader *github.Downloader, filename string, content []byte) ([]byte, error) {
+	if !github.IsLFSPointer(content) {
+		return content, nil
+	}
+	if !s.config.LFS {
+		return nil, fmt.Errorf("file %s is a Git LFS pointer; set spec.github.lfs: true to fetch its content", filename)
+	}
+	pointer, err := github.ParseLFSPointer(content)
+	if err != nil {
+		return nil, fmt.Errorf("file %s: %w", filename, err)
+	}
+	lfsStart := time.Now()
+	content, err = downloader.FetchLFSObject(ctx, s.config.Org, s.config.Repo, pointer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch LFS content for %s: %w", filename, err)
+	}
+	logf.FromContext(ctx).V(1).Info("Fetched Git LFS file", "filename", filename, "size", pointer.Size, "duration", time.Since(lfsStart))
+	return content, nil
+}
+
+// decodeFileName URL-decodes a file name (e.g., %20 -> space, %2F -> /),
+// keeping the original if it is not valid escaping.
+func decodeFileName(ctx context.Context, filename string) string {
+	decoded, err := url.QueryUnescape(filename)
+	if err != nil {
+		logf.FromContext(ctx).V(1).Info("Failed to decode filename, using original", "filename", filename, "error", err)
+		return filename
+	}
+	return decoded
+}
+
+// WithPrevious sets the last render (its commit SHA and content) that
+// spec.github.incremental applies changed files to.
+func (s *GitHubSource) WithPrevious(sha, content string) *GitHubSource {
+	s.previousSHA, s.previousContent = sha, content
+	return s
+}
+
+// WithoutCache makes Retrieve request archives past any cache, for a
+// changed spec.github.cacheBust.
+func (s *GitHubSource) WithoutCache() *GitHubSource {
+	s.noCache = true
+	return s
+}
+
+// SHA returns the commit SHA the last Retrieve rendered with
+// spec.github.incremental, or "" when the commit could not be resolved.
+func (s *GitHubSource) SHA() string {
+	return s.sha
+}
+
+// changedContent resolves spec.github.commit and, when there is a previous
+// render, returns it updated with the files changed since. ok is false when
+// the caller must download the full archive; sha is then still the resolved
+// commit (or "" if resolving failed).
+func (s *GitHubSource) changedContent(ctx context.Context, token string) (sha, content string, ok bool) {
+	log := logf.FromContext(ctx)
+
+	sha, err := github.ResolveBranch(token, s.config.Org, s.config.Repo, s.config.Commit)
+	if err != nil {
+		log.V(1).Info("Failed to resolve GitHub commit, downloading", "error", err.Error())
+		return "", "", false
+	}
+	if s.previousSHA == "" || s.previousContent == "" {
+		return sha, "", false
+	}
+	if sha == s.previousSHA {
+		log.Info("GitHub commit unchanged, reusing previous content", "sha", sha)
+		return sha, s.previousContent, true
+	}
+	content, err = s.applyChanges(ctx, token, sha)
+	if err != nil {
+		log.Info("Incremental GitHub update failed, downloading the full archive", "from", s.previousSHA, "to", sha, "error", err.Error())
+		return sha, "", false
+	}
+	return sha, content, true
+}
+
+// applyChanges fetches the files under spec.github.path(s) changed between the
+// previous render's commit and sha, and applies them to the previous content.
+// Like a full download it fails with github.ErrDuplicateFileName rather than
+// let a new file replace another one with the same base name.
+func (s *GitHubSource) applyChanges(ctx context.Context, token, sha string) (string, error) {
+	log := logf.FromContext(ctx)
+
+	changed, err := github.Compare(ctx, token, s.config.Org, s.config.Repo, s.previousSHA, sha)
+	if err != nil {
+		return "", err
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(s.previousContent), &members); err != nil {
+		return "", fmt.Errorf("failed to decode previous content: %w", err)
+	}
+
+	// Drop the keys of removed and renamed files first, so a file moved to
+	// another directory isn't taken for a duplicate of itself
+	for _, file := range changed {
+		switch file.Status {
+		case "renamed":
+			s.removeFile(ctx, members, file.PreviousFilename)
+		case "removed":
+			s.removeFile(ctx, members, file.Filename)
+		}
+	}
 
-	// Get GitHub token from secret or environment variable
-	if s.config.Secret != "" {
-		// Fetch GitHub token from Kubernetes secret
-		secret := &corev1.Secret{}
-		err := s.client.Get(ctx, types.NamespacedName{
-			Name:      s.config.Secret,
-			Namespace: s.namespace,
-		}, secret)
+	downloader := &github.Downloader{Token: token}
+	fetched := 0
+	// written maps each key set below to the file it came from
+	written := make(map[string]string)
+	for _, file := range changed {
+		if file.Status == "removed" || !github.InPaths(file.Filename, s.config.AllPaths(), s.config.MaxDepth) {
+			continue
+		}
+		name := decodeFileName(ctx, path.Base(file.Filename))
+		switch file.Status {
+		case "added", "copied", "renamed":
+			if s.hasFile(members, name) {
+				return "", fmt.Errorf("%w: %s is already rendered from another file", github.ErrDuplicateFileName, file.Filename)
+			}
+		default:
+			s.removeFile(ctx, members, file.Filename)
+		}
+
+		content, err := github.FetchFile(ctx, token, s.config.Org, s.config.Repo, sha, file.Filename)
 		if err != nil {
-			return "", fmt.Errorf("failed to get secret %s: %w", s.config.Secret, err)
+			return "", err
+		}
+		fetched++
+		if content, err = s.resolveLFS(ctx, downloader, file.Filename, content); err != nil {
+			return "", err
+		}
+		key, value := decofileKey(name, s.keepExtensions), json.RawMessage(content)
+		if !json.Valid(content) {
+			if !s.config.IncludeBinary {
+				log.Info("Skipping file that is not valid JSON; set spec.github.includeBinary: true to keep it", "filename", file.Filename)
+				continue
+			}
+			key, value = binaryMember(name, content)
+		}
+		if prev, ok := written[key]; ok {
+			return "", fmt.Errorf("%w: %s and %s", github.ErrDuplicateFileName, prev, file.Filename)
 		}
+		written[key] = file.Filename
+		members[key] = value
+	}
 
-		token = string(secret.Data["token"])
-		if token == "" {
-			return "", fmt.Errorf("secret %s does not contain 'token' key", s.config.Secret)
+	keys := make([]string, 0, len(members))
+	for key := range members {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	w := newJSONObjectWriter(len(s.previousContent))
+	for _, key := range keys {
+		if err := w.WriteMember(key, members[key]); err != nil {
+			return "", fmt.Errorf("failed to marshal files to JSON: %w", err)
 		}
-		log.V(1).Info("Using GitHub token from secret", "secret", s.config.Secret)
-	} else {
-		// Fall back to environment variable
-		token = os.Getenv("GITHUB_TOKEN")
-		log.V(1).Info("Using GitHub token from GITHUB_TOKEN environment variable")
 	}
 
-	// Download and extract from GitHub
+	log.Info("Applied GitHub changes incrementally", "from", s.previousSHA, "to", sha, "changedFiles", len(changed), "fetched", fetched)
+	return w.String(), nil
+}
+
+// hasFile reports whether members holds a file with base name name, as JSON
+// or as base64.
+func (s *GitHubSource) hasFile(members map[string]json.RawMessage, name string) bool {
+	_, asJSON := members[decofileKey(name, s.keepExtensions)]
+	_, asBinary := members[binaryKeyPrefix+name]
+	return asJSON || asBinary
+}
+
+// removeFile drops the key a file under spec.github.path(s) was stored under,
+// as JSON or as base64.
+func (s *GitHubSource) removeFile(ctx context.Context, members map[string]json.RawMessage, filename string) {
+	if !github.InPaths(filename, s.config.AllPaths(), s.config.MaxDepth) {
+		return
+	}
+	name := decodeFileName(ctx, path.Base(filename))
+	delete(members, decofileKey(name, s.keepExtensions))
+	delete(members, binaryKeyPrefix+name)
+}
+
+// githubRef is a commit of spec.github's repository and the paths extracted
+// from it: the base commit, or a layer.
+type githubRef struct {
+	commit string
+	paths  []string
+}
+
+// download fetches and extracts one commit/paths of the repository, with the
+// files of all paths merged. The caller closes the returned files.
+func (s *GitHubSource) download(ctx context.Context, downloader *github.Downloader, ref githubRef) (*github.Files, error) {
+	log := logf.FromContext(ctx)
+
 	downloadStart := time.Now()
 	log.Info("Starting GitHub download",
 		"org", s.config.Org,
 		"repo", s.config.Repo,
-		"commit", s.config.Commit,
-		"path", s.config.Path)
+		"commit", ref.commit,
+		"paths", ref.paths)
 
-	downloader := &github.Downloader{Token: token}
-	files, err := downloader.DownloadAndExtract(
+	files, err := downloader.DownloadAndExtractFiles(
+		ctx,
 		s.config.Org,
 		s.config.Repo,
-		s.config.Commit,
-		s.config.Path,
+		ref.commit,
+		ref.paths...,
 	)
 	downloadDuration := time.Since(downloadStart)
 	if err != nil {
 		log.Error(err, "GitHub download failed", "duration", downloadDuration)
-		return "", fmt.Errorf("failed to download from github: %w", err)
+		return nil, fmt.Errorf("failed to download from github: %w", err)
 	}
-	log.Info("GitHub download completed", "duration", downloadDuration, "filesCount", len(files))
+	log.Info("GitHub download completed", "duration", downloadDuration, "filesCount", files.Len(), "onDisk", files.OnDisk())
+	return files, nil
+}
 
-	// Store all files as a single JSON object to preserve original filenames
-	// (ConfigMap keys have strict character restrictions)
-	// Parse each file as JSON to avoid double-stringification
-	filesJSON := make(map[string]json.RawMessage)
-	for filename, content := range files {
-		// URL decode filename (e.g., %20 -> space, %2F -> /)
-		decodedFilename, err := url.QueryUnescape(filename)
-		if err != nil {
-			// If decode fails, use original
-			log.V(1).Info("Failed to decode filename, using original", "filename", filename, "error", err)
-			decodedFilename = filename
-		}
+// Layers returns the provenance of the content composed by the last
+// Retrieve, or nil when spec.github.layers is empty.
+func (s *GitHubSource) Layers() []decositesv1alpha1.GitHubLayerStatus {
+	return s.layers
+}
 
-		// Strip .json extension from filename
-		cleanFilename := strings.TrimSuffix(decodedFilename, ".json")
+// token returns the GitHub token from spec.github.secret, else from the
+// default secret, else from GITHUB_TOKEN.
+func (s *GitHubSource) token(ctx context.Context) (string, error) {
+	return GitHubToken(ctx, s.client, s.namespace, s.config.Secret, s.defaultSecret)
+}
 
-		// Validate that content is valid JSON before adding
-		if !json.Valid(content) {
-			log.Info("Skipping file with malformed JSON", "filename", cleanFilename)
-			continue
+// GitHubToken resolves the token for a GitHub source in namespace: the
+// "token" key of secret when set, else of defaultSecret (see
+// SourceOptions.DefaultGitHubSecret), else the GITHUB_TOKEN env var.
+func GitHubToken(ctx context.Context, c client.Reader, namespace, secret, defaultSecret string) (string, error) {
+	if secret != "" {
+		return secretToken(ctx, c, types.NamespacedName{Name: secret, Namespace: namespace})
+	}
+	if defaultSecret != "" {
+		key, err := ParseGitHubSecretRef(defaultSecret, namespace)
+		if err != nil {
+			return "", err
 		}
+		return secretToken(ctx, c, key)
+	}
+	// Fall back to environment variable
+	logf.FromContext(ctx).V(1).Info("Using GitHub token from GITHUB_TOKEN environment variable")
+	return os.Getenv("GITHUB_TOKEN"), nil
+}
 
-		filesJSON[cleanFilename] = json.RawMessage(content)
+// secretToken reads the "token" key of a Secret.
+func secretToken(ctx context.Context, c client.Reader, key types.NamespacedName) (string, error) {
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, key, secret); err != nil {
+		return "", fmt.Errorf("failed to get secret %s: %w", key, err)
 	}
 
-	// Marshal to JSON without HTML escaping (preserves &, <, > characters)
-	var buf bytes.Buffer
-	encoder := json.NewEncoder(&buf)
-	encoder.SetEscapeHTML(false)
-	err = encoder.Encode(filesJSON)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal files to JSON: %w", err)
+	token := string(secret.Data["token"])
+	if token == "" {
+		return "", fmt.Errorf("secret %s does not contain 'token' key", key)
 	}
+	logf.FromContext(ctx).V(1).Info("Using GitHub token from secret", "secret", key.String())
+	return token, nil
+}
 
-	log.Info("Successfully downloaded from GitHub", "files", len(files))
+// binaryKeyPrefix marks keys holding base64-encoded non-JSON files
+const binaryKeyPrefix = "base64:"
 
-	return strings.TrimSpace(buf.String()), nil
+// binaryMember returns the key and JSON value a non-JSON file is stored as
+// with spec.github.includeBinary: its base64 content as a JSON string, under
+// its full name (extension kept) prefixed with binaryKeyPrefix.
+func binaryMember(name string, content []byte) (string, []byte) {
+	value := make([]byte, 0, base64.StdEncoding.EncodedLen(len(content))+2)
+	value = append(value, '"')
+	value = base64.StdEncoding.AppendEncode(value, content)
+	value = append(value, '"')
+	return binaryKeyPrefix + name, value
 }
 
 // SourceType returns the source type identifier
@@ -0,0 +1,320 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+	"github.com/deco-sites/decofile-operator/internal/github"
+)
+
+func TestBinaryMember(t *testing.T) {
+	content := []byte{0x89, 'P', 'N', 'G', 0x00, 0xff}
+	key, value := binaryMember("static/logo.png", content)
+	if key != "base64:static/logo.png" {
+		t.Errorf("key = %q, want %q", key, "base64:static/logo.png")
+	}
+
+	w := newJSONObjectWriter(0)
+	if err := w.WriteMember(key, value); err != nil {
+		t.Fatalf("WriteMember: %v", err)
+	}
+	var out map[string]string
+	if err := json.Unmarshal([]byte(w.String()), &out); err != nil {
+		t.Fatalf("unmarshal %s: %v", w.String(), err)
+	}
+	got, err := base64.StdEncoding.DecodeString(out[key])
+	if err != nil || !bytes.Equal(got, content) {
+		t.Errorf("decoded value = %v (err %v), want %v", got, err, content)
+	}
+}
+
+func TestGitHubSourceToken_Precedence(t *testing.T) {
+	ctx := context.Background()
+	scheme := newOwnerTestScheme(t)
+	secret := func(namespace, name, token string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+			Data:       map[string][]byte{"token": []byte(token)},
+		}
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		secret(testNamespace, "own", "own-token"),
+		secret(testNamespace, "shared", "namespace-token"),
+		secret("deco-system", "shared", "central-token"),
+	).Build()
+	t.Setenv("GITHUB_TOKEN", "env-token")
+	tests := []struct {
+		name, secret, defaultSecret, want string
+	}{
+		{"env var", "", "", "env-token"},
+		{"default secret in another namespace", "", "deco-system/shared", "central-token"},
+		{"default secret in the Decofile's namespace", "", "shared", "namespace-token"},
+		{"own secret wins", "own", "deco-system/shared", "own-token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := NewGitHubSource(c, &decositesv1alpha1.GitHubSource{Secret: tt.secret}, testNamespace)
+			src.defaultSecret = tt.defaultSecret
+			got, err := src.token(ctx)
+			if err != nil {
+				t.Fatalf("token: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("token = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	// A configured default secret that is missing doesn't fall through
+	if _, err := GitHubToken(ctx, c, testNamespace, "", "deco-system/missing"); err == nil {
+		t.Error("token with a missing default secret succeeded, want error")
+	}
+}
+
+func TestParseGitHubSecretRef(t *testing.T) {
+	for ref, want := range map[string]string{
+		"shared":             "sites-foo/shared",
+		"deco-system/shared": "deco-system/shared",
+		"":                   "",
+		"/shared":            "",
+		"a/b/c":              "",
+	} {
+		key, err := ParseGitHubSecretRef(ref, "sites-foo")
+		if got := key.String(); (err == nil && got != want) || (err != nil && want != "") {
+			t.Errorf("ParseGitHubSecretRef(%q) = %q, %v; want %q", ref, got, err, want)
+		}
+	}
+}
+
+func TestIncrementalBase(t *testing.T) {
+	ctx := context.Background()
+	scheme := newOwnerTestScheme(t)
+	df := makeDecofile("foo", "")
+	df.Spec.Source = SourceTypeGitHub
+	df.Spec.GitHub = &decositesv1alpha1.GitHubSource{Org: "deco", Repo: "site", Commit: "aaa", Path: ".deco/blocks", Incremental: true}
+	df.Generation = 1
+	df.Status.GitHubSHA = "aaa"
+	df.Status.GitHubRenderHash = githubRenderHash(df)
+	df.Status.Conditions = []metav1.Condition{{Type: "Ready", Status: metav1.ConditionTrue, ObservedGeneration: 1}}
+	compressed, err := compressBrotli([]byte(`{"a":1}`))
+	if err != nil {
+		t.Fatalf("compress: %v", err)
+	}
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: df.ConfigMapName(), Namespace: testNamespace},
+		BinaryData: map[string][]byte{"decofile.bin": compressed},
+	}
+	r := &DecofileReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(cm).Build(), Scheme: scheme}
+
+	// A new commit bumps the generation but renders files the same way
+	df.Spec.GitHub.Commit = "bbb"
+	df.Generation = 2
+	if sha, content := r.incrementalBase(ctx, df, df.ConfigMapName()); sha != "aaa" || content != `{"a":1}` {
+		t.Errorf("base after a commit change = %q %q, want aaa and the previous content", sha, content)
+	}
+
+	df.Spec.GitHub.IncludeBinary = true
+	if sha, _ := r.incrementalBase(ctx, df, df.ConfigMapName()); sha != "" {
+		t.Errorf("base after an includeBinary change = %q, want none", sha)
+	}
+}
+
+const (
+	renderedSHA = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	headSHA     = "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+)
+
+// fakeGitHubCompare serves the GitHub API calls of an incremental render:
+// main resolves to headSHA, the compare from renderedSHA lists changed with
+// the given status, and files holds the contents at headSHA. It returns the
+// paths fetched.
+func fakeGitHubCompare(t *testing.T, status string, changed []github.ChangedFile, files map[string]string) func() []string {
+	t.Helper()
+	var mu sync.Mutex
+	var fetched []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch p := r.URL.Path; {
+		case p == "/repos/deco/site/commits/main":
+			_, _ = fmt.Fprint(w, headSHA)
+		case p == "/repos/deco/site/compare/"+renderedSHA+"..."+headSHA:
+			_ = json.NewEncoder(w).Encode(map[string]any{"status": status, "files": changed})
+		case strings.HasPrefix(p, "/repos/deco/site/contents/") && r.URL.Query().Get("ref") == headSHA:
+			name := strings.TrimPrefix(p, "/repos/deco/site/contents/")
+			content, ok := files[name]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			mu.Lock()
+			fetched = append(fetched, name)
+			mu.Unlock()
+			_, _ = fmt.Fprint(w, content)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	previous := github.SetAPIBaseURL(srv.URL)
+	t.Cleanup(func() {
+		github.SetAPIBaseURL(previous)
+		srv.Close()
+	})
+	return func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return fetched
+	}
+}
+
+func newIncrementalSource(previous string) *GitHubSource {
+	src := NewGitHubSource(nil, &decositesv1alpha1.GitHubSource{
+		Org: "deco", Repo: "site", Commit: "main", Path: ".deco/blocks", Incremental: true,
+	}, testNamespace)
+	return src.WithPrevious(renderedSHA, previous)
+}
+
+func TestGitHubSourceChangedContent(t *testing.T) {
+	fetched := fakeGitHubCompare(t, "ahead", []github.ChangedFile{
+		{Filename: ".deco/blocks/new.json", Status: "added"},
+		{Filename: ".deco/blocks/mod.json", Status: "modified"},
+		{Filename: ".deco/blocks/gone.json", Status: "removed"},
+		{Filename: ".deco/blocks/sub/renamed.json", Status: "renamed", PreviousFilename: ".deco/blocks/old.json"},
+		{Filename: "archive/leaving.json", Status: "renamed", PreviousFilename: ".deco/blocks/leaving.json"},
+		{Filename: "README.md", Status: "modified"},
+	}, map[string]string{
+		".deco/blocks/new.json":         `{"n":1}`,
+		".deco/blocks/mod.json":         `{"v":2}`,
+		".deco/blocks/sub/renamed.json": `{"r":1}`,
+		"archive/leaving.json":          `{"l":1}`,
+		"README.md":                     "# site",
+	})
+	src := newIncrementalSource(`{"gone":{},"kept":{"k":1},"leaving":{"l":1},"mod":{"v":1},"old":{"r":1}}`)
+
+	sha, content, ok := src.changedContent(context.Background(), "")
+	if !ok || sha != headSHA {
+		t.Fatalf("changedContent = %q, ok %v; want the changes applied at %s", sha, ok, headSHA)
+	}
+	if want := `{"kept":{"k":1},"mod":{"v":2},"new":{"n":1},"renamed":{"r":1}}`; content != want {
+		t.Errorf("content = %s, want %s", content, want)
+	}
+	if got := fetched(); len(got) != 3 {
+		t.Errorf("fetched %v, want only the added, modified and renamed files under the path", got)
+	}
+}
+
+func TestGitHubSourceChangedContent_NonJSON(t *testing.T) {
+	for _, includeBinary := range []bool{false, true} {
+		t.Run(fmt.Sprintf("includeBinary=%v", includeBinary), func(t *testing.T) {
+			fakeGitHubCompare(t, "ahead", []github.ChangedFile{
+				{Filename: ".deco/blocks/logo.png", Status: "added"},
+			}, map[string]string{".deco/blocks/logo.png": "\x89PNG"})
+			src := newIncrementalSource(`{"kept":{"k":1}}`)
+			src.config.IncludeBinary = includeBinary
+
+			_, content, ok := src.changedContent(context.Background(), "")
+			if !ok {
+				t.Fatal("changedContent fell back to a full download")
+			}
+			want := `{"kept":{"k":1}}`
+			if includeBinary {
+				want = `{"base64:logo.png":"` + base64.StdEncoding.EncodeToString([]byte("\x89PNG")) + `","kept":{"k":1}}`
+			}
+			if content != want {
+				t.Errorf("content = %s, want %s", content, want)
+			}
+		})
+	}
+}
+
+// A new file with the base name of one already rendered fails like a full
+// download would, instead of replacing it.
+func TestGitHubSourceApplyChanges_DuplicateFileName(t *testing.T) {
+	tests := []struct {
+		name    string
+		changed []github.ChangedFile
+	}{
+		{"added next to an unchanged file", []github.ChangedFile{
+			{Filename: ".deco/blocks/other/kept.json", Status: "added"},
+		}},
+		{"two added files", []github.ChangedFile{
+			{Filename: ".deco/blocks/a/new.json", Status: "added"},
+			{Filename: ".deco/blocks/b/new.json", Status: "added"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeGitHubCompare(t, "ahead", tt.changed, map[string]string{
+				".deco/blocks/other/kept.json": `{"k":2}`,
+				".deco/blocks/a/new.json":      `{"n":1}`,
+				".deco/blocks/b/new.json":      `{"n":2}`,
+			})
+			src := newIncrementalSource(`{"kept":{"k":1}}`)
+			if _, err := src.applyChanges(context.Background(), "", headSHA); !errors.Is(err, github.ErrDuplicateFileName) {
+				t.Errorf("applyChanges err = %v, want ErrDuplicateFileName", err)
+			}
+		})
+	}
+}
+
+// A diff that can't be applied to the previous render falls back to a full
+// download of the resolved commit.
+func TestGitHubSourceChangedContent_Fallback(t *testing.T) {
+	manyFiles := make([]github.ChangedFile, 300)
+	for i := range manyFiles {
+		manyFiles[i] = github.ChangedFile{Filename: fmt.Sprintf(".deco/blocks/%d.json", i), Status: "modified"}
+	}
+	tests := []struct {
+		name     string
+		status   string
+		changed  []github.ChangedFile
+		previous string
+	}{
+		{"truncated compare", "ahead", manyFiles, `{"kept":{"k":1}}`},
+		{"diverged compare", "diverged", nil, `{"kept":{"k":1}}`},
+		{"undecodable previous render", "ahead", nil, `not json`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetched := fakeGitHubCompare(t, tt.status, tt.changed, nil)
+			sha, content, ok := newIncrementalSource(tt.previous).changedContent(context.Background(), "")
+			if ok || content != "" {
+				t.Errorf("changedContent = %q, ok %v; want a fallback", content, ok)
+			}
+			if sha != headSHA {
+				t.Errorf("sha = %q, want the resolved %s to download", sha, headSHA)
+			}
+			if got := fetched(); len(got) != 0 {
+				t.Errorf("fetched %v, want nothing before falling back", got)
+			}
+		})
+	}
+}
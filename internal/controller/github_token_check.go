@@ -0,0 +1,114 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+	"github.com/deco-sites/decofile-operator/internal/github"
+)
+
+// condTypeGitHubTokenScopes reports whether the GitHub token can read the
+// repository of spec.github, so a token without the repo scope shows up as
+// such rather than as a download 404 that looks like a wrong path.
+const condTypeGitHubTokenScopes = "GitHubTokenScopes"
+
+// checkGitHubToken runs the token check, when r.CheckGitHubTokenScopes is
+// set, for a github-source Decofile whose
+// current generation hasn't been checked yet, and records the outcome in
+// status right away, as a failing download returns before the reconcile's
+// own status update. It returns the condition in effect, or nil when the
+// check doesn't apply.
+func (r *DecofileReconciler) checkGitHubToken(ctx context.Context, decofile *decositesv1alpha1.Decofile) *metav1.Condition {
+	if !r.CheckGitHubTokenScopes || decofile.Spec.Source != SourceTypeGitHub || decofile.Spec.GitHub == nil {
+		return nil
+	}
+	if existing := meta.FindStatusCondition(decofile.Status.Conditions, condTypeGitHubTokenScopes); existing != nil &&
+		existing.ObservedGeneration == decofile.Generation && existing.Status != metav1.ConditionUnknown {
+		return existing
+	}
+
+	gh := decofile.Spec.GitHub
+	var cond metav1.Condition
+	token, err := GitHubToken(ctx, r.Client, decofile.Namespace, gh.Secret, r.Sources.DefaultGitHubSecret)
+	if err != nil {
+		cond = metav1.Condition{Status: metav1.ConditionUnknown, Reason: "TokenUnavailable", Message: err.Error()}
+	} else {
+		access, err := github.CheckTokenAccess(ctx, token, gh.Org, gh.Repo)
+		cond = tokenAccessCondition(gh.Org+"/"+gh.Repo, token != "", access, err)
+	}
+	cond.Type = condTypeGitHubTokenScopes
+	cond.LastTransitionTime = metav1.Now()
+
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &decositesv1alpha1.Decofile{}
+		if err := r.Get(ctx, client.ObjectKeyFromObject(decofile), fresh); err != nil {
+			return err
+		}
+		updateCondition(fresh, cond)
+		return r.Status().Update(ctx, fresh)
+	})
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to record GitHub token check")
+	}
+	if cond.Status == metav1.ConditionFalse {
+		logf.FromContext(ctx).Info("GitHub token cannot read the repository", "reason", cond.Reason, "message", cond.Message)
+	}
+	return &cond
+}
+
+// tokenAccessCondition turns what the token can see of repo into the
+// GitHubTokenScopes condition. Without the repo scope a classic token gets
+// the same 404 for a private repository as for a missing one, so a 404 is
+// blamed on the scope whenever the token reports scopes lacking it.
+func tokenAccessCondition(repo string, hasToken bool, access github.TokenAccess, err error) metav1.Condition {
+	switch {
+	case err != nil:
+		return metav1.Condition{Status: metav1.ConditionUnknown, Reason: "CheckFailed", Message: err.Error()}
+	case access.Status == http.StatusOK:
+		return metav1.Condition{Status: metav1.ConditionTrue, Reason: "RepositoryReadable",
+			Message: fmt.Sprintf("The GitHub token can read %s", repo)}
+	case access.Status == http.StatusUnauthorized:
+		return metav1.Condition{Status: metav1.ConditionFalse, Reason: "BadCredentials",
+			Message: "GitHub rejected the token as invalid or expired"}
+	case access.Status == http.StatusForbidden:
+		// Rate limiting or SSO enforcement; neither says anything about the
+		// scopes, and the check is retried
+		return metav1.Condition{Status: metav1.ConditionUnknown, Reason: "Forbidden",
+			Message: fmt.Sprintf("GitHub refused to show %s (status 403): rate limited, or the token needs SSO authorization", repo)}
+	case !hasToken:
+		return metav1.Condition{Status: metav1.ConditionFalse, Reason: "RepositoryNotFound",
+			Message: fmt.Sprintf("%s is not visible without a token; a private repository needs spec.github.secret or GITHUB_TOKEN", repo)}
+	case access.Scopes != nil && !access.HasScope("repo"):
+		return metav1.Condition{Status: metav1.ConditionFalse, Reason: "MissingRepoScope",
+			Message: fmt.Sprintf("%s is not visible to the GitHub token, which lacks the repo scope private repositories need (token scopes: %s)",
+				repo, strings.Join(access.Scopes, ", "))}
+	default:
+		return metav1.Condition{Status: metav1.ConditionFalse, Reason: "RepositoryNotFound",
+			Message: fmt.Sprintf("%s does not exist or the GitHub token has not been granted access to it (status %d)", repo, access.Status)}
+	}
+}
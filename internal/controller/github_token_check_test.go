@@ -0,0 +1,70 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/deco-sites/decofile-operator/internal/github"
+)
+
+func TestTokenAccessCondition(t *testing.T) {
+	tests := []struct {
+		name        string
+		hasToken    bool
+		access      github.TokenAccess
+		err         error
+		wantStatus  metav1.ConditionStatus
+		wantReason  string
+		wantMessage string
+	}{
+		{name: "readable", hasToken: true, access: github.TokenAccess{Status: http.StatusOK, Private: true},
+			wantStatus: metav1.ConditionTrue, wantReason: "RepositoryReadable"},
+		{name: "classic token without repo scope", hasToken: true,
+			access:     github.TokenAccess{Status: http.StatusNotFound, Scopes: []string{"public_repo", "read:org"}},
+			wantStatus: metav1.ConditionFalse, wantReason: "MissingRepoScope", wantMessage: "token scopes: public_repo, read:org"},
+		{name: "classic token with repo scope", hasToken: true,
+			access:     github.TokenAccess{Status: http.StatusNotFound, Scopes: []string{"repo"}},
+			wantStatus: metav1.ConditionFalse, wantReason: "RepositoryNotFound"},
+		{name: "fine-grained token", hasToken: true, access: github.TokenAccess{Status: http.StatusNotFound},
+			wantStatus: metav1.ConditionFalse, wantReason: "RepositoryNotFound"},
+		{name: "no token", access: github.TokenAccess{Status: http.StatusNotFound},
+			wantStatus: metav1.ConditionFalse, wantReason: "RepositoryNotFound", wantMessage: "without a token"},
+		{name: "bad credentials", hasToken: true, access: github.TokenAccess{Status: http.StatusUnauthorized},
+			wantStatus: metav1.ConditionFalse, wantReason: "BadCredentials"},
+		{name: "forbidden", hasToken: true, access: github.TokenAccess{Status: http.StatusForbidden},
+			wantStatus: metav1.ConditionUnknown, wantReason: "Forbidden"},
+		{name: "request failed", hasToken: true, err: errors.New("dial tcp: timeout"),
+			wantStatus: metav1.ConditionUnknown, wantReason: "CheckFailed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tokenAccessCondition("deco-sites/storefront", tt.hasToken, tt.access, tt.err)
+			if got.Status != tt.wantStatus || got.Reason != tt.wantReason {
+				t.Errorf("condition = %s/%s, want %s/%s", got.Status, got.Reason, tt.wantStatus, tt.wantReason)
+			}
+			if !strings.Contains(got.Message, tt.wantMessage) {
+				t.Errorf("message = %q, want it to contain %q", got.Message, tt.wantMessage)
+			}
+		})
+	}
+}
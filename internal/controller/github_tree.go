@@ -0,0 +1,134 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+	"github.com/deco-sites/decofile-operator/internal/github"
+)
+
+// unchangedGitHubContent looks up the tree spec.github.path is extracted from
+// and, when it matches status.githubTree and the spec hasn't changed since
+// the last successful render, returns the content already in the ConfigMap
+// so the archive isn't downloaded again (a renotify, a notification retry, a
+// push to a tracked branch outside the path). The tree SHA is returned either
+// way to be recorded in status; it is "" when the lookup failed, which only
+// costs a download.
+func (r *DecofileReconciler) unchangedGitHubContent(ctx context.Context, decofile *decositesv1alpha1.Decofile, configMapName string) (tree, content string, ok bool) {
+	log := logf.FromContext(ctx)
+	gh := decofile.Spec.GitHub
+	// Layers come from other commits, which the base tree says nothing
+	// about, and several paths have a tree each
+	paths := gh.AllPaths()
+	if len(gh.Layers) > 0 || len(paths) != 1 {
+		return "", "", false
+	}
+
+	token, err := GitHubToken(ctx, r.Client, decofile.Namespace, gh.Secret, r.Sources.DefaultGitHubSecret)
+	if err != nil {
+		return "", "", false
+	}
+	// Look up the head the branch watcher saw, so the tree matches it
+	ref := gh.Commit
+	if head := decofile.Annotations[githubHeadAnnotation]; head != "" && trackedBranch(decofile) != "" {
+		ref = head
+	}
+	tree, err = github.TreeSHA(ctx, token, gh.Org, gh.Repo, ref, paths[0])
+	if err != nil {
+		log.V(1).Info("GitHub tree lookup failed, downloading", "error", err.Error())
+		return "", "", false
+	}
+	if tree != decofile.Status.GitHubTree || githubCacheBusted(decofile) {
+		return tree, "", false
+	}
+
+	// The rendered content also depends on the spec (transforms, key names,
+	// includeBinary); ${DECOFILE_COMMIT} changes with the commit alone
+	ready := meta.FindStatusCondition(decofile.Status.Conditions, "Ready")
+	if ready == nil || ready.Status != metav1.ConditionTrue || ready.ObservedGeneration != decofile.Generation ||
+		slices.Contains(decofile.Spec.Transforms, "substitute") {
+		return tree, "", false
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, client.ObjectKey{Name: configMapName, Namespace: decofile.Namespace}, cm); err != nil {
+		return tree, "", false
+	}
+	decoded, err := DecodeDecofileConfigMap(cm)
+	if err != nil {
+		log.Error(err, "Failed to decode ConfigMap content, downloading", "ConfigMap.Name", configMapName)
+		return tree, "", false
+	}
+
+	githubDownloadsSkipped.Inc()
+	log.Info("GitHub tree unchanged, reusing ConfigMap content", "tree", tree, "ref", ref)
+	return tree, string(decoded), true
+}
+
+// incrementalBase returns the last render of a Decofile with
+// spec.github.incremental, for the GitHub source to apply changed files to.
+// It returns "" when there is none to build on: no recorded commit, render
+// settings changed since (githubRenderHash), or transforms, whose output the
+// ConfigMap holds instead of the files' content. A new spec.github.commit
+// alone keeps the base: that is the change incremental mode is for.
+func (r *DecofileReconciler) incrementalBase(ctx context.Context, decofile *decositesv1alpha1.Decofile, configMapName string) (sha, content string) {
+	if decofile.Status.GitHubSHA == "" || len(decofile.Spec.Transforms) > 0 ||
+		decofile.Status.GitHubRenderHash != githubRenderHash(decofile) {
+		return "", ""
+	}
+	ready := meta.FindStatusCondition(decofile.Status.Conditions, "Ready")
+	if ready == nil || ready.Status != metav1.ConditionTrue {
+		return "", ""
+	}
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, client.ObjectKey{Name: configMapName, Namespace: decofile.Namespace}, cm); err != nil {
+		return "", ""
+	}
+	decoded, err := DecodeDecofileConfigMap(cm)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to decode ConfigMap content, downloading", "ConfigMap.Name", configMapName)
+		return "", ""
+	}
+	return decofile.Status.GitHubSHA, string(decoded)
+}
+
+// githubRenderHash identifies the spec fields that decide how the files of a
+// commit are rendered, recorded with status.githubSHA. Commit, cacheBust and
+// refreshInterval are left out: they pick which files are read, not how.
+func githubRenderHash(decofile *decositesv1alpha1.Decofile) string {
+	gh := decofile.Spec.GitHub
+	settings, _ := json.Marshal(struct {
+		Org, Repo       string
+		Paths           []string
+		MaxDepth        int
+		IncludeBinary   bool
+		LFS             bool
+		Layers          []decositesv1alpha1.GitHubLayer
+		StripExtensions bool
+	}{gh.Org, gh.Repo, gh.AllPaths(), gh.MaxDepth, gh.IncludeBinary, gh.LFS, gh.Layers, decofile.Spec.ShouldStripExtensions()})
+	return sha256hex(string(settings))
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"cmp"
+	"context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+// maxHistoryMessage bounds the message of a status.history entry, so a long
+// error can't bloat the status.
+const maxHistoryMessage = 512
+
+// recordHistory appends an entry to status.history, dropping the oldest
+// beyond MaxHistoryEntries, and reports whether the history changed. A
+// failure following a failure replaces it instead of being appended, so a
+// render retried with backoff keeps one entry rather than pushing the rest
+// of the history out; an exact repeat of the last entry is not recorded.
+func recordHistory(decofile *decositesv1alpha1.Decofile, event, message string) bool {
+	if len(message) > maxHistoryMessage {
+		message = message[:maxHistoryMessage-3] + "..."
+	}
+	history := decofile.Status.History
+	if n := len(history); n > 0 {
+		last := &history[n-1]
+		if last.Event == event && last.Message == message {
+			return false
+		}
+		if last.Event == decositesv1alpha1.HistoryFailed && event == decositesv1alpha1.HistoryFailed {
+			last.Time, last.Message = metav1.Now(), message
+			return true
+		}
+	}
+	history = append(history, decositesv1alpha1.HistoryEntry{Time: metav1.Now(), Event: event, Message: message})
+	if extra := len(history) - decositesv1alpha1.MaxHistoryEntries; extra > 0 {
+		history = append(history[:0:0], history[extra:]...)
+	}
+	decofile.Status.History = history
+	return true
+}
+
+// recordFailure records a failed render in status.history on its own, for
+// failures that return before the final status update. Errors are only
+// logged: the failure itself is what the reconcile reports.
+func (r *DecofileReconciler) recordFailure(ctx context.Context, req ctrl.Request, cause error) {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &decositesv1alpha1.Decofile{}
+		if err := r.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		if !recordHistory(fresh, decositesv1alpha1.HistoryFailed, cause.Error()) {
+			return nil
+		}
+		return r.Status().Update(ctx, fresh)
+	})
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to record failure in Decofile history")
+	}
+}
+
+// recordContentHash sets status.contentHash to hash. When that replaces a
+// different hash, the old one and the commit it was rendered at are kept as
+// status.previousContentHash and previousGitHubCommit, so it must run
+// before the GitHub status of the new content is recorded.
+func recordContentHash(df *decositesv1alpha1.Decofile, hash string) {
+	if df.Status.ContentHash != "" && df.Status.ContentHash != hash {
+		df.Status.PreviousContentHash = df.Status.ContentHash
+		df.Status.PreviousGitHubCommit = cmp.Or(df.Status.GitHubSHA, df.Status.GitHubHead, df.Status.GitHubCommit)
+	}
+	df.Status.ContentHash = hash
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+func historyEvents(df *decositesv1alpha1.Decofile) string {
+	events := make([]string, 0, len(df.Status.History))
+	for _, e := range df.Status.History {
+		events = append(events, e.Event)
+	}
+	return strings.Join(events, ",")
+}
+
+func TestRecordHistory(t *testing.T) {
+	df := makeDecofile("foo", "")
+	for i := range decositesv1alpha1.MaxHistoryEntries + 3 {
+		recordHistory(df, decositesv1alpha1.HistoryUpdated, fmt.Sprintf("revision %d", i))
+	}
+	if len(df.Status.History) != decositesv1alpha1.MaxHistoryEntries {
+		t.Fatalf("history has %d entries, want the cap of %d", len(df.Status.History), decositesv1alpha1.MaxHistoryEntries)
+	}
+	if got := df.Status.History[0].Message; got != "revision 3" {
+		t.Errorf("oldest entry = %q, want the oldest ones dropped", got)
+	}
+
+	if recordHistory(df, decositesv1alpha1.HistoryUpdated, fmt.Sprintf("revision %d", decositesv1alpha1.MaxHistoryEntries+2)) {
+		t.Error("an exact repeat of the last entry was recorded")
+	}
+
+	// Retried failures keep a single entry with the latest message
+	recordHistory(df, decositesv1alpha1.HistoryFailed, "attempt 1")
+	recordHistory(df, decositesv1alpha1.HistoryFailed, "attempt 2")
+	last := df.Status.History[len(df.Status.History)-1]
+	prev := df.Status.History[len(df.Status.History)-2]
+	if last.Event != decositesv1alpha1.HistoryFailed || last.Message != "attempt 2" || prev.Event == decositesv1alpha1.HistoryFailed {
+		t.Errorf("history ends with %+v, %+v; want a single Failed entry for attempt 2", prev, last)
+	}
+
+	recordHistory(df, decositesv1alpha1.HistoryFailed, strings.Repeat("x", 2*maxHistoryMessage))
+	if got := len(df.Status.History[len(df.Status.History)-1].Message); got != maxHistoryMessage {
+		t.Errorf("message length = %d, want it truncated to %d", got, maxHistoryMessage)
+	}
+}
+
+func TestReconcile_RecordsHistory(t *testing.T) {
+	ctx := context.Background()
+	scheme := newOwnerTestScheme(t)
+	df := makeDecofile("foo", "")
+	df.Spec.Source = SourceTypeInline
+	df.Spec.Inline = &decositesv1alpha1.InlineSource{Value: map[string]runtime.RawExtension{
+		"config.json": {Raw: []byte(`{"v":1}`)},
+	}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(df).
+		WithStatusSubresource(&decositesv1alpha1.Decofile{}).Build()
+	r := &DecofileReconciler{Client: c, Scheme: scheme}
+	key := client.ObjectKeyFromObject(df)
+
+	reconcileWith := func(value string) *decositesv1alpha1.Decofile {
+		t.Helper()
+		current := &decositesv1alpha1.Decofile{}
+		if err := c.Get(ctx, key, current); err != nil {
+			t.Fatalf("get Decofile: %v", err)
+		}
+		current.Spec.Inline.Value["config.json"] = runtime.RawExtension{Raw: []byte(value)}
+		if err := c.Update(ctx, current); err != nil {
+			t.Fatalf("update Decofile: %v", err)
+		}
+		_, _ = r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		if err := c.Get(ctx, key, current); err != nil {
+			t.Fatalf("get Decofile: %v", err)
+		}
+		return current
+	}
+
+	hash1, hash2 := sha256hex(`{"config":{"v":1}}`), sha256hex(`{"config":{"v":2}}`)
+	got := reconcileWith(`{"v":1}`)
+	if events := historyEvents(got); events != "Created" {
+		t.Fatalf("history = %s, want Created", events)
+	}
+	if got.Status.ContentHash != hash1 || got.Status.PreviousContentHash != "" {
+		t.Errorf("hashes = %q, previous %q; want %q and none", got.Status.ContentHash, got.Status.PreviousContentHash, hash1)
+	}
+	got = reconcileWith(`{"v":2}`)
+	if events := historyEvents(got); events != "Created,Updated,Notified" {
+		t.Errorf("history = %s, want Created,Updated,Notified", events)
+	}
+	if got.Status.ContentHash != hash2 || got.Status.PreviousContentHash != hash1 {
+		t.Errorf("hashes = %q, previous %q; want %q, previous %q", got.Status.ContentHash, got.Status.PreviousContentHash, hash2, hash1)
+	}
+	// An unchanged render adds nothing
+	got = reconcileWith(`{"v":2}`)
+	if events := historyEvents(got); events != "Created,Updated,Notified" {
+		t.Errorf("history after a no-op reconcile = %s, want it unchanged", events)
+	}
+	if got.Status.PreviousContentHash != hash1 {
+		t.Errorf("previous hash after a no-op reconcile = %q, want %q", got.Status.PreviousContentHash, hash1)
+	}
+}
+
+func TestRecordContentHash(t *testing.T) {
+	df := makeDecofile("foo", "")
+	df.Status.GitHubCommit = "main"
+	df.Status.GitHubHead = "aaaa"
+
+	recordContentHash(df, "h1")
+	if df.Status.PreviousContentHash != "" || df.Status.PreviousGitHubCommit != "" {
+		t.Errorf("first hash recorded a previous one: %+v", df.Status)
+	}
+	recordContentHash(df, "h1")
+	recordContentHash(df, "h2")
+	if df.Status.ContentHash != "h2" || df.Status.PreviousContentHash != "h1" {
+		t.Errorf("hashes = %q, previous %q; want h2, previous h1", df.Status.ContentHash, df.Status.PreviousContentHash)
+	}
+	// The branch head the old content was rendered at, not the branch name
+	if df.Status.PreviousGitHubCommit != "aaaa" {
+		t.Errorf("previous commit = %q, want the branch head aaaa", df.Status.PreviousGitHubCommit)
+	}
+}
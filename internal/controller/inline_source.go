@@ -21,14 +21,31 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/yaml"
+
 	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
 )
 
+// maxInlineEntries caps the number of keys in spec.inline.value. Inline content
+// lives in the Decofile object itself (etcd), so anything larger belongs in a
+// GitHub source.
+const maxInlineEntries = 5000
+
 // InlineSource handles retrieval of configuration data from inline JSON values
 type InlineSource struct {
 	config *decositesv1alpha1.InlineSource
+	// keepExtensions disables stripping ".json" from keys
+	keepExtensions bool
+	// client and namespace resolve spec.inline.valueFrom ConfigMaps
+	client    client.Client
+	namespace string
 }
 
 // NewInlineSource creates a new InlineSource with the given configuration
@@ -36,30 +53,293 @@ func NewInlineSource(config *decositesv1alpha1.InlineSource) *InlineSource {
 	return &InlineSource{config: config}
 }
 
-// Retrieve converts inline JSON values to a single JSON string
+// Retrieve converts inline JSON values to a single JSON string.
+// Entries are streamed into the output buffer one at a time (sorted by key)
+// instead of being collected into an intermediate map first. Entries with an
+// overlay are decoded, merged and re-encoded; the rest are written as-is.
 func (s *InlineSource) Retrieve(ctx context.Context) (string, error) {
-	// Build a map of filename to JSON content using RawMessage to avoid double-encoding
-	filesJSON := make(map[string]json.RawMessage)
+	values, err := s.values(ctx)
+	if err != nil {
+		return "", err
+	}
+	if len(values) > maxInlineEntries {
+		return "", fmt.Errorf("inline source has %d entries, exceeding the limit of %d", len(values), maxInlineEntries)
+	}
+	if len(s.config.Overlay) > maxInlineEntries {
+		return "", fmt.Errorf("inline overlay has %d entries, exceeding the limit of %d", len(s.config.Overlay), maxInlineEntries)
+	}
+
+	overlays, err := s.overlays()
+	if err != nil {
+		return "", err
+	}
+
+	keys := make([]string, 0, len(values)+len(overlays))
+	base := make(map[string]struct{}, len(values))
+	size := 2
+	for key, raw := range values {
+		keys = append(keys, key)
+		base[decofileKey(key, s.keepExtensions)] = struct{}{}
+		size += len(key) + len(raw) + 4
+	}
+	// Overlay entries with no base entry are added as new keys
+	for cleanKey, key := range overlays {
+		if _, ok := base[cleanKey]; !ok {
+			keys = append(keys, key)
+			size += len(key) + len(s.config.Overlay[key].Raw) + 4
+		}
+	}
+	// Order by cleaned key; on a collision ("a" vs "a.json") the last key wins
+	sort.Slice(keys, func(i, j int) bool {
+		ci, cj := decofileKey(keys[i], s.keepExtensions), decofileKey(keys[j], s.keepExtensions)
+		if ci != cj {
+			return ci < cj
+		}
+		return keys[i] < keys[j]
+	})
+
+	w := newJSONObjectWriter(size)
+	merged := 0
+	for i, key := range keys {
+		cleanKey := decofileKey(key, s.keepExtensions)
+		if i+1 < len(keys) && decofileKey(keys[i+1], s.keepExtensions) == cleanKey {
+			continue
+		}
+		value := values[key]
+		if overlayKey, ok := overlays[cleanKey]; ok {
+			if value, err = mergeJSON(value, s.config.Overlay[overlayKey].Raw); err != nil {
+				return "", fmt.Errorf("failed to apply overlay for key %s: %w", key, err)
+			}
+			merged++
+		}
+		if err := w.WriteMember(cleanKey, value); err != nil {
+			return "", fmt.Errorf("failed to marshal value for key %s: %w", key, err)
+		}
+	}
+
+	logf.FromContext(ctx).V(1).Info("Minified inline values", "bytesSaved", w.BytesSaved(), "overlaid", merged)
+	return w.String(), nil
+}
+
+// values returns the base entries: spec.inline.value plus the keys read
+// from spec.inline.valueFrom ConfigMaps. An entry whose cleaned key another
+// one already provides is rejected, naming both origins. Keys that only
+// differ by ".json" within spec.inline.value keep their last-key-wins order.
+func (s *InlineSource) values(ctx context.Context) (map[string][]byte, error) {
+	values := make(map[string][]byte, len(s.config.Value))
+	origin := make(map[string]string, len(s.config.Value))
 	for key, rawExt := range s.config.Value {
 		// RawExtension.Raw is already JSON bytes
 		if len(rawExt.Raw) == 0 {
-			return "", fmt.Errorf("empty value for key %s", key)
+			return nil, fmt.Errorf("empty value for key %s", key)
 		}
-		// Strip .json extension from key
-		cleanKey := strings.TrimSuffix(key, ".json")
-		filesJSON[cleanKey] = json.RawMessage(rawExt.Raw)
+		raw := rawExt.Raw
+		if s.config.Format == decositesv1alpha1.InlineFormatYAML {
+			var err error
+			if raw, err = yamlValueToJSON(raw); err != nil {
+				return nil, fmt.Errorf("spec.inline.value key %s: %w", key, err)
+			}
+		}
+		values[key] = raw
+		origin[decofileKey(key, s.keepExtensions)] = "spec.inline.value"
 	}
 
-	// Marshal to single JSON string without HTML escaping
-	var buf bytes.Buffer
-	encoder := json.NewEncoder(&buf)
-	encoder.SetEscapeHTML(false)
-	err := encoder.Encode(filesJSON)
+	for i, from := range s.config.ValueFrom {
+		name := from.ConfigMapRef.Name
+		if s.client == nil {
+			return nil, fmt.Errorf("spec.inline.valueFrom[%d]: no client to read ConfigMap %s", i, name)
+		}
+		cm := &corev1.ConfigMap{}
+		if err := s.client.Get(ctx, client.ObjectKey{Name: name, Namespace: s.namespace}, cm); err != nil {
+			if errors.IsNotFound(err) && from.Optional {
+				logf.FromContext(ctx).V(1).Info("Optional inline ConfigMap not found, skipping", "configMap", name)
+				continue
+			}
+			return nil, fmt.Errorf("spec.inline.valueFrom[%d]: failed to get ConfigMap %s: %w", i, name, err)
+		}
+
+		keys := from.Keys
+		if len(keys) == 0 {
+			keys = make([]string, 0, len(cm.Data))
+			for key := range cm.Data {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+		}
+		here := fmt.Sprintf("ConfigMap %s (spec.inline.valueFrom[%d])", name, i)
+		for _, key := range keys {
+			data, ok := cm.Data[key]
+			if !ok {
+				if from.Optional {
+					continue
+				}
+				return nil, fmt.Errorf("%s has no key %s", here, key)
+			}
+			if !json.Valid([]byte(data)) {
+				return nil, fmt.Errorf("%s key %s is not valid JSON", here, key)
+			}
+			cleanKey := decofileKey(key, s.keepExtensions)
+			if prev, ok := origin[cleanKey]; ok {
+				return nil, fmt.Errorf("key %s from %s collides with the same key from %s", cleanKey, here, prev)
+			}
+			values[key] = []byte(data)
+			origin[cleanKey] = here
+		}
+	}
+	return values, nil
+}
+
+// yamlValueToJSON converts a Value entry of spec.inline.format yaml, a JSON
+// string holding a YAML document, to JSON. An entry that isn't a string is
+// already JSON (which is valid YAML) and is kept as-is.
+func yamlValueToJSON(raw []byte) ([]byte, error) {
+	var doc string
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return raw, nil
+	}
+	if strings.TrimSpace(doc) == "" {
+		return nil, fmt.Errorf("YAML document is empty")
+	}
+	converted, err := yaml.YAMLToJSON([]byte(doc))
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal files to JSON: %w", err)
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	return converted, nil
+}
+
+// overlays maps the cleaned keys of spec.inline.overlay to the overlay key,
+// so "home" overlays a "home.json" base entry. On a collision the last key
+// wins, as for the base values.
+func (s *InlineSource) overlays() (map[string]string, error) {
+	if len(s.config.Overlay) == 0 {
+		return nil, nil
 	}
+	keys := make([]string, 0, len(s.config.Overlay))
+	for key, rawExt := range s.config.Overlay {
+		if len(rawExt.Raw) == 0 {
+			return nil, fmt.Errorf("empty overlay for key %s", key)
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	overlays := make(map[string]string, len(keys))
+	for _, key := range keys {
+		overlays[decofileKey(key, s.keepExtensions)] = key
+	}
+	return overlays, nil
+}
+
+// mergeJSON deep-merges overlay over base: objects are merged key by key,
+// anything else in the overlay (arrays, scalars, null) replaces the base
+// value. An empty base (an overlay-only key) yields the overlay. Numbers are
+// kept as written.
+func mergeJSON(base, overlay []byte) ([]byte, error) {
+	if len(base) == 0 {
+		return overlay, nil
+	}
+	b, err := decodeJSONNumbers(base)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base value: %w", err)
+	}
+	o, err := decodeJSONNumbers(overlay)
+	if err != nil {
+		return nil, fmt.Errorf("invalid overlay value: %w", err)
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(mergeValues(b, o)); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+func mergeValues(base, overlay any) any {
+	bo, ok := base.(map[string]any)
+	if !ok {
+		return overlay
+	}
+	oo, ok := overlay.(map[string]any)
+	if !ok {
+		return overlay
+	}
+	for k, ov := range oo {
+		if bv, ok := bo[k]; ok {
+			bo[k] = mergeValues(bv, ov)
+		} else {
+			bo[k] = ov
+		}
+	}
+	return bo
+}
+
+func decodeJSONNumbers(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("trailing data after JSON value")
+	}
+	return v, nil
+}
+
+// jsonObjectWriter streams members of a JSON object into a buffer, so large
+// objects are never held twice (once as a map, once encoded). Values are
+// always minified; BytesSaved reports how much whitespace that stripped.
+type jsonObjectWriter struct {
+	buf        bytes.Buffer
+	encoder    *json.Encoder
+	members    int
+	bytesSaved int
+}
+
+func newJSONObjectWriter(sizeHint int) *jsonObjectWriter {
+	w := &jsonObjectWriter{}
+	w.buf.Grow(sizeHint)
+	w.buf.WriteByte('{')
+	// Encoder is only used for keys, so they are written without HTML escaping
+	w.encoder = json.NewEncoder(&w.buf)
+	w.encoder.SetEscapeHTML(false)
+	return w
+}
+
+// WriteMember appends key: value, where value must be valid JSON (it is
+// compacted on the way in). Callers are responsible for key uniqueness.
+func (w *jsonObjectWriter) WriteMember(key string, value []byte) error {
+	mark := w.buf.Len()
+	if w.members > 0 {
+		w.buf.WriteByte(',')
+	}
+	if err := w.encoder.Encode(key); err != nil {
+		w.buf.Truncate(mark)
+		return err
+	}
+	w.buf.Truncate(w.buf.Len() - 1) // drop the encoder's trailing newline
+	w.buf.WriteByte(':')
+	valueStart := w.buf.Len()
+	if err := json.Compact(&w.buf, value); err != nil {
+		w.buf.Truncate(mark)
+		return err
+	}
+	w.bytesSaved += len(value) - (w.buf.Len() - valueStart)
+	w.members++
+	return nil
+}
+
+// BytesSaved returns the number of insignificant whitespace bytes removed
+// from the values written so far.
+func (w *jsonObjectWriter) BytesSaved() int {
+	return w.bytesSaved
+}
 
-	return strings.TrimSpace(buf.String()), nil
+// String closes the object and returns it.
+func (w *jsonObjectWriter) String() string {
+	w.buf.WriteByte('}')
+	return w.buf.String()
 }
 
 // SourceType returns the source type identifier
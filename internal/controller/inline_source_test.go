@@ -0,0 +1,445 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+func TestInlineSourceRetrieve(t *testing.T) {
+	src := NewInlineSource(&decositesv1alpha1.InlineSource{Value: map[string]runtime.RawExtension{
+		"b.json": {Raw: []byte(`{ "x": "<tag>" }`)},
+		"a":      {Raw: []byte(`[1, 2]`)},
+	}})
+
+	got, err := src.Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	want := `{"a":[1,2],"b":{"x":"<tag>"}}`
+	if got != want {
+		t.Errorf("Retrieve() = %s, want %s", got, want)
+	}
+}
+
+func TestInlineSourceRetrieve_KeepExtensions(t *testing.T) {
+	keep := false
+	df := &decositesv1alpha1.Decofile{Spec: decositesv1alpha1.DecofileSpec{
+		Source:          decositesv1alpha1.SourceInline,
+		StripExtensions: &keep,
+		Inline: &decositesv1alpha1.InlineSource{Value: map[string]runtime.RawExtension{
+			"b.json": {Raw: []byte(`1`)},
+			"b":      {Raw: []byte(`2`)},
+		}},
+	}}
+	src, err := NewSource(nil, df, SourceOptions{})
+	if err != nil {
+		t.Fatalf("NewSource: %v", err)
+	}
+
+	got, err := src.Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if want := `{"b":2,"b.json":1}`; got != want {
+		t.Errorf("Retrieve() = %s, want %s", got, want)
+	}
+}
+
+func TestInlineSourceRetrieve_YAML(t *testing.T) {
+	src := NewInlineSource(&decositesv1alpha1.InlineSource{
+		Format: decositesv1alpha1.InlineFormatYAML,
+		Value: map[string]runtime.RawExtension{
+			"home.json": {Raw: []byte(`"title: Home\nsections:\n  - hero\n  - footer\n"`)},
+			"site":      {Raw: []byte(`{"name":"already json"}`)},
+		},
+		Overlay: map[string]runtime.RawExtension{
+			"home": {Raw: []byte(`{"title":"Overlaid"}`)},
+		},
+	})
+
+	got, err := src.Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	want := `{"home":{"sections":["hero","footer"],"title":"Overlaid"},"site":{"name":"already json"}}`
+	if got != want {
+		t.Errorf("Retrieve() = %s, want %s", got, want)
+	}
+}
+
+func TestInlineSourceRetrieve_YAMLErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantErr string
+	}{
+		{"invalid YAML", `"a: [1, 2\n"`, "spec.inline.value key home: invalid YAML"},
+		{"empty document", `"  \n"`, "spec.inline.value key home: YAML document is empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := NewInlineSource(&decositesv1alpha1.InlineSource{
+				Format: decositesv1alpha1.InlineFormatYAML,
+				Value:  map[string]runtime.RawExtension{"home": {Raw: []byte(tt.value)}},
+			})
+			_, err := src.Retrieve(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Retrieve() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestJSONObjectWriter_Minifies(t *testing.T) {
+	w := newJSONObjectWriter(0)
+	pretty := "{\n  \"a\": [\n    1,\n    2\n  ]\n}"
+	if err := w.WriteMember("k", []byte(pretty)); err != nil {
+		t.Fatalf("WriteMember: %v", err)
+	}
+	if got, want := w.String(), `{"k":{"a":[1,2]}}`; got != want {
+		t.Errorf("String() = %s, want %s", got, want)
+	}
+	if got, want := w.BytesSaved(), len(pretty)-len(`{"a":[1,2]}`); got != want {
+		t.Errorf("BytesSaved() = %d, want %d", got, want)
+	}
+}
+
+func TestInlineSourceRetrieve_Errors(t *testing.T) {
+	tooMany := make(map[string]runtime.RawExtension, maxInlineEntries+1)
+	for i := 0; i <= maxInlineEntries; i++ {
+		tooMany[fmt.Sprintf("k%d", i)] = runtime.RawExtension{Raw: []byte(`{}`)}
+	}
+
+	tests := []struct {
+		name  string
+		value map[string]runtime.RawExtension
+	}{
+		{"empty value", map[string]runtime.RawExtension{"a": {}}},
+		{"invalid json", map[string]runtime.RawExtension{"a": {Raw: []byte(`{`)}}},
+		{"too many entries", tooMany},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := NewInlineSource(&decositesv1alpha1.InlineSource{Value: tt.value})
+			if _, err := src.Retrieve(context.Background()); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestInlineSourceRetrieve_Overlay(t *testing.T) {
+	raw := func(s string) runtime.RawExtension { return runtime.RawExtension{Raw: []byte(s)} }
+	tests := []struct {
+		name    string
+		value   map[string]runtime.RawExtension
+		overlay map[string]runtime.RawExtension
+		want    string
+	}{
+		{
+			name:    "objects merge recursively",
+			value:   map[string]runtime.RawExtension{"site.json": raw(`{"theme":{"color":"red","font":"serif"},"name":"base"}`)},
+			overlay: map[string]runtime.RawExtension{"site": raw(`{"theme":{"color":"blue"}}`)},
+			want:    `{"site":{"name":"base","theme":{"color":"blue","font":"serif"}}}`,
+		},
+		{
+			name:    "arrays replace",
+			value:   map[string]runtime.RawExtension{"a": raw(`{"hosts":["a","b"]}`)},
+			overlay: map[string]runtime.RawExtension{"a": raw(`{"hosts":["c"]}`)},
+			want:    `{"a":{"hosts":["c"]}}`,
+		},
+		{
+			name:    "scalars and null replace",
+			value:   map[string]runtime.RawExtension{"a": raw(`{"x":{"y":1},"z":2}`)},
+			overlay: map[string]runtime.RawExtension{"a": raw(`{"x":null,"z":"two"}`)},
+			want:    `{"a":{"x":null,"z":"two"}}`,
+		},
+		{
+			name:    "object over scalar replaces",
+			value:   map[string]runtime.RawExtension{"a": raw(`[1]`)},
+			overlay: map[string]runtime.RawExtension{"a": raw(`{"b":1}`)},
+			want:    `{"a":{"b":1}}`,
+		},
+		{
+			name:    "overlay-only key is added",
+			value:   map[string]runtime.RawExtension{"a": raw(`1`)},
+			overlay: map[string]runtime.RawExtension{"b.json": raw(`{ "c": 2 }`)},
+			want:    `{"a":1,"b":{"c":2}}`,
+		},
+		{
+			name:    "numbers and html kept as written",
+			value:   map[string]runtime.RawExtension{"a": raw(`{"big":12345678901234567890,"f":1.50}`)},
+			overlay: map[string]runtime.RawExtension{"a": raw(`{"tag":"<b>"}`)},
+			want:    `{"a":{"big":12345678901234567890,"f":1.50,"tag":"<b>"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := NewInlineSource(&decositesv1alpha1.InlineSource{Value: tt.value, Overlay: tt.overlay})
+			got, err := src.Retrieve(context.Background())
+			if err != nil {
+				t.Fatalf("Retrieve: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Retrieve() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInlineSourceRetrieve_OverlayErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		overlay map[string]runtime.RawExtension
+	}{
+		{"empty overlay", map[string]runtime.RawExtension{"a": {}}},
+		{"invalid overlay", map[string]runtime.RawExtension{"a": {Raw: []byte(`{`)}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := NewInlineSource(&decositesv1alpha1.InlineSource{
+				Value:   map[string]runtime.RawExtension{"a": {Raw: []byte(`{}`)}},
+				Overlay: tt.overlay,
+			})
+			if _, err := src.Retrieve(context.Background()); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func newValueFromSource(t *testing.T, config *decositesv1alpha1.InlineSource, cms ...*corev1.ConfigMap) *InlineSource {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatalf("add corev1 scheme: %v", err)
+	}
+	builder := fake.NewClientBuilder().WithScheme(scheme)
+	for _, cm := range cms {
+		builder = builder.WithObjects(cm)
+	}
+	src := NewInlineSource(config)
+	src.client, src.namespace = builder.Build(), testNamespace
+	return src
+}
+
+func inlineConfigMap(name string, data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Data:       data,
+	}
+}
+
+func TestInlineSourceRetrieve_ValueFrom(t *testing.T) {
+	cm := inlineConfigMap("pages", map[string]string{
+		"home.json":  `{"path":"/"}`,
+		"about.json": `{"path":"/about"}`,
+		"README":     `not json`,
+	})
+
+	tests := []struct {
+		name   string
+		config *decositesv1alpha1.InlineSource
+		want   string
+	}{
+		{
+			name: "selected keys merged with value",
+			config: &decositesv1alpha1.InlineSource{
+				Value: map[string]runtime.RawExtension{"site": {Raw: []byte(`{"name":"foo"}`)}},
+				ValueFrom: []decositesv1alpha1.InlineValueSource{{
+					ConfigMapRef: decositesv1alpha1.ConfigMapReference{Name: "pages"},
+					Keys:         []string{"home.json", "about.json"},
+				}},
+			},
+			want: `{"about":{"path":"/about"},"home":{"path":"/"},"site":{"name":"foo"}}`,
+		},
+		{
+			name: "missing optional ConfigMap",
+			config: &decositesv1alpha1.InlineSource{
+				Value: map[string]runtime.RawExtension{"site": {Raw: []byte(`{}`)}},
+				ValueFrom: []decositesv1alpha1.InlineValueSource{{
+					ConfigMapRef: decositesv1alpha1.ConfigMapReference{Name: "absent"},
+					Optional:     true,
+				}},
+			},
+			want: `{"site":{}}`,
+		},
+		{
+			name: "missing optional key",
+			config: &decositesv1alpha1.InlineSource{
+				ValueFrom: []decositesv1alpha1.InlineValueSource{{
+					ConfigMapRef: decositesv1alpha1.ConfigMapReference{Name: "pages"},
+					Keys:         []string{"home.json", "blog.json"},
+					Optional:     true,
+				}},
+			},
+			want: `{"home":{"path":"/"}}`,
+		},
+		{
+			name: "overlay applies to ConfigMap entries",
+			config: &decositesv1alpha1.InlineSource{
+				ValueFrom: []decositesv1alpha1.InlineValueSource{{
+					ConfigMapRef: decositesv1alpha1.ConfigMapReference{Name: "pages"},
+					Keys:         []string{"home.json"},
+				}},
+				Overlay: map[string]runtime.RawExtension{"home": {Raw: []byte(`{"title":"Home"}`)}},
+			},
+			want: `{"home":{"path":"/","title":"Home"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := newValueFromSource(t, tt.config, cm)
+			got, err := src.Retrieve(context.Background())
+			if err != nil {
+				t.Fatalf("Retrieve: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Retrieve() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInlineSourceRetrieve_ValueFromErrors(t *testing.T) {
+	pages := inlineConfigMap("pages", map[string]string{"home.json": `{"path":"/"}`, "README": `not json`})
+	more := inlineConfigMap("more", map[string]string{"home": `{}`})
+	ref := func(name string, keys ...string) decositesv1alpha1.InlineValueSource {
+		return decositesv1alpha1.InlineValueSource{
+			ConfigMapRef: decositesv1alpha1.ConfigMapReference{Name: name},
+			Keys:         keys,
+		}
+	}
+
+	tests := []struct {
+		name    string
+		config  *decositesv1alpha1.InlineSource
+		wantErr string
+	}{
+		{
+			name:    "missing ConfigMap",
+			config:  &decositesv1alpha1.InlineSource{ValueFrom: []decositesv1alpha1.InlineValueSource{ref("absent")}},
+			wantErr: "failed to get ConfigMap absent",
+		},
+		{
+			name:    "missing key",
+			config:  &decositesv1alpha1.InlineSource{ValueFrom: []decositesv1alpha1.InlineValueSource{ref("pages", "blog.json")}},
+			wantErr: "has no key blog.json",
+		},
+		{
+			name:    "invalid json",
+			config:  &decositesv1alpha1.InlineSource{ValueFrom: []decositesv1alpha1.InlineValueSource{ref("pages")}},
+			wantErr: "key README is not valid JSON",
+		},
+		{
+			name: "collision with value",
+			config: &decositesv1alpha1.InlineSource{
+				Value:     map[string]runtime.RawExtension{"home": {Raw: []byte(`{}`)}},
+				ValueFrom: []decositesv1alpha1.InlineValueSource{ref("pages", "home.json")},
+			},
+			wantErr: "key home from ConfigMap pages (spec.inline.valueFrom[0]) collides with the same key from spec.inline.value",
+		},
+		{
+			name: "collision between ConfigMaps",
+			config: &decositesv1alpha1.InlineSource{ValueFrom: []decositesv1alpha1.InlineValueSource{
+				ref("pages", "home.json"), ref("more"),
+			}},
+			wantErr: "collides with the same key from ConfigMap pages (spec.inline.valueFrom[0])",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := newValueFromSource(t, tt.config, pages, more)
+			_, err := src.Retrieve(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Retrieve() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestInlineReferencesConfigMap(t *testing.T) {
+	inline := &decositesv1alpha1.InlineSource{ValueFrom: []decositesv1alpha1.InlineValueSource{{
+		ConfigMapRef: decositesv1alpha1.ConfigMapReference{Name: "pages"},
+	}}}
+	spec := &decositesv1alpha1.DecofileSpec{Source: decositesv1alpha1.SourceInline, Inline: inline}
+	if !inlineReferencesConfigMap(spec, "pages") {
+		t.Error("expected spec to reference ConfigMap pages")
+	}
+	if inlineReferencesConfigMap(spec, "other") {
+		t.Error("expected spec not to reference ConfigMap other")
+	}
+	if inlineReferencesConfigMap(&decositesv1alpha1.DecofileSpec{Source: decositesv1alpha1.SourceGitHub}, "pages") {
+		t.Error("expected github spec not to reference any ConfigMap")
+	}
+}
+
+func benchmarkInlineValue(n int) map[string]runtime.RawExtension {
+	value := make(map[string]runtime.RawExtension, n)
+	for i := 0; i < n; i++ {
+		raw := fmt.Sprintf(`{"__resolveType":"site/loaders/%d.ts","props":{"title":"%s"}}`, i, strings.Repeat("x", 256))
+		value[fmt.Sprintf("pages-%d.json", i)] = runtime.RawExtension{Raw: []byte(raw)}
+	}
+	return value
+}
+
+// BenchmarkInlineSourceRetrieve measures the streaming encoder.
+func BenchmarkInlineSourceRetrieve(b *testing.B) {
+	src := NewInlineSource(&decositesv1alpha1.InlineSource{Value: benchmarkInlineValue(maxInlineEntries)})
+	ctx := context.Background()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := src.Retrieve(ctx); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkInlineSourceRetrieve_Map is the previous map-then-marshal approach,
+// kept as a baseline for comparing allocations.
+func BenchmarkInlineSourceRetrieve_Map(b *testing.B) {
+	value := benchmarkInlineValue(maxInlineEntries)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		filesJSON := make(map[string]json.RawMessage)
+		for key, rawExt := range value {
+			filesJSON[strings.TrimSuffix(key, ".json")] = json.RawMessage(rawExt.Raw)
+		}
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(filesJSON); err != nil {
+			b.Fatal(err)
+		}
+		_ = strings.TrimSpace(buf.String())
+	}
+}
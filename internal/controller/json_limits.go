@@ -0,0 +1,179 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+const (
+	// DefaultMaxJSONDepth bounds the nesting depth of decofile content. Real
+	// decofiles nest a few dozen levels at most.
+	DefaultMaxJSONDepth = 128
+	// DefaultMaxJSONKeys bounds the total number of object keys in decofile
+	// content, across all nesting levels.
+	DefaultMaxJSONKeys = 1_000_000
+)
+
+// ErrContentLimitExceeded is returned when decofile content is nested deeper
+// or has more keys than the configured limits allow.
+var ErrContentLimitExceeded = errors.New("decofile content exceeds limits")
+
+// ErrInvalidJSON is returned when rendered decofile content is not a single
+// valid JSON document, e.g. because of a buggy source or transform.
+var ErrInvalidJSON = errors.New("decofile content is not valid JSON")
+
+type jsonFrame struct {
+	object  bool
+	wantKey bool // next token in this object is a key or '}'
+}
+
+// checkJSONLimits walks content token by token, without building it in
+// memory, and fails once it nests deeper than maxDepth or holds more than
+// maxKeys object keys.
+func checkJSONLimits(content string, maxDepth, maxKeys int) error {
+	dec := json.NewDecoder(strings.NewReader(content))
+	var stack []jsonFrame
+	keys := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF && len(stack) == 0 {
+			return nil
+		}
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return fmt.Errorf("invalid JSON: %w", err)
+		}
+
+		top := len(stack) - 1
+		if top >= 0 && stack[top].wantKey {
+			if tok == json.Delim('}') {
+				stack = stack[:top]
+				valueDone(stack)
+				continue
+			}
+			keys++
+			if keys > maxKeys {
+				return fmt.Errorf("%w: more than %d keys", ErrContentLimitExceeded, maxKeys)
+			}
+			stack[top].wantKey = false
+			continue
+		}
+
+		switch tok {
+		case json.Delim('{'):
+			stack = append(stack, jsonFrame{object: true, wantKey: true})
+		case json.Delim('['):
+			stack = append(stack, jsonFrame{})
+		case json.Delim(']'):
+			stack = stack[:top]
+			valueDone(stack)
+		default:
+			valueDone(stack)
+		}
+		if len(stack) > maxDepth {
+			return fmt.Errorf("%w: nesting deeper than %d levels", ErrContentLimitExceeded, maxDepth)
+		}
+	}
+}
+
+// valueDone marks the enclosing object, if any, as expecting its next key.
+func valueDone(stack []jsonFrame) {
+	if n := len(stack); n > 0 && stack[n-1].object {
+		stack[n-1].wantKey = true
+	}
+}
+
+// checkContentLimits checks that content is valid JSON and applies the
+// reconciler's JSON limits (defaults when unset).
+func (r *DecofileReconciler) checkContentLimits(content string) error {
+	if !json.Valid([]byte(content)) {
+		// Only on failure: decode again for the error's position
+		var v json.RawMessage
+		if err := json.Unmarshal([]byte(content), &v); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidJSON, err)
+		}
+		return ErrInvalidJSON
+	}
+	maxDepth, maxKeys := r.MaxJSONDepth, r.MaxJSONKeys
+	if maxDepth <= 0 {
+		maxDepth = DefaultMaxJSONDepth
+	}
+	if maxKeys <= 0 {
+		maxKeys = DefaultMaxJSONKeys
+	}
+	return checkJSONLimits(content, maxDepth, maxKeys)
+}
+
+// rejectContent records content that exceeded the limits, isn't valid JSON
+// or lacks spec.requiredKeys as Ready=False/ContentLimitExceeded, InvalidJSON
+// or MissingRequiredKeys (ConfigMapTooLarge for a rendered ConfigMap over its
+// limits), before anything is stored. The reconcile is not requeued: the same
+// content would fail again until the spec (or commit) changes. Other errors are returned as is.
+func (r *DecofileReconciler) rejectContent(ctx context.Context, req ctrl.Request, cause error) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+	var reason string
+	switch {
+	case errors.Is(cause, ErrContentLimitExceeded):
+		reason = "ContentLimitExceeded"
+	case errors.Is(cause, ErrInvalidJSON):
+		reason = "InvalidJSON"
+	case errors.Is(cause, ErrConfigMapLimitExceeded):
+		reason = "ConfigMapTooLarge"
+	case errors.Is(cause, ErrMissingRequiredKeys):
+		reason = "MissingRequiredKeys"
+	default:
+		log.Error(cause, "Failed to check decofile content limits")
+		return ctrl.Result{}, cause
+	}
+	log.Error(cause, "Rejecting decofile content", "reason", reason)
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &decositesv1alpha1.Decofile{}
+		if err := r.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		updateCondition(fresh, metav1.Condition{
+			Type:               "Ready",
+			Status:             metav1.ConditionFalse,
+			Reason:             reason,
+			Message:            cause.Error(),
+			LastTransitionTime: metav1.Now(),
+		})
+		recordHistory(fresh, decositesv1alpha1.HistoryFailed, cause.Error())
+		return r.Status().Update(ctx, fresh)
+	})
+	if err != nil {
+		log.Error(err, "Failed to update Decofile status")
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{}, nil
+}
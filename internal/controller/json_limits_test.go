@@ -0,0 +1,77 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckJSONLimits(t *testing.T) {
+	cases := []struct {
+		name      string
+		content   string
+		maxDepth  int
+		maxKeys   int
+		wantLimit bool
+		wantErr   bool
+	}{
+		{"within limits", `{"a":{"b":[1,{"c":true}]},"d":"x"}`, 4, 4, false, false},
+		{"empty objects and arrays", `{"a":{},"b":[],"c":[{}]}`, 3, 3, false, false},
+		{"at depth limit", `{"a":{"b":{}}}`, 3, 10, false, false},
+		{"too deep", `{"a":{"b":{"c":{}}}}`, 3, 10, true, true},
+		{"deep arrays", `[[[[1]]]]`, 3, 10, true, true},
+		{"at key limit", `{"a":1,"b":{"c":2}}`, 10, 3, false, false},
+		{"too many keys", `{"a":1,"b":{"c":2,"d":3}}`, 10, 3, true, true},
+		{"keys in arrays count", `[{"a":1},{"b":2},{"c":3}]`, 10, 2, true, true},
+		{"string values are not keys", `{"a":"b","c":["d","e"]}`, 10, 2, false, false},
+		{"invalid JSON", `{"a":`, 10, 10, false, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkJSONLimits(tc.content, tc.maxDepth, tc.maxKeys)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("checkJSONLimits() = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got := errors.Is(err, ErrContentLimitExceeded); got != tc.wantLimit {
+				t.Errorf("errors.Is(ErrContentLimitExceeded) = %v, want %v (err=%v)", got, tc.wantLimit, err)
+			}
+		})
+	}
+}
+
+func TestCheckContentLimits_Defaults(t *testing.T) {
+	r := &DecofileReconciler{}
+	deep := strings.Repeat("[", DefaultMaxJSONDepth+1) + strings.Repeat("]", DefaultMaxJSONDepth+1)
+	if err := r.checkContentLimits(deep); !errors.Is(err, ErrContentLimitExceeded) {
+		t.Errorf("checkContentLimits(depth %d) = %v, want limit error", DefaultMaxJSONDepth+1, err)
+	}
+	if err := r.checkContentLimits(`{"a":{"b":1}}`); err != nil {
+		t.Errorf("checkContentLimits() = %v", err)
+	}
+}
+
+func TestCheckContentLimits_InvalidJSON(t *testing.T) {
+	r := &DecofileReconciler{}
+	// The token walk alone would accept concatenated documents
+	for _, content := range []string{`{"a":1}{"b":2}`, `{"a":`, `{"a":1,}`, ``} {
+		if err := r.checkContentLimits(content); !errors.Is(err, ErrInvalidJSON) {
+			t.Errorf("checkContentLimits(%q) = %v, want ErrInvalidJSON", content, err)
+		}
+	}
+}
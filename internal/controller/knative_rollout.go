@@ -0,0 +1,81 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// rolloutAnnotation is stamped on the pod template of the Knative Services of
+// a rolloutStrategy=knative-revision Decofile. Any change to the template makes
+// Knative create a new Revision, whose pods start with the new content.
+const rolloutAnnotation = "deco.sites/decofile-rollout"
+
+// rolloutKnativeServices sets rolloutAnnotation to stamp on the pod template of
+// every Knative Service in namespace whose deploymentId (on the Service, or
+// on its template as the Service webhook reads it) is deploymentId. Services
+// already carrying stamp are left alone, so a retried reconcile doesn't roll
+// a second Revision.
+func (r *DecofileReconciler) rolloutKnativeServices(ctx context.Context, namespace, deploymentId, stamp string) error {
+	log := logf.FromContext(ctx)
+
+	svcs := &servingv1.ServiceList{}
+	if err := r.List(ctx, svcs, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("list services for deploymentId=%s: %w", deploymentId, err)
+	}
+
+	found := 0
+	for i := range svcs.Items {
+		svc := &svcs.Items[i]
+		if knativeServiceDeploymentId(svc, r.Notify.deploymentIdLabel()) != deploymentId {
+			continue
+		}
+		found++
+		if svc.Spec.Template.Annotations[rolloutAnnotation] == stamp {
+			continue
+		}
+
+		patch := client.MergeFrom(svc.DeepCopy())
+		if svc.Spec.Template.Annotations == nil {
+			svc.Spec.Template.Annotations = map[string]string{}
+		}
+		svc.Spec.Template.Annotations[rolloutAnnotation] = stamp
+		if err := r.Patch(ctx, svc, patch); err != nil {
+			return fmt.Errorf("patch service %s: %w", svc.Name, err)
+		}
+		log.Info("Rolling out a new Knative Revision", "service", svc.Name, "stamp", stamp)
+	}
+	if found == 0 {
+		return fmt.Errorf("no Knative Service with deploymentId=%s in namespace %s", deploymentId, namespace)
+	}
+	return nil
+}
+
+// knativeServiceDeploymentId returns the deploymentId a Knative Service
+// carries under label, on the Service or, as the Service webhook reads it, on
+// its template.
+func knativeServiceDeploymentId(svc *servingv1.Service, label string) string {
+	if id := svc.Labels[label]; id != "" {
+		return id
+	}
+	return svc.Spec.Template.Labels[label]
+}
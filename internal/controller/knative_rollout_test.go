@@ -0,0 +1,65 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestRolloutKnativeServices(t *testing.T) {
+	svc := func(name string, labels, templateLabels map[string]string) *servingv1.Service {
+		s := &servingv1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace, Labels: labels}}
+		s.Spec.Template.Labels = templateLabels
+		return s
+	}
+	c := fake.NewClientBuilder().WithScheme(newOwnerTestScheme(t)).WithObjects(
+		svc("site", map[string]string{DefaultDeploymentIdLabel: "dep-1"}, nil),
+		svc("site-canary", nil, map[string]string{DefaultDeploymentIdLabel: "dep-1"}),
+		svc("other", map[string]string{DefaultDeploymentIdLabel: "dep-2"}, nil),
+	).Build()
+	r := &DecofileReconciler{Client: c, Scheme: c.Scheme()}
+	ctx := context.Background()
+
+	if err := r.rolloutKnativeServices(ctx, testNamespace, "dep-1", "1700000000"); err != nil {
+		t.Fatalf("rolloutKnativeServices: %v", err)
+	}
+	stamp := func(name string) string {
+		got := &servingv1.Service{}
+		if err := c.Get(ctx, types.NamespacedName{Namespace: testNamespace, Name: name}, got); err != nil {
+			t.Fatalf("get %s: %v", name, err)
+		}
+		return got.Spec.Template.Annotations[rolloutAnnotation]
+	}
+	for _, name := range []string{"site", "site-canary"} {
+		if got := stamp(name); got != "1700000000" {
+			t.Errorf("%s rollout stamp = %q, want 1700000000", name, got)
+		}
+	}
+	if got := stamp("other"); got != "" {
+		t.Errorf("other deploymentId was stamped: %q", got)
+	}
+
+	if err := r.rolloutKnativeServices(ctx, testNamespace, "dep-3", "1700000000"); err == nil {
+		t.Error("rolloutKnativeServices with no matching Service succeeded, want error")
+	}
+}
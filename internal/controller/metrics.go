@@ -19,6 +19,8 @@ package controller
 import (
 	"github.com/prometheus/client_golang/prometheus"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/deco-sites/decofile-operator/internal/github"
 )
 
 const (
@@ -92,6 +94,82 @@ var (
 		Name:      "sentinel_failovers_total",
 		Help:      "Total number of Sentinel master failovers detected via +switch-master pub/sub.",
 	})
+
+	// orphanConfigMapsReclaimed counts orphaned decofile ConfigMaps found by the GC sweep.
+	orphanConfigMapsReclaimed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "decofile",
+		Name:      "orphan_configmaps_reclaimed_total",
+		Help:      "Total number of orphaned decofile ConfigMaps reclaimed by the GC sweep.",
+	}, []string{"action"}) // action: deleted | dry_run
+
+	// githubDownloadsSkipped counts GitHub archive downloads avoided because
+	// the source tree was unchanged.
+	githubDownloadsSkipped = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "decofile",
+		Name:      "github_downloads_skipped_total",
+		Help:      "Total number of GitHub archive downloads skipped because the source tree was unchanged.",
+	})
+
+	// decofileReconcileTimeouts counts reconciles cut off by the reconcile
+	// timeout (see DecofileReconciler.ReconcileTimeout).
+	decofileReconcileTimeouts = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "decofile",
+		Name:      "reconcile_timeouts_total",
+		Help:      "Total number of Decofile reconciles that hit the reconcile timeout and were requeued.",
+	})
+
+	// rolloutEventsSent counts rollout events POSTed to the rollout events
+	// webhook (see RolloutEventSink).
+	rolloutEventsSent = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "decofile",
+		Name:      "rollout_events_sent_total",
+		Help:      "Total number of Decofile rollout events sent to the rollout events webhook, by result.",
+	}, []string{"result"}) // result: delivered | failed
+
+	// cachePurges counts purges sent to the cache purge URL (see
+	// CachePurger).
+	cachePurges = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "decofile",
+		Name:      "cache_purges_total",
+		Help:      "Total number of external cache purges sent after Decofile content changes, by result.",
+	}, []string{"result"}) // result: purged | failed
+
+	// destinationDeliveries counts deliveries to spec.destinations entries.
+	destinationDeliveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "decofile",
+		Name:      "destination_deliveries_total",
+		Help:      "Total number of rendered-content deliveries to Decofile destinations, by destination type and result.",
+	}, []string{"type", "result"}) // result: delivered | failed
+
+	// serviceInjectionRepairs counts Services re-injected by the
+	// ServiceInjectionReconciler after their injection drifted.
+	serviceInjectionRepairs = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "decofile",
+		Name:      "service_injection_repairs_total",
+		Help:      "Total number of Knative Services whose drifted Decofile injection was repaired.",
+	})
+
+	// githubRateLimitSaturation and githubRateLimitWaiting report how close
+	// GitHub traffic is to --github-rps.
+	githubRateLimitSaturation = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "github",
+		Name:      "rate_limiter_saturation",
+		Help:      "Share of the --github-rps burst used up on the busiest GitHub host (1 = requests are waiting).",
+	}, github.RateLimitSaturation)
+	githubRateLimitWaiting = prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: "github",
+		Name:      "rate_limiter_waiting_requests",
+		Help:      "Number of GitHub requests currently waiting for the --github-rps rate limiter.",
+	}, func() float64 { return float64(github.RateLimitWaiting()) })
 )
 
 // RecordSentinelFailover increments the sentinel_failovers_total counter.
@@ -116,5 +194,15 @@ func init() {
 		valkeyACLSelfHealed,
 		valkeyTenantsProvisioned,
 		valkeySentinelFailovers,
+		orphanConfigMapsReclaimed,
+		githubDownloadsSkipped,
+		decofileReconcileTimeouts,
+		rolloutEventsSent,
+		cachePurges,
+		destinationDeliveries,
+		serviceInjectionRepairs,
+		githubRateLimitSaturation,
+		githubRateLimitWaiting,
+		decofileReconciles,
 	)
 }
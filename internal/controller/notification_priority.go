@@ -0,0 +1,81 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/priorityqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+// notificationPriorityHandler raises the queue priority of Decofiles with a
+// positive spec.notificationPriority. It runs next to the For() handler, which
+// enqueues every Decofile at priority 0 (handler.LowPriority for resyncs and
+// the initial list); the priority queue keeps the higher of the two for a
+// request, and the controller requeues a request at the priority it was
+// dequeued with, so retries of a critical Decofile stay ahead as well.
+type notificationPriorityHandler struct{}
+
+var _ handler.EventHandler = notificationPriorityHandler{}
+
+// Create implements handler.EventHandler.
+func (notificationPriorityHandler) Create(_ context.Context, e event.CreateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	base := 0
+	if e.IsInInitialList {
+		base = handler.LowPriority
+	}
+	enqueueWithNotificationPriority(q, e.Object, base)
+}
+
+// Update implements handler.EventHandler.
+func (notificationPriorityHandler) Update(_ context.Context, e event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	base := 0
+	if e.ObjectOld.GetResourceVersion() == e.ObjectNew.GetResourceVersion() {
+		base = handler.LowPriority
+	}
+	enqueueWithNotificationPriority(q, e.ObjectNew, base)
+}
+
+// Delete implements handler.EventHandler. Deletes keep the default priority.
+func (notificationPriorityHandler) Delete(context.Context, event.DeleteEvent, workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+}
+
+// Generic implements handler.EventHandler.
+func (notificationPriorityHandler) Generic(context.Context, event.GenericEvent, workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+}
+
+// enqueueWithNotificationPriority adds obj at base plus its
+// spec.notificationPriority, when q is a priority queue and that is positive.
+func enqueueWithNotificationPriority(q workqueue.TypedRateLimitingInterface[reconcile.Request], obj client.Object, base int) {
+	pq, ok := q.(priorityqueue.PriorityQueue[reconcile.Request])
+	if !ok {
+		return
+	}
+	decofile, ok := obj.(*decositesv1alpha1.Decofile)
+	if !ok || decofile.Spec.NotificationPriority <= 0 {
+		return
+	}
+	pq.AddWithOpts(priorityqueue.AddOpts{Priority: base + int(decofile.Spec.NotificationPriority)},
+		reconcile.Request{NamespacedName: client.ObjectKeyFromObject(decofile)})
+}
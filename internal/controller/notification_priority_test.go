@@ -0,0 +1,73 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/priorityqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestNotificationPriorityHandler_OrdersQueue(t *testing.T) {
+	q := priorityqueue.New[reconcile.Request]("test")
+	defer q.ShutDown()
+
+	routine := makeDecofile("routine", "")
+	critical := makeDecofile("critical", "")
+	critical.Spec.NotificationPriority = 10
+
+	// Both go through the For() handler at the default priority first
+	var h handler.EventHandler = notificationPriorityHandler{}
+	for _, df := range []client.Object{routine, critical} {
+		q.Add(reconcile.Request{NamespacedName: client.ObjectKeyFromObject(df)})
+		h.Create(context.Background(), event.CreateEvent{Object: df}, q)
+	}
+	if q.Len() != 2 {
+		t.Fatalf("queue length = %d, want the 2 requests deduplicated", q.Len())
+	}
+
+	item, priority, _ := q.GetWithPriority()
+	if item.Name != "critical" || priority != 10 {
+		t.Fatalf("first item = %s at priority %d, want critical at 10", item.Name, priority)
+	}
+	q.Done(item)
+	item, priority, _ = q.GetWithPriority()
+	if item.Name != "routine" || priority != 0 {
+		t.Fatalf("second item = %s at priority %d, want routine at 0", item.Name, priority)
+	}
+	q.Done(item)
+}
+
+func TestNotificationPriorityHandler_ResyncStaysLow(t *testing.T) {
+	q := priorityqueue.New[reconcile.Request]("test")
+	defer q.ShutDown()
+
+	df := makeDecofile("critical", "")
+	df.Spec.NotificationPriority = 10
+	df.ResourceVersion = "1"
+	notificationPriorityHandler{}.Update(context.Background(), event.UpdateEvent{ObjectOld: df, ObjectNew: df}, q)
+
+	_, priority, _ := q.GetWithPriority()
+	if want := handler.LowPriority + 10; priority != want {
+		t.Fatalf("resync priority = %d, want %d", priority, want)
+	}
+}
@@ -17,30 +17,126 @@ limitations under the License.
 package controller
 
 import (
-	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+// DefaultDeploymentIdLabel is the default --deployment-id-label.
+const DefaultDeploymentIdLabel = "app.deco/deploymentId"
+
+// deploymentIdLabelOrDefault returns label, or DefaultDeploymentIdLabel when
+// it is empty.
+func deploymentIdLabelOrDefault(label string) string {
+	if label == "" {
+		return DefaultDeploymentIdLabel
+	}
+	return label
+}
+
+// NotifyOptions are the process-wide notification settings, set from flags in
+// main and handed to every Notifier through NewNotifier.
+type NotifyOptions struct {
+	// DeploymentIdLabel is the pod (and Service) label holding the
+	// deploymentId pods are selected by (--deployment-id-label). It must match
+	// the key the Service webhook stamps on pod templates. Empty means
+	// DefaultDeploymentIdLabel.
+	DeploymentIdLabel string
+	// ReadyPodsOnly skips pods that are running but don't pass their
+	// readiness checks yet, instead of spending reload retries on a server
+	// that isn't listening. The pod notify controller reloads them once they
+	// become Ready (--notify-ready-pods-only).
+	ReadyPodsOnly bool
+	// PodConcurrency is the number of pods a notification reloads in
+	// parallel, and the pod notify controller's concurrent reconciles
+	// (--notify-pod-concurrency). 0 means DefaultNotifyPodConcurrency.
+	PodConcurrency int
+	// MaxConnsPerHost caps the connections the shared notification HTTP
+	// client opens to a single host:port, whatever PodConcurrency is, so
+	// reloads that all reach one gateway don't overwhelm it. Requests over
+	// the cap wait for a connection within their timeout
+	// (--notify-max-conns-per-host). 0 = no cap.
+	MaxConnsPerHost int
+	// InFlight caps the reload requests in flight across every Notifier of
+	// the process, so a change to many Decofiles at once can't add their
+	// per-Decofile worker pools up to thousands of connections. Each attempt
+	// holds a slot only while its request runs, not during retry backoff.
+	// Created once, sized --notify-max-in-flight, and shared by every
+	// reconciler. Nil = no cap.
+	InFlight chan struct{}
+	// Scheme is the URL scheme of the requests sent to pods (reloads, the
+	// reload probe and the canary health check): http, or https for
+	// runtimes that only accept them over TLS (--notify-scheme). Empty
+	// means http.
+	Scheme string
+	// TLSConfig is the TLS config of the shared notification HTTP client
+	// for Scheme https, see NewNotifyTLSConfig. Nil verifies pods against
+	// the system roots.
+	TLSConfig *tls.Config
+}
+
+// deploymentIdLabel returns o.DeploymentIdLabel, or its default.
+func (o NotifyOptions) deploymentIdLabel() string {
+	return deploymentIdLabelOrDefault(o.DeploymentIdLabel)
+}
+
+// podConcurrency returns o.PodConcurrency, or its default.
+func (o NotifyOptions) podConcurrency() int {
+	if o.PodConcurrency <= 0 {
+		return DefaultNotifyPodConcurrency
+	}
+	return o.PodConcurrency
+}
+
+// DefaultNotifyPodConcurrency is the default --notify-pod-concurrency.
+const DefaultNotifyPodConcurrency = 10
+
 const (
-	reloadEndpoint        = "/.decofile/reload"
-	reloadTimeout         = 30 * time.Second // 30s per pod (simple POST, no long-polling)
-	maxRetries            = 3                // 3 attempts per pod
-	initialBackoff        = 2 * time.Second
-	deploymentIdLabel     = "app.deco/deploymentId"
-	maxNotificationTime   = 2 * time.Minute // 2 min for entire batch
-	notificationBatchSize = 10              // Parallel notification batch size (reduced to save memory)
-	appContainerName      = "app"
-	reloadTokenEnvVar     = "DECO_RELEASE_RELOAD_TOKEN"
+	reloadEndpoint       = "/.decofile/reload"
+	reloadTimeout        = 30 * time.Second // 30s per pod (simple POST, no long-polling)
+	maxRetries           = 3                // 3 attempts per pod
+	initialBackoff       = 2 * time.Second
+	knativeRevisionLabel = "serving.knative.dev/revision"
+	maxNotificationTime  = 2 * time.Minute // 2 min for entire batch
+	appContainerName     = "app"
+	defaultReloadPort    = 8000
+	// knativeQueueProxyContainer and knativeUserPortName are how Knative
+	// names its proxy container and the user container's declared port.
+	knativeQueueProxyContainer = "queue-proxy"
+	knativeUserPortName        = "user-port"
+	// knativeHTTP1PortName and knativeH2CPortName are the port names a
+	// Knative Service declares its user port with, kept on pods that are
+	// not rewritten to user-port.
+	knativeHTTP1PortName = "http1"
+	knativeH2CPortName   = "h2c"
+	// sidecarContainerName is the decofile-server sidecar injected by the
+	// Service webhook in sidecar mode.
+	sidecarContainerName = "decofile-server"
+	reloadTokenEnvVar    = "DECO_RELEASE_RELOAD_TOKEN"
+	// extraDecofileLabelPrefix is set by the Service webhook on pods that
+	// mount a Decofile as an extra (deco.sites/decofile-inject-extra), as
+	// <prefix><deploymentId>=true.
+	extraDecofileLabelPrefix = "decofile.deco.sites/"
+	// reloadAuthAnnotation on a pod (set on the Service's template) selects
+	// how reloads authenticate: "token" (default) sends the pod's reload
+	// token as an Authorization header, "none" sends no header at all, for
+	// endpoints that reject requests carrying one.
+	reloadAuthAnnotation = "deco.sites/reload-auth"
+	reloadAuthNone       = "none"
 
 	// HTTP Transport configuration to prevent connection leaks
 	maxIdleConns        = 100
@@ -61,13 +157,39 @@ const (
 // error so callers can branch on it via errors.Is.
 var ErrMissingReloadToken = errors.New("target pod is missing DECO_RELEASE_RELOAD_TOKEN")
 
+// ErrReloadAuthFailed indicates that a pod rejected the reload request with
+// 401/403 even though a token was sent -- typically the pod started before a
+// token change and holds a different DECO_RELEASE_RELOAD_TOKEN. Retrying with
+// the same token cannot succeed, so the pod is not retried.
+var ErrReloadAuthFailed = errors.New("target pod rejected the reload token")
+
+// ErrCanaryFailed indicates that the canary pod of a staged notification
+// rejected the reload or failed its health check, so the remaining pods were
+// left untouched.
+var ErrCanaryFailed = errors.New("canary pod failed")
+
+// errPodGone reports that a pod was deleted, stopped running or lost its IP
+// between reload attempts, e.g. when it is replaced during a rollout. The
+// pod is counted as skipped rather than failed.
+var errPodGone = errors.New("pod is gone or no longer running")
+
+// errPodNotReady reports that a running pod doesn't pass its readiness
+// checks (NotifyOptions.ReadyPodsOnly). It is counted as skipped, not failed.
+var errPodNotReady = errors.New("pod is not ready")
+
+// errNoPods reports that no pod matched a notification's selector. Only a
+// notification scoped to a Revision treats it as a failure.
+var errNoPods = errors.New("no pods found")
+
 // NewHTTPClient creates a shared HTTP client with proper connection pooling configuration.
 // This client should be reused across all reconciliations to prevent memory leaks.
-func NewHTTPClient() *http.Client {
+func NewHTTPClient(opts NotifyOptions) *http.Client {
 	transport := &http.Transport{
 		MaxIdleConns:        maxIdleConns,
 		MaxIdleConnsPerHost: maxIdleConnsPerHost,
+		MaxConnsPerHost:     opts.MaxConnsPerHost,
 		IdleConnTimeout:     idleConnTimeout,
+		TLSClientConfig:     opts.TLSConfig,
 	}
 	return &http.Client{
 		Timeout:   reloadTimeout,
@@ -79,15 +201,89 @@ func NewHTTPClient() *http.Client {
 type Notifier struct {
 	Client     client.Client
 	HTTPClient *http.Client
+	// ReloadMethod is the method of the reload request (spec.reloadMethod);
+	// empty means POST. GET requests carry no body.
+	ReloadMethod string
+	// Scheme is the URL scheme of requests to pods, http or https; empty
+	// means http.
+	Scheme string
+	// ReloadPath is the path of the reload request and probe
+	// (spec.reloadPath); empty means /.decofile/reload.
+	ReloadPath string
+	// Transport is spec.notifyTransport: how reloads reach the pods. Empty
+	// means HTTP.
+	Transport string
+	// EventStream receives the reloads of the sse transport.
+	EventStream *EventStream
+	// ExtraDeploymentId is set when notifying pods that mount the Decofile as
+	// an extra rather than as their DECO_RELEASE; the reload then carries
+	// ?deploymentId=<id> so the runtime knows which decofile changed.
+	ExtraDeploymentId string
+	// Canary notifies a single pod first and only notifies the others once
+	// it accepted the reload and, when CanaryHealthPath is set, answered 2xx
+	// on that path after CanaryDelay (spec.notificationStrategy=canary).
+	Canary           bool
+	CanaryHealthPath string
+	CanaryDelay      time.Duration
+	// Stats, when set, accumulates the pod counts of every notification.
+	Stats *NotifyStats
+	// ReadyOnly skips running pods that are not Ready
+	// (NotifyOptions.ReadyPodsOnly).
+	ReadyOnly bool
+	// Concurrency is the number of pods reloaded in parallel
+	// (NotifyOptions.PodConcurrency); less than 1 means one at a time.
+	Concurrency int
+	// InFlight is the semaphore a reload request holds a slot of while it
+	// runs, shared with the process's other Notifiers (NotifyOptions.InFlight).
+	// Nil means no cap.
+	InFlight chan struct{}
+	// DeploymentIdLabel is the label pods are selected by; empty means
+	// DefaultDeploymentIdLabel.
+	DeploymentIdLabel string
+	// PodSelector further restricts the pods notified (spec.podSelector),
+	// ANDed with the deploymentId or extra Decofile label. Nil = no
+	// restriction.
+	PodSelector labels.Selector
+}
+
+// NotifyStats counts the pods reached by a Notifier's notifications.
+type NotifyStats struct {
+	Notified int `json:"notified"`
+	Failed   int `json:"failed"`
+	Skipped  int `json:"skipped"`
 }
 
 // NewNotifier creates a new Notifier instance with a shared HTTP client
 // The httpClient should be reused across reconciliations to avoid connection leaks
-func NewNotifier(k8sClient client.Client, httpClient *http.Client) *Notifier {
+func NewNotifier(k8sClient client.Client, httpClient *http.Client, opts NotifyOptions) *Notifier {
 	return &Notifier{
-		Client:     k8sClient,
-		HTTPClient: httpClient,
+		Client:            k8sClient,
+		HTTPClient:        httpClient,
+		Scheme:            opts.Scheme,
+		ReadyOnly:         opts.ReadyPodsOnly,
+		Concurrency:       opts.podConcurrency(),
+		InFlight:          opts.InFlight,
+		DeploymentIdLabel: opts.DeploymentIdLabel,
+	}
+}
+
+// send delivers one reload attempt through transport, first waiting for a
+// slot of n.InFlight when it is set.
+func (n *Notifier) send(ctx context.Context, transport ReloadTransport, pod *corev1.Pod, payloadBytes []byte, token string) error {
+	if n.InFlight != nil {
+		select {
+		case n.InFlight <- struct{}{}:
+		default:
+			logf.FromContext(ctx).V(1).Info("Waiting for a notification slot", "pod", pod.Name, "maxInFlight", cap(n.InFlight))
+			select {
+			case n.InFlight <- struct{}{}:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		defer func() { <-n.InFlight }()
 	}
+	return transport.Send(ctx, pod, payloadBytes, token)
 }
 
 // extractReloadToken extracts the reload token from the "app" container's environment variables
@@ -108,27 +304,83 @@ func extractReloadToken(pod *corev1.Pod) string {
 // that the ConfigMap has changed and they should reload.
 // Uses parallel batch processing with 2-minute timeout.
 func (n *Notifier) NotifyPodsForDecofile(ctx context.Context, namespace, deploymentId, timestamp, decofileContent string) error {
+	err := n.notifyPods(ctx, namespace, client.MatchingLabels{deploymentIdLabelOrDefault(n.DeploymentIdLabel): deploymentId}, timestamp, decofileContent)
+	if errors.Is(err, errNoPods) {
+		return nil
+	}
+	return err
+}
+
+// NotifyPodsForRevision is like NotifyPodsForDecofile but only notifies the
+// pods of a single Knative Revision (e.g. the canary behind a traffic tag).
+// Finding none of its pods is an error: the Revision was resolved for this
+// deploymentId, so the content reached nobody it was meant for.
+func (n *Notifier) NotifyPodsForRevision(ctx context.Context, namespace, deploymentId, revision, timestamp, decofileContent string) error {
+	err := n.notifyPods(ctx, namespace, client.MatchingLabels{
+		deploymentIdLabelOrDefault(n.DeploymentIdLabel): deploymentId,
+		knativeRevisionLabel:                            revision,
+	}, timestamp, decofileContent)
+	if errors.Is(err, errNoPods) {
+		return fmt.Errorf("revision %s of deploymentId=%s: %w", revision, deploymentId, err)
+	}
+	return err
+}
+
+// NotifyPodsForExtraDecofile notifies the pods that mount the Decofile with
+// deploymentId as an extra. They belong to other Services, so they are never
+// scoped to a Revision of the Decofile's own.
+func (n *Notifier) NotifyPodsForExtraDecofile(ctx context.Context, namespace, deploymentId, timestamp, decofileContent string) error {
+	label, ok := extraDecofileLabel(deploymentId)
+	if !ok {
+		// The webhook can't label pods with it, so no pod mounts it as an extra
+		return nil
+	}
+	selector := client.MatchingLabels{label: "true"}
+	extra := *n
+	extra.ExtraDeploymentId = deploymentId
+	err := extra.notifyPods(ctx, namespace, selector, timestamp, decofileContent)
+	if errors.Is(err, errNoPods) {
+		return nil
+	}
+	return err
+}
+
+// extraDecofileLabel returns the pod label marking an extra mount of the
+// Decofile with deploymentId, and whether that is a valid label key.
+func extraDecofileLabel(deploymentId string) (string, bool) {
+	label := extraDecofileLabelPrefix + deploymentId
+	return label, len(validation.IsQualifiedName(label)) == 0
+}
+
+// notifyPods notifies every pod in namespace matching selector and
+// n.PodSelector, returning errNoPods when there is none.
+func (n *Notifier) notifyPods(ctx context.Context, namespace string, matching client.MatchingLabels, timestamp, decofileContent string) error {
 	log := logf.FromContext(ctx)
 
-	log.Info("Notifying pods for deploymentId", "deploymentId", deploymentId, "namespace", namespace)
+	selector := labels.SelectorFromSet(labels.Set(matching))
+	if n.PodSelector != nil {
+		reqs, _ := n.PodSelector.Requirements()
+		selector = selector.Add(reqs...)
+	}
+	log.Info("Notifying pods", "selector", selector.String(), "namespace", namespace)
 
 	// Create timeout context for entire operation
 	notifyCtx, cancel := context.WithTimeout(ctx, maxNotificationTime)
 	defer cancel()
 
-	// List pods with the deploymentId label
+	// List pods with the deploymentId (and optional revision) label
 	podList := &corev1.PodList{}
 	err := n.Client.List(notifyCtx, podList,
 		client.InNamespace(namespace),
-		client.MatchingLabels{deploymentIdLabel: deploymentId})
+		client.MatchingLabelsSelector{Selector: selector})
 
 	if err != nil {
-		return fmt.Errorf("failed to list pods for deploymentId %s: %w", deploymentId, err)
+		return fmt.Errorf("failed to list pods for %v: %w", selector, err)
 	}
 
 	if len(podList.Items) == 0 {
-		log.V(1).Info("No pods found for deploymentId", "deploymentId", deploymentId)
-		return nil
+		log.V(1).Info("No pods found", "selector", selector.String())
+		return fmt.Errorf("%w for %v", errNoPods, selector)
 	}
 
 	// Collect pod names to notify
@@ -137,62 +389,62 @@ func (n *Notifier) NotifyPodsForDecofile(ctx context.Context, namespace, deploym
 		podNames = append(podNames, pod.Name)
 	}
 
-	log.Info("Starting parallel pod notifications", "totalPods", len(podNames), "batchSize", notificationBatchSize)
-
-	// Prepare JSON payload once (reused across all pods to avoid memory duplication)
-	payload := map[string]interface{}{
-		"timestamp": timestamp,
-		"source":    "operator",
-		"decofile":  json.RawMessage(decofileContent),
-	}
-	payloadBytes, err := json.Marshal(payload)
+	// Prepare the payload once (reused across all pods to avoid memory duplication)
+	payloadBytes, err := n.reloadTransport().Encode(timestamp, decofileContent)
 	if err != nil {
-		return fmt.Errorf("failed to marshal payload: %w", err)
+		return err
 	}
 	log.V(1).Info("Marshaled notification payload", "size", len(payloadBytes))
 
-	// Notify pods in parallel batches
+	if n.Canary {
+		canary, err := n.notifyCanary(notifyCtx, namespace, podNames, timestamp, payloadBytes)
+		if err != nil {
+			return err
+		}
+		if canary != "" && n.Stats != nil {
+			n.Stats.Notified++
+		}
+		podNames = slices.DeleteFunc(podNames, func(name string) bool { return name == canary })
+		if len(podNames) == 0 {
+			return nil
+		}
+	}
+
+	workers := min(max(n.Concurrency, 1), len(podNames))
+	log.Info("Starting parallel pod notifications", "totalPods", len(podNames), "workers", workers)
+
+	// Notify pods from a bounded worker pool fed by a queue, so a fleet of
+	// thousands of pods costs a fixed number of goroutines and buffers
 	type notifyResult struct {
 		podName string
 		err     error
 	}
 
-	resultChan := make(chan notifyResult, len(podNames))
-	semaphore := make(chan struct{}, notificationBatchSize) // Limit concurrent notifications
-
-	// Launch goroutines for each pod
-	for _, podName := range podNames {
-		go func(name string) {
-			// Acquire semaphore
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
+	queue := make(chan string)
+	resultChan := make(chan notifyResult, workers)
 
-			// Get fresh pod data (avoids stale data)
-			pod := &corev1.Pod{}
-			err := n.Client.Get(notifyCtx, client.ObjectKey{Name: name, Namespace: namespace}, pod)
-			if err != nil {
-				resultChan <- notifyResult{name, fmt.Errorf("failed to get pod: %w", err)}
+	go func() {
+		defer close(queue)
+		for _, name := range podNames {
+			select {
+			case queue <- name:
+			case <-notifyCtx.Done():
 				return
 			}
-
-			// Skip if not running
-			if pod.Status.Phase != corev1.PodRunning {
-				log.V(1).Info("Skipping non-running pod", "pod", name, "phase", pod.Status.Phase)
-				resultChan <- notifyResult{name, nil} // Not an error, just skip
-				return
-			}
-
-			// Skip if no IP
-			if pod.Status.PodIP == "" {
-				log.V(1).Info("Skipping pod without IP", "pod", name)
-				resultChan <- notifyResult{name, nil}
-				return
+		}
+	}()
+
+	for range workers {
+		go func() {
+			for name := range queue {
+				result := notifyResult{name, n.notifyListedPod(notifyCtx, namespace, name, timestamp, payloadBytes)}
+				select {
+				case resultChan <- result:
+				case <-notifyCtx.Done():
+					return
+				}
 			}
-
-			// Notify pod
-			err = n.notifyPodWithRetry(notifyCtx, pod, timestamp, payloadBytes)
-			resultChan <- notifyResult{name, err}
-		}(podName)
+		}()
 	}
 
 	// Collect results
@@ -201,18 +453,23 @@ func (n *Notifier) NotifyPodsForDecofile(ctx context.Context, namespace, deploym
 	failCount := 0
 	skippedCount := 0
 	missingToken := false
+	authFailed := false
 
 	for i := 0; i < len(podNames); i++ {
 		select {
 		case result := <-resultChan:
 			if result.err != nil {
-				if strings.Contains(result.err.Error(), "failed to get pod") {
+				if errors.Is(result.err, errPodNotReady) {
+					skippedCount++
+				} else if errors.Is(result.err, errPodGone) {
 					skippedCount++
 					log.V(1).Info("Pod no longer exists", "pod", result.podName)
 				} else {
 					failCount++
 					if errors.Is(result.err, ErrMissingReloadToken) {
 						missingToken = true
+					} else if errors.Is(result.err, ErrReloadAuthFailed) {
+						authFailed = true
 					}
 					allErrors = append(allErrors, fmt.Sprintf("%s: %v", result.podName, result.err))
 					log.Error(result.err, "Failed to notify pod", "pod", result.podName)
@@ -227,83 +484,249 @@ func (n *Notifier) NotifyPodsForDecofile(ctx context.Context, namespace, deploym
 	}
 
 	log.Info("Notification summary", "success", successCount, "failed", failCount, "skipped", skippedCount, "total", len(podNames))
+	if n.Stats != nil {
+		n.Stats.Notified += successCount
+		n.Stats.Failed += failCount
+		n.Stats.Skipped += skippedCount
+	}
 
 	if len(allErrors) > 0 {
 		if missingToken {
 			return fmt.Errorf("%w: failed to notify %d pod(s): %s", ErrMissingReloadToken, failCount, strings.Join(allErrors, "; "))
 		}
+		if authFailed {
+			return fmt.Errorf("%w: failed to notify %d pod(s): %s", ErrReloadAuthFailed, failCount, strings.Join(allErrors, "; "))
+		}
 		return fmt.Errorf("failed to notify %d pod(s): %s", failCount, strings.Join(allErrors, "; "))
 	}
 
 	return nil
 }
 
-// notifyPodWithRetry attempts to notify a single pod with exponential backoff retry
-// POSTs JSON payload containing the decofile content
-func (n *Notifier) notifyPodWithRetry(ctx context.Context, pod *corev1.Pod, timestamp string, payloadBytes []byte) error {
+// notifyListedPod re-reads a pod found by notifyPods and notifies it.
+// Pods that aren't running or have no IP are skipped without an error.
+func (n *Notifier) notifyListedPod(ctx context.Context, namespace, name, timestamp string, payloadBytes []byte) error {
 	log := logf.FromContext(ctx)
 
-	// Get port from container
-	port := int32(8000)
-	if len(pod.Spec.Containers) > 0 && len(pod.Spec.Containers[0].Ports) > 0 {
-		port = pod.Spec.Containers[0].Ports[0].ContainerPort
+	// Get fresh pod data (avoids stale data)
+	pod := &corev1.Pod{}
+	if err := n.Client.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, pod); err != nil {
+		if apierrors.IsNotFound(err) {
+			return fmt.Errorf("%w: %s was deleted", errPodGone, name)
+		}
+		return fmt.Errorf("failed to get pod: %w", err)
 	}
 
-	requestURL := fmt.Sprintf("http://%s:%d%s", pod.Status.PodIP, port, reloadEndpoint)
+	// Skip if not running
+	if pod.Status.Phase != corev1.PodRunning {
+		log.V(1).Info("Skipping non-running pod", "pod", name, "phase", pod.Status.Phase)
+		return nil // Not an error, just skip
+	}
 
-	// Extract reload token from pod
-	token := extractReloadToken(pod)
-	if token == "" {
-		log.V(1).Info("No reload token found in pod, skipping authorization", "pod", pod.Name)
+	// Skip if no IP
+	if pod.Status.PodIP == "" {
+		log.V(1).Info("Skipping pod without IP", "pod", name)
+		return nil
 	}
 
-	backoff := initialBackoff
-	lastStatusCode := 0
+	// Skip if still starting: its reload server may not listen yet
+	if n.ReadyOnly && !isPodReady(pod) {
+		log.V(1).Info("Skipping pod that is not ready", "pod", name)
+		return fmt.Errorf("%w: %s", errPodNotReady, name)
+	}
 
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		log.V(1).Info("Attempting to notify pod", "pod", pod.Name, "attempt", attempt, "timestamp", timestamp)
+	return n.notifyPodWithRetry(ctx, pod, timestamp, payloadBytes)
+}
 
-		req, err := http.NewRequestWithContext(ctx, "POST", requestURL, bytes.NewReader(payloadBytes))
+// notifyCanary notifies the first running pod of podNames (in name order)
+// and checks its health, returning its name. It returns "" when no pod is
+// running, and an error wrapping ErrCanaryFailed when the canary fails.
+func (n *Notifier) notifyCanary(ctx context.Context, namespace string, podNames []string, timestamp string, payloadBytes []byte) (string, error) {
+	log := logf.FromContext(ctx)
+
+	candidates := slices.Sorted(slices.Values(podNames))
+	for _, name := range candidates {
+		pod := &corev1.Pod{}
+		if err := n.Client.Get(ctx, client.ObjectKey{Name: name, Namespace: namespace}, pod); err != nil {
+			continue
+		}
+		if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" || (n.ReadyOnly && !isPodReady(pod)) {
+			continue
+		}
+
+		log.Info("Notifying canary pod first", "pod", name)
+		err := n.notifyPodWithRetry(ctx, pod, timestamp, payloadBytes)
+		if errors.Is(err, errPodGone) || errors.Is(err, errPodNotReady) {
+			continue // replaced under us, pick another canary
+		}
 		if err != nil {
-			return fmt.Errorf("failed to create request: %w", err)
+			return "", fmt.Errorf("%w: %s rejected the reload: %w", ErrCanaryFailed, name, err)
+		}
+		if err := n.checkCanaryHealth(ctx, pod); err != nil {
+			return "", fmt.Errorf("%w: %s: %w", ErrCanaryFailed, name, err)
 		}
-		req.Header.Set("Content-Type", "application/json")
+		log.Info("Canary pod healthy, notifying the remaining pods", "pod", name)
+		return name, nil
+	}
+	return "", nil
+}
 
-		// Add authorization header if token exists
-		if token != "" {
-			req.Header.Set("Authorization", fmt.Sprintf("Token %s", token))
+// checkCanaryHealth waits CanaryDelay, then requests CanaryHealthPath on the
+// pod's reload port and requires a 2xx answer.
+func (n *Notifier) checkCanaryHealth(ctx context.Context, pod *corev1.Pod) error {
+	if n.CanaryDelay > 0 {
+		select {
+		case <-time.After(n.CanaryDelay):
+		case <-ctx.Done():
+			return ctx.Err()
 		}
+	}
+	if n.CanaryHealthPath == "" {
+		return nil
+	}
+	healthURL := podURL(n.Scheme, pod, n.CanaryHealthPath)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, healthURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create health request: %w", err)
+	}
+	resp, err := n.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("health check %s returned status %d", n.CanaryHealthPath, resp.StatusCode)
+	}
+	return nil
+}
 
-		resp, err := n.HTTPClient.Do(req)
-		if err == nil {
-			// Read status code before closing body
-			statusCode := resp.StatusCode
-			lastStatusCode = statusCode
-			// Close body immediately - do NOT use defer inside loop to avoid connection leak
-			if closeErr := resp.Body.Close(); closeErr != nil {
-				log.Error(closeErr, "Failed to close response body", "pod", pod.Name)
+// NotifyPod notifies a single pod, e.g. one that became ready after the last
+// content change.
+func (n *Notifier) NotifyPod(ctx context.Context, pod *corev1.Pod, timestamp, decofileContent string) error {
+	payloadBytes, err := n.reloadTransport().Encode(timestamp, decofileContent)
+	if err != nil {
+		return err
+	}
+	err = n.notifyPodWithRetry(ctx, pod, timestamp, payloadBytes)
+	if errors.Is(err, errPodGone) || errors.Is(err, errPodNotReady) {
+		return nil
+	}
+	return err
+}
+
+// reloadPayload builds the JSON body POSTed to /.decofile/reload.
+func reloadPayload(timestamp, decofileContent string) ([]byte, error) {
+	payload := map[string]interface{}{
+		"timestamp": timestamp,
+		"source":    "operator",
+		"decofile":  json.RawMessage(decofileContent),
+	}
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return payloadBytes, nil
+}
+
+// reloadPort returns the port the user container listens on. Reloads go to
+// it directly rather than through Knative's queue-proxy (8012), which sits in
+// front of the user container on the same pod IP: the request is operator
+// traffic and must not count against the revision's concurrency. The user
+// container is the one named "app", as the Service webhook picks it, else the
+// first one that is neither queue-proxy nor the sidecar. Knative keeps the
+// user port declared in the Service (named "user-port", or "http1"/"h2c" as
+// declared) on it, ahead of any other port it exposes, and always sets its
+// PORT env.
+func reloadPort(pod *corev1.Pod) int32 {
+	var user *corev1.Container
+	for i := range pod.Spec.Containers {
+		c := &pod.Spec.Containers[i]
+		if c.Name == appContainerName {
+			user = c
+			break
+		}
+		if user == nil && c.Name != knativeQueueProxyContainer && c.Name != sidecarContainerName {
+			user = c
+		}
+	}
+	if user == nil {
+		return defaultReloadPort
+	}
+	for _, name := range []string{knativeUserPortName, knativeHTTP1PortName, knativeH2CPortName} {
+		for _, p := range user.Ports {
+			if p.Name == name {
+				return p.ContainerPort
 			}
-			if statusCode >= 200 && statusCode < 300 {
-				log.V(1).Info("Pod notified successfully", "pod", pod.Name, "status", statusCode)
-				return nil
+		}
+	}
+	if len(user.Ports) > 0 {
+		return user.Ports[0].ContainerPort
+	}
+	for _, env := range user.Env {
+		if env.Name == "PORT" {
+			if port, err := strconv.ParseInt(env.Value, 10, 32); err == nil && port > 0 {
+				return int32(port)
 			}
-			log.V(1).Info("Pod returned non-success status", "pod", pod.Name, "status", statusCode)
-			err = fmt.Errorf("pod returned status %d", statusCode)
 		}
+	}
+	return defaultReloadPort
+}
 
-		// If this was the last attempt, return the error
-		if attempt == maxRetries {
+// notifyPodWithRetry attempts to notify a single pod with exponential backoff retry
+// Sends payloadBytes, encoded by the Notifier's ReloadTransport.
+// The pod is re-read before each retry, so a changed IP is followed and a pod
+// that went away returns errPodGone instead of exhausting the retries.
+func (n *Notifier) notifyPodWithRetry(ctx context.Context, pod *corev1.Pod, timestamp string, payloadBytes []byte) error {
+	log := logf.FromContext(ctx)
+	transport := n.reloadTransport()
+
+	backoff := initialBackoff
+
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		if attempt > 1 {
+			fresh, err := n.refreshPod(ctx, pod)
+			if err != nil {
+				log.V(1).Info("Stopping retries", "pod", pod.Name, "reason", err.Error())
+				return err
+			}
+			pod = fresh
+		}
+
+		// Extract reload token from pod
+		token := extractReloadToken(pod)
+		noAuth := pod.Annotations[reloadAuthAnnotation] == reloadAuthNone
+		if noAuth {
+			token = ""
+		} else if token == "" && attempt == 1 {
+			log.V(1).Info("No reload token found in pod, skipping authorization", "pod", pod.Name)
+		}
+
+		log.V(1).Info("Attempting to notify pod", "pod", pod.Name, "attempt", attempt, "timestamp", timestamp)
+
+		err := n.send(ctx, transport, pod, payloadBytes, token)
+		if err == nil {
+			log.V(1).Info("Pod notified successfully", "pod", pod.Name)
+			return nil
+		}
+		log.V(1).Info("Pod rejected the reload", "pod", pod.Name, "error", err.Error())
+
+		// Auth failures are not transient: the same token will be rejected
+		// again, so stop retrying this pod immediately.
+		if errors.Is(err, errReloadUnauthenticated) || errors.Is(err, errReloadPermissionDenied) {
 			// Only attribute the failure to a missing token when we have hard
-			// evidence: the operator sent no Authorization header (no token in the
-			// pod env) AND the pod actually answered 401 -- i.e. a fail-closed
-			// /.decofile/reload rejected the unauthenticated request. A 401 has
-			// other possible causes (e.g. a token that IS present but rejected, or
-			// an upstream block), and non-401 failures (5xx, connection reset /
-			// broken pipe) are not necessarily token-related -- leave those as
-			// generic failures.
-			if token == "" && lastStatusCode == http.StatusUnauthorized {
-				return fmt.Errorf("%w: max retries reached: %v", ErrMissingReloadToken, err)
+			// evidence: the operator sent no token (none in the pod env) AND
+			// the pod answered unauthenticated -- i.e. a fail-closed reload
+			// endpoint rejected the unauthenticated request. Anything else is
+			// a token the pod doesn't accept.
+			if token == "" && !noAuth && errors.Is(err, errReloadUnauthenticated) {
+				return fmt.Errorf("%w: %v", ErrMissingReloadToken, err)
 			}
+			return fmt.Errorf("%w: %v", ErrReloadAuthFailed, err)
+		}
+
+		// If this was the last attempt, return the error. Non-auth failures
+		// (5xx, connection reset / broken pipe) are left as generic failures.
+		if attempt == maxRetries {
 			return fmt.Errorf("max retries reached: %w", err)
 		}
 
@@ -319,3 +742,33 @@ func (n *Notifier) notifyPodWithRetry(ctx context.Context, pod *corev1.Pod, time
 
 	return fmt.Errorf("unexpected: loop ended without return")
 }
+
+// refreshPod re-reads pod before a retry. It returns errPodGone when the pod
+// was deleted, is terminating, isn't running or has no IP, and errPodNotReady
+// when it stopped being Ready under ReadyOnly. Any other read
+// error keeps the pod as it was, so a flaky API server doesn't cut retries.
+func (n *Notifier) refreshPod(ctx context.Context, pod *corev1.Pod) (*corev1.Pod, error) {
+	if n.Client == nil {
+		return pod, nil
+	}
+	fresh := &corev1.Pod{}
+	if err := n.Client.Get(ctx, client.ObjectKeyFromObject(pod), fresh); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: %s was deleted", errPodGone, pod.Name)
+		}
+		logf.FromContext(ctx).V(1).Info("Failed to refresh pod, retrying with the last known IP", "pod", pod.Name, "error", err.Error())
+		return pod, nil
+	}
+	if fresh.DeletionTimestamp != nil || fresh.Status.Phase != corev1.PodRunning || fresh.Status.PodIP == "" {
+		return nil, fmt.Errorf("%w: %s is %s (ip %q, terminating %t)", errPodGone, pod.Name,
+			fresh.Status.Phase, fresh.Status.PodIP, fresh.DeletionTimestamp != nil)
+	}
+	if n.ReadyOnly && !isPodReady(fresh) {
+		return nil, fmt.Errorf("%w: %s", errPodNotReady, pod.Name)
+	}
+	if fresh.Status.PodIP != pod.Status.PodIP {
+		logf.FromContext(ctx).Info("Pod IP changed between reload attempts", "pod", pod.Name,
+			"oldIP", pod.Status.PodIP, "newIP", fresh.Status.PodIP)
+	}
+	return fresh, nil
+}
@@ -0,0 +1,698 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// makeNotifyPod returns a running, Ready pod whose IP/port point at srv.
+func makeNotifyPod(t *testing.T, srv *httptest.Server, token string) *corev1.Pod {
+	t.Helper()
+	host, portStr, err := net.SplitHostPort(srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("split host port: %v", err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		t.Fatalf("parse port: %v", err)
+	}
+	container := corev1.Container{
+		Name:  appContainerName,
+		Ports: []corev1.ContainerPort{{ContainerPort: int32(port)}},
+	}
+	if token != "" {
+		container.Env = []corev1.EnvVar{{Name: reloadTokenEnvVar, Value: token}}
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: testNamespace},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{container}},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			PodIP:      host,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func TestNotifyPodWithRetry_AuthFailuresAreNotRetried(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		token   string
+		wantErr error
+	}{
+		{"401 without token", http.StatusUnauthorized, "", ErrMissingReloadToken},
+		{"401 with token", http.StatusUnauthorized, "stale", ErrReloadAuthFailed},
+		{"403 with token", http.StatusForbidden, "stale", ErrReloadAuthFailed},
+		{"403 without token", http.StatusForbidden, "", ErrReloadAuthFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				calls.Add(1)
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			n := NewNotifier(nil, srv.Client(), NotifyOptions{})
+			err := n.notifyPodWithRetry(context.Background(), makeNotifyPod(t, srv, tt.token), "1", []byte(`{}`))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got := calls.Load(); got != 1 {
+				t.Errorf("pod called %d times, want 1 (no retry on auth failure)", got)
+			}
+		})
+	}
+}
+
+// Retries re-read the pod: a pod replaced during a rollout is followed to
+// its new address, and a deleted pod stops the retries without an error.
+func TestNotifyPodWithRetry_RefreshesPod(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failing.Close()
+	scheme := newOwnerTestScheme(t)
+
+	t.Run("address changed", func(t *testing.T) {
+		var calls atomic.Int32
+		moved := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer moved.Close()
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(makeNotifyPod(t, moved, "")).Build()
+		n := NewNotifier(c, failing.Client(), NotifyOptions{})
+		if err := n.notifyPodWithRetry(context.Background(), makeNotifyPod(t, failing, ""), "1", []byte(`{}`)); err != nil {
+			t.Fatalf("notifyPodWithRetry: %v", err)
+		}
+		if calls.Load() != 1 {
+			t.Errorf("moved pod called %d times, want 1", calls.Load())
+		}
+	})
+
+	t.Run("pod deleted", func(t *testing.T) {
+		c := fake.NewClientBuilder().WithScheme(scheme).Build()
+		n := NewNotifier(c, failing.Client(), NotifyOptions{})
+		err := n.notifyPodWithRetry(context.Background(), makeNotifyPod(t, failing, ""), "1", []byte(`{}`))
+		if !errors.Is(err, errPodGone) {
+			t.Fatalf("err = %v, want errPodGone", err)
+		}
+	})
+}
+
+func TestNotifyPods_BoundedWorkers(t *testing.T) {
+	scheme := newOwnerTestScheme(t)
+	var inFlight, maxInFlight, reloads atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := maxInFlight.Load()
+			if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		reloads.Add(1)
+	}))
+	defer srv.Close()
+
+	const pods = 5 * DefaultNotifyPodConcurrency
+	builder := fake.NewClientBuilder().WithScheme(scheme)
+	for i := range pods {
+		pod := makeNotifyPod(t, srv, "")
+		pod.Name = fmt.Sprintf("site-%03d", i)
+		pod.Labels = map[string]string{DefaultDeploymentIdLabel: "dep-1"}
+		builder = builder.WithObjects(pod)
+	}
+	var stats NotifyStats
+	n := NewNotifier(builder.Build(), srv.Client(), NotifyOptions{})
+	n.Stats = &stats
+
+	if err := n.NotifyPodsForDecofile(context.Background(), testNamespace, "dep-1", "1", `{}`); err != nil {
+		t.Fatalf("NotifyPodsForDecofile: %v", err)
+	}
+	if reloads.Load() != pods || stats.Notified != pods {
+		t.Errorf("reloads = %d, notified = %d; want %d", reloads.Load(), stats.Notified, pods)
+	}
+	if got := maxInFlight.Load(); got > DefaultNotifyPodConcurrency {
+		t.Errorf("%d reloads in flight at once, want at most %d", got, DefaultNotifyPodConcurrency)
+	}
+}
+
+func TestNotifyPods_MaxConnsPerHost(t *testing.T) {
+	scheme := newOwnerTestScheme(t)
+	var inFlight, maxInFlight atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := maxInFlight.Load()
+			if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	// Every pod resolves to the same host:port, like pods behind one gateway
+	builder := fake.NewClientBuilder().WithScheme(scheme)
+	for i := range 20 {
+		pod := makeNotifyPod(t, srv, "")
+		pod.Name = fmt.Sprintf("site-%03d", i)
+		pod.Labels = map[string]string{DefaultDeploymentIdLabel: "dep-1"}
+		builder = builder.WithObjects(pod)
+	}
+	var stats NotifyStats
+	opts := NotifyOptions{MaxConnsPerHost: 2}
+	n := NewNotifier(builder.Build(), NewHTTPClient(opts), opts)
+	n.Stats = &stats
+
+	if err := n.NotifyPodsForDecofile(context.Background(), testNamespace, "dep-1", "1", `{}`); err != nil {
+		t.Fatalf("NotifyPodsForDecofile: %v", err)
+	}
+	if stats.Notified != 20 {
+		t.Errorf("notified = %d, want 20", stats.Notified)
+	}
+	if got := maxInFlight.Load(); got > 2 {
+		t.Errorf("%d connections to the host at once, want at most 2", got)
+	}
+}
+
+func TestNotifyPods_MaxInFlightAcrossNotifiers(t *testing.T) {
+	scheme := newOwnerTestScheme(t)
+	var inFlight, maxInFlight atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := maxInFlight.Load()
+			if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+	}))
+	defer srv.Close()
+
+	// Three Decofiles changing at once, each with its own Notifier and pods
+	builder := fake.NewClientBuilder().WithScheme(scheme)
+	for d := range 3 {
+		for i := range 10 {
+			pod := makeNotifyPod(t, srv, "")
+			pod.Name = fmt.Sprintf("site-%d-%03d", d, i)
+			pod.Labels = map[string]string{DefaultDeploymentIdLabel: fmt.Sprintf("dep-%d", d)}
+			builder = builder.WithObjects(pod)
+		}
+	}
+	c := builder.Build()
+	opts := NotifyOptions{InFlight: make(chan struct{}, 3)}
+	httpClient := NewHTTPClient(opts)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 3)
+	for d := range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n := NewNotifier(c, httpClient, opts)
+			errs[d] = n.NotifyPodsForDecofile(context.Background(), testNamespace, fmt.Sprintf("dep-%d", d), "1", `{}`)
+		}()
+	}
+	wg.Wait()
+	for d, err := range errs {
+		if err != nil {
+			t.Errorf("NotifyPodsForDecofile(dep-%d): %v", d, err)
+		}
+	}
+	if got := maxInFlight.Load(); got > 3 {
+		t.Errorf("%d reloads in flight at once, want at most 3", got)
+	}
+}
+
+func TestNotifyPods_SkipsUnreadyPods(t *testing.T) {
+	scheme := newOwnerTestScheme(t)
+	var readyReloads, unreadyReloads atomic.Int32
+	readySrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		readyReloads.Add(1)
+	}))
+	defer readySrv.Close()
+	unreadySrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		unreadyReloads.Add(1)
+	}))
+	defer unreadySrv.Close()
+
+	ready, unready := makeNotifyPod(t, readySrv, ""), makeNotifyPod(t, unreadySrv, "")
+	ready.Name, unready.Name = "site-a", "site-b"
+	unready.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse}}
+	for _, pod := range []*corev1.Pod{ready, unready} {
+		pod.Labels = map[string]string{DefaultDeploymentIdLabel: "dep-1"}
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(ready, unready).Build()
+
+	t.Run("ready only", func(t *testing.T) {
+		readyReloads.Store(0)
+		unreadyReloads.Store(0)
+		var stats NotifyStats
+		n := NewNotifier(c, readySrv.Client(), NotifyOptions{ReadyPodsOnly: true})
+		n.Stats = &stats
+		if err := n.NotifyPodsForDecofile(context.Background(), testNamespace, "dep-1", "1", `{}`); err != nil {
+			t.Fatalf("NotifyPodsForDecofile: %v", err)
+		}
+		if readyReloads.Load() != 1 || unreadyReloads.Load() != 0 {
+			t.Errorf("reloads = %d ready, %d unready; want 1, 0", readyReloads.Load(), unreadyReloads.Load())
+		}
+		if stats != (NotifyStats{Notified: 1, Skipped: 1}) {
+			t.Errorf("stats = %+v, want 1 notified and 1 skipped", stats)
+		}
+	})
+
+	t.Run("all running pods", func(t *testing.T) {
+		readyReloads.Store(0)
+		unreadyReloads.Store(0)
+		n := NewNotifier(c, readySrv.Client(), NotifyOptions{})
+		if err := n.NotifyPodsForDecofile(context.Background(), testNamespace, "dep-1", "1", `{}`); err != nil {
+			t.Fatalf("NotifyPodsForDecofile: %v", err)
+		}
+		if readyReloads.Load() != 1 || unreadyReloads.Load() != 1 {
+			t.Errorf("reloads = %d ready, %d unready; want both notified", readyReloads.Load(), unreadyReloads.Load())
+		}
+	})
+}
+
+func TestNotifyPods_Canary(t *testing.T) {
+	scheme := newOwnerTestScheme(t)
+	for _, healthStatus := range []int{http.StatusOK, http.StatusServiceUnavailable} {
+		t.Run(http.StatusText(healthStatus), func(t *testing.T) {
+			var canaryReloads, otherReloads atomic.Int32
+			canarySrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/live" {
+					w.WriteHeader(healthStatus)
+					return
+				}
+				canaryReloads.Add(1)
+			}))
+			defer canarySrv.Close()
+			otherSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				otherReloads.Add(1)
+			}))
+			defer otherSrv.Close()
+
+			canary, other := makeNotifyPod(t, canarySrv, ""), makeNotifyPod(t, otherSrv, "")
+			canary.Name, other.Name = "site-a", "site-b"
+			for _, pod := range []*corev1.Pod{canary, other} {
+				pod.Labels = map[string]string{DefaultDeploymentIdLabel: "dep-1"}
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(other, canary).Build()
+			n := NewNotifier(c, canarySrv.Client(), NotifyOptions{})
+			n.Canary = true
+			n.CanaryHealthPath = "/live"
+
+			err := n.NotifyPodsForDecofile(context.Background(), testNamespace, "dep-1", "1", `{}`)
+			if canaryReloads.Load() != 1 {
+				t.Errorf("canary reloaded %d times, want 1", canaryReloads.Load())
+			}
+			if healthStatus == http.StatusOK {
+				if err != nil || otherReloads.Load() != 1 {
+					t.Errorf("healthy canary: err = %v, other reloads = %d; want nil, 1", err, otherReloads.Load())
+				}
+				return
+			}
+			if !errors.Is(err, ErrCanaryFailed) {
+				t.Errorf("err = %v, want ErrCanaryFailed", err)
+			}
+			if otherReloads.Load() != 0 {
+				t.Errorf("other pod reloaded %d times after a failed canary, want 0", otherReloads.Load())
+			}
+		})
+	}
+}
+
+func TestNotifyPodWithRetry_SendsToken(t *testing.T) {
+	var gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	n := NewNotifier(nil, srv.Client(), NotifyOptions{})
+	if err := n.notifyPodWithRetry(context.Background(), makeNotifyPod(t, srv, "secret"), "1", []byte(`{}`)); err != nil {
+		t.Fatalf("notifyPodWithRetry: %v", err)
+	}
+	if gotAuth != "Token secret" {
+		t.Errorf("Authorization = %q, want %q", gotAuth, "Token secret")
+	}
+}
+
+func TestNotifyPodWithRetry_ReloadAuthNone(t *testing.T) {
+	var gotAuth []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Values("Authorization")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	pod := makeNotifyPod(t, srv, "secret")
+	pod.Annotations = map[string]string{reloadAuthAnnotation: reloadAuthNone}
+	n := NewNotifier(nil, srv.Client(), NotifyOptions{})
+	if err := n.notifyPodWithRetry(context.Background(), pod, "1", []byte(`{}`)); err != nil {
+		t.Fatalf("notifyPodWithRetry: %v", err)
+	}
+	if len(gotAuth) != 0 {
+		t.Errorf("Authorization = %q, want no header with %s=none", gotAuth, reloadAuthAnnotation)
+	}
+}
+
+func TestNotifyPodWithRetry_ReloadMethod(t *testing.T) {
+	tests := []struct {
+		method     string
+		wantMethod string
+		wantBody   string
+	}{
+		{"", http.MethodPost, `{}`},
+		{http.MethodPut, http.MethodPut, `{}`},
+		{http.MethodGet, http.MethodGet, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.wantMethod, func(t *testing.T) {
+			var gotMethod, gotBody string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotMethod = r.Method
+				body, _ := io.ReadAll(r.Body)
+				gotBody = string(body)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer srv.Close()
+
+			n := NewNotifier(nil, srv.Client(), NotifyOptions{})
+			n.ReloadMethod = tt.method
+			if err := n.notifyPodWithRetry(context.Background(), makeNotifyPod(t, srv, ""), "1", []byte(`{}`)); err != nil {
+				t.Fatalf("notifyPodWithRetry: %v", err)
+			}
+			if gotMethod != tt.wantMethod || gotBody != tt.wantBody {
+				t.Errorf("request = %s %q, want %s %q", gotMethod, gotBody, tt.wantMethod, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestNotifyPodWithRetry_ReloadPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		wantPath string
+	}{
+		{"", reloadEndpoint},
+		{"/internal/reload", "/internal/reload"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.wantPath, func(t *testing.T) {
+			var gotPath string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath = r.URL.Path
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer srv.Close()
+
+			n := NewNotifier(nil, srv.Client(), NotifyOptions{})
+			n.ReloadPath = tt.path
+			if err := n.notifyPodWithRetry(context.Background(), makeNotifyPod(t, srv, ""), "1", []byte(`{}`)); err != nil {
+				t.Fatalf("notifyPodWithRetry: %v", err)
+			}
+			if gotPath != tt.wantPath {
+				t.Errorf("request path = %q, want %q", gotPath, tt.wantPath)
+			}
+		})
+	}
+}
+
+func TestReloadPort(t *testing.T) {
+	queueProxy := corev1.Container{Name: knativeQueueProxyContainer, Ports: []corev1.ContainerPort{
+		{Name: "http-queueadm", ContainerPort: 8022},
+		{Name: "queue-port", ContainerPort: 8012},
+	}}
+	cases := []struct {
+		name       string
+		containers []corev1.Container
+		want       int32
+	}{
+		{"no containers", nil, defaultReloadPort},
+		{"app container", []corev1.Container{
+			{Name: appContainerName, Ports: []corev1.ContainerPort{{ContainerPort: 3000}}},
+		}, 3000},
+		{"queue-proxy listed first", []corev1.Container{
+			queueProxy,
+			{Name: "user-container", Ports: []corev1.ContainerPort{{Name: knativeUserPortName, ContainerPort: 8000}}},
+		}, 8000},
+		{"user-port preferred over first port", []corev1.Container{
+			{Name: appContainerName, Ports: []corev1.ContainerPort{
+				{Name: "metrics", ContainerPort: 9464},
+				{Name: knativeUserPortName, ContainerPort: 8000},
+			}},
+		}, 8000},
+		{"app after other containers, http1 among several ports", []corev1.Container{
+			queueProxy,
+			{Name: "worker", Ports: []corev1.ContainerPort{{ContainerPort: 7000}}},
+			{Name: appContainerName, Ports: []corev1.ContainerPort{
+				{Name: "metrics", ContainerPort: 9464},
+				{Name: "grpc", ContainerPort: 9000},
+				{Name: knativeHTTP1PortName, ContainerPort: 3000},
+			}},
+		}, 3000},
+		{"user-port preferred over http1", []corev1.Container{
+			{Name: appContainerName, Ports: []corev1.ContainerPort{
+				{Name: knativeHTTP1PortName, ContainerPort: 3000},
+				{Name: knativeUserPortName, ContainerPort: 8080},
+			}},
+		}, 8080},
+		{"sidecar before app", []corev1.Container{
+			{Name: sidecarContainerName, Ports: []corev1.ContainerPort{{ContainerPort: 8081}}},
+			{Name: appContainerName, Ports: []corev1.ContainerPort{{ContainerPort: 3000}}},
+		}, 3000},
+		{"PORT env only", []corev1.Container{
+			queueProxy,
+			{Name: "user-container", Env: []corev1.EnvVar{{Name: "PORT", Value: "8080"}}},
+		}, 8080},
+		{"only queue-proxy", []corev1.Container{queueProxy}, defaultReloadPort},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: tc.containers}}
+			if got := reloadPort(pod); got != tc.want {
+				t.Errorf("reloadPort() = %d, want %d", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestResolveTaggedRevision(t *testing.T) {
+	scheme := newOwnerTestScheme(t)
+	svc := &servingv1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "site", Namespace: testNamespace,
+			Labels: map[string]string{DefaultDeploymentIdLabel: "dep-1"}},
+	}
+	svc.Spec.Traffic = []servingv1.TrafficTarget{
+		{Tag: "pinned", RevisionName: "site-00001"},
+	}
+	svc.Status.Traffic = []servingv1.TrafficTarget{
+		{Tag: "canary", RevisionName: "site-00003"},
+		{Tag: "current", RevisionName: "site-00002"},
+	}
+	// Another site in the namespace, listed first, with the same tags
+	other := &servingv1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "another", Namespace: testNamespace,
+			Labels: map[string]string{DefaultDeploymentIdLabel: "dep-2"}},
+	}
+	other.Spec.Traffic = []servingv1.TrafficTarget{{Tag: "pinned", RevisionName: "another-00001"}}
+	other.Status.Traffic = []servingv1.TrafficTarget{{Tag: "canary", RevisionName: "another-00002"}}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(svc, other).Build()
+	r := &DecofileReconciler{Client: c, Scheme: scheme}
+
+	tests := []struct {
+		tag     string
+		want    string
+		wantErr bool
+	}{
+		{tag: "canary", want: "site-00003"},
+		{tag: "current", want: "site-00002"},
+		{tag: "pinned", want: "site-00001"},
+		{tag: "missing", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			got, err := r.resolveTaggedRevision(context.Background(), testNamespace, "dep-1", tt.tag)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("revision = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// A tag resolving to a Revision none of the Decofile's pods run fails the
+// notification instead of reporting a rollout that reloaded nobody.
+func TestNotifyPods_TaggedRevisionWithoutPods(t *testing.T) {
+	scheme := newOwnerTestScheme(t)
+	var reloads atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		reloads.Add(1)
+	}))
+	defer srv.Close()
+
+	svc := &servingv1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "site", Namespace: testNamespace},
+	}
+	svc.Spec.Template.Labels = map[string]string{DefaultDeploymentIdLabel: "dep-1"}
+	svc.Status.Traffic = []servingv1.TrafficTarget{{Tag: "canary", RevisionName: "site-00003"}}
+	pod := makeNotifyPod(t, srv, "")
+	pod.Labels = map[string]string{DefaultDeploymentIdLabel: "dep-1", knativeRevisionLabel: "site-00002"}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(svc, pod).Build()
+	r := &DecofileReconciler{Client: c, Scheme: scheme, HTTPClient: srv.Client()}
+
+	df := makeDecofile("site", "dep-1")
+	df.Spec.NotifyRevisionTag = "canary"
+	err := r.notifyPods(context.Background(), df, "dep-1", "1", `{}`, &NotifyStats{})
+	if !errors.Is(err, errNoPods) {
+		t.Errorf("notifyPods err = %v, want errNoPods", err)
+	}
+	if got := reloads.Load(); got != 0 {
+		t.Errorf("pods reloaded = %d, want none outside the tagged revision", got)
+	}
+}
+
+// Pods mounting the Decofile as an extra run in other Services' Revisions:
+// spec.notifyRevisionTag scopes only the Decofile's own pods.
+func TestNotifyPods_TaggedRevisionNotifiesExtraMounts(t *testing.T) {
+	scheme := newOwnerTestScheme(t)
+	var own, extra atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("deploymentId") == "dep-1" {
+			extra.Add(1)
+		} else {
+			own.Add(1)
+		}
+	}))
+	defer srv.Close()
+
+	svc := &servingv1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "site", Namespace: testNamespace,
+			Labels: map[string]string{DefaultDeploymentIdLabel: "dep-1"}},
+	}
+	svc.Status.Traffic = []servingv1.TrafficTarget{{Tag: "canary", RevisionName: "site-00003"}}
+	canary, stable, consumer := makeNotifyPod(t, srv, ""), makeNotifyPod(t, srv, ""), makeNotifyPod(t, srv, "")
+	canary.Name, stable.Name, consumer.Name = "site-canary", "site-stable", "consumer"
+	canary.Labels = map[string]string{DefaultDeploymentIdLabel: "dep-1", knativeRevisionLabel: "site-00003"}
+	stable.Labels = map[string]string{DefaultDeploymentIdLabel: "dep-1", knativeRevisionLabel: "site-00002"}
+	consumer.Labels = map[string]string{
+		DefaultDeploymentIdLabel:           "dep-2",
+		knativeRevisionLabel:               "consumer-00001",
+		extraDecofileLabelPrefix + "dep-1": "true",
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(svc, canary, stable, consumer).Build()
+	r := &DecofileReconciler{Client: c, Scheme: scheme, HTTPClient: srv.Client()}
+
+	df := makeDecofile("site", "dep-1")
+	df.Spec.NotifyRevisionTag = "canary"
+	if err := r.notifyPods(context.Background(), df, "dep-1", "1", `{}`, &NotifyStats{}); err != nil {
+		t.Fatalf("notifyPods: %v", err)
+	}
+	if got := own.Load(); got != 1 {
+		t.Errorf("own pods reloaded = %d, want only the tagged revision's", got)
+	}
+	if got := extra.Load(); got != 1 {
+		t.Errorf("extra mounts reloaded = %d, want the consumer outside the tagged revision", got)
+	}
+}
+
+func TestNotifyPodsForDecofile_CustomDeploymentIdLabel(t *testing.T) {
+	scheme := newOwnerTestScheme(t)
+	var reloads atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		reloads.Add(1)
+	}))
+	defer srv.Close()
+
+	custom, stale := makeNotifyPod(t, srv, ""), makeNotifyPod(t, srv, "")
+	custom.Name, stale.Name = "site-a", "site-b"
+	custom.Labels = map[string]string{"example.com/release": "dep-1"}
+	stale.Labels = map[string]string{DefaultDeploymentIdLabel: "dep-1"}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(custom, stale).Build()
+
+	n := NewNotifier(c, srv.Client(), NotifyOptions{DeploymentIdLabel: "example.com/release"})
+	if err := n.NotifyPodsForDecofile(context.Background(), testNamespace, "dep-1", "1", `{}`); err != nil {
+		t.Fatalf("NotifyPodsForDecofile: %v", err)
+	}
+	if got := reloads.Load(); got != 1 {
+		t.Errorf("pods reloaded = %d, want only the one with the configured label", got)
+	}
+}
+
+func TestNotifyPodsForDecofile_PodSelector(t *testing.T) {
+	scheme := newOwnerTestScheme(t)
+	var reloads atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		reloads.Add(1)
+	}))
+	defer srv.Close()
+
+	current, previous, other := makeNotifyPod(t, srv, ""), makeNotifyPod(t, srv, ""), makeNotifyPod(t, srv, "")
+	current.Name, previous.Name, other.Name = "site-v2", "site-v1", "other-v2"
+	current.Labels = map[string]string{DefaultDeploymentIdLabel: "dep-1", "version": "v2"}
+	previous.Labels = map[string]string{DefaultDeploymentIdLabel: "dep-1", "version": "v1"}
+	other.Labels = map[string]string{DefaultDeploymentIdLabel: "dep-2", "version": "v2"}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(current, previous, other).Build()
+
+	df := makeDecofile("site", "dep-1")
+	df.Spec.PodSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"version": "v2"}}
+	selector, err := decofilePodSelector(df)
+	if err != nil {
+		t.Fatalf("decofilePodSelector: %v", err)
+	}
+	n := NewNotifier(c, srv.Client(), NotifyOptions{})
+	n.PodSelector = selector
+	if err := n.NotifyPodsForDecofile(context.Background(), testNamespace, "dep-1", "1", `{}`); err != nil {
+		t.Fatalf("NotifyPodsForDecofile: %v", err)
+	}
+	if got := reloads.Load(); got != 1 {
+		t.Errorf("pods reloaded = %d, want only the dep-1 pod matching spec.podSelector", got)
+	}
+}
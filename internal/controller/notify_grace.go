@@ -0,0 +1,63 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+// notifyGrace applies spec.notifyGracePeriod to a reconcile. changed reports
+// whether the content just changed and timestamp is the current content
+// timestamp. It returns the pending marker to record (nil when nothing is
+// held back), whether pods are to be notified now and, while the change is
+// held back, how long until the grace period ends.
+//
+// The grace period runs from the first held-back change, so a steady stream
+// of edits can't postpone the notification indefinitely; later changes only
+// move the timestamp that will be notified.
+func notifyGrace(decofile *decositesv1alpha1.Decofile, changed bool, timestamp string, now time.Time) (*decositesv1alpha1.PendingNotification, bool, time.Duration) {
+	var grace time.Duration
+	if decofile.Spec.NotifyGracePeriod != nil {
+		grace = decofile.Spec.NotifyGracePeriod.Duration
+	}
+
+	pending := decofile.Status.PendingNotification.DeepCopy()
+	if pending == nil {
+		if !changed {
+			return nil, false, 0
+		}
+		if grace <= 0 {
+			return nil, true, 0
+		}
+		pending = &decositesv1alpha1.PendingNotification{NotifyAt: metav1.NewTime(now.Add(grace))}
+	}
+	pending.Timestamp = timestamp
+
+	// Removing the grace period releases a held-back change at once
+	notifyAt := pending.NotifyAt.Time
+	if grace <= 0 {
+		notifyAt = now
+	}
+	if now.Before(notifyAt) {
+		return pending, false, notifyAt.Sub(now)
+	}
+	return pending, true, 0
+}
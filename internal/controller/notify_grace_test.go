@@ -0,0 +1,181 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+func TestNotifyGrace(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	grace := &metav1.Duration{Duration: time.Minute}
+	held := &decositesv1alpha1.PendingNotification{Timestamp: "1", NotifyAt: metav1.NewTime(now.Add(30 * time.Second))}
+	due := &decositesv1alpha1.PendingNotification{Timestamp: "1", NotifyAt: metav1.NewTime(now.Add(-time.Second))}
+
+	tests := []struct {
+		name        string
+		grace       *metav1.Duration
+		pending     *decositesv1alpha1.PendingNotification
+		changed     bool
+		wantPending bool
+		wantNotify  bool
+		wantWait    time.Duration
+	}{
+		{"no grace period notifies a change", nil, nil, true, false, true, 0},
+		{"no change, nothing pending", grace, nil, false, false, false, 0},
+		{"first change is held back", grace, nil, true, true, false, time.Minute},
+		{"later change keeps the window", grace, held, true, true, false, 30 * time.Second},
+		{"held change waits without a new change", grace, held, false, true, false, 30 * time.Second},
+		{"window over notifies", grace, due, false, true, true, 0},
+		{"removed grace period releases the hold", nil, held, false, true, true, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			df := makeDecofile("foo", "")
+			df.Spec.NotifyGracePeriod = tt.grace
+			df.Status.PendingNotification = tt.pending.DeepCopy()
+
+			pending, notify, wait := notifyGrace(df, tt.changed, "2", now)
+			if (pending != nil) != tt.wantPending || notify != tt.wantNotify || wait != tt.wantWait {
+				t.Fatalf("notifyGrace = %+v, %v, %v; want pending %v, notify %v, wait %v", pending, notify, wait, tt.wantPending, tt.wantNotify, tt.wantWait)
+			}
+			if pending != nil && pending.Timestamp != "2" {
+				t.Errorf("pending timestamp = %q, want the current one", pending.Timestamp)
+			}
+		})
+	}
+}
+
+func TestReconcile_NotifyGracePeriodCoalescesChanges(t *testing.T) {
+	ctx := context.Background()
+	scheme := newOwnerTestScheme(t)
+
+	var notified []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Decofile json.RawMessage `json:"decofile"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		notified = append(notified, string(body.Decofile))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	df := makeDecofile("foo", "")
+	df.Spec.Source = SourceTypeInline
+	df.Spec.Inline = &decositesv1alpha1.InlineSource{Value: map[string]runtime.RawExtension{
+		"config.json": {Raw: []byte(`{"v":0}`)},
+	}}
+	df.Spec.NotifyGracePeriod = &metav1.Duration{Duration: time.Hour}
+	pod := makeNotifyPod(t, srv, "")
+	pod.Labels = map[string]string{DefaultDeploymentIdLabel: "foo"}
+	pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(df, pod).
+		WithStatusSubresource(&decositesv1alpha1.Decofile{}).Build()
+	r := &DecofileReconciler{Client: c, Scheme: scheme, HTTPClient: srv.Client()}
+	key := client.ObjectKeyFromObject(df)
+
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key}); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+
+	edit := func(value string) reconcile.Result {
+		t.Helper()
+		current := &decositesv1alpha1.Decofile{}
+		if err := c.Get(ctx, key, current); err != nil {
+			t.Fatalf("get Decofile: %v", err)
+		}
+		current.Spec.Inline.Value["config.json"] = runtime.RawExtension{Raw: []byte(value)}
+		if err := c.Update(ctx, current); err != nil {
+			t.Fatalf("update Decofile: %v", err)
+		}
+		res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		if err != nil {
+			t.Fatalf("Reconcile: %v", err)
+		}
+		return res
+	}
+
+	// Both edits reach the ConfigMap but are held back from pods
+	first := edit(`{"v":1}`)
+	if first.RequeueAfter <= 0 || first.RequeueAfter > time.Hour {
+		t.Errorf("RequeueAfter = %v, want the rest of the grace period", first.RequeueAfter)
+	}
+	got := &decositesv1alpha1.Decofile{}
+	if err := c.Get(ctx, key, got); err != nil {
+		t.Fatalf("get Decofile: %v", err)
+	}
+	if got.Status.PendingNotification == nil {
+		t.Fatal("status.pendingNotification not set for a held-back change")
+	}
+	notifyAt := got.Status.PendingNotification.NotifyAt
+	if cond := meta.FindStatusCondition(got.Status.Conditions, condTypePodsNotified); cond == nil || cond.Reason != "NotificationPending" {
+		t.Errorf("PodsNotified = %+v, want reason NotificationPending", cond)
+	}
+
+	edit(`{"v":2}`)
+	if err := c.Get(ctx, key, got); err != nil {
+		t.Fatalf("get Decofile: %v", err)
+	}
+	if got.Status.PendingNotification == nil || !got.Status.PendingNotification.NotifyAt.Equal(&notifyAt) {
+		t.Errorf("pendingNotification = %+v, want the first change's notifyAt %v kept", got.Status.PendingNotification, notifyAt)
+	}
+	if len(notified) != 0 {
+		t.Fatalf("pods notified %d time(s) within the grace period", len(notified))
+	}
+
+	// Once the grace period is over, pods get the final content once
+	got.Status.PendingNotification.NotifyAt = metav1.NewTime(time.Now().Add(-time.Second))
+	if err := c.Status().Update(ctx, got); err != nil {
+		t.Fatalf("update status: %v", err)
+	}
+	res, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if res.RequeueAfter != 0 {
+		t.Errorf("RequeueAfter = %v after notifying, want none", res.RequeueAfter)
+	}
+	if len(notified) != 1 || notified[0] != `{"config":{"v":2}}` {
+		t.Errorf("notified = %v, want only the final content", notified)
+	}
+	if err := c.Get(ctx, key, got); err != nil {
+		t.Fatalf("get Decofile: %v", err)
+	}
+	if got.Status.PendingNotification != nil {
+		t.Errorf("pendingNotification = %+v, want cleared", got.Status.PendingNotification)
+	}
+	if !meta.IsStatusConditionTrue(got.Status.Conditions, condTypePodsNotified) {
+		t.Errorf("PodsNotified not True after the held-back notification")
+	}
+}
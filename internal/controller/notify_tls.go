@@ -0,0 +1,63 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Schemes of the requests sent to pods (NotifyOptions.Scheme).
+const (
+	NotifySchemeHTTP  = "http"
+	NotifySchemeHTTPS = "https"
+)
+
+// NewNotifyTLSConfig returns the TLS config pods' certificates are checked
+// with: against the PEM certificates in caBundle (in addition to the system
+// roots) when set, for serverName instead of the pod IP when set, or not at
+// all with insecureSkipVerify.
+func NewNotifyTLSConfig(caBundle []byte, serverName string, insecureSkipVerify bool) (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		ServerName:         serverName,
+		InsecureSkipVerify: insecureSkipVerify, //nolint:gosec // opt-in via --notify-tls-insecure-skip-verify
+	}
+	if len(caBundle) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(caBundle) {
+			return nil, fmt.Errorf("no PEM certificates found in CA bundle")
+		}
+		cfg.RootCAs = pool
+	}
+	return cfg, nil
+}
+
+// podURL returns the URL of path on pod's reload port, with scheme (empty
+// means http).
+func podURL(scheme string, pod *corev1.Pod, path string) string {
+	if scheme == "" {
+		scheme = NotifySchemeHTTP
+	}
+	return fmt.Sprintf("%s://%s:%d%s", scheme, pod.Status.PodIP, reloadPort(pod), path)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestNotifyPodWithRetry_HTTPS(t *testing.T) {
+	var gotAuth string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+
+	cases := []struct {
+		name               string
+		caBundle           []byte
+		insecureSkipVerify bool
+	}{
+		{"verified against CA bundle", caBundle, false},
+		{"skip verify", nil, true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tlsConfig, err := NewNotifyTLSConfig(tc.caBundle, "", tc.insecureSkipVerify)
+			if err != nil {
+				t.Fatalf("NewNotifyTLSConfig: %v", err)
+			}
+			opts := NotifyOptions{Scheme: NotifySchemeHTTPS, TLSConfig: tlsConfig}
+
+			gotAuth = ""
+			n := NewNotifier(nil, NewHTTPClient(opts), opts)
+			if err := n.notifyPodWithRetry(context.Background(), makeNotifyPod(t, srv, "secret"), "1", []byte(`{}`)); err != nil {
+				t.Fatalf("notifyPodWithRetry: %v", err)
+			}
+			if gotAuth != "Token secret" {
+				t.Errorf("Authorization = %q, want %q", gotAuth, "Token secret")
+			}
+		})
+	}
+}
+
+func TestNewNotifyTLSConfig_InvalidBundle(t *testing.T) {
+	if _, err := NewNotifyTLSConfig([]byte("not a certificate"), "", false); err == nil {
+		t.Fatal("NewNotifyTLSConfig accepted a bundle without PEM certificates")
+	}
+}
+
+func TestPodURL(t *testing.T) {
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{Containers: []corev1.Container{
+			{Name: appContainerName, Ports: []corev1.ContainerPort{{ContainerPort: 3000}}},
+		}},
+		Status: corev1.PodStatus{PodIP: "10.0.0.7"},
+	}
+	if got, want := podURL("", pod, reloadEndpoint), "http://10.0.0.7:3000/.decofile/reload"; got != want {
+		t.Errorf("podURL() = %q, want %q", got, want)
+	}
+	if got, want := podURL(NotifySchemeHTTPS, pod, "/internal/reload"), "https://10.0.0.7:3000/internal/reload"; got != want {
+		t.Errorf("podURL(https) = %q, want %q", got, want)
+	}
+}
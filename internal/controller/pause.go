@@ -0,0 +1,87 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+// condTypePaused reports whether spec.pauseUntil is holding back changes.
+const condTypePaused = "Paused"
+
+// checkPause handles spec.pauseUntil. While the pause is in the future it
+// sets Paused=True and returns a result requeueing at its expiry, with
+// paused=true so the caller stops there. Once it has passed, a Paused=True
+// condition is flipped to False and the reconcile carries on.
+func (r *DecofileReconciler) checkPause(ctx context.Context, req ctrl.Request, decofile *decositesv1alpha1.Decofile) (result ctrl.Result, paused bool, err error) {
+	log := logf.FromContext(ctx)
+
+	var remaining time.Duration
+	if until := decofile.Spec.PauseUntil; until != nil {
+		remaining = time.Until(until.Time)
+	}
+	wasPaused := meta.IsStatusConditionTrue(decofile.Status.Conditions, condTypePaused)
+	if remaining <= 0 && !wasPaused {
+		return ctrl.Result{}, false, nil
+	}
+
+	cond := metav1.Condition{
+		Type:               condTypePaused,
+		Status:             metav1.ConditionFalse,
+		Reason:             "PauseExpired",
+		Message:            "Changes are delivered again",
+		LastTransitionTime: metav1.Now(),
+	}
+	if remaining > 0 {
+		until := decofile.Spec.PauseUntil.UTC().Format(time.RFC3339)
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = "PauseUntil"
+		cond.Message = fmt.Sprintf("Content updates and pod notifications are paused until %s", until)
+		log.Info("Decofile is paused, skipping reconcile", "until", until, "remaining", remaining)
+	}
+	if cur := meta.FindStatusCondition(decofile.Status.Conditions, condTypePaused); cur != nil && remaining > 0 &&
+		cur.Status == cond.Status && cur.Message == cond.Message && cur.ObservedGeneration == decofile.Generation {
+		return ctrl.Result{RequeueAfter: remaining}, true, nil
+	}
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &decositesv1alpha1.Decofile{}
+		if err := r.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		updateCondition(fresh, cond)
+		return r.Status().Update(ctx, fresh)
+	})
+	if err != nil {
+		log.Error(err, "Failed to update Paused condition")
+		return ctrl.Result{}, remaining > 0, err
+	}
+	if remaining > 0 {
+		return ctrl.Result{RequeueAfter: remaining}, true, nil
+	}
+	log.Info("Pause expired, resuming reconcile")
+	return ctrl.Result{}, false, nil
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+func TestReconcile_PauseUntil(t *testing.T) {
+	ctx := context.Background()
+	scheme := newOwnerTestScheme(t)
+	df := makeDecofile("foo", "")
+	df.Spec.Source = SourceTypeInline
+	df.Spec.Inline = &decositesv1alpha1.InlineSource{Value: map[string]runtime.RawExtension{
+		"config.json": {Raw: []byte(`{"a":1}`)},
+	}}
+	df.Spec.PauseUntil = &metav1.Time{Time: time.Now().Add(time.Hour)}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(df).
+		WithStatusSubresource(&decositesv1alpha1.Decofile{}).Build()
+	r := &DecofileReconciler{Client: c, Scheme: scheme}
+	key := client.ObjectKeyFromObject(df)
+
+	result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+	if err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if result.RequeueAfter <= 55*time.Minute || result.RequeueAfter > time.Hour {
+		t.Errorf("RequeueAfter = %v, want about 1h (the pause expiry)", result.RequeueAfter)
+	}
+	err = c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: df.ConfigMapName()}, &corev1.ConfigMap{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("ConfigMap get = %v, want NotFound while paused", err)
+	}
+	got := &decositesv1alpha1.Decofile{}
+	if err := c.Get(ctx, key, got); err != nil {
+		t.Fatalf("get Decofile: %v", err)
+	}
+	if !meta.IsStatusConditionTrue(got.Status.Conditions, condTypePaused) {
+		t.Fatalf("conditions = %+v, want Paused=True", got.Status.Conditions)
+	}
+
+	// Once the pause has passed, the condition flips and the reconcile goes on
+	got.Spec.PauseUntil = &metav1.Time{Time: time.Now().Add(-time.Minute)}
+	_, paused, err := r.checkPause(ctx, reconcile.Request{NamespacedName: key}, got)
+	if err != nil || paused {
+		t.Fatalf("checkPause after expiry = paused %t, err %v; want false, nil", paused, err)
+	}
+	if err := c.Get(ctx, key, got); err != nil {
+		t.Fatalf("get Decofile: %v", err)
+	}
+	if cond := meta.FindStatusCondition(got.Status.Conditions, condTypePaused); cond == nil || cond.Status != metav1.ConditionFalse {
+		t.Errorf("Paused = %+v, want False after expiry", cond)
+	}
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+// DecofilePodReconciler pushes the current decofile to pods that become Ready
+// after the last content change (Knative scale-from-zero or scale-up). The
+// Decofile controller only notifies on change, so without this late-joining
+// pods would never receive a reload POST.
+type DecofilePodReconciler struct {
+	client.Client
+	// HTTPClient is the shared notification client (see NewHTTPClient).
+	HTTPClient *http.Client
+	// Notify holds the process-wide notification settings, as on
+	// DecofileReconciler.
+	Notify NotifyOptions
+}
+
+// Reconcile notifies a single pod that just became Ready, once per Decofile
+// it consumes.
+func (r *DecofilePodReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	pod := &corev1.Pod{}
+	if err := r.Get(ctx, req.NamespacedName, pod); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !isPodReady(pod) {
+		return ctrl.Result{}, nil
+	}
+
+	decofiles, err := r.decofilesForPod(ctx, pod)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	var errs []error
+	for _, pd := range decofiles {
+		errs = append(errs, r.notifyPod(ctx, pod, pd.decofile, pd.extra))
+	}
+	return ctrl.Result{}, stderrors.Join(errs...)
+}
+
+// notifyPod pushes decofile's current content to pod; extra is set when the
+// pod mounts it as an extra rather than as its DECO_RELEASE.
+func (r *DecofilePodReconciler) notifyPod(ctx context.Context, pod *corev1.Pod, decofile *decositesv1alpha1.Decofile, extra bool) error {
+	log := logf.FromContext(ctx)
+
+	if decofile.Spec.NotifyTransport == decositesv1alpha1.NotifyTransportSSE {
+		// The pod gets the current timestamp when it subscribes to the
+		// event stream, which may well be after it became Ready
+		return nil
+	}
+
+	podSelector, err := decofilePodSelector(decofile)
+	if err != nil {
+		return err
+	}
+	if podSelector != nil && !podSelector.Matches(labels.Set(pod.Labels)) {
+		// Outside spec.podSelector, so never notified of this Decofile
+		return nil
+	}
+
+	// Read and push under the Decofile's lock so this can't interleave with
+	// a reconcile rewriting the ConfigMap
+	defer decofileLocks.Lock(client.ObjectKeyFromObject(decofile))()
+
+	cm := &corev1.ConfigMap{}
+	if err := r.Get(ctx, client.ObjectKey{Name: decofile.ConfigMapName(), Namespace: pod.Namespace}, cm); err != nil {
+		if errors.IsNotFound(err) {
+			// Not created yet; the Decofile controller notifies once it is
+			return nil
+		}
+		return err
+	}
+
+	content, err := DecodeDecofileConfigMap(cm)
+	if err != nil {
+		return err
+	}
+	timestamp := cm.Data[decofile.TimestampKeyOrDefault()]
+
+	log.Info("Notifying newly ready pod", "pod", pod.Name, "decofile", decofile.Name, "extra", extra, "timestamp", timestamp)
+	notifier := NewNotifier(r.Client, r.HTTPClient, r.Notify)
+	notifier.ReloadMethod = decofile.Spec.ReloadMethod
+	notifier.ReloadPath = decofile.Spec.ReloadPath
+	notifier.Transport = decofile.Spec.NotifyTransport
+	if extra {
+		notifier.ExtraDeploymentId = decofile.DeploymentIdOrName()
+	}
+	if err := notifier.NotifyPod(ctx, pod, timestamp, string(content)); err != nil {
+		// Auth failures won't resolve by retrying; transient ones are requeued
+		if stderrors.Is(err, ErrMissingReloadToken) || stderrors.Is(err, ErrReloadAuthFailed) {
+			log.Error(err, "Newly ready pod rejected the reload request", "pod", pod.Name)
+			return nil
+		}
+		return fmt.Errorf("notify pod %s: %w", pod.Name, err)
+	}
+	return nil
+}
+
+// podDecofile is a Decofile a pod consumes.
+type podDecofile struct {
+	decofile *decositesv1alpha1.Decofile
+	extra    bool
+}
+
+// decofilesForPod returns the ConfigMap-target Decofiles the pod consumes:
+// the one whose deploymentId matches its deploymentId label, then those it
+// carries an extra Decofile label for.
+func (r *DecofilePodReconciler) decofilesForPod(ctx context.Context, pod *corev1.Pod) ([]podDecofile, error) {
+	return listPodDecofiles(ctx, r.Client, pod, r.Notify.DeploymentIdLabel)
+}
+
+// listPodDecofiles implements decofilesForPod on any client.Reader, reading
+// the pod's deploymentId from label (empty means DefaultDeploymentIdLabel).
+func listPodDecofiles(ctx context.Context, c client.Reader, pod *corev1.Pod, label string) ([]podDecofile, error) {
+	deploymentId := pod.Labels[deploymentIdLabelOrDefault(label)]
+	decofiles := &decositesv1alpha1.DecofileList{}
+	if err := c.List(ctx, decofiles, client.InNamespace(pod.Namespace)); err != nil {
+		return nil, fmt.Errorf("list decofiles: %w", err)
+	}
+	var primary, extras []podDecofile
+	for i := range decofiles.Items {
+		df := &decofiles.Items[i]
+		if df.Spec.Target != "" && df.Spec.Target != decositesv1alpha1.TargetConfigMap {
+			continue
+		}
+		id := df.DeploymentIdOrName()
+		if id == deploymentId {
+			if primary == nil {
+				primary = []podDecofile{{decofile: df}}
+			}
+		} else if pod.Labels[extraDecofileLabelPrefix+id] == "true" {
+			extras = append(extras, podDecofile{decofile: df, extra: true})
+		}
+	}
+	return append(primary, extras...), nil
+}
+
+// isPodReady reports whether the pod is running, has an IP and passes its
+// readiness checks (i.e. the app can serve the reload endpoint).
+func isPodReady(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning || pod.Status.PodIP == "" || pod.DeletionTimestamp != nil {
+		return false
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DecofilePodReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	// Only react to a decofile pod transitioning to Ready. Create events are
+	// skipped so an operator restart (cache sync) doesn't re-notify every pod.
+	becameReady := predicate.Funcs{
+		CreateFunc: func(_ event.CreateEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldPod, okOld := e.ObjectOld.(*corev1.Pod)
+			newPod, okNew := e.ObjectNew.(*corev1.Pod)
+			if !okOld || !okNew || newPod.Labels[r.Notify.deploymentIdLabel()] == "" {
+				return false
+			}
+			return !isPodReady(oldPod) && isPodReady(newPod)
+		},
+		DeleteFunc:  func(_ event.DeleteEvent) bool { return false },
+		GenericFunc: func(_ event.GenericEvent) bool { return false },
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&corev1.Pod{}, builder.WithPredicates(becameReady)).
+		Named("decofile-pods").
+		WithOptions(controller.Options{
+			MaxConcurrentReconciles: r.Notify.podConcurrency(),
+		}).
+		Complete(r)
+}
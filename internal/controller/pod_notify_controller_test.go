@@ -0,0 +1,145 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+func TestIsPodReady(t *testing.T) {
+	ready := corev1.PodCondition{Type: corev1.PodReady, Status: corev1.ConditionTrue}
+	notReady := corev1.PodCondition{Type: corev1.PodReady, Status: corev1.ConditionFalse}
+
+	tests := []struct {
+		name   string
+		status corev1.PodStatus
+		want   bool
+	}{
+		{"running, ip, ready", corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.0.0.1", Conditions: []corev1.PodCondition{ready}}, true},
+		{"running, ip, not ready", corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.0.0.1", Conditions: []corev1.PodCondition{notReady}}, false},
+		{"running, no ip", corev1.PodStatus{Phase: corev1.PodRunning, Conditions: []corev1.PodCondition{ready}}, false},
+		{"pending", corev1.PodStatus{Phase: corev1.PodPending, PodIP: "10.0.0.1", Conditions: []corev1.PodCondition{ready}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isPodReady(&corev1.Pod{Status: tt.status}); got != tt.want {
+				t.Errorf("isPodReady() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecofilePodReconciler_NotifiesReadyPod(t *testing.T) {
+	ctx := context.Background()
+	scheme := newOwnerTestScheme(t)
+
+	var got struct {
+		Timestamp string          `json:"timestamp"`
+		Decofile  json.RawMessage `json:"decofile"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	content := `{"a":{"b":1}}`
+	compressed, err := compressBrotli([]byte(content))
+	if err != nil {
+		t.Fatalf("compress: %v", err)
+	}
+	df := makeDecofile("site-main", "dep-1")
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: df.ConfigMapName(), Namespace: testNamespace},
+		Data: map[string]string{
+			"decofile.bin":  base64.StdEncoding.EncodeToString(compressed),
+			"timestamp.txt": "1700000000",
+		},
+	}
+	pod := makeNotifyPod(t, srv, "")
+	pod.Labels = map[string]string{DefaultDeploymentIdLabel: "dep-1"}
+	pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(df, cm, pod).Build()
+	r := &DecofilePodReconciler{Client: c, HTTPClient: srv.Client()}
+
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: pod.Name}}); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if got.Timestamp != "1700000000" {
+		t.Errorf("timestamp = %q, want %q", got.Timestamp, "1700000000")
+	}
+	if string(got.Decofile) != content {
+		t.Errorf("decofile = %s, want %s", got.Decofile, content)
+	}
+}
+
+func TestDecofilePodReconciler_NotifiesExtraDecofiles(t *testing.T) {
+	ctx := context.Background()
+	scheme := newOwnerTestScheme(t)
+
+	got := map[string]string{} // deploymentId query -> decofile body
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Decofile json.RawMessage `json:"decofile"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		got[r.URL.Query().Get("deploymentId")] = string(body.Decofile)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	objs := []client.Object{}
+	for _, df := range []*decositesv1alpha1.Decofile{makeDecofile("site-main", "dep-1"), makeDecofile("shared", "shared")} {
+		compressed, err := compressBrotli([]byte(`{"from":"` + df.Name + `"}`))
+		if err != nil {
+			t.Fatalf("compress: %v", err)
+		}
+		objs = append(objs, df, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: df.ConfigMapName(), Namespace: testNamespace},
+			Data:       map[string]string{"decofile.bin": base64.StdEncoding.EncodeToString(compressed)},
+		})
+	}
+	pod := makeNotifyPod(t, srv, "")
+	pod.Labels = map[string]string{DefaultDeploymentIdLabel: "dep-1", extraDecofileLabelPrefix + "shared": "true"}
+	pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(append(objs, pod)...).Build()
+	r := &DecofilePodReconciler{Client: c, HTTPClient: srv.Client()}
+
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: testNamespace, Name: pod.Name}}); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	want := map[string]string{"": `{"from":"site-main"}`, "shared": `{"from":"shared"}`}
+	if len(got) != len(want) || got[""] != want[""] || got["shared"] != want["shared"] {
+		t.Errorf("reloads = %v, want %v", got, want)
+	}
+}
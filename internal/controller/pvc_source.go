@@ -0,0 +1,64 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+// PVCSource handles retrieval of configuration data from a
+// PersistentVolumeClaim mounted into the operator at <dir>/<claimName>
+type PVCSource struct {
+	config *decositesv1alpha1.PVCSource
+	dir    string
+	// keepExtensions disables stripping ".json" from keys
+	keepExtensions bool
+}
+
+// NewPVCSource creates a new PVCSource reading claims mounted under dir
+func NewPVCSource(config *decositesv1alpha1.PVCSource, dir string) *PVCSource {
+	return &PVCSource{config: config, dir: dir}
+}
+
+// Retrieve reads spec.pvc.path within the claim's mount like a file source
+// rooted there, so neither ".." nor a symlink can reach outside the volume.
+func (s *PVCSource) Retrieve(ctx context.Context) (string, error) {
+	if s.dir == "" {
+		return "", fmt.Errorf("source %q is disabled: the operator has no --decofile-pvc-source-dir", SourceTypePVC)
+	}
+	mount := filepath.Join(s.dir, s.config.ClaimName)
+	if info, err := os.Stat(mount); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("claim %q is not mounted in the operator at %s", s.config.ClaimName, mount)
+	}
+	p := s.config.Path
+	if p == "" {
+		p = "."
+	}
+	files := NewFileSource(&decositesv1alpha1.FileSource{Paths: []string{p}}, mount)
+	files.keepExtensions = s.keepExtensions
+	return files.Retrieve(ctx)
+}
+
+// SourceType returns the source type identifier
+func (s *PVCSource) SourceType() string {
+	return SourceTypePVC
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+func TestPVCSourceRetrieve(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "decofiles", "my-site", "home.json"), `{"a":1}`)
+	writeFile(t, filepath.Join(dir, "decofiles", "my-site", "pages", "about.json"), `{"b":2}`)
+	writeFile(t, filepath.Join(dir, "decofiles", "other", "x.json"), `{"c":3}`)
+
+	src := NewPVCSource(&decositesv1alpha1.PVCSource{ClaimName: "decofiles", Path: "my-site"}, dir)
+	got, err := src.Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if want := `{"home":{"a":1},"pages/about":{"b":2}}`; got != want {
+		t.Errorf("Retrieve() = %s, want %s", got, want)
+	}
+
+	got, err = NewPVCSource(&decositesv1alpha1.PVCSource{ClaimName: "decofiles"}, dir).Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("Retrieve whole volume: %v", err)
+	}
+	if want := `{"my-site/home":{"a":1},"my-site/pages/about":{"b":2},"other/x":{"c":3}}`; got != want {
+		t.Errorf("Retrieve() = %s, want %s", got, want)
+	}
+}
+
+func TestPVCSourceRetrieve_StaysInClaim(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "other-claim", "secret.json"), `{"secret":true}`)
+	writeFile(t, filepath.Join(dir, "decofiles", "ok.json"), `{}`)
+	if err := os.Symlink(filepath.Join(dir, "other-claim", "secret.json"), filepath.Join(dir, "decofiles", "link.json")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+
+	for _, p := range []string{"../other-claim/secret.json", "link.json"} {
+		src := NewPVCSource(&decositesv1alpha1.PVCSource{ClaimName: "decofiles", Path: p}, dir)
+		if got, err := src.Retrieve(context.Background()); err == nil {
+			t.Errorf("Retrieve(%s) = %s, want error", p, got)
+		}
+	}
+
+	_, err := NewPVCSource(&decositesv1alpha1.PVCSource{ClaimName: "unmounted"}, dir).Retrieve(context.Background())
+	if err == nil || !strings.Contains(err.Error(), `claim "unmounted" is not mounted`) {
+		t.Errorf("Retrieve of an unmounted claim = %v, want not-mounted error", err)
+	}
+	if _, err := NewPVCSource(&decositesv1alpha1.PVCSource{ClaimName: "decofiles"}, "").Retrieve(context.Background()); err == nil {
+		t.Error("Retrieve without a pvc source directory succeeded, want error")
+	}
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// reconcileTracker remembers when each Decofile was last reconciled by this
+// replica. It is a prometheus.Collector so the exported age is computed at
+// scrape time and keeps growing while a Decofile is stuck.
+type reconcileTracker struct {
+	mu   sync.Mutex
+	at   map[types.NamespacedName]time.Time
+	last time.Time
+	desc *prometheus.Desc
+	now  func() time.Time
+}
+
+func newReconcileTracker() *reconcileTracker {
+	return &reconcileTracker{
+		at: map[types.NamespacedName]time.Time{},
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(metricsNamespace, "decofile", "seconds_since_last_reconcile"),
+			"Seconds since the Decofile was last reconciled by this replica.",
+			[]string{"namespace", "decofile"}, nil,
+		),
+		now: time.Now,
+	}
+}
+
+// decofileReconciles is fed by DecofileReconciler and registered in metrics.go.
+var decofileReconciles = newReconcileTracker()
+
+// Observe records a reconcile of key.
+func (t *reconcileTracker) Observe(key types.NamespacedName) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	t.at[key] = now
+	t.last = now
+}
+
+// Forget drops a deleted Decofile so its series disappears.
+func (t *reconcileTracker) Forget(key types.NamespacedName) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.at, key)
+}
+
+// Last returns the time of the most recent reconcile of any Decofile and how
+// many Decofiles are currently tracked.
+func (t *reconcileTracker) Last() (time.Time, int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.last, len(t.at)
+}
+
+// Describe implements prometheus.Collector.
+func (t *reconcileTracker) Describe(ch chan<- *prometheus.Desc) {
+	ch <- t.desc
+}
+
+// Collect implements prometheus.Collector.
+func (t *reconcileTracker) Collect(ch chan<- prometheus.Metric) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	for key, at := range t.at {
+		ch <- prometheus.MustNewConstMetric(t.desc, prometheus.GaugeValue,
+			now.Sub(at).Seconds(), key.Namespace, key.Name)
+	}
+}
+
+// DecofileReconcileReadyCheck fails readiness when this replica leads (elected
+// is closed), knows of at least one Decofile, and has not reconciled any of
+// them for longer than window. Replicas waiting for the lease always pass.
+// window should exceed the manager's sync period, which bounds how long a
+// healthy controller goes without reconciling.
+func DecofileReconcileReadyCheck(window time.Duration, elected <-chan struct{}) healthz.Checker {
+	return func(_ *http.Request) error {
+		select {
+		case <-elected:
+		default:
+			return nil
+		}
+		last, tracked := decofileReconciles.Last()
+		if tracked == 0 {
+			return nil
+		}
+		if age := decofileReconciles.now().Sub(last); age > window {
+			return fmt.Errorf("no Decofile reconciled in %s (window %s)", age.Round(time.Second), window)
+		}
+		return nil
+	}
+}
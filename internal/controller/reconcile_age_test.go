@@ -0,0 +1,89 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestReconcileTracker_Collect(t *testing.T) {
+	now := time.Unix(1000, 0)
+	tr := newReconcileTracker()
+	tr.now = func() time.Time { return now }
+
+	foo := types.NamespacedName{Namespace: testNamespace, Name: "foo"}
+	bar := types.NamespacedName{Namespace: testNamespace, Name: "bar"}
+	tr.Observe(foo)
+	tr.Observe(bar)
+	tr.Forget(bar)
+	now = now.Add(90 * time.Second)
+
+	ch := make(chan prometheus.Metric, 4)
+	tr.Collect(ch)
+	close(ch)
+	var got []*dto.Metric
+	for m := range ch {
+		var out dto.Metric
+		if err := m.Write(&out); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+		got = append(got, &out)
+	}
+	if len(got) != 1 {
+		t.Fatalf("Collect() emitted %d series, want 1", len(got))
+	}
+	if v := got[0].GetGauge().GetValue(); v != 90 {
+		t.Errorf("seconds since last reconcile = %v, want 90", v)
+	}
+	labels := map[string]string{}
+	for _, l := range got[0].GetLabel() {
+		labels[l.GetName()] = l.GetValue()
+	}
+	if labels["namespace"] != testNamespace || labels["decofile"] != "foo" {
+		t.Errorf("labels = %v", labels)
+	}
+}
+
+func TestDecofileReconcileReadyCheck(t *testing.T) {
+	saved := decofileReconciles
+	defer func() { decofileReconciles = saved }()
+	now := time.Unix(1000, 0)
+	decofileReconciles = newReconcileTracker()
+	decofileReconciles.now = func() time.Time { return now }
+
+	elected := make(chan struct{})
+	check := DecofileReconcileReadyCheck(time.Hour, elected)
+	decofileReconciles.Observe(types.NamespacedName{Namespace: testNamespace, Name: "foo"})
+	now = now.Add(2 * time.Hour)
+
+	if err := check(nil); err != nil {
+		t.Errorf("check before election = %v, want nil", err)
+	}
+	close(elected)
+	if err := check(nil); err == nil {
+		t.Error("check on a stale leader = nil, want error")
+	}
+	decofileReconciles.Observe(types.NamespacedName{Namespace: testNamespace, Name: "bar"})
+	if err := check(nil); err != nil {
+		t.Errorf("check after a reconcile = %v, want nil", err)
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+const (
+	condTypeReloadEndpointReachable = "ReloadEndpointReachable"
+	// reloadProbeTimeout bounds the probe request to a pod.
+	reloadProbeTimeout = 10 * time.Second
+	// reloadProbeRequeue re-probes a Decofile whose endpoint wasn't reachable
+	// yet, e.g. because the new Revision's pods were still starting.
+	reloadProbeRequeue = 2 * time.Minute
+)
+
+// ProbeReloadEndpoint sends a HEAD request to the reload endpoint of the
+// newest ready pod with deploymentId, without a payload, so content is not
+// reloaded. It returns the probed pod's name ("" when no pod is ready) and
+// the HTTP status it answered with.
+func (n *Notifier) ProbeReloadEndpoint(ctx context.Context, namespace, deploymentId string) (string, int, error) {
+	podList := &corev1.PodList{}
+	if err := n.Client.List(ctx, podList, client.InNamespace(namespace), client.MatchingLabels{deploymentIdLabelOrDefault(n.DeploymentIdLabel): deploymentId}); err != nil {
+		return "", 0, fmt.Errorf("failed to list pods for %s: %w", deploymentId, err)
+	}
+	var pod *corev1.Pod
+	for i := range podList.Items {
+		p := &podList.Items[i]
+		if isPodReady(p) && (pod == nil || pod.CreationTimestamp.Before(&p.CreationTimestamp)) {
+			pod = p
+		}
+	}
+	if pod == nil {
+		return "", 0, nil
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, reloadProbeTimeout)
+	defer cancel()
+	requestURL := podURL(n.Scheme, pod, n.reloadPath())
+	req, err := http.NewRequestWithContext(probeCtx, http.MethodHead, requestURL, nil)
+	if err != nil {
+		return pod.Name, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	if token := extractReloadToken(pod); token != "" && pod.Annotations[reloadAuthAnnotation] != reloadAuthNone {
+		req.Header.Set("Authorization", fmt.Sprintf("Token %s", token))
+	}
+	resp, err := n.HTTPClient.Do(req)
+	if err != nil {
+		return pod.Name, 0, err
+	}
+	_ = resp.Body.Close()
+	return pod.Name, resp.StatusCode, nil
+}
+
+// reloadProbeCondition probes the Decofile's pods and returns the
+// ReloadEndpointReachable condition. Any answer but 404 or a 5xx means the
+// runtime serves the reload route (405 and 401/403 included: the route is
+// there, even if HEAD or the token isn't accepted).
+func (r *DecofileReconciler) reloadProbeCondition(ctx context.Context, decofile *decositesv1alpha1.Decofile, deploymentId string) metav1.Condition {
+	cond := metav1.Condition{Type: condTypeReloadEndpointReachable, LastTransitionTime: metav1.Now()}
+	notifier := NewNotifier(r.Client, r.HTTPClient, r.Notify)
+	notifier.ReloadPath = decofile.Spec.ReloadPath
+	path := notifier.reloadPath()
+	pod, status, err := notifier.ProbeReloadEndpoint(ctx, decofile.Namespace, deploymentId)
+	switch {
+	case pod == "" && err == nil:
+		cond.Status, cond.Reason = metav1.ConditionUnknown, "NoReadyPods"
+		cond.Message = fmt.Sprintf("No ready pod with deploymentId %s to probe", deploymentId)
+	case err != nil:
+		cond.Status, cond.Reason = metav1.ConditionFalse, "Unreachable"
+		cond.Message = fmt.Sprintf("Probing %s on pod %s failed: %v", path, pod, err)
+	case status == http.StatusNotFound:
+		cond.Status, cond.Reason = metav1.ConditionFalse, "EndpointNotImplemented"
+		cond.Message = fmt.Sprintf("Pod %s answered 404 at %s; the runtime doesn't implement the reload contract", pod, path)
+	case status >= 500:
+		cond.Status, cond.Reason = metav1.ConditionFalse, "EndpointError"
+		cond.Message = fmt.Sprintf("Pod %s answered %d at %s", pod, status, path)
+	default:
+		cond.Status, cond.Reason = metav1.ConditionTrue, "EndpointReachable"
+		cond.Message = fmt.Sprintf("Pod %s answered %d at %s", pod, status, path)
+	}
+	return cond
+}
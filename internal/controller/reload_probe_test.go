@@ -0,0 +1,87 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReloadProbeCondition(t *testing.T) {
+	scheme := newOwnerTestScheme(t)
+	readyPod := func(t *testing.T, srv *httptest.Server, name string, age time.Duration) *corev1.Pod {
+		pod := makeNotifyPod(t, srv, "tok")
+		pod.Name = name
+		pod.Labels = map[string]string{DefaultDeploymentIdLabel: "dep-1"}
+		pod.CreationTimestamp = metav1.NewTime(time.Now().Add(-age))
+		pod.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}}
+		return pod
+	}
+
+	tests := []struct {
+		name       string
+		status     int
+		noPods     bool
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		{"route answers 200", http.StatusOK, false, metav1.ConditionTrue, "EndpointReachable"},
+		{"route rejects HEAD", http.StatusMethodNotAllowed, false, metav1.ConditionTrue, "EndpointReachable"},
+		{"route missing", http.StatusNotFound, false, metav1.ConditionFalse, "EndpointNotImplemented"},
+		{"route failing", http.StatusInternalServerError, false, metav1.ConditionFalse, "EndpointError"},
+		{"no ready pods", 0, true, metav1.ConditionUnknown, "NoReadyPods"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotMethod, gotPath, gotAuth string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotMethod, gotPath, gotAuth = r.Method, r.URL.Path, r.Header.Get("Authorization")
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+			unreachable := httptest.NewServer(http.NotFoundHandler())
+			unreachable.Close()
+
+			var objs []client.Object
+			if !tt.noPods {
+				// The older pod points at a closed server; the newest ready pod is probed
+				objs = append(objs, readyPod(t, unreachable, "site-old", time.Hour), readyPod(t, srv, "site-new", time.Minute))
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+			r := &DecofileReconciler{Client: c, Scheme: scheme, HTTPClient: srv.Client()}
+
+			cond := r.reloadProbeCondition(context.Background(), makeDecofile("site", "dep-1"), "dep-1")
+			if cond.Type != condTypeReloadEndpointReachable || cond.Status != tt.wantStatus || cond.Reason != tt.wantReason {
+				t.Fatalf("condition = %+v, want %s/%s", cond, tt.wantStatus, tt.wantReason)
+			}
+			if tt.noPods {
+				return
+			}
+			if gotMethod != http.MethodHead || gotPath != reloadEndpoint || gotAuth != "Token tok" {
+				t.Errorf("probe = %s %s (auth %q), want HEAD %s with the pod's token", gotMethod, gotPath, gotAuth, reloadEndpoint)
+			}
+		})
+	}
+}
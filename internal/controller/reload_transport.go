@@ -0,0 +1,131 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	corev1 "k8s.io/api/core/v1"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+// errReloadUnauthenticated and errReloadPermissionDenied are returned by a
+// ReloadTransport when the pod rejected the reload's credentials (HTTP 401
+// and 403, gRPC Unauthenticated and PermissionDenied). Neither is retried.
+var (
+	errReloadUnauthenticated  = errors.New("reload unauthenticated")
+	errReloadPermissionDenied = errors.New("reload permission denied")
+)
+
+// ReloadTransport delivers reloads to pods (spec.notifyTransport).
+type ReloadTransport interface {
+	// Encode builds the reload payload once, to be sent to every pod.
+	Encode(timestamp, decofileContent string) ([]byte, error)
+	// Send delivers payload to pod, authenticated with token unless it is
+	// empty. A rejected token wraps errReloadUnauthenticated or
+	// errReloadPermissionDenied; any other error is retried.
+	Send(ctx context.Context, pod *corev1.Pod, payload []byte, token string) error
+}
+
+// reloadTransport returns the transport selected by n.Transport.
+func (n *Notifier) reloadTransport() ReloadTransport {
+	switch n.Transport {
+	case decositesv1alpha1.NotifyTransportGRPC:
+		return &grpcReloadTransport{extraDeploymentId: n.ExtraDeploymentId}
+	case decositesv1alpha1.NotifyTransportSSE:
+		return &sseReloadTransport{stream: n.EventStream, extraDeploymentId: n.ExtraDeploymentId}
+	}
+	return &httpReloadTransport{client: n.HTTPClient, scheme: n.Scheme, method: n.ReloadMethod, path: n.reloadPath(),
+		extraDeploymentId: n.ExtraDeploymentId}
+}
+
+// reloadPath returns n.ReloadPath, defaulting to reloadEndpoint.
+func (n *Notifier) reloadPath() string {
+	if n.ReloadPath == "" {
+		return reloadEndpoint
+	}
+	return n.ReloadPath
+}
+
+// httpReloadTransport sends the reload as an HTTP request to
+// /.decofile/reload (or spec.reloadPath) on the pod's user port.
+type httpReloadTransport struct {
+	client *http.Client
+	// scheme is http or https (NotifyOptions.Scheme); empty means http.
+	scheme string
+	// method is spec.reloadMethod; empty means POST. GET carries no body.
+	method string
+	// path is the request path, spec.reloadPath or reloadEndpoint.
+	path string
+	// extraDeploymentId is sent as ?deploymentId= to pods mounting the
+	// Decofile as an extra.
+	extraDeploymentId string
+}
+
+func (t *httpReloadTransport) Encode(timestamp, decofileContent string) ([]byte, error) {
+	return reloadPayload(timestamp, decofileContent)
+}
+
+func (t *httpReloadTransport) Send(ctx context.Context, pod *corev1.Pod, payload []byte, token string) error {
+	method := t.method
+	if method == "" {
+		method = http.MethodPost
+	}
+	requestURL := podURL(t.scheme, pod, t.path)
+	if t.extraDeploymentId != "" {
+		requestURL += "?deploymentId=" + url.QueryEscape(t.extraDeploymentId)
+	}
+
+	var body io.Reader
+	if method != http.MethodGet {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Token %s", token))
+	}
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	// Close the body right away; the caller retries in a loop
+	_ = resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusUnauthorized:
+		return fmt.Errorf("%w: pod returned status %d", errReloadUnauthenticated, resp.StatusCode)
+	case resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("%w: pod returned status %d", errReloadPermissionDenied, resp.StatusCode)
+	default:
+		return fmt.Errorf("pod returned status %d", resp.StatusCode)
+	}
+}
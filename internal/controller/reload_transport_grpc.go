@@ -0,0 +1,131 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// reloadRPC is the full method name of the Reload RPC in
+	// docs/reload.proto.
+	reloadRPC = "/deco.decofile.v1.DecofileRuntime/Reload"
+	// reloadGRPCPortAnnotation on a pod (set on the Service's template) is
+	// the port its gRPC reload service listens on, when that isn't the
+	// user port.
+	reloadGRPCPortAnnotation = "deco.sites/reload-grpc-port"
+)
+
+// ReloadRequest field numbers in docs/reload.proto.
+const (
+	reloadFieldTimestamp    protowire.Number = 1
+	reloadFieldDecofile     protowire.Number = 2
+	reloadFieldDeploymentId protowire.Number = 3
+)
+
+// grpcReloadTransport calls DecofileRuntime.Reload on the pod. The request
+// is encoded by hand and passed through rawCodec, so the operator carries
+// no generated code for a three-field message.
+type grpcReloadTransport struct {
+	// extraDeploymentId fills ReloadRequest.deployment_id for pods
+	// mounting the Decofile as an extra.
+	extraDeploymentId string
+}
+
+func (t *grpcReloadTransport) Encode(timestamp, decofileContent string) ([]byte, error) {
+	b := make([]byte, 0, len(decofileContent)+len(timestamp)+len(t.extraDeploymentId)+16)
+	b = protowire.AppendTag(b, reloadFieldTimestamp, protowire.BytesType)
+	b = protowire.AppendString(b, timestamp)
+	b = protowire.AppendTag(b, reloadFieldDecofile, protowire.BytesType)
+	b = protowire.AppendString(b, decofileContent)
+	if t.extraDeploymentId != "" {
+		b = protowire.AppendTag(b, reloadFieldDeploymentId, protowire.BytesType)
+		b = protowire.AppendString(b, t.extraDeploymentId)
+	}
+	return b, nil
+}
+
+func (t *grpcReloadTransport) Send(ctx context.Context, pod *corev1.Pod, payload []byte, token string) error {
+	target := "passthrough:///" + net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(int(grpcReloadPort(pod))))
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return fmt.Errorf("failed to create gRPC client: %w", err)
+	}
+	defer conn.Close() //nolint:errcheck
+
+	ctx, cancel := context.WithTimeout(ctx, reloadTimeout)
+	defer cancel()
+	if token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", "Token "+token)
+	}
+	var reply []byte
+	err = conn.Invoke(ctx, reloadRPC, payload, &reply, grpc.ForceCodec(rawCodec{}))
+	switch status.Code(err) {
+	case codes.OK:
+		return nil
+	case codes.Unauthenticated:
+		return fmt.Errorf("%w: %v", errReloadUnauthenticated, err)
+	case codes.PermissionDenied:
+		return fmt.Errorf("%w: %v", errReloadPermissionDenied, err)
+	default:
+		return fmt.Errorf("reload RPC failed: %w", err)
+	}
+}
+
+// grpcReloadPort is the port of the pod's gRPC reload service: the
+// deco.sites/reload-grpc-port annotation, else the user port.
+func grpcReloadPort(pod *corev1.Pod) int32 {
+	if port, err := strconv.ParseInt(pod.Annotations[reloadGRPCPortAnnotation], 10, 32); err == nil && port > 0 && port < 65536 {
+		return int32(port)
+	}
+	return reloadPort(pod)
+}
+
+// rawCodec passes already-encoded protobuf messages through untouched. It
+// is named "proto" so requests carry the content type a protobuf server
+// expects, and is only ever forced per call, never registered.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v any) ([]byte, error) {
+	b, ok := v.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("rawCodec cannot marshal %T", v)
+	}
+	return b, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v any) error {
+	b, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("rawCodec cannot unmarshal into %T", v)
+	}
+	*b = append((*b)[:0], data...)
+	return nil
+}
+
+func (rawCodec) Name() string { return "proto" }
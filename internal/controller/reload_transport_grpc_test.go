@@ -0,0 +1,167 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+// reloadCall is a Reload RPC received by startReloadServer.
+type reloadCall struct {
+	method, authorization           string
+	timestamp, decofile, deployment string
+}
+
+// startReloadServer serves DecofileRuntime.Reload without generated code,
+// answering with reject (nil accepts). It returns a pod pointing at it.
+func startReloadServer(t *testing.T, token string, reject error) (*corev1.Pod, func() []reloadCall) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	var mu sync.Mutex
+	var calls []reloadCall
+	srv := grpc.NewServer(grpc.ForceServerCodec(rawCodec{}),
+		grpc.UnknownServiceHandler(func(_ any, stream grpc.ServerStream) error {
+			var req []byte
+			if err := stream.RecvMsg(&req); err != nil {
+				return err
+			}
+			call := reloadCall{}
+			call.method, _ = grpc.MethodFromServerStream(stream)
+			if md, ok := metadata.FromIncomingContext(stream.Context()); ok && len(md.Get("authorization")) > 0 {
+				call.authorization = md.Get("authorization")[0]
+			}
+			for len(req) > 0 {
+				num, typ, n := protowire.ConsumeTag(req)
+				if n < 0 || typ != protowire.BytesType {
+					return status.Error(codes.InvalidArgument, "bad request")
+				}
+				req = req[n:]
+				v, n := protowire.ConsumeString(req)
+				if n < 0 {
+					return status.Error(codes.InvalidArgument, "bad request")
+				}
+				req = req[n:]
+				switch num {
+				case reloadFieldTimestamp:
+					call.timestamp = v
+				case reloadFieldDecofile:
+					call.decofile = v
+				case reloadFieldDeploymentId:
+					call.deployment = v
+				}
+			}
+			mu.Lock()
+			calls = append(calls, call)
+			mu.Unlock()
+			if reject != nil {
+				return reject
+			}
+			return stream.SendMsg([]byte{})
+		}))
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	_, port, _ := net.SplitHostPort(lis.Addr().String())
+	container := corev1.Container{Name: appContainerName, Ports: []corev1.ContainerPort{{ContainerPort: 8000}}}
+	if token != "" {
+		container.Env = []corev1.EnvVar{{Name: reloadTokenEnvVar, Value: token}}
+	}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: testNamespace,
+			Annotations: map[string]string{reloadGRPCPortAnnotation: port}},
+		Spec:   corev1.PodSpec{Containers: []corev1.Container{container}},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "127.0.0.1"},
+	}
+	return pod, func() []reloadCall {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]reloadCall(nil), calls...)
+	}
+}
+
+func TestNotifyPod_GRPCTransport(t *testing.T) {
+	pod, calls := startReloadServer(t, "secret", nil)
+	n := &Notifier{Transport: decositesv1alpha1.NotifyTransportGRPC, ExtraDeploymentId: "shared"}
+	if err := n.NotifyPod(context.Background(), pod, "1700000000", `{"a":1}`); err != nil {
+		t.Fatalf("NotifyPod: %v", err)
+	}
+	got := calls()
+	want := reloadCall{method: reloadRPC, authorization: "Token secret",
+		timestamp: "1700000000", decofile: `{"a":1}`, deployment: "shared"}
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("calls = %+v, want [%+v]", got, want)
+	}
+}
+
+func TestNotifyPod_GRPCAuthFailuresAreNotRetried(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    codes.Code
+		token   string
+		wantErr error
+	}{
+		{"unauthenticated without token", codes.Unauthenticated, "", ErrMissingReloadToken},
+		{"unauthenticated with token", codes.Unauthenticated, "stale", ErrReloadAuthFailed},
+		{"permission denied", codes.PermissionDenied, "stale", ErrReloadAuthFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pod, calls := startReloadServer(t, tt.token, status.Error(tt.code, "no"))
+			n := &Notifier{Transport: decositesv1alpha1.NotifyTransportGRPC}
+			err := n.NotifyPod(context.Background(), pod, "1", `{}`)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("NotifyPod() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := len(calls()); got != 1 {
+				t.Errorf("Reload called %d times, want 1", got)
+			}
+		})
+	}
+}
+
+func TestGRPCReloadPort(t *testing.T) {
+	pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{
+		Name: appContainerName, Ports: []corev1.ContainerPort{{ContainerPort: 8000}},
+	}}}}
+	if got := grpcReloadPort(pod); got != 8000 {
+		t.Errorf("grpcReloadPort() = %d, want the user port 8000", got)
+	}
+	for value, want := range map[string]int32{"9000": 9000, "0": 8000, "x": 8000, strconv.Itoa(1 << 16): 8000} {
+		pod.Annotations = map[string]string{reloadGRPCPortAnnotation: value}
+		if got := grpcReloadPort(pod); got != want {
+			t.Errorf("grpcReloadPort(%q) = %d, want %d", value, got, want)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+const (
+	// streamEventReload carries a reload, with the same JSON body as the
+	// HTTP transport's POST plus deploymentId for extra mounts.
+	streamEventReload = "reload"
+	// streamEventCurrent is sent on connect, once per Decofile the pod
+	// uses, with the timestamp of its current content.
+	streamEventCurrent = "current"
+
+	// eventStreamRetry is the reconnection delay sent to subscribers.
+	eventStreamRetry = 5 * time.Second
+	// eventStreamHeartbeat keeps idle streams open through proxies.
+	eventStreamHeartbeat = 30 * time.Second
+	// eventStreamBuffer bounds the events queued for one stream; a pod
+	// further behind fails the reload, which the notifier retries.
+	eventStreamBuffer = 8
+)
+
+// errNoEventStream is returned by the sse transport for a pod with no open
+// event stream, e.g. one that is reconnecting. It is retried like any other
+// failed reload.
+var errNoEventStream = errors.New("pod has no open event stream")
+
+type streamEvent struct {
+	name string
+	data []byte
+}
+
+// EventStream serves Server-Sent Events to pods whose Decofiles use
+// spec.notifyTransport=sse. Instead of being sent reload requests, pods
+// subscribe to GET /events/{namespace}/{pod} with their reload token and the
+// notifier pushes each reload to their open streams. It only runs on the
+// leader, which is the one reconciling.
+type EventStream struct {
+	Client client.Reader
+	Addr   string
+	// DeploymentIdLabel is the pod label holding the deploymentId; empty
+	// means DefaultDeploymentIdLabel.
+	DeploymentIdLabel string
+
+	mu   sync.Mutex
+	subs map[types.NamespacedName]map[chan streamEvent]struct{}
+}
+
+// NewEventStream returns an EventStream listening on addr.
+func NewEventStream(c client.Reader, addr string) *EventStream {
+	return &EventStream{Client: c, Addr: addr}
+}
+
+// Handler returns the HTTP handler serving the event stream.
+func (s *EventStream) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /events/{namespace}/{pod}", s.subscribe)
+	return mux
+}
+
+func (s *EventStream) Start(ctx context.Context) error {
+	// No WriteTimeout: streams stay open for as long as the pod is connected
+	srv := &http.Server{
+		Addr:              s.Addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		IdleTimeout:       120 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		// Close rather than Shutdown: open streams never go idle. Pods
+		// reconnect to the next leader.
+		_ = srv.Close()
+	}()
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+func (s *EventStream) NeedLeaderElection() bool { return true }
+
+// subscribe authenticates the pod by the reload token in its Authorization
+// header ("Token <DECO_RELEASE_RELOAD_TOKEN>") and streams its events until
+// it disconnects. Events missed while disconnected are not replayed; the
+// current timestamps sent on connect let the runtime catch up instead.
+func (s *EventStream) subscribe(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	log := logf.FromContext(ctx)
+	key := types.NamespacedName{Namespace: req.PathValue("namespace"), Name: req.PathValue("pod")}
+
+	pod := &corev1.Pod{}
+	if err := s.Client.Get(ctx, key, pod); err != nil {
+		if !apierrors.IsNotFound(err) {
+			log.Error(err, "Failed to get pod for event stream", "pod", key)
+			http.Error(w, "failed to get pod", http.StatusInternalServerError)
+			return
+		}
+		// Answered like a bad token, so pod names can't be probed
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	token, ok := strings.CutPrefix(req.Header.Get("Authorization"), "Token ")
+	want := extractReloadToken(pod)
+	if !ok || want == "" || subtle.ConstantTimeCompare([]byte(token), []byte(want)) != 1 {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	// Registered before reading the current state, so a reload racing the
+	// connect is queued rather than lost
+	events := make(chan streamEvent, eventStreamBuffer)
+	s.add(key, events)
+	defer s.remove(key, events)
+	log.V(1).Info("Pod subscribed to event stream", "pod", key)
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", eventStreamRetry.Milliseconds()); err != nil {
+		return
+	}
+	for _, ev := range s.currentEvents(ctx, pod) {
+		if err := writeStreamEvent(w, ev); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventStreamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return
+		case ev := <-events:
+			err = writeStreamEvent(w, ev)
+		case <-heartbeat.C:
+			_, err = io.WriteString(w, ": keepalive\n\n")
+		}
+		if err != nil {
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// currentEvents returns a streamEventCurrent event for every sse Decofile
+// pod consumes whose ConfigMap exists.
+func (s *EventStream) currentEvents(ctx context.Context, pod *corev1.Pod) []streamEvent {
+	decofiles, err := listPodDecofiles(ctx, s.Client, pod, s.DeploymentIdLabel)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list Decofiles for event stream", "pod", pod.Name)
+		return nil
+	}
+	var events []streamEvent
+	for _, pd := range decofiles {
+		df := pd.decofile
+		if df.Spec.NotifyTransport != decositesv1alpha1.NotifyTransportSSE {
+			continue
+		}
+		cm := &corev1.ConfigMap{}
+		if err := s.Client.Get(ctx, client.ObjectKey{Namespace: df.Namespace, Name: df.ConfigMapName()}, cm); err != nil {
+			continue
+		}
+		timestamp := cm.Data[df.TimestampKeyOrDefault()]
+		if timestamp == "" {
+			continue
+		}
+		current := map[string]string{"timestamp": timestamp, "source": "operator"}
+		if pd.extra {
+			current["deploymentId"] = df.DeploymentIdOrName()
+		}
+		data, err := json.Marshal(current)
+		if err != nil {
+			continue
+		}
+		events = append(events, streamEvent{name: streamEventCurrent, data: data})
+	}
+	return events
+}
+
+// writeStreamEvent writes ev in the text/event-stream format. data is
+// compact JSON, so it always fits on a single data line.
+func writeStreamEvent(w io.Writer, ev streamEvent) error {
+	_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.name, ev.data)
+	return err
+}
+
+func (s *EventStream) add(pod types.NamespacedName, events chan streamEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.subs == nil {
+		s.subs = map[types.NamespacedName]map[chan streamEvent]struct{}{}
+	}
+	if s.subs[pod] == nil {
+		s.subs[pod] = map[chan streamEvent]struct{}{}
+	}
+	s.subs[pod][events] = struct{}{}
+}
+
+func (s *EventStream) remove(pod types.NamespacedName, events chan streamEvent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.subs[pod], events)
+	if len(s.subs[pod]) == 0 {
+		delete(s.subs, pod)
+	}
+}
+
+// publish queues ev on every open stream of pod. It fails when the pod has
+// none, or when all of them are too far behind.
+func (s *EventStream) publish(pod types.NamespacedName, ev streamEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subs := s.subs[pod]
+	if len(subs) == 0 {
+		return fmt.Errorf("%w: %s", errNoEventStream, pod.Name)
+	}
+	queued := false
+	for events := range subs {
+		select {
+		case events <- ev:
+			queued = true
+		default:
+		}
+	}
+	if !queued {
+		return fmt.Errorf("event stream of %s is full", pod.Name)
+	}
+	return nil
+}
+
+// sseReloadTransport pushes the reload to the pod's open event streams. The
+// pod presented its reload token when it subscribed, so Send ignores token.
+type sseReloadTransport struct {
+	stream *EventStream
+	// extraDeploymentId is added to the event as deploymentId for pods
+	// mounting the Decofile as an extra.
+	extraDeploymentId string
+}
+
+func (t *sseReloadTransport) Encode(timestamp, decofileContent string) ([]byte, error) {
+	payload := map[string]interface{}{
+		"timestamp": timestamp,
+		"source":    "operator",
+		"decofile":  json.RawMessage(decofileContent),
+	}
+	if t.extraDeploymentId != "" {
+		payload["deploymentId"] = t.extraDeploymentId
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	return data, nil
+}
+
+func (t *sseReloadTransport) Send(_ context.Context, pod *corev1.Pod, payload []byte, _ string) error {
+	if t.stream == nil {
+		return errEventStreamDisabled
+	}
+	return t.stream.publish(client.ObjectKeyFromObject(pod), streamEvent{name: streamEventReload, data: payload})
+}
+
+// errEventStreamDisabled is returned for notifyTransport sse when the
+// operator runs without --notify-events-bind-address.
+var errEventStreamDisabled = errors.New("notifyTransport sse requires the operator's event stream (--notify-events-bind-address)")
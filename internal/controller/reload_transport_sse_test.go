@@ -0,0 +1,142 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+// readStreamEvent returns the event and data fields of the next event on r,
+// skipping retry-only blocks and comments.
+func readStreamEvent(t *testing.T, r *bufio.Reader) (string, string) {
+	t.Helper()
+	var name, data string
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read event stream: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && name != "":
+			return name, data
+		case strings.HasPrefix(line, "event: "):
+			name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestEventStream(t *testing.T) {
+	scheme := newOwnerTestScheme(t)
+	podSrv := httptest.NewServer(http.NotFoundHandler())
+	defer podSrv.Close()
+	pod := makeNotifyPod(t, podSrv, "secret")
+	pod.Labels = map[string]string{DefaultDeploymentIdLabel: "foo"}
+	df := makeDecofile("foo", "")
+	df.Spec.NotifyTransport = decositesv1alpha1.NotifyTransportSSE
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: df.ConfigMapName(), Namespace: testNamespace},
+		Data:       map[string]string{df.TimestampKeyOrDefault(): "1700000000"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(pod, df, cm).Build()
+	stream := NewEventStream(c, "")
+	srv := httptest.NewServer(stream.Handler())
+	defer srv.Close()
+
+	subscribe := func(ctx context.Context, token string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/events/"+testNamespace+"/"+pod.Name, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Token "+token)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("subscribe: %v", err)
+		}
+		return resp
+	}
+
+	resp := subscribe(context.Background(), "wrong")
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("wrong token: status = %d, want 401", resp.StatusCode)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	resp = subscribe(ctx, "secret")
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("subscribe: status %d, content type %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	body := bufio.NewReader(resp.Body)
+	if name, data := readStreamEvent(t, body); name != streamEventCurrent || !strings.Contains(data, `"timestamp":"1700000000"`) {
+		t.Errorf("first event = %s %s, want the current timestamp", name, data)
+	}
+
+	transport := &sseReloadTransport{stream: stream}
+	payload, err := transport.Encode("1700000001", `{"v": 1}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := transport.Send(ctx, pod, payload, ""); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if name, data := readStreamEvent(t, body); name != streamEventReload || data != `{"decofile":{"v":1},"source":"operator","timestamp":"1700000001"}` {
+		t.Errorf("reload event = %s %s", name, data)
+	}
+
+	// Once the pod disconnects, reloads fail and are retried by the notifier
+	cancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		err := transport.Send(context.Background(), pod, payload, "")
+		if errors.Is(err, errNoEventStream) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Send after disconnect = %v, want errNoEventStream", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSSEReloadTransport_Disabled(t *testing.T) {
+	transport := &sseReloadTransport{}
+	err := transport.Send(context.Background(), &corev1.Pod{}, nil, "")
+	if !errors.Is(err, errEventStreamDisabled) {
+		t.Errorf("Send without a stream = %v, want errEventStreamDisabled", err)
+	}
+	if _, err := transport.Encode("1", `{}`); err != nil {
+		t.Errorf("Encode: %v", err)
+	}
+}
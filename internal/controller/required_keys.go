@@ -0,0 +1,67 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrMissingRequiredKeys is returned when rendered decofile content lacks
+// top-level keys listed in spec.requiredKeys.
+var ErrMissingRequiredKeys = errors.New("decofile content is missing required keys")
+
+// checkRequiredKeys fails when content, already checked to be valid JSON, is
+// not an object holding every key of required at its top level. Values are
+// skipped without being decoded.
+func checkRequiredKeys(content string, required []string) error {
+	if len(required) == 0 {
+		return nil
+	}
+	missing := make(map[string]bool, len(required))
+	for _, key := range required {
+		missing[key] = true
+	}
+
+	dec := json.NewDecoder(strings.NewReader(content))
+	if tok, err := dec.Token(); err == nil && tok == json.Delim('{') {
+		var skip json.RawMessage
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return fmt.Errorf("%w: %w", ErrInvalidJSON, err)
+			}
+			delete(missing, tok.(string))
+			if err := dec.Decode(&skip); err != nil {
+				return fmt.Errorf("%w: %w", ErrInvalidJSON, err)
+			}
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	var absent []string
+	for _, key := range required {
+		if missing[key] {
+			absent = append(absent, key)
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrMissingRequiredKeys, strings.Join(absent, ", "))
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCheckRequiredKeys(t *testing.T) {
+	cases := []struct {
+		name     string
+		content  string
+		required []string
+		wantErr  string
+	}{
+		{"none required", `{}`, nil, ""},
+		{"all present", `{"site.json":{"a":1},"pages":[1,2],"x":null}`, []string{"pages", "site.json"}, ""},
+		{"nested keys don't count", `{"site.json":{"pages":{}}}`, []string{"site.json", "pages"},
+			"decofile content is missing required keys: pages"},
+		{"missing listed in spec order", `{"b":1}`, []string{"c", "b", "a"},
+			"decofile content is missing required keys: c, a"},
+		{"not an object", `[{"site.json":1}]`, []string{"site.json"},
+			"decofile content is missing required keys: site.json"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkRequiredKeys(tc.content, tc.required)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("checkRequiredKeys() = %v, want nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrMissingRequiredKeys) || err.Error() != tc.wantErr {
+				t.Fatalf("checkRequiredKeys() = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
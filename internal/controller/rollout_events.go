@@ -0,0 +1,185 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+const (
+	// RolloutEventCompleted is the type of the event sent once a Decofile's
+	// new content reached its pods.
+	RolloutEventCompleted = "decofile.rollout.completed"
+
+	defaultRolloutEventAttempts = 3
+	defaultRolloutEventBackoff  = time.Second
+	// rolloutEventTimeout bounds the delivery of one event, retries included.
+	rolloutEventTimeout = time.Minute
+)
+
+// RolloutEvent is the JSON body POSTed to the rollout events webhook.
+type RolloutEvent struct {
+	Type         string `json:"type"`
+	Decofile     string `json:"decofile"`
+	Namespace    string `json:"namespace"`
+	DeploymentId string `json:"deploymentId"`
+	// Timestamp is the content timestamp pods were notified with
+	Timestamp string `json:"timestamp"`
+	// Commit is the GitHub commit rendered, for source=github
+	Commit          string `json:"commit,omitempty"`
+	RolloutStrategy string `json:"rolloutStrategy"`
+	// Pods counts the notified pods; nil with rolloutStrategy
+	// knative-revision, which starts new pods instead
+	Pods *NotifyStats `json:"pods,omitempty"`
+}
+
+// RolloutEventSink POSTs a RolloutEvent to an external webhook (e.g. release
+// tooling) when a Decofile rollout completes. A nil sink on the reconciler
+// disables it.
+type RolloutEventSink struct {
+	URL        string
+	HTTPClient *http.Client
+	// Attempts is the number of deliveries tried per event
+	// (0 = defaultRolloutEventAttempts).
+	Attempts int
+	// Backoff is the wait before the first retry, doubled for each further
+	// one (0 = defaultRolloutEventBackoff).
+	Backoff time.Duration
+}
+
+// Dispatch sends ev in the background, so a slow or unreachable webhook
+// never holds up the reconcile. Failures are logged and counted.
+func (s *RolloutEventSink) Dispatch(ctx context.Context, ev RolloutEvent) {
+	log := logf.FromContext(ctx)
+	go func() {
+		sendCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), rolloutEventTimeout)
+		defer cancel()
+		if err := s.Send(sendCtx, ev); err != nil {
+			rolloutEventsSent.WithLabelValues("failed").Inc()
+			log.Error(err, "Failed to send rollout event", "url", s.URL)
+			return
+		}
+		rolloutEventsSent.WithLabelValues("delivered").Inc()
+		log.V(1).Info("Sent rollout event", "url", s.URL)
+	}()
+}
+
+// Send POSTs ev, retrying on network errors, 5xx, 408 and 429 answers.
+func (s *RolloutEventSink) Send(ctx context.Context, ev RolloutEvent) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return fmt.Errorf("failed to marshal rollout event: %w", err)
+	}
+	attempts := s.Attempts
+	if attempts <= 0 {
+		attempts = defaultRolloutEventAttempts
+	}
+	backoff := s.Backoff
+	if backoff <= 0 {
+		backoff = defaultRolloutEventBackoff
+	}
+
+	for attempt := 1; ; attempt++ {
+		retryable, err := s.post(ctx, body)
+		if err == nil {
+			return nil
+		}
+		if !retryable || attempt >= attempts {
+			return fmt.Errorf("rollout event not delivered after %d attempt(s): %w", attempt, err)
+		}
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return fmt.Errorf("rollout event not delivered: %w", ctx.Err())
+		}
+		backoff *= 2
+	}
+}
+
+// post makes one delivery attempt, reporting whether a failure is worth
+// retrying.
+func (s *RolloutEventSink) post(ctx context.Context, body []byte) (retryable bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	_ = resp.Body.Close()
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode >= 500, resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests:
+		return true, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+}
+
+// sendRolloutEvent dispatches a RolloutEventCompleted event for decofile to
+// the rollout events webhook, if one is configured. pods is nil when the
+// rollout started a new Knative Revision instead of notifying pods.
+func (r *DecofileReconciler) sendRolloutEvent(ctx context.Context, decofile *decositesv1alpha1.Decofile, deploymentId, timestamp, commit string, pods *NotifyStats) {
+	if r.RolloutEvents == nil {
+		return
+	}
+	strategy := decofile.Spec.RolloutStrategy
+	if strategy == "" {
+		strategy = decositesv1alpha1.RolloutReload
+	}
+	r.RolloutEvents.Dispatch(ctx, RolloutEvent{
+		Type:            RolloutEventCompleted,
+		Decofile:        decofile.Name,
+		Namespace:       decofile.Namespace,
+		DeploymentId:    deploymentId,
+		Timestamp:       timestamp,
+		Commit:          commit,
+		RolloutStrategy: strategy,
+		Pods:            pods,
+	})
+}
+
+// renderedCommit returns the GitHub commit a source=github Decofile was
+// rendered at: the resolved SHA when known, else the tracked branch head,
+// else spec.github.commit.
+func renderedCommit(decofile *decositesv1alpha1.Decofile, sha string) string {
+	if decofile.Spec.Source != SourceTypeGitHub || decofile.Spec.GitHub == nil {
+		return ""
+	}
+	if sha != "" {
+		return sha
+	}
+	if head := decofile.Annotations[githubHeadAnnotation]; head != "" && trackedBranch(decofile) != "" {
+		return head
+	}
+	return decofile.Spec.GitHub.Commit
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRolloutEventSinkSend(t *testing.T) {
+	var calls atomic.Int32
+	var got RolloutEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch calls.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+				t.Errorf("decode event: %v", err)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+	sink := &RolloutEventSink{URL: srv.URL, HTTPClient: srv.Client(), Backoff: time.Millisecond}
+
+	ev := RolloutEvent{Type: RolloutEventCompleted, Decofile: "foo", Namespace: testNamespace, DeploymentId: "dep-1",
+		Timestamp: "1700000000", Commit: "abc123", RolloutStrategy: "reload", Pods: &NotifyStats{Notified: 3, Skipped: 1}}
+	if err := sink.Send(context.Background(), ev); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("webhook calls = %d, want 2 (a 502 is retried)", calls.Load())
+	}
+	if got.Decofile != "foo" || got.Commit != "abc123" || got.Pods == nil || got.Pods.Notified != 3 {
+		t.Errorf("received event = %+v", got)
+	}
+}
+
+func TestRolloutEventSinkSend_GivesUp(t *testing.T) {
+	for _, tc := range []struct {
+		status    int
+		wantCalls int32
+	}{
+		{http.StatusBadRequest, 1}, // the webhook rejected the event, retrying won't help
+		{http.StatusServiceUnavailable, 3},
+	} {
+		var calls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(tc.status)
+		}))
+		sink := &RolloutEventSink{URL: srv.URL, HTTPClient: srv.Client(), Attempts: 3, Backoff: time.Millisecond}
+		if err := sink.Send(context.Background(), RolloutEvent{Type: RolloutEventCompleted}); err == nil {
+			t.Errorf("status %d: Send succeeded, want error", tc.status)
+		}
+		if calls.Load() != tc.wantCalls {
+			t.Errorf("status %d: webhook calls = %d, want %d", tc.status, calls.Load(), tc.wantCalls)
+		}
+		srv.Close()
+	}
+}
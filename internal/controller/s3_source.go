@@ -0,0 +1,182 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+const (
+	// defaultS3SourceRegion is used when spec.s3.region is unset
+	defaultS3SourceRegion = "us-east-1"
+	// Secret keys holding the S3 source credentials, named after the AWS
+	// environment variables so the same Secret can be mounted with envFrom
+	s3AccessKeyIDKey     = "AWS_ACCESS_KEY_ID"
+	s3SecretAccessKeyKey = "AWS_SECRET_ACCESS_KEY"
+	s3SessionTokenKey    = "AWS_SESSION_TOKEN"
+)
+
+// S3Source handles retrieval of configuration data from the objects under a
+// key prefix of an S3 bucket
+type S3Source struct {
+	config    *decositesv1alpha1.S3Source
+	client    client.Client
+	namespace string
+	// keepExtensions disables stripping ".json" from keys
+	keepExtensions bool
+}
+
+// NewS3Source creates a new S3Source reading credentials from the Decofile's
+// namespace
+func NewS3Source(k8sClient client.Client, config *decositesv1alpha1.S3Source, namespace string) *S3Source {
+	return &S3Source{config: config, client: k8sClient, namespace: namespace}
+}
+
+// Retrieve lists the objects under the prefix and reads them into a single
+// JSON string
+func (s *S3Source) Retrieve(ctx context.Context) (string, error) {
+	log := logf.FromContext(ctx)
+	s3Client, err := s.newClient(ctx)
+	if err != nil {
+		return "", err
+	}
+	location := fmt.Sprintf("s3://%s/%s", s.config.Bucket, s.config.Prefix)
+
+	type entry struct{ key, name string }
+	var entries []entry
+	pages := s3.NewListObjectsV2Paginator(s3Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.config.Bucket),
+		Prefix: aws.String(s.config.Prefix),
+	})
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx)
+		if err != nil {
+			if noBucket := (*s3types.NoSuchBucket)(nil); errors.As(err, &noBucket) {
+				return "", fmt.Errorf("s3 bucket %q does not exist", s.config.Bucket)
+			}
+			return "", fmt.Errorf("failed to list %s: %w", location, err)
+		}
+		for _, obj := range page.Contents {
+			name := aws.ToString(obj.Key)
+			rel := strings.TrimPrefix(strings.TrimPrefix(name, s.config.Prefix), "/")
+			// Skip folder placeholder objects
+			if rel == "" || strings.HasSuffix(rel, "/") {
+				continue
+			}
+			entries = append(entries, entry{decofileKey(rel, s.keepExtensions), name})
+		}
+	}
+	if len(entries) == 0 {
+		return "", fmt.Errorf("no objects found under %s", location)
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].key < entries[j].key })
+
+	w := newJSONObjectWriter(0)
+	for i, e := range entries {
+		// "a.json" and "a" strip to the same key: the last one wins
+		if i+1 < len(entries) && entries[i+1].key == e.key {
+			continue
+		}
+		content, err := s.getObject(ctx, s3Client, e.name)
+		if err != nil {
+			return "", err
+		}
+		if !json.Valid(content) {
+			log.Info("Skipping object that is not valid JSON", "key", e.name)
+			continue
+		}
+		if err := w.WriteMember(e.key, content); err != nil {
+			return "", fmt.Errorf("failed to marshal objects to JSON: %w", err)
+		}
+	}
+
+	log.V(1).Info("Read s3 source", "location", location, "objects", len(entries), "minifiedBytesSaved", w.BytesSaved())
+	return w.String(), nil
+}
+
+// getObject reads one object's content
+func (s *S3Source) getObject(ctx context.Context, s3Client *s3.Client, key string) ([]byte, error) {
+	out, err := s3Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.config.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get s3://%s/%s: %w", s.config.Bucket, key, err)
+	}
+	defer out.Body.Close() //nolint:errcheck
+	content, err := io.ReadAll(out.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read s3://%s/%s: %w", s.config.Bucket, key, err)
+	}
+	return content, nil
+}
+
+// newClient builds an S3 client for the source's region and endpoint, with
+// the credentials of spec.s3.secret, or anonymous ones when it is unset
+func (s *S3Source) newClient(ctx context.Context) (*s3.Client, error) {
+	var provider aws.CredentialsProvider = aws.AnonymousCredentials{}
+	if s.config.Secret != "" {
+		key := types.NamespacedName{Name: s.config.Secret, Namespace: s.namespace}
+		secret := &corev1.Secret{}
+		if err := s.client.Get(ctx, key, secret); err != nil {
+			return nil, fmt.Errorf("failed to get secret %s: %w", key, err)
+		}
+		id, secretKey := string(secret.Data[s3AccessKeyIDKey]), string(secret.Data[s3SecretAccessKeyKey])
+		if id == "" || secretKey == "" {
+			return nil, fmt.Errorf("secret %s must contain '%s' and '%s' keys", key, s3AccessKeyIDKey, s3SecretAccessKeyKey)
+		}
+		provider = credentials.NewStaticCredentialsProvider(id, secretKey, string(secret.Data[s3SessionTokenKey]))
+	}
+
+	region := s.config.Region
+	if region == "" {
+		region = defaultS3SourceRegion
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(region), awsconfig.WithCredentialsProvider(provider))
+	if err != nil {
+		return nil, fmt.Errorf("load aws config for s3 source: %w", err)
+	}
+	return s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if s.config.Endpoint != "" {
+			o.BaseEndpoint = aws.String(s.config.Endpoint)
+			o.UsePathStyle = true
+		}
+	}), nil
+}
+
+// SourceType returns the source type identifier
+func (s *S3Source) SourceType() string {
+	return SourceTypeS3
+}
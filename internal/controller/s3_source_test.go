@@ -0,0 +1,140 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+// newFakeS3 serves ListObjectsV2 and GetObject for one path-style bucket
+func newFakeS3(t *testing.T, bucket string, objects map[string]string) (*httptest.Server, *[]string) {
+	t.Helper()
+	var auth []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = append(auth, r.Header.Get("Authorization"))
+		name, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+		if name != bucket {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<Error><Code>NoSuchBucket</Code><Message>The specified bucket does not exist</Message></Error>`)
+			return
+		}
+		if key == "" {
+			prefix := r.URL.Query().Get("prefix")
+			var b strings.Builder
+			fmt.Fprintf(&b, `<ListBucketResult><Name>%s</Name><Prefix>%s</Prefix><IsTruncated>false</IsTruncated>`, bucket, prefix)
+			for k := range objects {
+				if strings.HasPrefix(k, prefix) {
+					fmt.Fprintf(&b, `<Contents><Key>%s</Key><Size>%d</Size></Contents>`, k, len(objects[k]))
+				}
+			}
+			b.WriteString(`</ListBucketResult>`)
+			w.Header().Set("Content-Type", "application/xml")
+			fmt.Fprint(w, b.String())
+			return
+		}
+		body, ok := objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<Error><Code>NoSuchKey</Code></Error>`)
+			return
+		}
+		fmt.Fprint(w, body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &auth
+}
+
+func TestS3SourceRetrieve(t *testing.T) {
+	srv, auth := newFakeS3(t, "decofiles", map[string]string{
+		"sites/foo/home.json":        `{ "a": 1 }`,
+		"sites/foo/pages/about.json": `{"b":2}`,
+		"sites/foo/pages/":           ``,
+		"sites/foo/README.md":        `not json`,
+		"sites/bar/other.json":       `{"c":3}`,
+	})
+
+	src := NewS3Source(nil, &decositesv1alpha1.S3Source{Bucket: "decofiles", Prefix: "sites/foo/", Endpoint: srv.URL}, testNamespace)
+	got, err := src.Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if want := `{"home":{"a":1},"pages/about":{"b":2}}`; got != want {
+		t.Errorf("Retrieve() = %s, want %s", got, want)
+	}
+	for _, a := range *auth {
+		if a != "" {
+			t.Fatalf("anonymous request sent Authorization %q", a)
+		}
+	}
+}
+
+func TestS3SourceRetrieve_Credentials(t *testing.T) {
+	srv, auth := newFakeS3(t, "decofiles", map[string]string{"site.json": `{}`})
+	scheme := newOwnerTestScheme(t)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "s3-creds", Namespace: testNamespace},
+		Data: map[string][]byte{
+			s3AccessKeyIDKey:     []byte("AKIDEXAMPLE"),
+			s3SecretAccessKeyKey: []byte("secret"),
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+
+	src := NewS3Source(c, &decositesv1alpha1.S3Source{Bucket: "decofiles", Endpoint: srv.URL, Secret: "s3-creds"}, testNamespace)
+	if _, err := src.Retrieve(context.Background()); err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if len(*auth) == 0 || !strings.Contains((*auth)[0], "Credential=AKIDEXAMPLE/") {
+		t.Errorf("Authorization = %v, want a signature with the secret's access key", *auth)
+	}
+
+	src = NewS3Source(c, &decositesv1alpha1.S3Source{Bucket: "decofiles", Endpoint: srv.URL, Secret: "missing"}, testNamespace)
+	if _, err := src.Retrieve(context.Background()); err == nil {
+		t.Error("Retrieve with a missing secret succeeded, want error")
+	}
+}
+
+func TestS3SourceRetrieve_NotFound(t *testing.T) {
+	srv, _ := newFakeS3(t, "decofiles", map[string]string{"sites/foo/home.json": `{}`})
+
+	for _, tc := range []struct {
+		name    string
+		config  decositesv1alpha1.S3Source
+		wantErr string
+	}{
+		{"missing bucket", decositesv1alpha1.S3Source{Bucket: "nope", Endpoint: srv.URL}, `s3 bucket "nope" does not exist`},
+		{"empty prefix", decositesv1alpha1.S3Source{Bucket: "decofiles", Prefix: "sites/bar/", Endpoint: srv.URL}, "no objects found under s3://decofiles/sites/bar/"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewS3Source(nil, &tc.config, testNamespace).Retrieve(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Retrieve() error = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
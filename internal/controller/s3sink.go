@@ -118,15 +118,16 @@ func (r *DecofileReconciler) reconcileS3(ctx context.Context, req ctrl.Request,
 	deploymentId := decofile.DeploymentIdOrName()
 
 	// GitHub gate: if the commit is unchanged and we've delivered before, there's
-	// nothing to do — skip the (expensive) repo download entirely.
-	if decofile.Spec.Source == SourceTypeGitHub && decofile.Spec.GitHub != nil &&
-		decofile.Status.GitHubCommit == decofile.Spec.GitHub.Commit &&
-		decofile.Status.ContentHash != "" {
+	// nothing to do — skip the (expensive) repo download entirely. Layers
+	// may move independently of the base commit, so they are always fetched
+	// (the content hash still skips an unchanged upload).
+	if decofile.Spec.Source == SourceTypeGitHub && decofile.Spec.GitHub != nil && len(decofile.Spec.GitHub.Layers) == 0 &&
+		githubUpToDate(decofile) && decofile.Status.ContentHash != "" && decofile.Status.S3URL != "" {
 		log.V(1).Info("s3: github commit unchanged and already delivered, skipping")
 		return ctrl.Result{}, nil
 	}
 
-	source, err := NewSource(r.Client, decofile)
+	source, err := NewSource(r.Client, decofile, r.Sources)
 	if err != nil {
 		log.Error(err, "s3: failed to create source")
 		return ctrl.Result{}, err
@@ -136,11 +137,24 @@ func (r *DecofileReconciler) reconcileS3(ctx context.Context, req ctrl.Request,
 		log.Error(err, "s3: failed to retrieve source")
 		return ctrl.Result{}, err
 	}
+	jsonContent, err = r.applyTransforms(ctx, decofile, jsonContent)
+	if err != nil {
+		log.Error(err, "s3: failed to transform content")
+		return ctrl.Result{}, err
+	}
+	if err := r.checkContentLimits(jsonContent); err != nil {
+		return r.rejectContent(ctx, req, err)
+	}
+	if err := checkRequiredKeys(jsonContent, decofile.Spec.RequiredKeys); err != nil {
+		return r.rejectContent(ctx, req, err)
+	}
 
+	// The ConfigMap target records ContentHash too, so a Decofile switched
+	// to s3 (no S3URL yet) is uploaded even when its content is unchanged
 	hash := sha256hex(jsonContent)
-	changed := hash != decofile.Status.ContentHash
 	key := decofile.S3ObjectKey(r.S3.prefix)
 	url := r.S3.URLFor(key)
+	changed := hash != decofile.Status.ContentHash || decofile.Status.S3URL != url
 
 	if changed {
 		if err := r.S3.Upload(ctx, key, jsonContent); err != nil {
@@ -156,17 +170,29 @@ func (r *DecofileReconciler) reconcileS3(ctx context.Context, req ctrl.Request,
 	// the mounted/URL source is only the cold-start read).
 	podsNotified := true
 	var notifyErr string
-	if changed {
+	if changed && decofile.Spec.RolloutStrategy == decositesv1alpha1.RolloutKnativeRevision {
+		if err := r.rolloutKnativeServices(ctx, decofile.Namespace, deploymentId, hash); err != nil {
+			log.Error(err, "s3: failed to roll out a new Knative Revision", "deploymentId", deploymentId)
+			podsNotified = false
+			notifyErr = err.Error()
+		} else {
+			r.sendRolloutEvent(ctx, decofile, deploymentId, hash, renderedCommit(decofile, ""), nil)
+		}
+	} else if changed {
 		ts := fmt.Sprintf("%d", time.Now().Unix())
-		notifier := NewNotifier(r.Client, r.HTTPClient)
-		if err := notifier.NotifyPodsForDecofile(ctx, decofile.Namespace, deploymentId, ts, jsonContent); err != nil {
+		var stats NotifyStats
+		if err := r.notifyPods(ctx, decofile, deploymentId, ts, jsonContent, &stats); err != nil {
 			log.Error(err, "s3: failed to notify pods", "deploymentId", deploymentId)
 			podsNotified = false
 			notifyErr = err.Error()
 		} else {
 			log.Info("s3: notified pods", "deploymentId", deploymentId)
+			r.sendRolloutEvent(ctx, decofile, deploymentId, ts, renderedCommit(decofile, ""), &stats)
 		}
 	}
+	if changed {
+		r.purgeCache(ctx, decofile, deploymentId, hash, url)
+	}
 
 	// Update status on the freshest object to avoid conflicts.
 	fresh := &decositesv1alpha1.Decofile{}
@@ -176,10 +202,21 @@ func (r *DecofileReconciler) reconcileS3(ctx context.Context, req ctrl.Request,
 	}
 	fresh.Status.LastUpdated = metav1.Time{Time: time.Now()}
 	fresh.Status.SourceType = source.SourceType()
-	fresh.Status.ContentHash = hash
+	if cs, ok := source.(*CompositeSource); ok {
+		fresh.Status.CompositeSources = cs.Contributions()
+	}
+	recordContentHash(fresh, hash)
 	fresh.Status.S3URL = url
+	if changed {
+		fresh.Status.Revision++
+	}
 	if fresh.Spec.Source == SourceTypeGitHub && fresh.Spec.GitHub != nil {
-		fresh.Status.GitHubCommit = fresh.Spec.GitHub.Commit
+		fresh.Status.GitHubRef = decofile.Spec.GitHub.Commit
+		fresh.Status.GitHubCommit = renderedCommit(decofile, "")
+		fresh.Status.GitHubHead = decofile.Annotations[githubHeadAnnotation]
+		if gs, ok := source.(*GitHubSource); ok {
+			fresh.Status.GitHubLayers = gs.Layers()
+		}
 	}
 	updateCondition(fresh, metav1.Condition{
 		Type:               "Ready",
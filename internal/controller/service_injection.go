@@ -0,0 +1,234 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+// Service annotations and template names set by the Service webhook
+// (internal/webhook/v1), which the injection check looks for.
+const (
+	decofileInjectAnnot       = "deco.sites/decofile-inject"
+	decofileInjectBypassAnnot = "deco.sites/decofile-inject-bypass"
+	decofileInjectModeAnnot   = "deco.sites/decofile-inject-mode"
+	decoReleaseEnvVar         = "DECO_RELEASE"
+	decofileVolumeName        = "decofile-config"
+	injectModeSidecar         = "sidecar"
+
+	// injectionRepairedAnnotation records the Service generation the last
+	// repair was attempted at, so a Service the webhook leaves uninjected
+	// (e.g. while it is not registered) is patched once per spec change
+	// instead of on every event.
+	injectionRepairedAnnotation = "deco.sites/decofile-injection-repaired"
+)
+
+// ServiceInjectionReconciler keeps the Decofile injection of Knative Services
+// converged: when a Service annotated deco.sites/decofile-inject lacks the
+// volume, mount or env vars the Service webhook injects (removed by another
+// controller or a manual edit, or admitted before its Decofile existed), it
+// patches an annotation on the Service, whose admission re-runs the webhook.
+// Enabled with --repair-service-injection, as it mutates Services.
+type ServiceInjectionReconciler struct {
+	client.Client
+	// DeploymentIdLabel is the Service label holding the deploymentId, as
+	// the webhook reads it; empty means DefaultDeploymentIdLabel.
+	DeploymentIdLabel string
+}
+
+// +kubebuilder:rbac:groups=serving.knative.dev,resources=services,verbs=get;list;watch;patch
+
+// Reconcile re-injects a single Service when its injection drifted.
+func (r *ServiceInjectionReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	svc := &servingv1.Service{}
+	if err := r.Get(ctx, req.NamespacedName, svc); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+	if !injectionRequested(svc) || svc.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+
+	label := deploymentIdLabelOrDefault(r.DeploymentIdLabel)
+	deploymentId := svc.Labels[label]
+	if deploymentId == "" {
+		deploymentId = svc.Spec.Template.Labels[label]
+	}
+	decofile, err := r.findDecofile(ctx, svc.Namespace, deploymentId)
+	if err != nil || decofile == nil {
+		// Without a Decofile the webhook has nothing to inject either
+		return ctrl.Result{}, err
+	}
+
+	missing := injectionDrift(svc, decofile, label)
+	if missing == "" {
+		return ctrl.Result{}, nil
+	}
+	generation := strconv.FormatInt(svc.Generation, 10)
+	if svc.Annotations[injectionRepairedAnnotation] == generation {
+		log.V(1).Info("Service injection still drifted after a repair at this generation, leaving it",
+			"service", svc.Name, "missing", missing)
+		return ctrl.Result{}, nil
+	}
+
+	patch := client.MergeFrom(svc.DeepCopy())
+	svc.Annotations[injectionRepairedAnnotation] = generation
+	if err := r.Patch(ctx, svc, patch); err != nil {
+		return ctrl.Result{}, fmt.Errorf("patch service %s: %w", svc.Name, err)
+	}
+	serviceInjectionRepairs.Inc()
+	log.Info("Repaired drifted Decofile injection", "service", svc.Name, "decofile", decofile.Name, "missing", missing)
+	return ctrl.Result{}, nil
+}
+
+// injectionRequested reports whether the Service webhook injects svc.
+func injectionRequested(svc *servingv1.Service) bool {
+	return svc.Annotations[decofileInjectAnnot] == "true" && svc.Annotations[decofileInjectBypassAnnot] != "true"
+}
+
+// findDecofile returns the Decofile with deploymentId in namespace, or nil.
+func (r *ServiceInjectionReconciler) findDecofile(ctx context.Context, namespace, deploymentId string) (*decositesv1alpha1.Decofile, error) {
+	if deploymentId == "" {
+		return nil, nil
+	}
+	decofiles := &decositesv1alpha1.DecofileList{}
+	if err := r.List(ctx, decofiles, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("list decofiles: %w", err)
+	}
+	for i := range decofiles.Items {
+		if decofiles.Items[i].DeploymentIdOrName() == deploymentId {
+			return &decofiles.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// injectionDrift names the first piece of the webhook's injection of
+// decofile that svc's pod template lacks, or returns "" when it is complete.
+// label is the deploymentId label key the webhook stamps.
+func injectionDrift(svc *servingv1.Service, decofile *decositesv1alpha1.Decofile, label string) string {
+	podSpec := &svc.Spec.Template.Spec.PodSpec
+	if len(podSpec.Containers) == 0 {
+		return ""
+	}
+	idx := 0
+	for i, c := range podSpec.Containers {
+		if c.Name == appContainerName {
+			idx = i
+			break
+		}
+	}
+	app := &podSpec.Containers[idx]
+	for _, env := range []string{decoReleaseEnvVar, reloadTokenEnvVar} {
+		if !slices.ContainsFunc(app.Env, func(e corev1.EnvVar) bool { return e.Name == env }) {
+			return "env " + env
+		}
+	}
+	if svc.Spec.Template.Labels[label] == "" {
+		return "label " + label
+	}
+	if decofile.Spec.Target == decositesv1alpha1.TargetS3 {
+		// Read over HTTP, nothing is mounted
+		return ""
+	}
+
+	if !slices.ContainsFunc(podSpec.Volumes, func(v corev1.Volume) bool {
+		return v.Name == decofileVolumeName && v.ConfigMap != nil && v.ConfigMap.Name == decofile.ConfigMapName()
+	}) {
+		return "volume " + decofileVolumeName
+	}
+	mounter := app
+	if svc.Annotations[decofileInjectModeAnnot] == injectModeSidecar {
+		i := slices.IndexFunc(podSpec.Containers, func(c corev1.Container) bool { return c.Name == sidecarContainerName })
+		if i < 0 {
+			return "container " + sidecarContainerName
+		}
+		mounter = &podSpec.Containers[i]
+	}
+	if !slices.ContainsFunc(mounter.VolumeMounts, func(m corev1.VolumeMount) bool { return m.Name == decofileVolumeName }) {
+		return "volume mount " + decofileVolumeName
+	}
+	return ""
+}
+
+// mapDecofileToServices enqueues the injected Services of a Decofile, so
+// Services admitted before it existed are injected once it does.
+func (r *ServiceInjectionReconciler) mapDecofileToServices(ctx context.Context, obj client.Object) []reconcile.Request {
+	decofile, ok := obj.(*decositesv1alpha1.Decofile)
+	if !ok {
+		return nil
+	}
+	svcs := &servingv1.ServiceList{}
+	if err := r.List(ctx, svcs, client.InNamespace(decofile.Namespace)); err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to list Services for Decofile", "decofile", decofile.Name)
+		return nil
+	}
+	label := deploymentIdLabelOrDefault(r.DeploymentIdLabel)
+	var requests []reconcile.Request
+	for i := range svcs.Items {
+		svc := &svcs.Items[i]
+		id := svc.Labels[label]
+		if id == "" {
+			id = svc.Spec.Template.Labels[label]
+		}
+		if injectionRequested(svc) && id == decofile.DeploymentIdOrName() {
+			requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: svc.Name, Namespace: svc.Namespace}})
+		}
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *ServiceInjectionReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	injected := predicate.NewPredicateFuncs(func(obj client.Object) bool {
+		svc, ok := obj.(*servingv1.Service)
+		return ok && injectionRequested(svc)
+	})
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&servingv1.Service{}, builder.WithPredicates(injected, predicate.GenerationChangedPredicate{})).
+		Watches(
+			&decositesv1alpha1.Decofile{},
+			handler.EnqueueRequestsFromMapFunc(r.mapDecofileToServices),
+			builder.WithPredicates(predicate.Funcs{
+				CreateFunc:  func(_ event.CreateEvent) bool { return true },
+				UpdateFunc:  func(_ event.UpdateEvent) bool { return false },
+				DeleteFunc:  func(_ event.DeleteEvent) bool { return false },
+				GenericFunc: func(_ event.GenericEvent) bool { return false },
+			}),
+		).
+		Named("service-injection").
+		Complete(r)
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	servingv1 "knative.dev/serving/pkg/apis/serving/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// makeInjectedService returns a Service as the webhook leaves it after
+// mounting the ConfigMap of the Decofile with deploymentId.
+func makeInjectedService(name, deploymentId string) *servingv1.Service {
+	svc := &servingv1.Service{ObjectMeta: metav1.ObjectMeta{
+		Name:        name,
+		Namespace:   testNamespace,
+		Labels:      map[string]string{DefaultDeploymentIdLabel: deploymentId},
+		Annotations: map[string]string{decofileInjectAnnot: "true"},
+	}}
+	svc.Spec.Template.Labels = map[string]string{DefaultDeploymentIdLabel: deploymentId}
+	svc.Spec.Template.Spec.Volumes = []corev1.Volume{{
+		Name: decofileVolumeName,
+		VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "decofile-" + deploymentId},
+		}},
+	}}
+	svc.Spec.Template.Spec.Containers = []corev1.Container{{
+		Name: appContainerName,
+		Env: []corev1.EnvVar{
+			{Name: decoReleaseEnvVar, Value: "file:///app/decofile/decofile.bin"},
+			{Name: reloadTokenEnvVar, Value: "token"},
+		},
+		VolumeMounts: []corev1.VolumeMount{{Name: decofileVolumeName, MountPath: "/app/decofile"}},
+	}}
+	return svc
+}
+
+func TestInjectionDrift(t *testing.T) {
+	df := makeDecofile("site", "")
+	cases := []struct {
+		name  string
+		drift func(*servingv1.Service)
+		want  string
+	}{
+		{"injected", func(*servingv1.Service) {}, ""},
+		{"env removed", func(s *servingv1.Service) { s.Spec.Template.Spec.Containers[0].Env = nil }, "env " + decoReleaseEnvVar},
+		{"volume removed", func(s *servingv1.Service) { s.Spec.Template.Spec.Volumes = nil }, "volume " + decofileVolumeName},
+		{"mount removed", func(s *servingv1.Service) { s.Spec.Template.Spec.Containers[0].VolumeMounts = nil }, "volume mount " + decofileVolumeName},
+		{"sidecar removed", func(s *servingv1.Service) { s.Annotations[decofileInjectModeAnnot] = injectModeSidecar }, "container " + sidecarContainerName},
+	}
+	for _, tc := range cases {
+		svc := makeInjectedService("site", "site")
+		tc.drift(svc)
+		if got := injectionDrift(svc, df, DefaultDeploymentIdLabel); got != tc.want {
+			t.Errorf("%s: injectionDrift() = %q, want %q", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestServiceInjectionReconciler_RepairsOncePerGeneration(t *testing.T) {
+	ctx := context.Background()
+	scheme := newOwnerTestScheme(t)
+	svc := makeInjectedService("site", "site")
+	svc.Spec.Template.Spec.Volumes = nil
+	uninjected := makeInjectedService("orphan", "missing")
+	uninjected.Spec.Template.Spec.Volumes = nil
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(makeDecofile("site", ""), svc, uninjected).Build()
+	r := &ServiceInjectionReconciler{Client: c}
+
+	for _, name := range []string{"site", "orphan"} {
+		if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKey{Name: name, Namespace: testNamespace}}); err != nil {
+			t.Fatalf("Reconcile(%s): %v", name, err)
+		}
+	}
+	got := &servingv1.Service{}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(svc), got); err != nil {
+		t.Fatalf("get service: %v", err)
+	}
+	if want := strconv.FormatInt(got.Generation, 10); got.Annotations[injectionRepairedAnnotation] != want {
+		t.Fatalf("%s = %q, want the repaired generation %s", injectionRepairedAnnotation, got.Annotations[injectionRepairedAnnotation], want)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(uninjected), got); err != nil {
+		t.Fatalf("get service: %v", err)
+	}
+	if _, ok := got.Annotations[injectionRepairedAnnotation]; ok {
+		t.Error("Service without a Decofile was patched")
+	}
+
+	// No webhook re-injects here, so the next reconcile must leave it alone
+	if err := c.Get(ctx, client.ObjectKeyFromObject(svc), got); err != nil {
+		t.Fatalf("get service: %v", err)
+	}
+	before := got.ResourceVersion
+	if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(svc)}); err != nil {
+		t.Fatalf("Reconcile: %v", err)
+	}
+	if err := c.Get(ctx, client.ObjectKeyFromObject(svc), got); err != nil {
+		t.Fatalf("get service: %v", err)
+	}
+	if got.ResourceVersion != before {
+		t.Error("Service patched again at the same generation")
+	}
+}
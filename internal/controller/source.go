@@ -19,6 +19,7 @@ package controller
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
@@ -26,34 +27,113 @@ import (
 )
 
 const (
-	SourceTypeInline = "inline"
-	SourceTypeGitHub = "github"
+	SourceTypeInline    = "inline"
+	SourceTypeGitHub    = "github"
+	SourceTypeFile      = "file"
+	SourceTypeS3        = "s3"
+	SourceTypePVC       = "pvc"
+	SourceTypeComposite = "composite"
 )
 
 // DecofileSource is an interface for retrieving configuration data from different sources
 type DecofileSource interface {
 	// Retrieve fetches the configuration data and returns it as a single JSON string
-	// The JSON contains all files in the format: {"filename.json": {...}, ...}
+	// The JSON contains all files in the format: {"filename": {...}, ...}
+	// (".json" is kept in the key when spec.stripExtensions is false)
 	Retrieve(ctx context.Context) (string, error)
 	// SourceType returns the type of source (inline, github, etc.)
 	SourceType() string
 }
 
+// Every source kind implements DecofileSource
+var (
+	_ DecofileSource = (*InlineSource)(nil)
+	_ DecofileSource = (*GitHubSource)(nil)
+	_ DecofileSource = (*FileSource)(nil)
+	_ DecofileSource = (*S3Source)(nil)
+	_ DecofileSource = (*PVCSource)(nil)
+	_ DecofileSource = (*CompositeSource)(nil)
+)
+
+// SourceOptions are the operator-wide settings of Decofile sources, set from
+// flags in main.
+type SourceOptions struct {
+	// FileDir is the directory source=file Decofiles read from
+	// (--decofile-file-source-dir). Empty disables the file source.
+	FileDir string
+	// PVCDir is the directory source=pvc claims are mounted under, each at
+	// <dir>/<claimName> (--decofile-pvc-source-dir). Empty disables the pvc
+	// source.
+	PVCDir string
+	// DefaultGitHubSecret names the Secret whose "token" key authenticates
+	// GitHub sources without spec.github.secret: "namespace/name", or a bare
+	// name looked up in the Decofile's namespace (--github-default-secret).
+	// Empty falls back to the GITHUB_TOKEN env var.
+	DefaultGitHubSecret string
+	// GitHubDiskExtractThreshold is the archive size in bytes above which
+	// GitHub sources are extracted to a temp directory instead of memory
+	// (--github-disk-extract-threshold). 0 keeps everything in memory.
+	GitHubDiskExtractThreshold int64
+}
+
 // NewSource creates the appropriate DecofileSource implementation based on the Decofile spec
-func NewSource(k8sClient client.Client, decofile *decositesv1alpha1.Decofile) (DecofileSource, error) {
-	switch decofile.Spec.Source {
-	case SourceTypeInline:
-		if decofile.Spec.Inline == nil {
-			return nil, fmt.Errorf("inline source specified but no inline data provided")
+func NewSource(k8sClient client.Client, decofile *decositesv1alpha1.Decofile, opts SourceOptions) (DecofileSource, error) {
+	if err := decofile.Spec.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid decofile spec: %w", err)
+	}
+	keepExtensions := !decofile.Spec.ShouldStripExtensions()
+	if decofile.Spec.Source == SourceTypeComposite {
+		src := &CompositeSource{}
+		for i := range decofile.Spec.Composite.Sources {
+			sub, err := newSingleSource(k8sClient, decofile.Spec.Composite.Sources[i].Spec(), decofile.Namespace, keepExtensions, opts)
+			if err != nil {
+				return nil, err
+			}
+			src.sources = append(src.sources, sub)
 		}
-		return NewInlineSource(decofile.Spec.Inline), nil
+		return src, nil
+	}
+	return newSingleSource(k8sClient, &decofile.Spec, decofile.Namespace, keepExtensions, opts)
+}
+
+// newSingleSource creates the DecofileSource for a spec with one source,
+// either a Decofile's or an entry of a composite one.
+func newSingleSource(k8sClient client.Client, spec *decositesv1alpha1.DecofileSpec, namespace string, keepExtensions bool, opts SourceOptions) (DecofileSource, error) {
+	switch spec.Source {
+	case SourceTypeInline:
+		src := NewInlineSource(spec.Inline)
+		src.keepExtensions = keepExtensions
+		src.client, src.namespace = k8sClient, namespace
+		return src, nil
 	case SourceTypeGitHub:
-		if decofile.Spec.GitHub == nil {
-			return nil, fmt.Errorf("github source specified but no github config provided")
-		}
-		return NewGitHubSource(k8sClient, decofile.Spec.GitHub, decofile.Namespace), nil
+		src := NewGitHubSource(k8sClient, spec.GitHub, namespace)
+		src.keepExtensions = keepExtensions
+		src.defaultSecret = opts.DefaultGitHubSecret
+		src.diskExtractThreshold = opts.GitHubDiskExtractThreshold
+		return src, nil
+	case SourceTypeFile:
+		src := NewFileSource(spec.File, opts.FileDir)
+		src.keepExtensions = keepExtensions
+		return src, nil
+	case SourceTypeS3:
+		src := NewS3Source(k8sClient, spec.S3, namespace)
+		src.keepExtensions = keepExtensions
+		return src, nil
+	case SourceTypePVC:
+		src := NewPVCSource(spec.PVC, opts.PVCDir)
+		src.keepExtensions = keepExtensions
+		return src, nil
 	default:
-		return nil, fmt.Errorf("unknown source type: %s (must be '%s' or '%s')",
-			decofile.Spec.Source, SourceTypeInline, SourceTypeGitHub)
+		return nil, fmt.Errorf("unknown source type: %s (must be '%s', '%s', '%s', '%s', '%s' or '%s')",
+			spec.Source, SourceTypeInline, SourceTypeGitHub, SourceTypeFile, SourceTypeS3, SourceTypePVC, SourceTypeComposite)
+	}
+}
+
+// decofileKey returns the key a file is stored under in the assembled JSON
+// object: its name without the ".json" extension, unless keepExtension is set.
+func decofileKey(name string, keepExtension bool) string {
+	if keepExtension {
+		return name
 	}
+	return strings.TrimSuffix(name, ".json")
 }
@@ -0,0 +1,158 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+// Built-in transformer names (DecofileSpec.Transforms).
+const (
+	TransformMinify         = "minify"
+	TransformSubstitute     = "substitute"
+	TransformValidateSchema = "validate-schema"
+)
+
+// Transformer post-processes retrieved decofile content before it is stored.
+// Transformers are chained in the order listed in spec.transforms.
+type Transformer interface {
+	Transform(ctx context.Context, content string) (string, error)
+}
+
+// TransformerFunc adapts a function to the Transformer interface.
+type TransformerFunc func(ctx context.Context, content string) (string, error)
+
+// Transform calls f(ctx, content).
+func (f TransformerFunc) Transform(ctx context.Context, content string) (string, error) {
+	return f(ctx, content)
+}
+
+// TransformerRegistry maps spec.transforms names to Transformers.
+type TransformerRegistry struct {
+	impls map[string]Transformer
+}
+
+func NewTransformerRegistry() *TransformerRegistry {
+	return &TransformerRegistry{impls: map[string]Transformer{}}
+}
+
+// NewDefaultTransformerRegistry returns a registry with the built-in
+// minify, substitute and validate-schema transformers.
+func NewDefaultTransformerRegistry() *TransformerRegistry {
+	r := NewTransformerRegistry()
+	r.Register(TransformMinify, TransformerFunc(minifyTransform))
+	r.Register(TransformSubstitute, TransformerFunc(substituteTransform))
+	r.Register(TransformValidateSchema, TransformerFunc(validateSchemaTransform))
+	return r
+}
+
+func (r *TransformerRegistry) Register(name string, t Transformer) {
+	r.impls[name] = t
+}
+
+// For returns the Transformer registered under name.
+func (r *TransformerRegistry) For(name string) (Transformer, bool) {
+	t, ok := r.impls[name]
+	return t, ok
+}
+
+// Apply runs the named transformers in order over content. The Decofile is
+// made available to transformers through the context (see decofileFromContext).
+func (r *TransformerRegistry) Apply(ctx context.Context, decofile *decositesv1alpha1.Decofile, names []string, content string) (string, error) {
+	ctx = context.WithValue(ctx, decofileContextKey{}, decofile)
+	for _, name := range names {
+		t, ok := r.For(name)
+		if !ok {
+			return "", fmt.Errorf("unknown transform %q", name)
+		}
+		out, err := t.Transform(ctx, content)
+		if err != nil {
+			return "", fmt.Errorf("transform %q: %w", name, err)
+		}
+		content = out
+	}
+	return content, nil
+}
+
+type decofileContextKey struct{}
+
+// decofileFromContext returns the Decofile being transformed, if any.
+func decofileFromContext(ctx context.Context) *decositesv1alpha1.Decofile {
+	df, _ := ctx.Value(decofileContextKey{}).(*decositesv1alpha1.Decofile)
+	return df
+}
+
+// minifyTransform strips insignificant whitespace from the whole document.
+func minifyTransform(_ context.Context, content string) (string, error) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(content)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// substituteTransform expands ${DECOFILE_NAME}, ${DECOFILE_NAMESPACE},
+// ${DECOFILE_DEPLOYMENT_ID} and ${DECOFILE_COMMIT} placeholders. Values are
+// derived from the spec (never the clock) so unchanged input keeps producing
+// unchanged content.
+func substituteTransform(ctx context.Context, content string) (string, error) {
+	df := decofileFromContext(ctx)
+	if df == nil {
+		return content, nil
+	}
+	commit := ""
+	if df.Spec.GitHub != nil {
+		commit = df.Spec.GitHub.Commit
+	}
+	vars := []string{
+		"DECOFILE_NAME", df.Name,
+		"DECOFILE_NAMESPACE", df.Namespace,
+		"DECOFILE_DEPLOYMENT_ID", df.DeploymentIdOrName(),
+		"DECOFILE_COMMIT", commit,
+	}
+	pairs := make([]string, 0, len(vars))
+	for i := 0; i < len(vars); i += 2 {
+		// Placeholders live inside JSON strings, so escape the value for one
+		escaped, err := json.Marshal(vars[i+1])
+		if err != nil {
+			return "", err
+		}
+		pairs = append(pairs, "${"+vars[i]+"}", string(escaped[1:len(escaped)-1]))
+	}
+	return strings.NewReplacer(pairs...).Replace(content), nil
+}
+
+// validateSchemaTransform checks the decofile shape: a JSON object whose
+// values (blocks) are JSON objects. Content is passed through unchanged.
+func validateSchemaTransform(_ context.Context, content string) (string, error) {
+	var blocks map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(content), &blocks); err != nil {
+		return "", fmt.Errorf("decofile must be a JSON object: %w", err)
+	}
+	for key, raw := range blocks {
+		if trimmed := bytes.TrimSpace(raw); len(trimmed) == 0 || trimmed[0] != '{' {
+			return "", fmt.Errorf("block %q must be a JSON object", key)
+		}
+	}
+	return content, nil
+}
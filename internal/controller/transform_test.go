@@ -0,0 +1,63 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+func TestTransformerRegistryApply(t *testing.T) {
+	df := makeDecofile("site-main", "dep-1")
+	df.Spec.GitHub = &decositesv1alpha1.GitHubSource{Commit: "abc123"}
+	reg := NewDefaultTransformerRegistry()
+
+	tests := []struct {
+		name    string
+		names   []string
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{name: "no transforms", in: `{"a": {}}`, want: `{"a": {}}`},
+		{name: "minify", names: []string{TransformMinify}, in: "{\n  \"a\": {}\n}", want: `{"a":{}}`},
+		{
+			name:  "substitute",
+			names: []string{TransformSubstitute},
+			in:    `{"a":{"v":"${DECOFILE_NAMESPACE}/${DECOFILE_NAME}@${DECOFILE_COMMIT}#${DECOFILE_DEPLOYMENT_ID}"}}`,
+			want:  `{"a":{"v":"sites-foo/site-main@abc123#dep-1"}}`,
+		},
+		{name: "validate-schema ok", names: []string{TransformValidateSchema}, in: `{"a":{}}`, want: `{"a":{}}`},
+		{name: "validate-schema non-object block", names: []string{TransformValidateSchema}, in: `{"a":1}`, wantErr: true},
+		{name: "validate-schema not an object", names: []string{TransformValidateSchema}, in: `[]`, wantErr: true},
+		{name: "chained", names: []string{TransformValidateSchema, TransformMinify}, in: `{ "a": { } }`, want: `{"a":{}}`},
+		{name: "unknown", names: []string{"nope"}, in: `{}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := reg.Apply(context.Background(), df, tt.names, tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Apply() err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Apply() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
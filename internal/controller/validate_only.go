@@ -0,0 +1,136 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+const condTypeValidated = "Validated"
+
+// errSourceRetrieval marks validation failures that may be transient (the
+// source could not be read), as opposed to content that is invalid.
+var errSourceRetrieval = errors.New("failed to retrieve source")
+
+// reconcileValidateOnly handles spec.validateOnly: it renders the Decofile
+// like a normal reconcile and records the outcome as the Validated
+// condition. Nothing but status is written. Retrieval failures are requeued;
+// invalid content is not, it would fail the same way until the spec changes.
+func (r *DecofileReconciler) reconcileValidateOnly(ctx context.Context, req ctrl.Request, decofile *decositesv1alpha1.Decofile) (ctrl.Result, error) {
+	log := logf.FromContext(ctx)
+
+	cond := metav1.Condition{
+		Type:               condTypeValidated,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Valid",
+		LastTransitionTime: metav1.Now(),
+	}
+	message, reason, verr := r.validateDecofile(ctx, decofile)
+	if verr != nil {
+		log.Info("Decofile failed validation", "reason", reason, "error", verr.Error())
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = reason
+		cond.Message = verr.Error()
+	} else {
+		log.Info("Decofile validated", "result", message)
+		cond.Message = message
+	}
+
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		fresh := &decositesv1alpha1.Decofile{}
+		if err := r.Get(ctx, req.NamespacedName, fresh); err != nil {
+			return err
+		}
+		updateCondition(fresh, cond)
+		return r.Status().Update(ctx, fresh)
+	})
+	if err != nil {
+		log.Error(err, "Failed to update Decofile status")
+		return ctrl.Result{}, err
+	}
+	if errors.Is(verr, errSourceRetrieval) {
+		return ctrl.Result{}, verr
+	}
+	return ctrl.Result{}, nil
+}
+
+// validateDecofile runs the read-only part of a reconcile: source retrieval,
+// transforms, content limits, required keys and, for the ConfigMap target, the size of the
+// data that would be stored. It returns a summary on success, or the
+// condition reason and error of the first failing step.
+func (r *DecofileReconciler) validateDecofile(ctx context.Context, decofile *decositesv1alpha1.Decofile) (string, string, error) {
+	if err := decofile.Spec.Validate(); err != nil {
+		return "", "InvalidSpec", err
+	}
+	source, err := NewSource(r.Client, decofile, r.Sources)
+	if err != nil {
+		return "", "InvalidSpec", err
+	}
+	content, err := source.Retrieve(ctx)
+	if err != nil {
+		return "", "SourceRetrievalFailed", fmt.Errorf("%w: %w", errSourceRetrieval, err)
+	}
+	content, err = r.applyTransforms(ctx, decofile, content)
+	if err != nil {
+		return "", "TransformFailed", err
+	}
+	if err := r.checkContentLimits(content); err != nil {
+		if errors.Is(err, ErrContentLimitExceeded) {
+			return "", "ContentLimitExceeded", err
+		}
+		return "", "InvalidJSON", err
+	}
+	if err := checkRequiredKeys(content, decofile.Spec.RequiredKeys); err != nil {
+		return "", "MissingRequiredKeys", err
+	}
+
+	compressed, err := compressBrotli([]byte(content))
+	if err != nil {
+		return "", "CompressionFailed", err
+	}
+	if decofile.Spec.Target != "" && decofile.Spec.Target != decositesv1alpha1.TargetConfigMap {
+		return fmt.Sprintf("%d bytes (%d compressed)", len(content), len(compressed)), "", nil
+	}
+
+	codecs, err := r.consumerCodecs(ctx, decofile)
+	if err != nil {
+		logf.FromContext(ctx).Error(err, "Failed to resolve consumer codecs (non-fatal)")
+	}
+	configData := map[string]string{"decofile.bin": base64.StdEncoding.EncodeToString(compressed)}
+	if err := addCodecKeys(configData, []byte(content), codecs); err != nil {
+		return "", "CompressionFailed", err
+	}
+	decofileMeta := newContentMeta(configData, content, source.SourceType(), decofile.Spec.DefaultCodec(), r.binaryData())
+	if err := r.checkConfigMapLimits(configData, decofile.TimestampKeyOrDefault(), decofileMeta); err != nil {
+		return "", "ConfigMapTooLarge", err
+	}
+	size := 0
+	for k, v := range configData {
+		size += len(k) + storedLen(k, v, r.binaryData())
+	}
+	return fmt.Sprintf("%d bytes (%d compressed, ConfigMap data %d bytes)", len(content), len(compressed), size), "", nil
+}
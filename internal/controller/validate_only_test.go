@@ -0,0 +1,87 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+func TestReconcile_ValidateOnly(t *testing.T) {
+	cases := []struct {
+		name       string
+		content    string
+		maxDepth   int
+		required   []string
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		{name: "valid", content: `{"a":{"b":1}}`, wantStatus: metav1.ConditionTrue, wantReason: "Valid"},
+		{name: "too deep", content: `{"a":{"b":{"c":1}}}`, maxDepth: 2,
+			wantStatus: metav1.ConditionFalse, wantReason: "ContentLimitExceeded"},
+		{name: "missing required key", content: `{"a":1}`, required: []string{"site.json"},
+			wantStatus: metav1.ConditionFalse, wantReason: "MissingRequiredKeys"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			scheme := newOwnerTestScheme(t)
+			df := makeDecofile("foo", "")
+			df.Spec.Source = SourceTypeInline
+			df.Spec.Inline = &decositesv1alpha1.InlineSource{Value: map[string]runtime.RawExtension{
+				"config.json": {Raw: []byte(tc.content)},
+			}}
+			df.Spec.ValidateOnly = true
+			df.Spec.RequiredKeys = tc.required
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(df).
+				WithStatusSubresource(&decositesv1alpha1.Decofile{}).Build()
+			r := &DecofileReconciler{Client: c, Scheme: scheme, MaxJSONDepth: tc.maxDepth}
+
+			key := client.ObjectKeyFromObject(df)
+			if _, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key}); err != nil {
+				t.Fatalf("Reconcile: %v", err)
+			}
+
+			got := &decositesv1alpha1.Decofile{}
+			if err := c.Get(ctx, key, got); err != nil {
+				t.Fatalf("get Decofile: %v", err)
+			}
+			cond := meta.FindStatusCondition(got.Status.Conditions, condTypeValidated)
+			if cond == nil || cond.Status != tc.wantStatus || cond.Reason != tc.wantReason {
+				t.Errorf("Validated = %+v, want %s/%s", cond, tc.wantStatus, tc.wantReason)
+			}
+			if meta.FindStatusCondition(got.Status.Conditions, "Ready") != nil || got.Status.Revision != 0 {
+				t.Errorf("status = %+v, want only the Validated condition", got.Status)
+			}
+			err := c.Get(ctx, client.ObjectKey{Namespace: testNamespace, Name: df.ConfigMapName()}, &corev1.ConfigMap{})
+			if !apierrors.IsNotFound(err) {
+				t.Errorf("ConfigMap get = %v, want NotFound", err)
+			}
+		})
+	}
+}
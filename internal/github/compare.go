@@ -0,0 +1,112 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxCompareFiles is the number of files the compare API lists before it
+// truncates. A diff that large is cheaper to fetch as a full archive anyway.
+const maxCompareFiles = 300
+
+// ChangedFile is one file touched between two commits.
+type ChangedFile struct {
+	Filename         string `json:"filename"`
+	Status           string `json:"status"` // added, removed, modified, renamed, copied, changed, unchanged
+	PreviousFilename string `json:"previous_filename,omitempty"`
+}
+
+// Compare lists the files changed between base and head. It fails unless
+// head descends from base and the list is complete, since a partial or
+// diverged diff can't be applied on top of content rendered at base.
+func Compare(ctx context.Context, token, org, repo, base, head string) ([]ChangedFile, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("%s/repos/%s/%s/compare/%s...%s?per_page=%d", apiBaseURL, org, repo, base, head, maxCompareFiles), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create compare request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if token != "" {
+		req.Header.Set("Authorization", "token "+token)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("compare request failed: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("compare request for %s/%s %s...%s failed: status %d", org, repo, base, head, resp.StatusCode)
+	}
+
+	var body struct {
+		Status       string        `json:"status"`
+		TotalCommits int           `json:"total_commits"`
+		Files        []ChangedFile `json:"files"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode compare response: %w", err)
+	}
+	switch body.Status {
+	case "ahead", "identical":
+	default:
+		return nil, fmt.Errorf("%s/%s@%s is %s of %s", org, repo, head, body.Status, base)
+	}
+	if len(body.Files) >= maxCompareFiles {
+		return nil, fmt.Errorf("compare %s...%s lists %d files, diff may be truncated", base, head, len(body.Files))
+	}
+	return body.Files, nil
+}
+
+// FetchFile returns the raw content of one file at a commit.
+func FetchFile(ctx context.Context, token, org, repo, sha, path string) ([]byte, error) {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("%s/repos/%s/%s/contents/%s?ref=%s", apiBaseURL, org, repo, strings.Join(segments, "/"), sha), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create contents request: %w", err)
+	}
+	// Returns the file bytes instead of a base64 JSON envelope
+	req.Header.Set("Accept", "application/vnd.github.raw")
+	if token != "" {
+		req.Header.Set("Authorization", "token "+token)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("contents request failed: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("contents request for %s@%s failed: status %d", path, sha, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s@%s: %w", path, sha, err)
+	}
+	return data, nil
+}
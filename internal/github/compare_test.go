@@ -0,0 +1,69 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	withAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/o/r/compare/" + shaA + "..." + shaB:
+			_, _ = w.Write([]byte(`{"status":"ahead","files":[
+				{"filename":".deco/blocks/a.json","status":"modified"},
+				{"filename":".deco/blocks/c.json","status":"renamed","previous_filename":".deco/blocks/b.json"}]}`))
+		case "/repos/o/r/compare/" + shaB + "..." + shaA:
+			_, _ = w.Write([]byte(`{"status":"behind","files":[]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	files, err := Compare(context.Background(), "", "o", "r", shaA, shaB)
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	if len(files) != 2 || files[1].Status != "renamed" || files[1].PreviousFilename != ".deco/blocks/b.json" {
+		t.Errorf("Compare() = %+v", files)
+	}
+	// A force-push leaves nothing to apply the diff on top of
+	if _, err := Compare(context.Background(), "", "o", "r", shaB, shaA); err == nil {
+		t.Error("Compare() of a diverged head succeeded, want error")
+	}
+}
+
+func TestFetchFile(t *testing.T) {
+	withAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/repos/o/r/contents/.deco/blocks/a%20b.json" || r.URL.Query().Get("ref") != shaA ||
+			!strings.Contains(r.Header.Get("Accept"), "raw") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"x":1}`))
+	})
+	data, err := FetchFile(context.Background(), "", "o", "r", shaA, ".deco/blocks/a b.json")
+	if err != nil || string(data) != `{"x":1}` {
+		t.Errorf("FetchFile() = %q, %v", data, err)
+	}
+	if _, err := FetchFile(context.Background(), "", "o", "r", shaA, "missing.json"); err == nil {
+		t.Error("FetchFile of a missing file succeeded, want error")
+	}
+}
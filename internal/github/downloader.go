@@ -19,9 +19,16 @@ package github
 import (
 	"archive/zip"
 	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"path/filepath"
 	"strings"
 	"time"
@@ -30,11 +37,51 @@ import (
 const (
 	// downloadTimeout is the maximum time for downloading the ZIP file
 	downloadTimeout = 5 * time.Minute
+	// DefaultMaxConcurrentDownloads bounds in-flight archive downloads across
+	// all reconciles (see SetMaxConcurrentDownloads).
+	DefaultMaxConcurrentDownloads = 4
 )
 
+// downloadSlots is a process-wide semaphore held for the whole download and
+// extraction, so a burst of Decofiles can't buffer unbounded archives at once
+// regardless of reconcile concurrency.
+var downloadSlots = make(chan struct{}, DefaultMaxConcurrentDownloads)
+
+// SetMaxConcurrentDownloads sets the download concurrency limit (n < 1 means
+// 1). It must be called at startup, before any download starts.
+func SetMaxConcurrentDownloads(n int) {
+	if n < 1 {
+		n = 1
+	}
+	downloadSlots = make(chan struct{}, n)
+}
+
+// acquireDownloadSlot blocks until a download slot is free or ctx is done,
+// and returns the function that releases the slot.
+func acquireDownloadSlot(ctx context.Context) (func(), error) {
+	slots := downloadSlots
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting for a download slot: %w", ctx.Err())
+	}
+}
+
 // Downloader handles downloading and extracting files from GitHub repositories
 type Downloader struct {
 	Token string
+	// MaxDepth limits extraction to files at most this many directory levels
+	// below the path (1 = only files directly in it). 0 = unlimited.
+	MaxDepth int
+	// NoCache asks caches between the operator and GitHub (e.g. a proxy)
+	// for a fresh archive instead of a stored one.
+	NoCache bool
+	// DiskExtractThreshold is the archive size in bytes above which
+	// DownloadAndExtractFiles spills the ZIP and its extracted files to a
+	// temp directory instead of buffering them in memory. 0 disables
+	// spilling.
+	DiskExtractThreshold int64
 }
 
 // BuildZipURL creates the codeload URL for downloading repository as ZIP
@@ -42,22 +89,125 @@ func BuildZipURL(org, repo, commit string) string {
 	return fmt.Sprintf("https://codeload.github.com/%s/%s/zip/%s", org, repo, commit)
 }
 
-// httpClient is a shared HTTP client with timeout for GitHub downloads
-var httpClient = &http.Client{
-	Timeout: downloadTimeout,
-	Transport: &http.Transport{
-		MaxIdleConns:        10,
-		MaxIdleConnsPerHost: 5,
-		IdleConnTimeout:     90 * time.Second,
-	},
+// httpClient is a shared HTTP client with timeout for GitHub downloads. Its
+// requests are paced by SetRequestsPerSecond.
+var httpClient = newHTTPClient(nil)
+
+func newHTTPClient(rootCAs *x509.CertPool) *http.Client {
+	return &http.Client{
+		Timeout: downloadTimeout,
+		Transport: &rateLimitedTransport{base: &http.Transport{
+			MaxIdleConns:        10,
+			MaxIdleConnsPerHost: 5,
+			IdleConnTimeout:     90 * time.Second,
+			TLSClientConfig:     &tls.Config{RootCAs: rootCAs, MinVersion: tls.VersionTLS12},
+		}},
+	}
+}
+
+// SetCABundle adds the PEM certificates in bundle to the system roots trusted
+// by all downloads (archives and LFS objects), for endpoints served under a
+// private CA. It must be called at startup, before any download starts.
+func SetCABundle(bundle []byte) error {
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(bundle) {
+		return fmt.Errorf("no PEM certificates found in CA bundle")
+	}
+	httpClient = newHTTPClient(pool)
+	return nil
 }
 
-// DownloadAndExtract downloads ZIP from GitHub and extracts files from specified path
-func (d *Downloader) DownloadAndExtract(org, repo, commit, path string) (map[string][]byte, error) {
+// ErrDuplicateFileName is returned when two of the paths extracted together
+// hold files with the same base name, which files are keyed by.
+var ErrDuplicateFileName = errors.New("file name found under more than one path")
+
+// DownloadAndExtract downloads ZIP from GitHub and extracts the files under
+// any of paths, merged into one map keyed by base name.
+func (d *Downloader) DownloadAndExtract(ctx context.Context, org, repo, commit string, paths ...string) (map[string][]byte, error) {
+	release, err := acquireDownloadSlot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	body, err := d.download(ctx, org, repo, commit)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close() //nolint:errcheck
+
+	// Read ZIP into memory with timing
+	readStart := time.Now()
+	zipData, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response (after %v): %w", time.Since(readStart), err)
+	}
+
+	// Extract files with timing
+	extractStart := time.Now()
+	files, err := extractFiles(zipData, paths, d.MaxDepth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract (after %v): %w", time.Since(extractStart), err)
+	}
+
+	return files, nil
+}
+
+// DownloadAndExtractFiles is like DownloadAndExtract, but archives larger than
+// d.DiskExtractThreshold are streamed to a temp directory and extracted there
+// rather than held in memory. The caller must Close the returned Files.
+func (d *Downloader) DownloadAndExtractFiles(ctx context.Context, org, repo, commit string, paths ...string) (*Files, error) {
+	release, err := acquireDownloadSlot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	body, err := d.download(ctx, org, repo, commit)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close() //nolint:errcheck
+
+	// Buffer up to the threshold; anything larger continues on disk
+	var head []byte
+	if d.DiskExtractThreshold > 0 {
+		head, err = io.ReadAll(io.LimitReader(body, d.DiskExtractThreshold+1))
+	} else {
+		head, err = io.ReadAll(body)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if d.DiskExtractThreshold <= 0 || int64(len(head)) <= d.DiskExtractThreshold {
+		files, err := extractFiles(head, paths, d.MaxDepth)
+		if err != nil {
+			return nil, fmt.Errorf("failed to extract: %w", err)
+		}
+		return &Files{mem: files}, nil
+	}
+
+	dir, err := os.MkdirTemp("", "decofile-github-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	files := &Files{disk: make(map[string]string), dir: dir}
+	if err := files.spill(io.MultiReader(bytes.NewReader(head), body), paths, d.MaxDepth); err != nil {
+		_ = files.Close()
+		return nil, err
+	}
+	return files, nil
+}
+
+// download issues the codeload request and returns the response body.
+func (d *Downloader) download(ctx context.Context, org, repo, commit string) (io.ReadCloser, error) {
 	url := BuildZipURL(org, repo, commit)
 
 	// Create HTTP request
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
@@ -66,6 +216,10 @@ func (d *Downloader) DownloadAndExtract(org, repo, commit, path string) (map[str
 	if d.Token != "" {
 		req.Header.Set("Authorization", fmt.Sprintf("token %s", d.Token))
 	}
+	if d.NoCache {
+		req.Header.Set("Cache-Control", "no-cache")
+		req.Header.Set("Pragma", "no-cache")
+	}
 
 	// Download ZIP with timing
 	httpStart := time.Now()
@@ -73,44 +227,117 @@ func (d *Downloader) DownloadAndExtract(org, repo, commit, path string) (map[str
 	if err != nil {
 		return nil, fmt.Errorf("failed to download (after %v): %w", time.Since(httpStart), err)
 	}
-	defer func() {
-		if closeErr := resp.Body.Close(); closeErr != nil {
-			err = fmt.Errorf("failed to close response body: %w", closeErr)
-		}
-	}()
 
 	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
 		return nil, fmt.Errorf("failed to download: status %d (after %v)", resp.StatusCode, time.Since(httpStart))
 	}
 
-	// Read ZIP into memory with timing
-	readStart := time.Now()
-	zipData, err := io.ReadAll(resp.Body)
+	body, err := decodeBody(resp.Header.Get("Content-Encoding"), resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response (after %v): %w", time.Since(readStart), err)
+		_ = resp.Body.Close()
+		return nil, err
 	}
-	// Log timing info via error message formatting (caller will log)
-	httpDuration := time.Since(httpStart)
-	_ = httpDuration // timing available for debugging
+	return body, nil
+}
 
-	// Extract files with timing
-	extractStart := time.Now()
-	files, err := extractFiles(zipData, path)
+// decodeBody undoes a Content-Encoding left on the response. The transport
+// only decodes gzip it asked for itself, so a fronting proxy that compresses
+// the (already zipped) archive would otherwise hand us a body zip can't read.
+func decodeBody(encoding string, body io.ReadCloser) (io.ReadCloser, error) {
+	var (
+		r   io.ReadCloser
+		err error
+	)
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "", "identity":
+		return body, nil
+	case "gzip", "x-gzip":
+		r, err = gzip.NewReader(body)
+	case "deflate":
+		r, err = zlib.NewReader(body)
+	default:
+		return nil, fmt.Errorf("unsupported Content-Encoding %q", encoding)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to extract (after %v): %w", time.Since(extractStart), err)
+		return nil, fmt.Errorf("failed to decode %s response: %w", encoding, err)
 	}
+	return &decodedBody{ReadCloser: r, raw: body}, nil
+}
 
-	return files, nil
+// decodedBody closes both the decoder and the underlying response body.
+type decodedBody struct {
+	io.ReadCloser
+	raw io.Closer
+}
+
+func (b *decodedBody) Close() error {
+	err := b.ReadCloser.Close()
+	if rawErr := b.raw.Close(); err == nil {
+		err = rawErr
+	}
+	return err
 }
 
-func extractFiles(zipData []byte, targetPath string) (map[string][]byte, error) {
+func extractFiles(zipData []byte, targetPaths []string, maxDepth int) (map[string][]byte, error) {
 	reader, err := zip.NewReader(bytes.NewReader(zipData), int64(len(zipData)))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read zip: %w", err)
 	}
 
 	files := make(map[string][]byte)
+	err = walkZip(reader, targetPaths, maxDepth, func(file *zip.File, rc io.Reader) error {
+		content, err := io.ReadAll(rc)
+		if err != nil {
+			return err
+		}
+		// Use filename without full path as key (just the basename)
+		files[filepath.Base(file.Name)] = content
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// InPath reports whether the repository file name is extracted for
+// targetPath: it has targetPath as a prefix and, when maxDepth > 0, lies at
+// most maxDepth directory levels below it (1 = directly in it).
+func InPath(name, targetPath string, maxDepth int) bool {
+	rest, ok := strings.CutPrefix(name, targetPath)
+	if !ok {
+		return false
+	}
+	return maxDepth <= 0 || strings.Count(strings.TrimPrefix(rest, "/"), "/") < maxDepth
+}
+
+// InPaths reports whether the repository file name is extracted for any of
+// targetPaths (see InPath).
+func InPaths(name string, targetPaths []string, maxDepth int) bool {
+	_, ok := matchPath(name, targetPaths, maxDepth)
+	return ok
+}
+
+// matchPath returns the first of targetPaths the repository file name is
+// extracted for.
+func matchPath(name string, targetPaths []string, maxDepth int) (string, bool) {
+	for _, targetPath := range targetPaths {
+		if InPath(name, filepath.ToSlash(targetPath), maxDepth) {
+			return targetPath, true
+		}
+	}
+	return "", false
+}
+
+// walkZip calls fn for every regular file under any of targetPaths in the
+// archive, down to maxDepth directory levels (0 = unlimited). Files are keyed
+// by base name, so one found under two different paths fails with
+// ErrDuplicateFileName rather than one replacing the other.
+func walkZip(reader *zip.Reader, targetPaths []string, maxDepth int, fn func(file *zip.File, rc io.Reader) error) error {
 	var rootDir string
+	// pathOf maps each base name extracted so far to the path it came from
+	pathOf := make(map[string]string)
 
 	for i, file := range reader.File {
 		// First entry is typically the root directory
@@ -131,31 +358,32 @@ func extractFiles(zipData []byte, targetPath string) (map[string][]byte, error)
 
 		// Normalize path separators
 		relativePath = filepath.ToSlash(relativePath)
-		targetPath = filepath.ToSlash(targetPath)
 
-		// Check if file is within target path
-		if !strings.HasPrefix(relativePath, targetPath) {
+		// Check if file is within a target path
+		targetPath, ok := matchPath(relativePath, targetPaths, maxDepth)
+		if !ok {
 			continue
 		}
+		name := filepath.Base(file.Name)
+		if prev, seen := pathOf[name]; seen && prev != targetPath {
+			return fmt.Errorf("%w: %s is under both %q and %q", ErrDuplicateFileName, name, prev, targetPath)
+		}
+		pathOf[name] = targetPath
 
 		// Read file content
 		rc, err := file.Open()
 		if err != nil {
-			return nil, fmt.Errorf("failed to open file %s: %w", file.Name, err)
+			return fmt.Errorf("failed to open file %s: %w", file.Name, err)
 		}
 
-		content, err := io.ReadAll(rc)
+		err = fn(file, rc)
 		if closeErr := rc.Close(); closeErr != nil {
-			return nil, fmt.Errorf("failed to close file %s: %w", file.Name, closeErr)
+			return fmt.Errorf("failed to close file %s: %w", file.Name, closeErr)
 		}
 		if err != nil {
-			return nil, fmt.Errorf("failed to read file %s: %w", file.Name, err)
+			return fmt.Errorf("failed to read file %s: %w", file.Name, err)
 		}
-
-		// Use filename without full path as key (just the basename)
-		filename := filepath.Base(file.Name)
-		files[filename] = content
 	}
 
-	return files, nil
+	return nil
 }
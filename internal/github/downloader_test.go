@@ -0,0 +1,368 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// makeZip builds a codeload-style archive: a root directory followed by files.
+func makeZip(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	if _, err := w.Create("repo-sha/"); err != nil {
+		t.Fatalf("create root dir: %v", err)
+	}
+	for name, content := range files {
+		f, err := w.Create("repo-sha/" + name)
+		if err != nil {
+			t.Fatalf("create %s: %v", name, err)
+		}
+		if _, err := f.Write([]byte(content)); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+var testArchive = map[string]string{
+	".deco/blocks/a.json":       `{"a":1}`,
+	".deco/blocks/b%20c.json":   `{"b":2}`,
+	"src/ignored.json":          `{}`,
+	".deco/blocks/nested/d.txt": `d`,
+}
+
+func TestExtractFiles(t *testing.T) {
+	files, err := extractFiles(makeZip(t, testArchive), []string{".deco/blocks"}, 0)
+	if err != nil {
+		t.Fatalf("extractFiles: %v", err)
+	}
+	want := map[string][]byte{
+		"a.json":     []byte(`{"a":1}`),
+		"b%20c.json": []byte(`{"b":2}`),
+		"d.txt":      []byte(`d`),
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("extractFiles() = %v, want %v", files, want)
+	}
+}
+
+func TestExtractFiles_MaxDepth(t *testing.T) {
+	files, err := extractFiles(makeZip(t, testArchive), []string{".deco/blocks"}, 1)
+	if err != nil {
+		t.Fatalf("extractFiles: %v", err)
+	}
+	if _, ok := files["d.txt"]; ok || len(files) != 2 {
+		t.Errorf("extractFiles() with maxDepth 1 = %v, want only a.json and b%%20c.json", files)
+	}
+}
+
+func TestExtractFiles_MultiplePaths(t *testing.T) {
+	archive := map[string]string{
+		"content/a.json":  `{"a":1}`,
+		"sections/b.json": `{"b":2}`,
+		"src/c.json":      `{}`,
+	}
+	files, err := extractFiles(makeZip(t, archive), []string{"content", "sections"}, 0)
+	if err != nil {
+		t.Fatalf("extractFiles: %v", err)
+	}
+	want := map[string][]byte{
+		"a.json": []byte(`{"a":1}`),
+		"b.json": []byte(`{"b":2}`),
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("extractFiles() = %v, want %v", files, want)
+	}
+}
+
+func TestExtractFiles_DuplicateNameAcrossPaths(t *testing.T) {
+	archive := map[string]string{
+		"content/a.json":  `{"a":1}`,
+		"sections/a.json": `{"a":2}`,
+	}
+	_, err := extractFiles(makeZip(t, archive), []string{"content", "sections"}, 0)
+	if !errors.Is(err, ErrDuplicateFileName) || !strings.Contains(err.Error(), "a.json") {
+		t.Fatalf("extractFiles() error = %v, want ErrDuplicateFileName naming a.json", err)
+	}
+
+	files := &Files{disk: make(map[string]string), dir: t.TempDir()}
+	if err := files.spill(bytes.NewReader(makeZip(t, archive)), []string{"content", "sections"}, 0); !errors.Is(err, ErrDuplicateFileName) {
+		t.Errorf("spill() error = %v, want ErrDuplicateFileName", err)
+	}
+}
+
+func TestInPath(t *testing.T) {
+	cases := []struct {
+		name, path string
+		maxDepth   int
+		want       bool
+	}{
+		{".deco/blocks/a.json", ".deco/blocks", 0, true},
+		{".deco/blocks/x/y/z.json", ".deco/blocks", 0, true},
+		{"src/a.json", ".deco/blocks", 0, false},
+		{".deco/blocks/a.json", ".deco/blocks", 1, true},
+		{".deco/blocks/a.json", ".deco/blocks/", 1, true},
+		{".deco/blocks/x/a.json", ".deco/blocks", 1, false},
+		{".deco/blocks/x/a.json", ".deco/blocks", 2, true},
+		{"a.json", "", 1, true},
+		{"x/a.json", "", 1, false},
+	}
+	for _, tc := range cases {
+		if got := InPath(tc.name, tc.path, tc.maxDepth); got != tc.want {
+			t.Errorf("InPath(%q, %q, %d) = %v, want %v", tc.name, tc.path, tc.maxDepth, got, tc.want)
+		}
+	}
+}
+
+func TestFilesSpill(t *testing.T) {
+	dir := t.TempDir()
+	files := &Files{disk: make(map[string]string), dir: dir}
+	if err := files.spill(bytes.NewReader(makeZip(t, testArchive)), []string{".deco/blocks"}, 0); err != nil {
+		t.Fatalf("spill: %v", err)
+	}
+
+	if !files.OnDisk() {
+		t.Error("OnDisk() = false, want true")
+	}
+	if got, want := files.Names(), []string{"a.json", "b%20c.json", "d.txt"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+	content, err := files.ReadFile("a.json")
+	if err != nil || string(content) != `{"a":1}` {
+		t.Errorf("ReadFile(a.json) = %q, %v", content, err)
+	}
+
+	if err := files.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("temp dir still exists after Close: %v", err)
+	}
+}
+
+func TestFilesSpill_InvalidArchive(t *testing.T) {
+	files := &Files{disk: make(map[string]string), dir: t.TempDir()}
+	if err := files.spill(bytes.NewReader([]byte("not a zip")), []string{""}, 0); err == nil {
+		t.Fatal("expected error for invalid archive")
+	}
+}
+
+func TestAcquireDownloadSlot_BoundsConcurrency(t *testing.T) {
+	SetMaxConcurrentDownloads(2)
+	defer SetMaxConcurrentDownloads(DefaultMaxConcurrentDownloads)
+
+	var inFlight, peak atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := acquireDownloadSlot(context.Background())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer release()
+			n := inFlight.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+			inFlight.Add(-1)
+		}()
+	}
+	wg.Wait()
+
+	if got := peak.Load(); got > 2 {
+		t.Errorf("peak concurrent downloads = %d, want <= 2", got)
+	}
+}
+
+func TestAcquireDownloadSlot_HonorsContext(t *testing.T) {
+	SetMaxConcurrentDownloads(1)
+	defer SetMaxConcurrentDownloads(DefaultMaxConcurrentDownloads)
+
+	release, err := acquireDownloadSlot(context.Background())
+	if err != nil {
+		t.Fatalf("acquireDownloadSlot: %v", err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := acquireDownloadSlot(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("acquireDownloadSlot with all slots taken = %v, want DeadlineExceeded", err)
+	}
+}
+
+// A proxy that gzips the archive again must not break extraction.
+func TestDecodeBody_GzipEncodedResponse(t *testing.T) {
+	archive := makeZip(t, testArchive)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		_, _ = gz.Write(archive)
+		_ = gz.Close()
+	}))
+	defer srv.Close()
+
+	// An explicit Accept-Encoding stops the transport from decoding for us,
+	// which is what happens when the proxy compresses unasked.
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	body, err := decodeBody(resp.Header.Get("Content-Encoding"), resp.Body)
+	if err != nil {
+		t.Fatalf("decodeBody: %v", err)
+	}
+	defer body.Close() //nolint:errcheck
+	zipData, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("read body: %v", err)
+	}
+	files, err := extractFiles(zipData, []string{".deco/blocks"}, 0)
+	if err != nil {
+		t.Fatalf("extractFiles: %v", err)
+	}
+	if len(files) != 3 {
+		t.Errorf("extracted %d files, want 3", len(files))
+	}
+}
+
+func TestDecodeBody(t *testing.T) {
+	payload := []byte("archive bytes")
+	var gz, zl bytes.Buffer
+	gw := gzip.NewWriter(&gz)
+	_, _ = gw.Write(payload)
+	_ = gw.Close()
+	zw := zlib.NewWriter(&zl)
+	_, _ = zw.Write(payload)
+	_ = zw.Close()
+
+	cases := []struct {
+		encoding string
+		body     []byte
+		wantErr  bool
+	}{
+		{"", payload, false},
+		{"identity", payload, false},
+		{"gzip", gz.Bytes(), false},
+		{"X-Gzip", gz.Bytes(), false},
+		{"deflate", zl.Bytes(), false},
+		{"br", payload, true},
+		{"gzip", payload, true},
+	}
+	for _, tc := range cases {
+		body, err := decodeBody(tc.encoding, io.NopCloser(bytes.NewReader(tc.body)))
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("decodeBody(%q) succeeded, want error", tc.encoding)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("decodeBody(%q): %v", tc.encoding, err)
+		}
+		got, err := io.ReadAll(body)
+		if err != nil || !bytes.Equal(got, payload) {
+			t.Errorf("decodeBody(%q) read %q, %v; want %q", tc.encoding, got, err, payload)
+		}
+		if err := body.Close(); err != nil {
+			t.Errorf("Close: %v", err)
+		}
+	}
+}
+
+func TestSetCABundle(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+	saved := httpClient
+	defer func() { httpClient = saved }()
+
+	if _, err := httpClient.Get(srv.URL); err == nil {
+		t.Fatal("request to a server under an unknown CA succeeded, want x509 error")
+	}
+
+	bundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	if err := SetCABundle(bundle); err != nil {
+		t.Fatalf("SetCABundle: %v", err)
+	}
+	resp, err := httpClient.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("request with the CA bundle: %v", err)
+	}
+	_ = resp.Body.Close()
+
+	if err := SetCABundle([]byte("not a certificate")); err == nil {
+		t.Error("SetCABundle with no PEM blocks succeeded, want error")
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestDownload_NoCache(t *testing.T) {
+	saved := httpClient
+	defer func() { httpClient = saved }()
+	var got http.Header
+	httpClient = &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		got = req.Header.Clone()
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+	})}
+
+	for _, noCache := range []bool{false, true} {
+		d := &Downloader{NoCache: noCache}
+		body, err := d.download(context.Background(), "deco-sites", "storefront", "main")
+		if err != nil {
+			t.Fatalf("download: %v", err)
+		}
+		_ = body.Close()
+		if want := map[bool]string{true: "no-cache"}[noCache]; got.Get("Cache-Control") != want {
+			t.Errorf("NoCache=%v: Cache-Control = %q, want %q", noCache, got.Get("Cache-Control"), want)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// apiBaseURL is the GitHub REST API root; overridden in tests.
+var apiBaseURL = "https://api.github.com"
+
+// SetAPIBaseURL points REST API requests at u (e.g. a test server) and
+// returns the previous root. It must not be called while requests are made.
+func SetAPIBaseURL(u string) (previous string) {
+	previous, apiBaseURL = apiBaseURL, strings.TrimSuffix(u, "/")
+	return previous
+}
+
+// commitSHAPattern matches a full commit SHA, as opposed to a branch or tag.
+var commitSHAPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// IsCommitSHA reports whether ref is a full commit SHA. Anything else in
+// spec.github.commit is a branch (or tag) that moves.
+func IsCommitSHA(ref string) bool {
+	return commitSHAPattern.MatchString(ref)
+}
+
+// PushEvent is a push to a branch, as reported by the repository events API.
+type PushEvent struct {
+	Branch string
+	Head   string // commit SHA the branch now points at
+}
+
+// RepoEvents polls the events API of one repository. It sends the previous
+// ETag, so an unchanged feed costs a 304 that GitHub does not count against
+// the rate limit, and reports only push events it hasn't returned before.
+// Not safe for concurrent use.
+type RepoEvents struct {
+	Org, Repo string
+
+	etag   string
+	lastID int64
+}
+
+type repoEvent struct {
+	ID      string `json:"id"`
+	Type    string `json:"type"`
+	Payload struct {
+		Ref  string `json:"ref"`
+		Head string `json:"head"`
+	} `json:"payload"`
+}
+
+// Poll fetches new push events, newest first, plus the interval GitHub asks
+// clients to wait before polling again (X-Poll-Interval; 0 if absent).
+func (e *RepoEvents) Poll(token string) ([]PushEvent, time.Duration, error) {
+	req, err := http.NewRequest(http.MethodGet,
+		fmt.Sprintf("%s/repos/%s/%s/events?per_page=100", apiBaseURL, e.Org, e.Repo), nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create events request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if token != "" {
+		req.Header.Set("Authorization", "token "+token)
+	}
+	if e.etag != "" {
+		req.Header.Set("If-None-Match", e.etag)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("events request failed: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	interval := pollInterval(resp.Header)
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return nil, interval, nil
+	case http.StatusOK:
+	default:
+		return nil, interval, fmt.Errorf("events request failed: status %d", resp.StatusCode)
+	}
+
+	var events []repoEvent
+	if err := json.NewDecoder(resp.Body).Decode(&events); err != nil {
+		return nil, interval, fmt.Errorf("failed to decode events: %w", err)
+	}
+	e.etag = resp.Header.Get("ETag")
+
+	var pushes []PushEvent
+	newest := e.lastID
+	for _, ev := range events {
+		id, err := strconv.ParseInt(ev.ID, 10, 64)
+		if err != nil || id <= e.lastID {
+			continue
+		}
+		newest = max(newest, id)
+		branch, ok := strings.CutPrefix(ev.Payload.Ref, "refs/heads/")
+		if ev.Type != "PushEvent" || !ok || ev.Payload.Head == "" {
+			continue
+		}
+		pushes = append(pushes, PushEvent{Branch: branch, Head: ev.Payload.Head})
+	}
+	e.lastID = newest
+	return pushes, interval, nil
+}
+
+// ResolveBranch returns the commit SHA a branch points at. It is the
+// fallback when the events feed is unavailable.
+func ResolveBranch(token, org, repo, branch string) (string, error) {
+	req, err := http.NewRequest(http.MethodGet,
+		fmt.Sprintf("%s/repos/%s/%s/commits/%s", apiBaseURL, org, repo, branch), nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create commit request: %w", err)
+	}
+	// Returns the bare SHA instead of the full commit object
+	req.Header.Set("Accept", "application/vnd.github.sha")
+	if token != "" {
+		req.Header.Set("Authorization", "token "+token)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("commit request failed: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("commit request for %s/%s@%s failed: status %d", org, repo, branch, resp.StatusCode)
+	}
+	sha, err := io.ReadAll(io.LimitReader(resp.Body, 128))
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit SHA: %w", err)
+	}
+	if s := strings.TrimSpace(string(sha)); IsCommitSHA(s) {
+		return s, nil
+	}
+	return "", fmt.Errorf("unexpected commit response for %s/%s@%s", org, repo, branch)
+}
+
+// CommitExists reports whether ref (a SHA, branch or tag) names a commit of
+// org/repo, with a HEAD request that transfers no commit data. GitHub
+// answers 404 when the repository is missing or hidden from the token, and
+// 422 when it has no such commit; both report false. Other failures are
+// errors.
+func CommitExists(ctx context.Context, token, org, repo, ref string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead,
+		fmt.Sprintf("%s/repos/%s/%s/commits/%s", apiBaseURL, org, repo, ref), nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create commit request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github.sha")
+	if token != "" {
+		req.Header.Set("Authorization", "token "+token)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("commit request failed: %w", err)
+	}
+	_ = resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound, http.StatusUnprocessableEntity:
+		return false, nil
+	default:
+		return false, fmt.Errorf("commit request for %s/%s@%s failed: status %d", org, repo, ref, resp.StatusCode)
+	}
+}
+
+func pollInterval(h http.Header) time.Duration {
+	seconds, err := strconv.Atoi(h.Get("X-Poll-Interval"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,158 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	shaA = "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"
+	shaB = "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"
+)
+
+func withAPI(t *testing.T, h http.HandlerFunc) {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	saved := apiBaseURL
+	apiBaseURL = srv.URL
+	t.Cleanup(func() {
+		apiBaseURL = saved
+		srv.Close()
+	})
+}
+
+func TestRepoEventsPoll(t *testing.T) {
+	feed := `[
+		{"id":"3","type":"PushEvent","payload":{"ref":"refs/heads/main","head":"` + shaB + `"}},
+		{"id":"2","type":"WatchEvent","payload":{}},
+		{"id":"1","type":"PushEvent","payload":{"ref":"refs/tags/v1","head":"` + shaA + `"}}
+	]`
+	withAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/deco-sites/storefront/events" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("X-Poll-Interval", "60")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(feed))
+	})
+
+	e := &RepoEvents{Org: "deco-sites", Repo: "storefront"}
+	pushes, interval, err := e.Poll("")
+	if err != nil {
+		t.Fatalf("Poll: %v", err)
+	}
+	if want := []PushEvent{{Branch: "main", Head: shaB}}; !reflect.DeepEqual(pushes, want) {
+		t.Errorf("Poll() = %+v, want %+v", pushes, want)
+	}
+	if interval != time.Minute {
+		t.Errorf("interval = %v, want 1m", interval)
+	}
+
+	// Unchanged feed: conditional request, nothing new
+	pushes, _, err = e.Poll("")
+	if err != nil || len(pushes) != 0 {
+		t.Errorf("second Poll() = %+v, %v; want no events", pushes, err)
+	}
+
+	// A changed ETag with already seen events reports nothing either
+	e.etag = `"stale"`
+	pushes, _, err = e.Poll("")
+	if err != nil || len(pushes) != 0 {
+		t.Errorf("Poll() after refetch = %+v, %v; want no events", pushes, err)
+	}
+}
+
+func TestRepoEventsPoll_Error(t *testing.T) {
+	withAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	if _, _, err := (&RepoEvents{Org: "o", Repo: "r"}).Poll(""); err == nil {
+		t.Error("Poll on 403 succeeded, want error")
+	}
+}
+
+func TestResolveBranch(t *testing.T) {
+	withAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/o/r/commits/main" || !strings.Contains(r.Header.Get("Accept"), "sha") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(shaA))
+	})
+	sha, err := ResolveBranch("", "o", "r", "main")
+	if err != nil || sha != shaA {
+		t.Errorf("ResolveBranch() = %q, %v; want %q", sha, err, shaA)
+	}
+	if _, err := ResolveBranch("", "o", "r", "missing"); err == nil {
+		t.Error("ResolveBranch for a missing branch succeeded, want error")
+	}
+}
+
+func TestIsCommitSHA(t *testing.T) {
+	for ref, want := range map[string]bool{shaA: true, "main": false, "abc123": false, "refs/heads/main": false} {
+		if got := IsCommitSHA(ref); got != want {
+			t.Errorf("IsCommitSHA(%q) = %v, want %v", ref, got, want)
+		}
+	}
+}
+
+func TestCommitExists(t *testing.T) {
+	withAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead || r.Header.Get("Authorization") != "token secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Path {
+		case "/repos/deco-sites/storefront/commits/" + shaA:
+		case "/repos/deco-sites/storefront/commits/" + shaB:
+			w.WriteHeader(http.StatusUnprocessableEntity)
+		case "/repos/deco-sites/flaky/commits/" + shaA:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	tests := []struct {
+		repo, ref string
+		want      bool
+		wantErr   bool
+	}{
+		{"storefront", shaA, true, false},
+		{"storefront", shaB, false, false},
+		{"missing", shaA, false, false},
+		{"flaky", shaA, false, true},
+	}
+	for _, tt := range tests {
+		got, err := CommitExists(context.Background(), "secret", "deco-sites", tt.repo, tt.ref)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("CommitExists(%s@%s) = %v, %v; want %v, error %v", tt.repo, tt.ref, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
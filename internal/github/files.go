@@ -0,0 +1,146 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// Files holds the files extracted from a repository archive, keyed by
+// basename. Small archives are kept in memory; large ones live in a temp
+// directory that is removed by Close.
+type Files struct {
+	mem  map[string][]byte
+	disk map[string]string // basename -> extracted file path
+	dir  string
+}
+
+// Len returns the number of extracted files.
+func (f *Files) Len() int {
+	if f.disk != nil {
+		return len(f.disk)
+	}
+	return len(f.mem)
+}
+
+// OnDisk reports whether the files were extracted to a temp directory.
+func (f *Files) OnDisk() bool {
+	return f.dir != ""
+}
+
+// Names returns the extracted file basenames in sorted order.
+func (f *Files) Names() []string {
+	names := make([]string, 0, f.Len())
+	if f.disk != nil {
+		for name := range f.disk {
+			names = append(names, name)
+		}
+	} else {
+		for name := range f.mem {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ReadFile returns the content of the named file.
+func (f *Files) ReadFile(name string) ([]byte, error) {
+	if f.disk != nil {
+		p, ok := f.disk[name]
+		if !ok {
+			return nil, fmt.Errorf("file %s not found", name)
+		}
+		return os.ReadFile(p)
+	}
+	content, ok := f.mem[name]
+	if !ok {
+		return nil, fmt.Errorf("file %s not found", name)
+	}
+	return content, nil
+}
+
+// Close removes the temp directory, if any.
+func (f *Files) Close() error {
+	if f.dir == "" {
+		return nil
+	}
+	return os.RemoveAll(f.dir)
+}
+
+// spill streams the ZIP in r to f.dir and extracts files under targetPaths
+// (down to maxDepth levels) next to it, one file at a time.
+func (f *Files) spill(r io.Reader, targetPaths []string, maxDepth int) error {
+	zipPath := filepath.Join(f.dir, "archive.zip")
+	zf, err := os.Create(zipPath)
+	if err != nil {
+		return fmt.Errorf("failed to create temp archive: %w", err)
+	}
+	size, err := io.Copy(zf, r)
+	if closeErr := zf.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write temp archive: %w", err)
+	}
+
+	zr, err := os.Open(zipPath)
+	if err != nil {
+		return fmt.Errorf("failed to open temp archive: %w", err)
+	}
+	defer zr.Close() //nolint:errcheck
+
+	reader, err := zip.NewReader(zr, size)
+	if err != nil {
+		return fmt.Errorf("failed to read zip: %w", err)
+	}
+
+	// Extracted files are named by sequence to sidestep unusual characters
+	n := 0
+	err = walkZip(reader, targetPaths, maxDepth, func(file *zip.File, rc io.Reader) error {
+		n++
+		p := filepath.Join(f.dir, "f"+strconv.Itoa(n))
+		out, err := os.Create(p)
+		if err != nil {
+			return err
+		}
+		_, err = io.Copy(out, rc)
+		if closeErr := out.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+		f.disk[filepath.Base(file.Name)] = p
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to extract: %w", err)
+	}
+
+	// The archive itself is no longer needed once extracted
+	if err := os.Remove(zipPath); err != nil {
+		return fmt.Errorf("failed to remove temp archive: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// lfsPointerPrefix starts every Git LFS pointer file
+	lfsPointerPrefix = "version https://git-lfs"
+	// maxLFSPointerSize bounds pointer detection; real pointers are ~130 bytes
+	maxLFSPointerSize = 1024
+	lfsMediaType      = "application/vnd.git-lfs+json"
+)
+
+// LFSPointer is a parsed Git LFS pointer file.
+type LFSPointer struct {
+	Oid  string // sha256 hex digest of the real content
+	Size int64
+}
+
+// IsLFSPointer reports whether content is a Git LFS pointer file rather than
+// the file itself, as found in codeload archives of LFS-tracked paths.
+func IsLFSPointer(content []byte) bool {
+	return len(content) <= maxLFSPointerSize && bytes.HasPrefix(content, []byte(lfsPointerPrefix))
+}
+
+// ParseLFSPointer parses the oid and size lines of a pointer file.
+func ParseLFSPointer(content []byte) (LFSPointer, error) {
+	var p LFSPointer
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		key, value, _ := strings.Cut(scanner.Text(), " ")
+		switch key {
+		case "oid":
+			p.Oid = strings.TrimPrefix(value, "sha256:")
+		case "size":
+			size, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return p, fmt.Errorf("invalid LFS pointer size %q: %w", value, err)
+			}
+			p.Size = size
+		}
+	}
+	if p.Oid == "" {
+		return p, fmt.Errorf("LFS pointer has no sha256 oid")
+	}
+	return p, nil
+}
+
+// BuildLFSBatchURL creates the Git LFS batch API URL for a repository
+func BuildLFSBatchURL(org, repo string) string {
+	return fmt.Sprintf("https://github.com/%s/%s.git/info/lfs/objects/batch", org, repo)
+}
+
+type lfsBatchRequest struct {
+	Operation string          `json:"operation"`
+	Transfers []string        `json:"transfers"`
+	Objects   []lfsBatchEntry `json:"objects"`
+}
+
+type lfsBatchEntry struct {
+	Oid  string `json:"oid"`
+	Size int64  `json:"size"`
+}
+
+type lfsBatchResponse struct {
+	Objects []struct {
+		Oid     string `json:"oid"`
+		Actions struct {
+			Download *struct {
+				Href   string            `json:"href"`
+				Header map[string]string `json:"header"`
+			} `json:"download"`
+		} `json:"actions"`
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	} `json:"objects"`
+}
+
+// FetchLFSObject downloads the real content behind an LFS pointer using the
+// downloader's token, and checks it against the pointer's oid.
+func (d *Downloader) FetchLFSObject(ctx context.Context, org, repo string, pointer LFSPointer) ([]byte, error) {
+	return d.fetchLFSObject(ctx, BuildLFSBatchURL(org, repo), pointer)
+}
+
+func (d *Downloader) fetchLFSObject(ctx context.Context, batchURL string, pointer LFSPointer) ([]byte, error) {
+	reqBody, err := json.Marshal(lfsBatchRequest{
+		Operation: "download",
+		Transfers: []string{"basic"},
+		Objects:   []lfsBatchEntry{{Oid: pointer.Oid, Size: pointer.Size}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode LFS batch request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, batchURL, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LFS batch request: %w", err)
+	}
+	req.Header.Set("Accept", lfsMediaType)
+	req.Header.Set("Content-Type", lfsMediaType)
+	if d.Token != "" {
+		req.SetBasicAuth("x-access-token", d.Token)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("LFS batch request failed: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("LFS batch request failed: status %d", resp.StatusCode)
+	}
+	var batch lfsBatchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&batch); err != nil {
+		return nil, fmt.Errorf("failed to decode LFS batch response: %w", err)
+	}
+	if len(batch.Objects) != 1 {
+		return nil, fmt.Errorf("LFS batch response has %d objects, want 1", len(batch.Objects))
+	}
+	obj := batch.Objects[0]
+	if obj.Error != nil {
+		return nil, fmt.Errorf("LFS object %s: %s (code %d)", pointer.Oid, obj.Error.Message, obj.Error.Code)
+	}
+	if obj.Actions.Download == nil {
+		return nil, fmt.Errorf("LFS object %s has no download action", pointer.Oid)
+	}
+
+	// The download href is usually pre-signed storage: send only the headers
+	// the batch API handed out, never our token
+	dl, err := http.NewRequestWithContext(ctx, http.MethodGet, obj.Actions.Download.Href, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LFS download request: %w", err)
+	}
+	for k, v := range obj.Actions.Download.Header {
+		dl.Header.Set(k, v)
+	}
+	dlResp, err := httpClient.Do(dl)
+	if err != nil {
+		return nil, fmt.Errorf("LFS download failed: %w", err)
+	}
+	defer dlResp.Body.Close() //nolint:errcheck
+	if dlResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("LFS download failed: status %d", dlResp.StatusCode)
+	}
+	content, err := io.ReadAll(dlResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read LFS object: %w", err)
+	}
+
+	sum := sha256.Sum256(content)
+	if hex.EncodeToString(sum[:]) != pointer.Oid {
+		return nil, fmt.Errorf("LFS object %s failed checksum verification", pointer.Oid)
+	}
+	return content, nil
+}
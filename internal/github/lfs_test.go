@@ -0,0 +1,109 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func lfsPointerFor(content []byte) []byte {
+	sum := sha256.Sum256(content)
+	return fmt.Appendf(nil, "version https://git-lfs.github.com/spec/v1\noid sha256:%s\nsize %d\n",
+		hex.EncodeToString(sum[:]), len(content))
+}
+
+func TestParseLFSPointer(t *testing.T) {
+	content := []byte(`{"big":true}`)
+	pointer := lfsPointerFor(content)
+	if !IsLFSPointer(pointer) {
+		t.Fatalf("IsLFSPointer(%q) = false", pointer)
+	}
+	if IsLFSPointer(content) {
+		t.Errorf("IsLFSPointer(%q) = true", content)
+	}
+	p, err := ParseLFSPointer(pointer)
+	if err != nil {
+		t.Fatalf("ParseLFSPointer: %v", err)
+	}
+	sum := sha256.Sum256(content)
+	if p.Oid != hex.EncodeToString(sum[:]) || p.Size != int64(len(content)) {
+		t.Errorf("ParseLFSPointer() = %+v", p)
+	}
+	if _, err := ParseLFSPointer([]byte("version https://git-lfs.github.com/spec/v1\nsize 3\n")); err == nil {
+		t.Error("ParseLFSPointer without oid succeeded, want error")
+	}
+}
+
+func TestFetchLFSObject(t *testing.T) {
+	content := []byte(`{"big":true}`)
+	p, _ := ParseLFSPointer(lfsPointerFor(content))
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/batch", "/batch-corrupt":
+			if _, pass, _ := r.BasicAuth(); pass != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			var req lfsBatchRequest
+			_ = json.NewDecoder(r.Body).Decode(&req)
+			if req.Operation != "download" || len(req.Objects) != 1 || req.Objects[0].Oid != p.Oid {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = fmt.Fprintf(w, `{"objects":[{"oid":%q,"actions":{"download":{"href":%q,"header":{"X-Signed":"1"}}}}]}`,
+				p.Oid, srv.URL+"/object"+strings.TrimPrefix(r.URL.Path, "/batch"))
+		case "/object":
+			if r.Header.Get("Authorization") != "" || r.Header.Get("X-Signed") != "1" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			_, _ = w.Write(content)
+		case "/object-corrupt":
+			_, _ = w.Write([]byte(`{"big":false}`))
+		}
+	}))
+	defer srv.Close()
+
+	d := &Downloader{Token: "secret"}
+	got, err := d.fetchLFSObject(context.Background(), srv.URL+"/batch", p)
+	if err != nil {
+		t.Fatalf("fetchLFSObject: %v", err)
+	}
+	if string(got) != string(content) {
+		t.Errorf("fetchLFSObject() = %q, want %q", got, content)
+	}
+
+	if _, err := (&Downloader{Token: "wrong"}).fetchLFSObject(context.Background(), srv.URL+"/batch", p); err == nil {
+		t.Error("fetchLFSObject with a bad token succeeded, want error")
+	}
+	if _, err := d.fetchLFSObject(context.Background(), srv.URL+"/batch-corrupt", p); err == nil {
+		t.Error("fetchLFSObject with mismatched content succeeded, want error")
+	}
+	if _, err := d.fetchLFSObject(context.Background(), srv.URL+"/batch", LFSPointer{Oid: "other", Size: 1}); err == nil {
+		t.Error("fetchLFSObject for an unknown object succeeded, want error")
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/time/rate"
+)
+
+// requestLimits paces every request the package makes (API calls, archive
+// and LFS downloads) per destination host, across all reconciles. Unlimited
+// until SetRequestsPerSecond is called.
+var requestLimits = &hostLimiter{limit: rate.Inf}
+
+// SetRequestsPerSecond caps requests to each host at rps per second, with a
+// burst of rps rounded up (rps <= 0 removes the cap). It must be called at
+// startup, before any request is made.
+func SetRequestsPerSecond(rps float64) {
+	l := &hostLimiter{limit: rate.Inf}
+	if rps > 0 {
+		l.limit = rate.Limit(rps)
+		l.burst = int(math.Ceil(rps))
+	}
+	requestLimits = l
+}
+
+// RateLimitWaiting returns how many requests are currently waiting for the
+// rate limiter.
+func RateLimitWaiting() int {
+	return int(requestLimits.waiting.Load())
+}
+
+// RateLimitSaturation returns how much of the burst is used up on the
+// busiest host, from 0 (idle) to 1 (requests have to wait). Always 0 when
+// unlimited.
+func RateLimitSaturation() float64 {
+	return requestLimits.saturation()
+}
+
+// hostLimiter holds one token bucket per host.
+type hostLimiter struct {
+	limit   rate.Limit
+	burst   int
+	mu      sync.Mutex
+	hosts   map[string]*rate.Limiter
+	waiting atomic.Int64
+}
+
+// wait blocks until a request to host may be sent or ctx is done.
+func (h *hostLimiter) wait(ctx context.Context, host string) error {
+	if h.limit == rate.Inf {
+		return nil
+	}
+	h.mu.Lock()
+	if h.hosts == nil {
+		h.hosts = map[string]*rate.Limiter{}
+	}
+	l, ok := h.hosts[host]
+	if !ok {
+		l = rate.NewLimiter(h.limit, h.burst)
+		h.hosts[host] = l
+	}
+	h.mu.Unlock()
+
+	h.waiting.Add(1)
+	defer h.waiting.Add(-1)
+	if err := l.Wait(ctx); err != nil {
+		return fmt.Errorf("waiting for the %s rate limit: %w", host, err)
+	}
+	return nil
+}
+
+func (h *hostLimiter) saturation() float64 {
+	if h.limit == rate.Inf {
+		return 0
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	var sat float64
+	for _, l := range h.hosts {
+		used := 1 - l.Tokens()/float64(h.burst)
+		sat = max(sat, min(used, 1))
+	}
+	return sat
+}
+
+// rateLimitedTransport waits for the host's rate limit before each request,
+// redirects included.
+type rateLimitedTransport struct {
+	base http.RoundTripper
+}
+
+func (t *rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := requestLimits.wait(req.Context(), req.URL.Host); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSetRequestsPerSecond(t *testing.T) {
+	saved := requestLimits
+	t.Cleanup(func() { requestLimits = saved })
+
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer srv.Close()
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer other.Close()
+
+	SetRequestsPerSecond(20) // burst 20, then a request every 50ms per host
+	if got := RateLimitSaturation(); got != 0 {
+		t.Errorf("saturation before any request = %v, want 0", got)
+	}
+	start := time.Now()
+	for range 23 {
+		resp, err := httpClient.Get(srv.URL)
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		_ = resp.Body.Close()
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("23 requests took %v, want the 3 above the burst paced at 20/s", elapsed)
+	}
+	if got := RateLimitSaturation(); got < 0.9 {
+		t.Errorf("saturation after a burst = %v, want the bucket drained", got)
+	}
+
+	// Hosts have their own buckets
+	start = time.Now()
+	resp, err := httpClient.Get(other.URL)
+	if err != nil {
+		t.Fatalf("Get other host: %v", err)
+	}
+	_ = resp.Body.Close()
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("first request to another host took %v, want no wait", elapsed)
+	}
+
+	// A caller that can't wait for a token gives up
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+	if _, err := httpClient.Do(req); err == nil {
+		t.Error("request with a too short deadline succeeded, want a rate limit error")
+	}
+	if got := calls.Load(); got != 23 {
+		t.Errorf("server got %d requests, want 23", got)
+	}
+	if RateLimitWaiting() != 0 {
+		t.Errorf("waiting = %d after all requests returned, want 0", RateLimitWaiting())
+	}
+
+	SetRequestsPerSecond(0)
+	if err := requestLimits.wait(context.Background(), "example.com"); err != nil || RateLimitSaturation() != 0 {
+		t.Errorf("unlimited: wait = %v, saturation = %v", err, RateLimitSaturation())
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// TokenAccess is what a token can see of one repository.
+type TokenAccess struct {
+	// Status is the HTTP status of GET /repos/{org}/{repo}: 200 when the
+	// token can read it, 404 when the repository is missing or hidden from
+	// the token, 401 for a bad token.
+	Status int
+	// Private reports the repository's visibility when Status is 200.
+	Private bool
+	// Scopes are the classic OAuth scopes from X-OAuth-Scopes. It is nil
+	// when GitHub sent no such header, as for fine-grained and app tokens
+	// or no token at all.
+	Scopes []string
+}
+
+// HasScope reports whether the token's classic scopes include scope.
+func (a TokenAccess) HasScope(scope string) bool {
+	return slices.Contains(a.Scopes, scope)
+}
+
+// CheckTokenAccess asks GitHub what token can see of org/repo. A 404 is
+// not an error: it is the answer a token without the repo scope gets for a
+// private repository.
+func CheckTokenAccess(ctx context.Context, token, org, repo string) (TokenAccess, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("%s/repos/%s/%s", apiBaseURL, org, repo), nil)
+	if err != nil {
+		return TokenAccess{}, fmt.Errorf("failed to create repository request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if token != "" {
+		req.Header.Set("Authorization", "token "+token)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return TokenAccess{}, fmt.Errorf("repository request failed: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	access := TokenAccess{Status: resp.StatusCode}
+	if _, ok := resp.Header[http.CanonicalHeaderKey("X-OAuth-Scopes")]; ok {
+		access.Scopes = []string{}
+		for _, scope := range strings.Split(resp.Header.Get("X-OAuth-Scopes"), ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				access.Scopes = append(access.Scopes, scope)
+			}
+		}
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var body struct {
+			Private bool `json:"private"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			return TokenAccess{}, fmt.Errorf("failed to decode repository response: %w", err)
+		}
+		access.Private = body.Private
+	case http.StatusNotFound, http.StatusUnauthorized, http.StatusForbidden:
+	default:
+		return TokenAccess{}, fmt.Errorf("repository request for %s/%s failed: status %d", org, repo, resp.StatusCode)
+	}
+	return access, nil
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestCheckTokenAccess(t *testing.T) {
+	withAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/deco-sites/public":
+			w.Header().Set("X-OAuth-Scopes", "read:org, public_repo")
+			_, _ = w.Write([]byte(`{"private":false}`))
+		case "/repos/deco-sites/private":
+			if r.Header.Get("Authorization") != "token fine-grained" {
+				w.Header().Set("X-OAuth-Scopes", "public_repo")
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(`{"private":true}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+
+	tests := []struct {
+		name, token, repo string
+		want              TokenAccess
+	}{
+		{"public repo", "classic", "public", TokenAccess{Status: http.StatusOK, Scopes: []string{"read:org", "public_repo"}}},
+		{"private repo without repo scope", "classic", "private", TokenAccess{Status: http.StatusNotFound, Scopes: []string{"public_repo"}}},
+		{"private repo with a fine-grained token", "fine-grained", "private", TokenAccess{Status: http.StatusOK, Private: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CheckTokenAccess(context.Background(), tt.token, "deco-sites", tt.repo)
+			if err != nil {
+				t.Fatalf("CheckTokenAccess: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckTokenAccess() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if got := (TokenAccess{Scopes: []string{"repo"}}); !got.HasScope("repo") {
+		t.Error("HasScope(repo) = false")
+	}
+	if _, err := CheckTokenAccess(context.Background(), "classic", "deco-sites", "broken"); err == nil {
+		t.Error("expected error for a 500 response")
+	}
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	pathpkg "path"
+	"strings"
+	"sync"
+)
+
+// maxTreeCacheEntries bounds treeCache; it is simply reset when full.
+const maxTreeCacheEntries = 1024
+
+// treeCache remembers the last tree SHA and ETag per API URL, so repeated
+// lookups are conditional requests (a 304 doesn't count against the rate
+// limit) and lookups at a commit SHA, which can't change, make none at all.
+var treeCache = struct {
+	sync.Mutex
+	m map[string]cachedTree
+}{m: map[string]cachedTree{}}
+
+type cachedTree struct {
+	etag, sha string
+}
+
+// TreeSHA returns the SHA of the git tree holding everything a download of
+// path at ref extracts. The archive is filtered by path prefix, so that is
+// path itself when it ends in "/", else its parent directory. Equal SHAs mean
+// the extracted files are identical, whatever commit they come from.
+func TreeSHA(ctx context.Context, token, org, repo, ref, path string) (string, error) {
+	scope := strings.Trim(path, "/")
+	if !strings.HasSuffix(path, "/") {
+		if scope = pathpkg.Dir(scope); scope == "." {
+			scope = ""
+		}
+	}
+
+	if scope == "" {
+		// Root tree, from the commit itself
+		var commit struct {
+			Commit struct {
+				Tree struct {
+					SHA string `json:"sha"`
+				} `json:"tree"`
+			} `json:"commit"`
+		}
+		u := fmt.Sprintf("%s/repos/%s/%s/commits/%s", apiBaseURL, org, repo, url.PathEscape(ref))
+		return cachedGet(ctx, token, u, IsCommitSHA(ref), func(body []byte) (string, error) {
+			if err := json.Unmarshal(body, &commit); err != nil {
+				return "", err
+			}
+			return commit.Commit.Tree.SHA, nil
+		})
+	}
+
+	// A directory's own SHA is listed by its parent
+	parent, name := pathpkg.Split(scope)
+	u := fmt.Sprintf("%s/repos/%s/%s/contents", apiBaseURL, org, repo)
+	if parent != "" {
+		u += "/" + strings.TrimSuffix(parent, "/")
+	}
+	u += "?ref=" + url.QueryEscape(ref)
+	return cachedGet(ctx, token, u, IsCommitSHA(ref), func(body []byte) (string, error) {
+		var entries []struct {
+			Name string `json:"name"`
+			Type string `json:"type"`
+			SHA  string `json:"sha"`
+		}
+		if err := json.Unmarshal(body, &entries); err != nil {
+			return "", err
+		}
+		for _, e := range entries {
+			if e.Name == name && e.Type == "dir" {
+				return e.SHA, nil
+			}
+		}
+		return "", fmt.Errorf("directory %s not found in %s/%s@%s", scope, org, repo, ref)
+	})
+}
+
+// cachedGet fetches u and extracts a SHA from it with parse, going through
+// treeCache. immutable entries are served from the cache without a request.
+func cachedGet(ctx context.Context, token, u string, immutable bool, parse func([]byte) (string, error)) (string, error) {
+	treeCache.Lock()
+	cached, ok := treeCache.m[u]
+	treeCache.Unlock()
+	if ok && immutable {
+		return cached.sha, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create tree request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if token != "" {
+		req.Header.Set("Authorization", "token "+token)
+	}
+	if ok {
+		req.Header.Set("If-None-Match", cached.etag)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("tree request failed: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		if ok {
+			return cached.sha, nil
+		}
+		return "", fmt.Errorf("tree request failed: unexpected 304")
+	case http.StatusOK:
+	default:
+		return "", fmt.Errorf("tree request failed: status %d", resp.StatusCode)
+	}
+
+	var body json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode tree response: %w", err)
+	}
+	sha, err := parse(body)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode tree response: %w", err)
+	}
+	if sha == "" {
+		return "", fmt.Errorf("tree response has no SHA")
+	}
+
+	treeCache.Lock()
+	if len(treeCache.m) >= maxTreeCacheEntries {
+		treeCache.m = map[string]cachedTree{}
+	}
+	treeCache.m[u] = cachedTree{etag: resp.Header.Get("ETag"), sha: sha}
+	treeCache.Unlock()
+	return sha, nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package github
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestTreeSHA(t *testing.T) {
+	var notModified atomic.Int32
+	withAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"e1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"e1"`)
+		switch r.URL.Path {
+		case "/repos/o/r/commits/main":
+			_, _ = w.Write([]byte(`{"sha":"` + shaA + `","commit":{"tree":{"sha":"root-tree"}}}`))
+		case "/repos/o/r/contents":
+			_, _ = w.Write([]byte(`[{"name":".deco","type":"dir","sha":"deco-tree"},{"name":"README.md","type":"file","sha":"x"}]`))
+		case "/repos/o/r/contents/.deco":
+			_, _ = w.Write([]byte(`[{"name":"blocks","type":"dir","sha":"blocks-tree"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	t.Cleanup(func() { treeCache.m = map[string]cachedTree{} })
+
+	cases := []struct{ ref, path, want string }{
+		{"main", "", "root-tree"},
+		// Prefix-filtered downloads depend on the whole parent directory
+		{"main", ".deco/blocks", "deco-tree"},
+		{"main", ".deco/blocks/", "blocks-tree"},
+	}
+	for _, tc := range cases {
+		got, err := TreeSHA(context.Background(), "", "o", "r", tc.ref, tc.path)
+		if err != nil || got != tc.want {
+			t.Errorf("TreeSHA(%q, %q) = %q, %v; want %q", tc.ref, tc.path, got, err, tc.want)
+		}
+	}
+
+	// Repeated branch lookups are conditional requests
+	if got, err := TreeSHA(context.Background(), "", "o", "r", "main", ""); err != nil || got != "root-tree" {
+		t.Errorf("cached TreeSHA = %q, %v; want root-tree", got, err)
+	}
+	if notModified.Load() != 1 {
+		t.Errorf("304 responses = %d, want 1", notModified.Load())
+	}
+
+	if _, err := TreeSHA(context.Background(), "", "o", "r", "main", "missing/dir/"); err == nil {
+		t.Error("TreeSHA for a missing directory succeeded, want error")
+	}
+}
+
+func TestTreeSHA_CommitIsCached(t *testing.T) {
+	var requests atomic.Int32
+	withAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte(`{"commit":{"tree":{"sha":"root-tree"}}}`))
+	})
+	t.Cleanup(func() { treeCache.m = map[string]cachedTree{} })
+
+	for range 3 {
+		if got, err := TreeSHA(context.Background(), "", "o", "r", shaA, ""); err != nil || got != "root-tree" {
+			t.Fatalf("TreeSHA = %q, %v; want root-tree", got, err)
+		}
+	}
+	if requests.Load() != 1 {
+		t.Errorf("requests = %d, want 1 for an immutable commit", requests.Load())
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// DefaultCertMinValidity is the default --webhook-cert-min-validity. A
+// cert-manager certificate is renewed long before that, so one this close to
+// expiry means rotation is stuck.
+const DefaultCertMinValidity = 24 * time.Hour
+
+// CertificateReadyCheck fails readiness when the serving certificate returned
+// by getCert is not yet valid, expires within minValidity, or, when dnsName
+// is set, is not valid for dnsName (the webhook Service's DNS name). Expired
+// or mismatched certificates then show up as a not-ready pod instead of
+// admission requests failing their TLS handshake.
+func CertificateReadyCheck(getCert func(*tls.ClientHelloInfo) (*tls.Certificate, error), dnsName string, minValidity time.Duration) healthz.Checker {
+	return func(_ *http.Request) error {
+		cert, err := getCert(nil)
+		if err != nil {
+			return fmt.Errorf("no webhook serving certificate: %w", err)
+		}
+		if cert == nil || len(cert.Certificate) == 0 {
+			return fmt.Errorf("no webhook serving certificate loaded")
+		}
+		leaf := cert.Leaf
+		if leaf == nil {
+			if leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+				return fmt.Errorf("invalid webhook serving certificate: %w", err)
+			}
+		}
+
+		now := time.Now()
+		if now.Before(leaf.NotBefore) {
+			return fmt.Errorf("webhook serving certificate is not valid before %s", leaf.NotBefore.UTC().Format(time.RFC3339))
+		}
+		if left := leaf.NotAfter.Sub(now); left < minValidity {
+			if left <= 0 {
+				return fmt.Errorf("webhook serving certificate expired at %s", leaf.NotAfter.UTC().Format(time.RFC3339))
+			}
+			return fmt.Errorf("webhook serving certificate expires in %s, at %s", left.Round(time.Minute), leaf.NotAfter.UTC().Format(time.RFC3339))
+		}
+		if dnsName != "" {
+			if err := leaf.VerifyHostname(dnsName); err != nil {
+				return fmt.Errorf("webhook serving certificate does not match the webhook service: %w", err)
+			}
+		}
+		return nil
+	}
+}
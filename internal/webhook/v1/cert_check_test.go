@@ -0,0 +1,90 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Run without envtest: go test -run TestCertificateReadyCheck ./internal/webhook/v1/
+
+func selfSigned(t *testing.T, dnsName string, notBefore, notAfter time.Time) *tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: dnsName},
+		DNSNames:     []string{dnsName},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	return &tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+func TestCertificateReadyCheck(t *testing.T) {
+	const svc = "decofile-operator-webhook-service.decofile-operator-system.svc"
+	now := time.Now()
+	cases := []struct {
+		name    string
+		cert    *tls.Certificate
+		certErr error
+		dnsName string
+		wantErr string
+	}{
+		{name: "valid", cert: selfSigned(t, svc, now.Add(-time.Hour), now.Add(90*24*time.Hour)), dnsName: svc},
+		{name: "name check disabled", cert: selfSigned(t, "other.svc", now.Add(-time.Hour), now.Add(90*24*time.Hour))},
+		{name: "expired", cert: selfSigned(t, svc, now.Add(-48*time.Hour), now.Add(-time.Hour)), dnsName: svc, wantErr: "expired"},
+		{name: "near expiry", cert: selfSigned(t, svc, now.Add(-48*time.Hour), now.Add(time.Hour)), dnsName: svc, wantErr: "expires in"},
+		{name: "not yet valid", cert: selfSigned(t, svc, now.Add(time.Hour), now.Add(90*24*time.Hour)), dnsName: svc, wantErr: "not valid before"},
+		{name: "wrong service", cert: selfSigned(t, "other.svc", now.Add(-time.Hour), now.Add(90*24*time.Hour)), dnsName: svc, wantErr: "does not match"},
+		{name: "not loaded", certErr: errors.New("no such file"), wantErr: "no webhook serving certificate"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := CertificateReadyCheck(func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+				return tc.cert, tc.certErr
+			}, tc.dnsName, DefaultCertMinValidity)
+			err := check(nil)
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Errorf("check() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("check() = %v, want error containing %q", err, tc.wantErr)
+			}
+		})
+	}
+}
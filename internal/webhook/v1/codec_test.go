@@ -0,0 +1,83 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	servingknativedevv1 "knative.dev/serving/pkg/apis/serving/v1"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+// Run without envtest: go test -run TestInjectDecofileVolume_Codecs ./internal/webhook/v1/
+func TestInjectDecofileVolume_Codecs(t *testing.T) {
+	cases := []struct {
+		codecs      string
+		wantRelease string
+		wantAnnot   string
+	}{
+		{"", "file:///app/decofile/decofile.bin", ""},
+		{"br,gzip", "file:///app/decofile/decofile.bin", ""},
+		{"gzip", "file:///app/decofile/decofile.gz", decositesv1alpha1.CodecGzip},
+		{"none", "file:///app/decofile/decofile.json", decositesv1alpha1.CodecIdentity},
+	}
+	df := &decositesv1alpha1.Decofile{ObjectMeta: metav1.ObjectMeta{Name: "site", Namespace: "sites-foo"}}
+	for _, tc := range cases {
+		svc := &servingknativedevv1.Service{}
+		svc.Annotations = map[string]string{decofileCodecsAnnot: tc.codecs}
+		svc.Spec.Template.Spec.Containers = []corev1.Container{{Name: appContainerName}}
+
+		d := &ServiceCustomDefaulter{}
+		if err := d.injectDecofileVolume(context.Background(), svc, df, "/app/decofile"); err != nil {
+			t.Fatalf("injectDecofileVolume(%q): %v", tc.codecs, err)
+		}
+		var release string
+		for _, env := range svc.Spec.Template.Spec.Containers[0].Env {
+			if env.Name == decoReleaseEnvVar {
+				release = env.Value
+			}
+		}
+		if release != tc.wantRelease {
+			t.Errorf("codecs %q: DECO_RELEASE = %q, want %q", tc.codecs, release, tc.wantRelease)
+		}
+		if got := svc.Spec.Template.Annotations[decofileCodecAnnot]; got != tc.wantAnnot {
+			t.Errorf("codecs %q: template %s = %q, want %q", tc.codecs, decofileCodecAnnot, got, tc.wantAnnot)
+		}
+	}
+}
+
+// Run without envtest: go test -run TestInjectDecofileVolume_DecofileCompression ./internal/webhook/v1/
+func TestInjectDecofileVolume_DecofileCompression(t *testing.T) {
+	df := &decositesv1alpha1.Decofile{ObjectMeta: metav1.ObjectMeta{Name: "site", Namespace: "sites-foo"}}
+	df.Spec.Compression = decositesv1alpha1.CompressionGzip
+	for _, tc := range []struct {
+		annotations map[string]string
+		wantRelease string
+	}{
+		{nil, "file:///app/decofile/decofile.gz"},
+		{map[string]string{decofileCodecsAnnot: "none"}, "file:///app/decofile/decofile.json"},
+		{map[string]string{decofileCodecsAnnot: "br"}, "file:///app/decofile/decofile.bin"},
+	} {
+		svc := &servingknativedevv1.Service{}
+		svc.Annotations = tc.annotations
+		svc.Spec.Template.Spec.Containers = []corev1.Container{{Name: appContainerName}}
+
+		if err := (&ServiceCustomDefaulter{}).injectDecofileVolume(context.Background(), svc, df, "/app/decofile"); err != nil {
+			t.Fatalf("injectDecofileVolume(%v): %v", tc.annotations, err)
+		}
+		for _, env := range svc.Spec.Template.Spec.Containers[0].Env {
+			if env.Name == decoReleaseEnvVar && env.Value != tc.wantRelease {
+				t.Errorf("annotations %v: DECO_RELEASE = %q, want %q", tc.annotations, env.Value, tc.wantRelease)
+			}
+		}
+	}
+}
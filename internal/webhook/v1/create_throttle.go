@@ -0,0 +1,95 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// DefaultDecofileCreateBurst is the default --decofile-create-burst.
+const DefaultDecofileCreateBurst = 10
+
+// maxCreateThrottleWait is how long a create is held in the webhook waiting
+// for a token before it is rejected with 429. Kept well under the API
+// server's default 10s webhook timeout.
+const maxCreateThrottleWait = 5 * time.Second
+
+// decofileCreatesThrottled counts creates that had to wait for, or were
+// refused, a token.
+var decofileCreatesThrottled = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "deco_operator",
+	Subsystem: "decofile",
+	Name:      "creates_throttled_total",
+	Help:      "Total number of Decofile creates delayed or rejected by the admission rate limiter.",
+}, []string{"outcome"}) // outcome: delayed | rejected
+
+func init() {
+	metrics.Registry.MustRegister(decofileCreatesThrottled)
+}
+
+// newCreateLimiter returns the token bucket for Decofile creates, or nil when
+// throttling is off (qps <= 0). Each webhook replica has its own bucket.
+func newCreateLimiter(qps float64, burst int) *rate.Limiter {
+	if qps <= 0 {
+		return nil
+	}
+	return rate.NewLimiter(rate.Limit(qps), max(burst, 1))
+}
+
+// throttleCreate takes a token from limiter, waiting up to
+// maxCreateThrottleWait for one. When none is due in time it returns a 429
+// with Retry-After, which kubectl and GitOps tools retry. Dry-run requests
+// (e.g. Argo CD's diff) don't spend tokens.
+func throttleCreate(ctx context.Context, limiter *rate.Limiter) error {
+	if limiter == nil {
+		return nil
+	}
+	if req, err := admission.RequestFromContext(ctx); err == nil && req.DryRun != nil && *req.DryRun {
+		return nil
+	}
+
+	r := limiter.Reserve()
+	delay := r.Delay()
+	if delay == 0 {
+		return nil
+	}
+	if delay > maxCreateThrottleWait {
+		r.Cancel()
+		decofileCreatesThrottled.WithLabelValues("rejected").Inc()
+		return apierrors.NewTooManyRequests("too many Decofile creates, retry later",
+			int(math.Ceil(delay.Seconds())))
+	}
+
+	decofileCreatesThrottled.WithLabelValues("delayed").Inc()
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		r.Cancel()
+		return apierrors.NewTooManyRequests("too many Decofile creates, retry later", 1)
+	}
+}
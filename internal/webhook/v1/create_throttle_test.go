@@ -0,0 +1,52 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"testing"
+
+	"golang.org/x/time/rate"
+	admissionv1 "k8s.io/api/admission/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// Run without envtest: go test -run TestThrottleCreate ./internal/webhook/v1/
+func TestThrottleCreate(t *testing.T) {
+	ctx := context.Background()
+	if err := throttleCreate(ctx, nil); err != nil {
+		t.Fatalf("nil limiter: %v", err)
+	}
+
+	// One token, refilled every ~17 minutes: the second create can't wait it out
+	limiter := rate.NewLimiter(rate.Limit(0.001), 1)
+	if err := throttleCreate(ctx, limiter); err != nil {
+		t.Fatalf("first create: %v", err)
+	}
+
+	dryRun := admission.NewContextWithRequest(ctx, admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{DryRun: ptr.To(true)},
+	})
+	if err := throttleCreate(dryRun, limiter); err != nil {
+		t.Errorf("dry-run create: %v, want no throttling", err)
+	}
+
+	err := throttleCreate(ctx, limiter)
+	if !apierrors.IsTooManyRequests(err) {
+		t.Fatalf("create over the limit: %v, want 429", err)
+	}
+	if secs, ok := apierrors.SuggestsClientDelay(err); !ok || secs <= 0 {
+		t.Errorf("Retry-After = %d, %v; want a positive delay", secs, ok)
+	}
+	// A rejected create gives its reservation back
+	if tokens := limiter.Tokens(); tokens < -0.01 {
+		t.Errorf("tokens after rejection = %v, want ~0", tokens)
+	}
+}
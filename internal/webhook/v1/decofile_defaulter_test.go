@@ -0,0 +1,50 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"testing"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+// Run without envtest: go test -run TestDecofileDefault ./internal/webhook/v1/
+func TestDecofileDefault_NormalizesGitHubSource(t *testing.T) {
+	df := &decositesv1alpha1.Decofile{Spec: decositesv1alpha1.DecofileSpec{
+		Source: decositesv1alpha1.SourceGitHub,
+		GitHub: &decositesv1alpha1.GitHubSource{
+			Org:    "https://github.com/deco-sites/",
+			Repo:   "storefront.git",
+			Commit: "abc123",
+			Path:   ".deco/blocks",
+		},
+	}}
+	if err := (&DecofileCustomDefaulter{}).Default(context.Background(), df); err != nil {
+		t.Fatalf("Default() error = %v", err)
+	}
+	if df.Spec.GitHub.Org != "deco-sites" || df.Spec.GitHub.Repo != "storefront" {
+		t.Errorf("github = %s/%s, want deco-sites/storefront", df.Spec.GitHub.Org, df.Spec.GitHub.Repo)
+	}
+	if err := df.Spec.Validate(); err != nil {
+		t.Errorf("normalized spec does not validate: %v", err)
+	}
+}
+
+func TestDecofileDefault_InlineUntouched(t *testing.T) {
+	df := &decositesv1alpha1.Decofile{Spec: decositesv1alpha1.DecofileSpec{
+		Source: decositesv1alpha1.SourceInline,
+		Inline: &decositesv1alpha1.InlineSource{},
+	}}
+	if err := (&DecofileCustomDefaulter{}).Default(context.Background(), df); err != nil {
+		t.Fatalf("Default() error = %v", err)
+	}
+	if df.Spec.GitHub != nil {
+		t.Errorf("github = %+v, want nil", df.Spec.GitHub)
+	}
+}
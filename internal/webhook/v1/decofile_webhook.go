@@ -19,7 +19,14 @@ package v1
 import (
 	"context"
 	"fmt"
+	"os"
+	"strings"
 
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	servingknativedevv1 "knative.dev/serving/pkg/apis/serving/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -35,37 +42,180 @@ import (
 var decofilelog = logf.Log.WithName("decofile-resource")
 
 // +kubebuilder:rbac:groups=serving.knative.dev,resources=services,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get
+
+const (
+	operatorNamespaceEnvVar = "OPERATOR_NAMESPACE"
+	serviceAccountNamespace = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+)
+
+// operatorNamespace returns the namespace the operator runs in: the
+// OPERATOR_NAMESPACE env var, falling back to the in-cluster service account
+// namespace. Empty when neither is available (e.g. running locally).
+func operatorNamespace() string {
+	if ns := os.Getenv(operatorNamespaceEnvVar); ns != "" {
+		return ns
+	}
+	data, err := os.ReadFile(serviceAccountNamespace)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// DecofileWebhookOptions are the settings of the Decofile webhook, set from
+// flags in main.
+type DecofileWebhookOptions struct {
+	// DeploymentIdLabel is as for SetupServiceWebhookWithManager.
+	DeploymentIdLabel string
+	// DefaultGitHubSecret is the --github-default-secret the controller
+	// authenticates GitHub sources with, used here to verify commits.
+	DefaultGitHubSecret string
+	// CreateQPS and CreateBurst throttle Decofile creates
+	// (--decofile-create-qps, --decofile-create-burst). CreateQPS <= 0
+	// disables throttling.
+	CreateQPS   float64
+	CreateBurst int
+}
 
 // SetupDecofileWebhookWithManager registers the webhook for Decofile in the manager.
-func SetupDecofileWebhookWithManager(mgr ctrl.Manager) error {
+func SetupDecofileWebhookWithManager(mgr ctrl.Manager, opts DecofileWebhookOptions) error {
 	return ctrl.NewWebhookManagedBy(mgr).For(&decositesv1alpha1.Decofile{}).
-		WithValidator(&DecofileCustomValidator{Client: mgr.GetClient()}).
+		WithDefaulter(&DecofileCustomDefaulter{}).
+		WithValidator(&DecofileCustomValidator{
+			Client:              mgr.GetClient(),
+			OperatorNamespace:   operatorNamespace(),
+			CreateLimiter:       newCreateLimiter(opts.CreateQPS, opts.CreateBurst),
+			DeploymentIdLabel:   opts.DeploymentIdLabel,
+			DefaultGitHubSecret: opts.DefaultGitHubSecret,
+		}).
 		Complete()
 }
 
-// +kubebuilder:webhook:path=/validate-deco-sites-v1alpha1-decofile,mutating=false,failurePolicy=fail,sideEffects=None,groups=deco.sites,resources=decofiles,verbs=delete,versions=v1alpha1,name=vdecofile.kb.io,admissionReviewVersions=v1
+// +kubebuilder:webhook:path=/mutate-deco-sites-v1alpha1-decofile,mutating=true,failurePolicy=fail,sideEffects=None,groups=deco.sites,resources=decofiles,verbs=create;update,versions=v1alpha1,name=mdecofile.kb.io,admissionReviewVersions=v1
+
+// DecofileCustomDefaulter struct is responsible for setting default values on the Decofile resource
+// when it is created or updated.
+//
+// NOTE: The +kubebuilder:object:generate=false marker prevents controller-gen from generating DeepCopy methods,
+// as this struct is used only for temporary operations and does not need to be deeply copied.
+type DecofileCustomDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &DecofileCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the type Decofile.
+// It strips pasted URL prefixes, trailing slashes and ".git" from spec.github (and composite github entries)
+// org/repo so BuildZipURL doesn't produce a codeload URL that 404s.
+func (d *DecofileCustomDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	decofile, ok := obj.(*decositesv1alpha1.Decofile)
+	if !ok {
+		return fmt.Errorf("expected a Decofile object but got %T", obj)
+	}
+	sources := []*decositesv1alpha1.GitHubSource{decofile.Spec.GitHub}
+	if decofile.Spec.Composite != nil {
+		for i := range decofile.Spec.Composite.Sources {
+			sources = append(sources, decofile.Spec.Composite.Sources[i].GitHub)
+		}
+	}
+	for _, gh := range sources {
+		if gh == nil {
+			continue
+		}
+		org, repo := gh.Org, gh.Repo
+		gh.Normalize()
+		if gh.Org != org || gh.Repo != repo {
+			decofilelog.Info("Normalized GitHub source", "name", decofile.Name, "namespace", decofile.Namespace,
+				"org", gh.Org, "repo", gh.Repo)
+		}
+	}
+	return nil
+}
+
+// +kubebuilder:webhook:path=/validate-deco-sites-v1alpha1-decofile,mutating=false,failurePolicy=fail,sideEffects=None,groups=deco.sites,resources=decofiles,verbs=create;update;delete,versions=v1alpha1,name=vdecofile.kb.io,admissionReviewVersions=v1
 
 // DecofileCustomValidator struct is responsible for validating the Decofile resource
-// when it is deleted.
+// when it is created, updated or deleted.
 //
 // NOTE: The +kubebuilder:object:generate=false marker prevents controller-gen from generating DeepCopy methods,
 // as this struct is used only for temporary operations and does not need to be deeply copied.
 type DecofileCustomValidator struct {
 	Client client.Client
+	// OperatorNamespace is the namespace the operator runs in. Decofiles are
+	// rejected there so their ConfigMaps can't collide with operator config.
+	OperatorNamespace string
+	// CreateLimiter, when set, smooths bursts of creates (e.g. a GitOps sync
+	// applying hundreds of Decofiles) before they reach the controller.
+	CreateLimiter *rate.Limiter
+	// CommitExists checks commits for deco.sites/verify-commit; nil uses
+	// github.CommitExists.
+	CommitExists func(ctx context.Context, token, org, repo, ref string) (bool, error)
+	// DeploymentIdLabel is the Service label holding the deploymentId, read
+	// to find the Services still using a Decofile on delete. Empty means
+	// controller.DefaultDeploymentIdLabel.
+	DeploymentIdLabel string
+	// DefaultGitHubSecret authenticates commit checks for Decofiles without
+	// spec.github.secret, see controller.GitHubToken.
+	DefaultGitHubSecret string
 }
 
 var _ webhook.CustomValidator = &DecofileCustomValidator{}
 
 // ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type Decofile.
 func (v *DecofileCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
-	// No validation on create
+	decofile, ok := obj.(*decositesv1alpha1.Decofile)
+	if !ok {
+		return nil, fmt.Errorf("expected a Decofile object but got %T", obj)
+	}
+	if err := decofile.Spec.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid Decofile %s: %w", decofile.Name, err)
+	}
+
+	// Name and namespace are immutable, so checking on create is enough
+	if v.OperatorNamespace != "" && decofile.Namespace == v.OperatorNamespace {
+		return nil, fmt.Errorf("decofiles cannot be created in the operator namespace %s", v.OperatorNamespace)
+	}
+	if err := throttleCreate(ctx, v.CreateLimiter); err != nil {
+		return nil, err
+	}
+	// The derived ConfigMap name is reserved if a ConfigMap not owned by a
+	// Decofile already holds it; the controller would otherwise overwrite it.
+	cm := &corev1.ConfigMap{}
+	err := v.Client.Get(ctx, client.ObjectKey{Name: decofile.ConfigMapName(), Namespace: decofile.Namespace}, cm)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			// Fail-open like ValidateDelete: don't block on API errors
+			decofilelog.Error(err, "Failed to get ConfigMap during Decofile validation, allowing creation")
+		}
+		return nil, nil
+	}
+	if owner := metav1.GetControllerOf(cm); owner == nil || owner.Kind != "Decofile" {
+		return nil, fmt.Errorf("cannot create Decofile %s: ConfigMap %s already exists and is not managed by a Decofile",
+			decofile.Name, decofile.ConfigMapName())
+	}
 	return nil, nil
 }
 
 // ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type Decofile.
 func (v *DecofileCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
-	// No validation on update
-	return nil, nil
+	decofile, ok := newObj.(*decositesv1alpha1.Decofile)
+	if !ok {
+		return nil, fmt.Errorf("expected a Decofile object for the newObj but got %T", newObj)
+	}
+	oldDecofile, ok := oldObj.(*decositesv1alpha1.Decofile)
+	if !ok {
+		return nil, fmt.Errorf("expected a Decofile object for the oldObj but got %T", oldObj)
+	}
+	// Metadata-only writes (the operator's finalizers and annotations) keep
+	// the stored spec, which may predate a stricter rule; rejecting them
+	// would leave such a Decofile impossible to delete
+	if equality.Semantic.DeepEqual(oldDecofile.Spec, decofile.Spec) {
+		return nil, nil
+	}
+	if err := decofile.Spec.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid Decofile %s: %w", decofile.Name, err)
+	}
+	return v.verifyCommit(ctx, oldDecofile, decofile)
 }
 
 // ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type Decofile.
@@ -77,6 +227,14 @@ func (v *DecofileCustomValidator) ValidateDelete(ctx context.Context, obj runtim
 
 	decofilelog.Info("Validating Decofile deletion", "name", decofile.Name, "namespace", decofile.Namespace)
 
+	// spec.deletionPolicy allow/orphan opt out of the in-use check; orphan
+	// keeps the ConfigMap, which the controller releases before deletion
+	switch decofile.Spec.DeletionPolicy {
+	case decositesv1alpha1.DeletionAllow, decositesv1alpha1.DeletionOrphan:
+		decofilelog.Info("Decofile deletion allowed by deletionPolicy", "name", decofile.Name, "deletionPolicy", decofile.Spec.DeletionPolicy)
+		return nil, nil
+	}
+
 	// Determine deploymentId for this Decofile
 	deploymentId := decofile.Spec.DeploymentId
 	if deploymentId == "" {
@@ -100,11 +258,8 @@ func (v *DecofileCustomValidator) ValidateDelete(ctx context.Context, obj runtim
 		// Check if Service has injection enabled
 		if svc.Annotations != nil && svc.Annotations[decofileInjectAnnot] == "true" {
 			// Check if Service's deploymentId matches this Decofile
-			if svc.Labels != nil {
-				svcDeploymentId := svc.Labels[deploymentIdLabel]
-				if svcDeploymentId == deploymentId {
-					usingServices = append(usingServices, svc.Name)
-				}
+			if serviceDeploymentId(svc, v.DeploymentIdLabel) == deploymentId || serviceUsesExtraDecofile(svc, decofile) {
+				usingServices = append(usingServices, svc.Name)
 			}
 		}
 	}
@@ -113,7 +268,7 @@ func (v *DecofileCustomValidator) ValidateDelete(ctx context.Context, obj runtim
 		return admission.Warnings{
 				fmt.Sprintf("Decofile %s is currently in use by %d Service(s)", decofile.Name, len(usingServices)),
 			},
-			fmt.Errorf("cannot delete Decofile %s: still in use by Service(s): %v. Remove deco.sites/decofile-inject annotation or delete the Service(s) first",
+			fmt.Errorf("cannot delete Decofile %s: still in use by Service(s): %v. Remove deco.sites/decofile-inject annotation (or this Decofile from deco.sites/decofile-inject-extra) or delete the Service(s) first",
 				decofile.Name, usingServices)
 	}
 
@@ -0,0 +1,111 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+// Run without envtest: go test -run TestDecofileValidateCreate ./internal/webhook/v1/
+func TestDecofileValidateCreate(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := corev1.AddToScheme(s); err != nil {
+		t.Fatalf("add core scheme: %v", err)
+	}
+
+	unowned := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "decofile-taken", Namespace: "sites-foo"}}
+	owned := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:      "decofile-mine",
+		Namespace: "sites-foo",
+		OwnerReferences: []metav1.OwnerReference{{
+			APIVersion: decositesv1alpha1.GroupVersion.String(),
+			Kind:       "Decofile",
+			Name:       "mine",
+			UID:        "uid-1",
+			Controller: ptr.To(true),
+		}},
+	}}
+
+	cases := []struct {
+		name      string
+		namespace string
+		decofile  string
+		objs      []client.Object
+		spec      decositesv1alpha1.DecofileSpec
+		wantErr   bool
+	}{
+		{name: "site namespace, no ConfigMap", namespace: "sites-foo", decofile: "new"},
+		{name: "operator namespace", namespace: "operator-system", decofile: "new", wantErr: true},
+		{name: "ConfigMap not managed by a Decofile", namespace: "sites-foo", decofile: "taken", objs: []client.Object{unowned}, wantErr: true},
+		{name: "ConfigMap managed by a Decofile", namespace: "sites-foo", decofile: "mine", objs: []client.Object{owned}},
+		{name: "invalid spec", namespace: "sites-foo", decofile: "new", spec: decositesv1alpha1.DecofileSpec{Source: decositesv1alpha1.SourceGitHub}, wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := &DecofileCustomValidator{
+				Client:            fake.NewClientBuilder().WithScheme(s).WithObjects(tc.objs...).Build(),
+				OperatorNamespace: "operator-system",
+			}
+			df := &decositesv1alpha1.Decofile{
+				ObjectMeta: metav1.ObjectMeta{Name: tc.decofile, Namespace: tc.namespace},
+				Spec:       tc.spec,
+			}
+			if df.Spec.Source == "" {
+				df.Spec = decositesv1alpha1.DecofileSpec{
+					Source: decositesv1alpha1.SourceInline,
+					Inline: &decositesv1alpha1.InlineSource{},
+				}
+			}
+			_, err := v.ValidateCreate(context.Background(), df)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("ValidateCreate() err = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+// Run without envtest: go test -run TestDecofileValidateUpdate_MetadataOnly ./internal/webhook/v1/
+func TestDecofileValidateUpdate_MetadataOnly(t *testing.T) {
+	// Stored before incremental was rejected together with layers
+	legacy := &decositesv1alpha1.Decofile{
+		ObjectMeta: metav1.ObjectMeta{Name: "site", Namespace: "sites-foo"},
+		Spec: decositesv1alpha1.DecofileSpec{
+			Source: decositesv1alpha1.SourceGitHub,
+			GitHub: &decositesv1alpha1.GitHubSource{
+				Org: "deco-sites", Repo: "storefront", Commit: "main", Path: ".deco/blocks", Incremental: true,
+				Layers: []decositesv1alpha1.GitHubLayer{{Commit: "promo"}},
+			},
+		},
+	}
+	if err := legacy.Spec.Validate(); err == nil {
+		t.Fatal("test setup: legacy spec passes Validate")
+	}
+	v := &DecofileCustomValidator{}
+
+	released := legacy.DeepCopy()
+	released.Finalizers = []string{"deco.sites/orphan-configmap"}
+	if _, err := v.ValidateUpdate(context.Background(), legacy, released); err != nil {
+		t.Errorf("finalizer-only update: err = %v, want it allowed", err)
+	}
+
+	edited := released.DeepCopy()
+	edited.Spec.GitHub.Commit = "next"
+	if _, err := v.ValidateUpdate(context.Background(), released, edited); err == nil {
+		t.Error("spec update of a legacy spec succeeded, want it validated")
+	}
+}
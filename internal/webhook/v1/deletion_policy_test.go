@@ -0,0 +1,55 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	servingknativedevv1 "knative.dev/serving/pkg/apis/serving/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+	"github.com/deco-sites/decofile-operator/internal/controller"
+)
+
+// Run without envtest: go test -run TestValidateDelete_DeletionPolicy ./internal/webhook/v1/
+func TestValidateDelete_DeletionPolicy(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := servingknativedevv1.AddToScheme(s); err != nil {
+		t.Fatalf("add scheme: %v", err)
+	}
+	svc := &servingknativedevv1.Service{ObjectMeta: metav1.ObjectMeta{
+		Name:        "site",
+		Namespace:   "sites-foo",
+		Labels:      map[string]string{controller.DefaultDeploymentIdLabel: "dep-1"},
+		Annotations: map[string]string{decofileInjectAnnot: "true"},
+	}}
+	v := &DecofileCustomValidator{Client: fake.NewClientBuilder().WithScheme(s).WithObjects(svc).Build()}
+
+	for _, tc := range []struct {
+		policy  string
+		wantErr bool
+	}{
+		{"", true},
+		{decositesv1alpha1.DeletionBlock, true},
+		{decositesv1alpha1.DeletionAllow, false},
+		{decositesv1alpha1.DeletionOrphan, false},
+	} {
+		df := &decositesv1alpha1.Decofile{
+			ObjectMeta: metav1.ObjectMeta{Name: "site", Namespace: "sites-foo"},
+			Spec:       decositesv1alpha1.DecofileSpec{DeploymentId: "dep-1", DeletionPolicy: tc.policy},
+		}
+		_, err := v.ValidateDelete(context.Background(), df)
+		if gotErr := err != nil; gotErr != tc.wantErr {
+			t.Errorf("deletionPolicy %q: err = %v, want error %v", tc.policy, err, tc.wantErr)
+		}
+	}
+}
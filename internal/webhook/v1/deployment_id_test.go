@@ -0,0 +1,86 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+*/
+
+package v1
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	servingknativedevv1 "knative.dev/serving/pkg/apis/serving/v1"
+
+	"github.com/deco-sites/decofile-operator/internal/controller"
+)
+
+// Run without envtest: go test -run TestGetDeploymentId ./internal/webhook/v1/
+func TestGetDeploymentId(t *testing.T) {
+	cases := []struct {
+		name       string
+		svcLabels  map[string]string
+		tmplLabels map[string]string
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:      "label on Service",
+			svcLabels: map[string]string{controller.DefaultDeploymentIdLabel: "svc-id"},
+			want:      "svc-id",
+		},
+		{
+			name:       "label only on pod template",
+			tmplLabels: map[string]string{controller.DefaultDeploymentIdLabel: "tmpl-id"},
+			want:       "tmpl-id",
+		},
+		{
+			name:       "Service label wins over pod template",
+			svcLabels:  map[string]string{controller.DefaultDeploymentIdLabel: "svc-id"},
+			tmplLabels: map[string]string{controller.DefaultDeploymentIdLabel: "tmpl-id"},
+			want:       "svc-id",
+		},
+		{
+			name:       "empty Service label falls back",
+			svcLabels:  map[string]string{controller.DefaultDeploymentIdLabel: ""},
+			tmplLabels: map[string]string{controller.DefaultDeploymentIdLabel: "tmpl-id"},
+			want:       "tmpl-id",
+		},
+		{
+			name:    "no label anywhere",
+			wantErr: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			svc := &servingknativedevv1.Service{ObjectMeta: metav1.ObjectMeta{Labels: tc.svcLabels}}
+			svc.Spec.Template.Labels = tc.tmplLabels
+
+			got, err := (&ServiceCustomDefaulter{}).getDeploymentId(svc)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("getDeploymentId() err = %v, wantErr %v", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("getDeploymentId() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGetDeploymentId_CustomLabel(t *testing.T) {
+	d := &ServiceCustomDefaulter{DeploymentIdLabel: "example.com/release"}
+	svc := &servingknativedevv1.Service{ObjectMeta: metav1.ObjectMeta{
+		Labels: map[string]string{"example.com/release": "svc-id", "app.deco/deploymentId": "ignored"},
+	}}
+	got, err := d.getDeploymentId(svc)
+	if err != nil || got != "svc-id" {
+		t.Fatalf("getDeploymentId() = %q, %v; want %q", got, err, "svc-id")
+	}
+
+	_, err = d.getDeploymentId(&servingknativedevv1.Service{})
+	if err == nil || !strings.Contains(err.Error(), "example.com/release") {
+		t.Errorf("getDeploymentId() err = %v, want it to name the configured label", err)
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	servingknativedevv1 "knative.dev/serving/pkg/apis/serving/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+const (
+	// decofileInjectExtraAnnot lists further Decofiles (by deploymentId or
+	// name, comma-separated) to mount next to the primary one, e.g. a
+	// Decofile shared by several sites. Each is mounted read-only at
+	// <dir of mount path>/decofiles/<deploymentId>; DECO_RELEASE keeps
+	// pointing at the primary and DECO_RELEASE_EXTRA lists the others as
+	// <deploymentId>=<url> pairs.
+	decofileInjectExtraAnnot = "deco.sites/decofile-inject-extra"
+	decoReleaseExtraEnvVar   = "DECO_RELEASE_EXTRA"
+
+	// extraDecofileLabelPrefix marks the pod template with one
+	// <prefix><deploymentId>=true label per extra Decofile, which the
+	// controller selects on to notify and render codecs for those pods.
+	extraDecofileLabelPrefix = "decofile.deco.sites/"
+	extraVolumePrefix        = "decofile-config-"
+)
+
+// extraDecofileRefs parses the decofile-inject-extra annotation, dropping
+// blanks, duplicates and the primary deploymentId.
+func extraDecofileRefs(service *servingknativedevv1.Service, primary string) []string {
+	var refs []string
+	for _, ref := range strings.Split(service.Annotations[decofileInjectExtraAnnot], ",") {
+		ref = strings.TrimSpace(ref)
+		if ref == "" || ref == primary || slices.Contains(refs, ref) {
+			continue
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// findExtraDecofile resolves an extra reference, by deploymentId first and
+// then by Decofile name.
+func (d *ServiceCustomDefaulter) findExtraDecofile(ctx context.Context, namespace, ref string) (*decositesv1alpha1.Decofile, error) {
+	if df, err := d.findDecofileByDeploymentId(ctx, namespace, ref); err == nil {
+		return df, nil
+	}
+	df := &decositesv1alpha1.Decofile{}
+	if err := d.Client.Get(ctx, client.ObjectKey{Name: ref, Namespace: namespace}, df); err != nil {
+		return nil, fmt.Errorf("no Decofile found with deploymentId or name %s in namespace %s", ref, namespace)
+	}
+	return df, nil
+}
+
+// injectExtraDecofiles mounts every Decofile listed in decofile-inject-extra
+// into the target container alongside the primary one at mountDir, and
+// removes the volumes, mounts and labels of extras no longer listed. Like the
+// primary, an extra that doesn't exist yet is skipped with a warning.
+func (d *ServiceCustomDefaulter) injectExtraDecofiles(ctx context.Context, service *servingknativedevv1.Service, primary, mountDir string) error {
+	refs := extraDecofileRefs(service, primary)
+	// Extras are read by the same runtime, in the codec chosen for the primary
+	codec := cmp.Or(service.Spec.Template.Annotations[decofileCodecAnnot], decositesv1alpha1.CodecBrotli)
+	containerIdx := d.findTargetContainer(service)
+
+	var ids, releases []string
+	for _, ref := range refs {
+		df, err := d.findExtraDecofile(ctx, service.Namespace, ref)
+		if err != nil {
+			servicelog.Info("WARNING: extra Decofile not found; it will not be mounted until the Service is redeployed",
+				"service", service.Name, "namespace", service.Namespace, "ref", ref, "reason", err.Error())
+			continue
+		}
+		if df.Spec.Target == decositesv1alpha1.TargetS3 {
+			return fmt.Errorf("%s: Decofile %s has target s3; only ConfigMap Decofiles can be mounted as extras", decofileInjectExtraAnnot, df.Name)
+		}
+		id := df.DeploymentIdOrName()
+		if id == primary || slices.Contains(ids, id) {
+			continue
+		}
+		if errs := validation.IsDNS1123Label(extraVolumePrefix + id); len(errs) > 0 {
+			return fmt.Errorf("%s: deploymentId %q can't name a volume: %s", decofileInjectExtraAnnot, id, strings.Join(errs, "; "))
+		}
+
+		dir := path.Join(path.Dir(mountDir), "decofiles", id)
+		d.addOrUpdateNamedVolume(service, extraVolumePrefix+id, df.ConfigMapName())
+		d.addOrUpdateNamedVolumeMount(service, containerIdx, extraVolumePrefix+id, dir)
+		ids = append(ids, id)
+		releases = append(releases, fmt.Sprintf("%s=file://%s/%s", id, dir, decositesv1alpha1.DecofileCodecKey(codec)))
+	}
+
+	d.pruneExtraDecofiles(service, containerIdx, ids)
+
+	container := &service.Spec.Template.Spec.Containers[containerIdx]
+	container.Env = slices.DeleteFunc(container.Env, func(env corev1.EnvVar) bool {
+		return env.Name == decoReleaseExtraEnvVar
+	})
+	if len(releases) > 0 {
+		container.Env = append(container.Env, corev1.EnvVar{Name: decoReleaseExtraEnvVar, Value: strings.Join(releases, ",")})
+	}
+
+	if len(ids) > 0 && service.Spec.Template.Labels == nil {
+		service.Spec.Template.Labels = make(map[string]string)
+	}
+	for _, id := range ids {
+		service.Spec.Template.Labels[extraDecofileLabelPrefix+id] = "true"
+	}
+	return nil
+}
+
+// pruneExtraDecofiles drops extra Decofile volumes, mounts and labels whose
+// deploymentId isn't in keep.
+func (d *ServiceCustomDefaulter) pruneExtraDecofiles(service *servingknativedevv1.Service, containerIdx int, keep []string) {
+	stale := func(name, prefix string) bool {
+		id, ok := strings.CutPrefix(name, prefix)
+		return ok && !slices.Contains(keep, id)
+	}
+	spec := &service.Spec.Template.Spec
+	spec.Volumes = slices.DeleteFunc(spec.Volumes, func(vol corev1.Volume) bool {
+		return stale(vol.Name, extraVolumePrefix)
+	})
+	container := &spec.Containers[containerIdx]
+	container.VolumeMounts = slices.DeleteFunc(container.VolumeMounts, func(mount corev1.VolumeMount) bool {
+		return stale(mount.Name, extraVolumePrefix)
+	})
+	for label := range service.Spec.Template.Labels {
+		if stale(label, extraDecofileLabelPrefix) {
+			delete(service.Spec.Template.Labels, label)
+		}
+	}
+}
+
+// serviceUsesExtraDecofile reports whether a Service lists the Decofile
+// (by deploymentId or name) in decofile-inject-extra.
+func serviceUsesExtraDecofile(service *servingknativedevv1.Service, decofile *decositesv1alpha1.Decofile) bool {
+	refs := extraDecofileRefs(service, "")
+	return slices.Contains(refs, decofile.DeploymentIdOrName()) || slices.Contains(refs, decofile.Name)
+}
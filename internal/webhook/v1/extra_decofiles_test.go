@@ -0,0 +1,138 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	servingknativedevv1 "knative.dev/serving/pkg/apis/serving/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+	"github.com/deco-sites/decofile-operator/internal/controller"
+)
+
+// Run without envtest: go test -run TestDefault_ExtraDecofiles ./internal/webhook/v1/
+func TestDefault_ExtraDecofiles(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := decositesv1alpha1.AddToScheme(s); err != nil {
+		t.Fatalf("add scheme: %v", err)
+	}
+	decofile := func(name, deploymentId string) *decositesv1alpha1.Decofile {
+		return &decositesv1alpha1.Decofile{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "sites-foo"},
+			Spec:       decositesv1alpha1.DecofileSpec{DeploymentId: deploymentId},
+		}
+	}
+	d := &ServiceCustomDefaulter{Client: fake.NewClientBuilder().WithScheme(s).WithObjects(
+		decofile("site", "dep-1"),
+		decofile("shared-config", "shared"),
+		decofile("other", ""),
+	).Build()}
+
+	svc := &servingknativedevv1.Service{ObjectMeta: metav1.ObjectMeta{
+		Name:      "site",
+		Namespace: "sites-foo",
+		Labels:    map[string]string{controller.DefaultDeploymentIdLabel: "dep-1"},
+		Annotations: map[string]string{
+			decofileInjectAnnot:      "true",
+			decofileInjectExtraAnnot: "shared, other, missing, dep-1",
+		},
+	}}
+	svc.Spec.Template.Spec.Containers = []corev1.Container{{Name: appContainerName}}
+	if err := d.Default(context.Background(), svc); err != nil {
+		t.Fatalf("Default: %v", err)
+	}
+
+	mounts := map[string]string{}
+	for _, m := range svc.Spec.Template.Spec.Containers[0].VolumeMounts {
+		mounts[m.Name] = m.MountPath
+	}
+	wantMounts := map[string]string{
+		"decofile-config":        "/app/decofile",
+		"decofile-config-shared": "/app/decofiles/shared",
+		"decofile-config-other":  "/app/decofiles/other",
+	}
+	for name, path := range wantMounts {
+		if mounts[name] != path {
+			t.Errorf("mount %s = %q, want %q", name, mounts[name], path)
+		}
+	}
+	if len(mounts) != len(wantMounts) {
+		t.Errorf("mounts = %v, want %v", mounts, wantMounts)
+	}
+
+	env := map[string]string{}
+	for _, e := range svc.Spec.Template.Spec.Containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	if got, want := env[decoReleaseEnvVar], "file:///app/decofile/decofile.bin"; got != want {
+		t.Errorf("DECO_RELEASE = %q, want %q", got, want)
+	}
+	if got, want := env[decoReleaseExtraEnvVar], "shared=file:///app/decofiles/shared/decofile.bin,other=file:///app/decofiles/other/decofile.bin"; got != want {
+		t.Errorf("DECO_RELEASE_EXTRA = %q, want %q", got, want)
+	}
+	for _, id := range []string{"shared", "other"} {
+		if svc.Spec.Template.Labels[extraDecofileLabelPrefix+id] != "true" {
+			t.Errorf("missing extra label for %s: %v", id, svc.Spec.Template.Labels)
+		}
+	}
+
+	// Dropping an extra removes its volume, mount, env entry and label
+	svc.Annotations[decofileInjectExtraAnnot] = "shared"
+	if err := d.Default(context.Background(), svc); err != nil {
+		t.Fatalf("Default: %v", err)
+	}
+	for _, vol := range svc.Spec.Template.Spec.Volumes {
+		if vol.Name == "decofile-config-other" {
+			t.Errorf("stale volume %s kept", vol.Name)
+		}
+	}
+	for _, m := range svc.Spec.Template.Spec.Containers[0].VolumeMounts {
+		if m.Name == "decofile-config-other" {
+			t.Errorf("stale mount %s kept", m.Name)
+		}
+	}
+	if _, ok := svc.Spec.Template.Labels[extraDecofileLabelPrefix+"other"]; ok {
+		t.Errorf("stale extra label kept: %v", svc.Spec.Template.Labels)
+	}
+	for _, e := range svc.Spec.Template.Spec.Containers[0].Env {
+		if e.Name == decoReleaseExtraEnvVar && e.Value != "shared=file:///app/decofiles/shared/decofile.bin" {
+			t.Errorf("DECO_RELEASE_EXTRA = %q after dropping other", e.Value)
+		}
+	}
+}
+
+// Run without envtest: go test -run TestDefault_ExtraDecofilesRequireVolumeMode ./internal/webhook/v1/
+func TestDefault_ExtraDecofilesRequireVolumeMode(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := decositesv1alpha1.AddToScheme(s); err != nil {
+		t.Fatalf("add scheme: %v", err)
+	}
+	df := &decositesv1alpha1.Decofile{ObjectMeta: metav1.ObjectMeta{Name: "dep-1", Namespace: "sites-foo"}}
+	d := &ServiceCustomDefaulter{Client: fake.NewClientBuilder().WithScheme(s).WithObjects(df).Build()}
+
+	svc := &servingknativedevv1.Service{ObjectMeta: metav1.ObjectMeta{
+		Name:      "site",
+		Namespace: "sites-foo",
+		Labels:    map[string]string{controller.DefaultDeploymentIdLabel: "dep-1"},
+		Annotations: map[string]string{
+			decofileInjectAnnot:      "true",
+			decofileInjectModeAnnot:  injectModeSidecar,
+			decofileInjectExtraAnnot: "shared",
+		},
+	}}
+	svc.Spec.Template.Spec.Containers = []corev1.Container{{Name: appContainerName}}
+	if err := d.Default(context.Background(), svc); err == nil {
+		t.Fatal("Default() with extras in sidecar mode succeeded, want error")
+	}
+}
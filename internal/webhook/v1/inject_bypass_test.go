@@ -0,0 +1,40 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	servingknativedevv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+// Run without envtest: go test -run TestDefault_InjectBypass ./internal/webhook/v1/
+func TestDefault_InjectBypass(t *testing.T) {
+	svc := &servingknativedevv1.Service{}
+	svc.Name = "site"
+	svc.Annotations = map[string]string{
+		decofileInjectAnnot:       "true",
+		decofileInjectBypassAnnot: "true",
+	}
+	svc.Spec.Template.Spec.Containers = []corev1.Container{{Name: appContainerName}}
+
+	// No client and no deploymentId label: anything past the bypass check
+	// would fail, so a nil error proves Default returned early.
+	d := &ServiceCustomDefaulter{}
+	if err := d.Default(context.Background(), svc); err != nil {
+		t.Fatalf("Default() error = %v, want nil", err)
+	}
+	if vols := svc.Spec.Template.Spec.Volumes; len(vols) != 0 {
+		t.Errorf("volumes = %+v, want none", vols)
+	}
+	if env := svc.Spec.Template.Spec.Containers[0].Env; len(env) != 0 {
+		t.Errorf("env = %+v, want none", env)
+	}
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	servingknativedevv1 "knative.dev/serving/pkg/apis/serving/v1"
+)
+
+// Run without envtest: go test -run TestAddOrUpdateScratchVolume ./internal/webhook/v1/
+func TestAddOrUpdateScratchVolume(t *testing.T) {
+	svc := &servingknativedevv1.Service{}
+	svc.Spec.Template.Spec.Containers = []corev1.Container{{Name: appContainerName}}
+	d := &ServiceCustomDefaulter{}
+
+	// Applying twice (e.g. on Service update) must stay idempotent
+	d.addOrUpdateScratchVolume(svc, 0, "/tmp/old")
+	d.addOrUpdateScratchVolume(svc, 0, "/app/.scratch")
+
+	vols := svc.Spec.Template.Spec.Volumes
+	if len(vols) != 1 || vols[0].Name != scratchVolumeName || vols[0].EmptyDir == nil {
+		t.Fatalf("volumes = %+v, want one emptyDir %s", vols, scratchVolumeName)
+	}
+	mounts := svc.Spec.Template.Spec.Containers[0].VolumeMounts
+	if len(mounts) != 1 {
+		t.Fatalf("mounts = %+v, want exactly one", mounts)
+	}
+	if mounts[0].MountPath != "/app/.scratch" || mounts[0].ReadOnly {
+		t.Errorf("mount = %+v, want writable mount at /app/.scratch", mounts[0])
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	servingknativedevv1 "knative.dev/serving/pkg/apis/serving/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+	"github.com/deco-sites/decofile-operator/internal/controller"
+)
+
+// Run without envtest: go test -run TestValidateCreate_InjectionWarnings ./internal/webhook/v1/
+func TestValidateCreate_InjectionWarnings(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := decositesv1alpha1.AddToScheme(s); err != nil {
+		t.Fatalf("add scheme: %v", err)
+	}
+	df := &decositesv1alpha1.Decofile{
+		ObjectMeta: metav1.ObjectMeta{Name: "site", Namespace: "sites-foo"},
+		Spec:       decositesv1alpha1.DecofileSpec{DeploymentId: "dep-1"},
+	}
+	v := &ServiceCustomValidator{Client: fake.NewClientBuilder().WithScheme(s).WithObjects(df).Build()}
+
+	service := func(deploymentId string, annotations map[string]string) *servingknativedevv1.Service {
+		return &servingknativedevv1.Service{ObjectMeta: metav1.ObjectMeta{
+			Name:        "site",
+			Namespace:   "sites-foo",
+			Labels:      map[string]string{controller.DefaultDeploymentIdLabel: deploymentId},
+			Annotations: annotations,
+		}}
+	}
+	cases := []struct {
+		name    string
+		svc     *servingknativedevv1.Service
+		wantSub []string
+	}{
+		{"matching decofile", service("dep-1", map[string]string{decofileInjectAnnot: "true"}), nil},
+		{"no injection", service("dep-2", nil), nil},
+		{"missing decofile", service("dep-2", map[string]string{decofileInjectAnnot: "true"}), []string{"deploymentId dep-2"}},
+		{"missing extra", service("dep-1", map[string]string{
+			decofileInjectAnnot:      "true",
+			decofileInjectExtraAnnot: "site, shared",
+		}), []string{"lists shared"}},
+	}
+	for _, tc := range cases {
+		warnings, err := v.ValidateCreate(context.Background(), tc.svc)
+		if err != nil {
+			t.Errorf("%s: ValidateCreate() err = %v, want nil (warnings never reject)", tc.name, err)
+		}
+		if len(warnings) != len(tc.wantSub) {
+			t.Errorf("%s: warnings = %q, want %d", tc.name, warnings, len(tc.wantSub))
+			continue
+		}
+		for i, sub := range tc.wantSub {
+			if !strings.Contains(warnings[i], sub) {
+				t.Errorf("%s: warning %q does not mention %q", tc.name, warnings[i], sub)
+			}
+		}
+	}
+}
@@ -33,6 +33,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+	"github.com/deco-sites/decofile-operator/internal/controller"
 )
 
 const (
@@ -41,8 +42,37 @@ const (
 	decoReleaseEnvVar      = "DECO_RELEASE"
 	decofileInjectAnnot    = "deco.sites/decofile-inject"
 	decofileMountPathAnnot = "deco.sites/decofile-mount-path"
-	deploymentIdLabel      = "app.deco/deploymentId"
+	decofileVolumeName     = "decofile-config"
 	valkeyACLSecretName    = "valkey-acl"
+
+	// decofileScratchAnnot requests a writable emptyDir mounted at the given
+	// path for the runtime's scratch files (e.g. lock files), so pods with a
+	// read-only root filesystem work while the decofile mount stays read-only.
+	decofileScratchAnnot = "deco.sites/decofile-scratch-path"
+	scratchVolumeName    = "decofile-scratch"
+
+	// decofileCodecsAnnot lists the codecs the runtime can read (e.g.
+	// "br,gzip"). The selected codec is recorded on the pod template as
+	// decofileCodecAnnot, which the controller reads off the Revisions to
+	// decide which keys to render into the shared ConfigMap.
+	decofileCodecsAnnot = "deco.sites/decofile-codecs"
+	decofileCodecAnnot  = "deco.sites/decofile-codec"
+
+	// decofileInjectModeAnnot selects how the ConfigMap reaches the runtime:
+	// "volume" (default) mounts it into the app container, "sidecar" mounts it
+	// into a sidecar that serves it on localhost (image/port from the
+	// operator's DECOFILE_SIDECAR_IMAGE / DECOFILE_SIDECAR_PORT).
+	decofileInjectModeAnnot = "deco.sites/decofile-inject-mode"
+	injectModeVolume        = "volume"
+	injectModeSidecar       = "sidecar"
+	sidecarContainerName    = "decofile-server"
+	sidecarMountDir         = "/var/run/decofile"
+	defaultSidecarPort      = "8099"
+
+	// decofileInjectBypassAnnot skips mutation even when decofileInjectAnnot
+	// is "true", so test harnesses can keep a Service's inject annotation
+	// intact while opting out of the webhook.
+	decofileInjectBypassAnnot = "deco.sites/decofile-inject-bypass"
 )
 
 // nolint:unused
@@ -51,11 +81,13 @@ var servicelog = logf.Log.WithName("service-resource")
 
 // +kubebuilder:rbac:groups=deco.sites,resources=decofiles,verbs=get;list;watch
 
-// SetupServiceWebhookWithManager registers the webhook for Service in the manager.
-func SetupServiceWebhookWithManager(mgr ctrl.Manager) error {
+// SetupServiceWebhookWithManager registers the webhook for Service in the
+// manager. deploymentIdLabel is the label holding a Service's deploymentId
+// (--deployment-id-label); empty means controller.DefaultDeploymentIdLabel.
+func SetupServiceWebhookWithManager(mgr ctrl.Manager, deploymentIdLabel string) error {
 	return ctrl.NewWebhookManagedBy(mgr).For(&servingknativedevv1.Service{}).
-		WithDefaulter(&ServiceCustomDefaulter{Client: mgr.GetClient()}).
-		WithValidator(&ServiceCustomValidator{}).
+		WithDefaulter(&ServiceCustomDefaulter{Client: mgr.GetClient(), DeploymentIdLabel: deploymentIdLabel}).
+		WithValidator(&ServiceCustomValidator{Client: mgr.GetClient(), DeploymentIdLabel: deploymentIdLabel}).
 		Complete()
 }
 
@@ -70,22 +102,41 @@ func SetupServiceWebhookWithManager(mgr ctrl.Manager) error {
 // as this struct is used only for temporary operations and does not need to be deeply copied.
 type ServiceCustomDefaulter struct {
 	Client client.Client
+	// DeploymentIdLabel is the label read for a Service's deploymentId and
+	// stamped on its pod template, which the controller selects pods by.
+	// Empty means controller.DefaultDeploymentIdLabel.
+	DeploymentIdLabel string
 }
 
 var _ webhook.CustomDefaulter = &ServiceCustomDefaulter{}
 
-// getDeploymentId extracts deploymentId from Service labels
+// getDeploymentId extracts deploymentId from Service (or pod template) labels
 func (d *ServiceCustomDefaulter) getDeploymentId(service *servingknativedevv1.Service) (string, error) {
-	if service.Labels == nil {
-		return "", fmt.Errorf("service has deco.sites/decofile-inject annotation but no labels")
+	deploymentId := serviceDeploymentId(service, d.DeploymentIdLabel)
+	if deploymentId == "" {
+		return "", fmt.Errorf("service has deco.sites/decofile-inject annotation but no %s label", deploymentIdLabelOrDefault(d.DeploymentIdLabel))
 	}
 
-	deploymentId, exists := service.Labels[deploymentIdLabel]
-	if !exists || deploymentId == "" {
-		return "", fmt.Errorf("service has deco.sites/decofile-inject annotation but no app.deco/deploymentId label")
+	return deploymentId, nil
+}
+
+// deploymentIdLabelOrDefault returns label, or
+// controller.DefaultDeploymentIdLabel when it is empty.
+func deploymentIdLabelOrDefault(label string) string {
+	if label == "" {
+		return controller.DefaultDeploymentIdLabel
 	}
+	return label
+}
 
-	return deploymentId, nil
+// serviceDeploymentId returns the deploymentId label of a Service, falling
+// back to the pod template labels when the Service itself lacks it.
+func serviceDeploymentId(service *servingknativedevv1.Service, label string) string {
+	label = deploymentIdLabelOrDefault(label)
+	if deploymentId := service.Labels[label]; deploymentId != "" {
+		return deploymentId
+	}
+	return service.Spec.Template.Labels[label]
 }
 
 // findDecofileByDeploymentId finds a Decofile matching the given deploymentId
@@ -116,9 +167,12 @@ func (d *ServiceCustomDefaulter) injectDecofileVolume(_ context.Context, service
 	// This ensures the name is always available, even if the Decofile hasn't been reconciled yet
 	configMapName := decofile.ConfigMapName()
 
-	// Create DECO_RELEASE environment variable
-	// Always use .bin since we always compress with Brotli
-	decoReleaseValue := fmt.Sprintf("file://%s/decofile.bin", mountDir)
+	// Create DECO_RELEASE environment variable pointing at the key for the
+	// codec this runtime supports (.bin, Brotli, unless it or the Decofile
+	// declares otherwise)
+	codec := selectCodec(service, decofile)
+	decoReleaseValue := fmt.Sprintf("file://%s/%s", mountDir, decositesv1alpha1.DecofileCodecKey(codec))
+	d.setCodecAnnotation(service, codec)
 
 	// Ensure volumes array exists
 	if service.Spec.Template.Spec.Volumes == nil {
@@ -140,6 +194,83 @@ func (d *ServiceCustomDefaulter) injectDecofileVolume(_ context.Context, service
 	return nil
 }
 
+// selectCodec picks the codec service's runtime reads decofile in: from the
+// codecs it lists in decofileCodecsAnnot, else the Decofile's
+// spec.compression.
+func selectCodec(service *servingknativedevv1.Service, decofile *decositesv1alpha1.Decofile) string {
+	if supported, ok := service.Annotations[decofileCodecsAnnot]; ok {
+		return decositesv1alpha1.SelectDecofileCodec(supported)
+	}
+	return decofile.Spec.DefaultCodec()
+}
+
+// setCodecAnnotation records a non-default codec on the pod template so it
+// propagates to the Revision; the default (br) leaves no annotation.
+func (d *ServiceCustomDefaulter) setCodecAnnotation(service *servingknativedevv1.Service, codec string) {
+	if codec == decositesv1alpha1.CodecBrotli {
+		delete(service.Spec.Template.Annotations, decofileCodecAnnot)
+		return
+	}
+	if service.Spec.Template.Annotations == nil {
+		service.Spec.Template.Annotations = make(map[string]string)
+	}
+	service.Spec.Template.Annotations[decofileCodecAnnot] = codec
+}
+
+// injectDecofileSidecar mounts the Decofile ConfigMap into a sidecar that
+// serves it over localhost, and points DECO_RELEASE at it. The app container
+// gets no volume mount. The sidecar reads DECOFILE_PATH (encoded as
+// DECOFILE_CODEC, see DecofileCodecKey) and serves the JSON at /decofile.json
+// on PORT; it declares no containerPort, as Knative allows only one
+// container to.
+func (d *ServiceCustomDefaulter) injectDecofileSidecar(service *servingknativedevv1.Service, decofile *decositesv1alpha1.Decofile) error {
+	if len(service.Spec.Template.Spec.Containers) == 0 {
+		return fmt.Errorf("no containers found in Service spec")
+	}
+	image := os.Getenv("DECOFILE_SIDECAR_IMAGE")
+	if image == "" {
+		return fmt.Errorf("%s=%s but DECOFILE_SIDECAR_IMAGE is not set on the operator", decofileInjectModeAnnot, injectModeSidecar)
+	}
+	port := os.Getenv("DECOFILE_SIDECAR_PORT")
+	if port == "" {
+		port = defaultSidecarPort
+	}
+
+	codec := selectCodec(service, decofile)
+	d.setCodecAnnotation(service, codec)
+	d.addOrUpdateVolume(service, decofile.ConfigMapName())
+
+	sidecar := corev1.Container{
+		Name:  sidecarContainerName,
+		Image: image,
+		Env: []corev1.EnvVar{
+			{Name: "DECOFILE_PATH", Value: sidecarMountDir + "/" + decositesv1alpha1.DecofileCodecKey(codec)},
+			{Name: "DECOFILE_CODEC", Value: codec},
+			{Name: "PORT", Value: port},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: "decofile-config", MountPath: sidecarMountDir, ReadOnly: true},
+		},
+	}
+	replaced := false
+	for i, c := range service.Spec.Template.Spec.Containers {
+		if c.Name == sidecarContainerName {
+			service.Spec.Template.Spec.Containers[i] = sidecar
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		service.Spec.Template.Spec.Containers = append(service.Spec.Template.Spec.Containers, sidecar)
+	}
+
+	// The sidecar is appended, so it never shadows the first-container fallback
+	idx := d.findTargetContainer(service)
+	d.addOrUpdateEnvVars(service, idx, fmt.Sprintf("http://localhost:%s/decofile.json", port))
+	d.ensureAllowedAuthority(service, idx, "localhost:"+port)
+	return nil
+}
+
 // defaultAllowedAuthorities mirrors the deco runtime's built-in allowlist
 // (engine/trustedAuthority.ts). Setting DECO_ALLOWED_AUTHORITIES replaces (not
 // appends to) that default, so when we inject an S3/CloudFront host we must
@@ -229,7 +360,11 @@ func (d *ServiceCustomDefaulter) ensureAllowedAuthority(service *servingknatived
 
 // addOrUpdateVolume adds or updates the decofile volume
 func (d *ServiceCustomDefaulter) addOrUpdateVolume(service *servingknativedevv1.Service, configMapName string) {
-	volumeName := "decofile-config"
+	d.addOrUpdateNamedVolume(service, decofileVolumeName, configMapName)
+}
+
+// addOrUpdateNamedVolume adds or updates a ConfigMap volume called volumeName
+func (d *ServiceCustomDefaulter) addOrUpdateNamedVolume(service *servingknativedevv1.Service, volumeName, configMapName string) {
 	volumeExists := false
 
 	for i, vol := range service.Spec.Template.Spec.Volumes {
@@ -268,7 +403,11 @@ func (d *ServiceCustomDefaulter) findTargetContainer(service *servingknativedevv
 
 // addOrUpdateVolumeMount adds or updates the volume mount
 func (d *ServiceCustomDefaulter) addOrUpdateVolumeMount(service *servingknativedevv1.Service, containerIdx int, mountDir string) {
-	volumeName := "decofile-config"
+	d.addOrUpdateNamedVolumeMount(service, containerIdx, decofileVolumeName, mountDir)
+}
+
+// addOrUpdateNamedVolumeMount adds or updates the read-only mount of volumeName
+func (d *ServiceCustomDefaulter) addOrUpdateNamedVolumeMount(service *servingknativedevv1.Service, containerIdx int, volumeName, mountDir string) {
 	mountExists := false
 
 	for i, mount := range service.Spec.Template.Spec.PodSpec.Containers[containerIdx].VolumeMounts {
@@ -292,6 +431,37 @@ func (d *ServiceCustomDefaulter) addOrUpdateVolumeMount(service *servingknatived
 	}
 }
 
+// addOrUpdateScratchVolume adds or updates the writable emptyDir scratch volume
+// and its mount on the target container.
+func (d *ServiceCustomDefaulter) addOrUpdateScratchVolume(service *servingknativedevv1.Service, containerIdx int, mountDir string) {
+	volumeExists := false
+	for _, vol := range service.Spec.Template.Spec.Volumes {
+		if vol.Name == scratchVolumeName {
+			volumeExists = true
+			break
+		}
+	}
+	if !volumeExists {
+		service.Spec.Template.Spec.Volumes = append(service.Spec.Template.Spec.Volumes, corev1.Volume{
+			Name:         scratchVolumeName,
+			VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+		})
+	}
+
+	container := &service.Spec.Template.Spec.PodSpec.Containers[containerIdx]
+	for i, mount := range container.VolumeMounts {
+		if mount.Name == scratchVolumeName {
+			container.VolumeMounts[i].MountPath = mountDir
+			container.VolumeMounts[i].ReadOnly = false
+			return
+		}
+	}
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      scratchVolumeName,
+		MountPath: mountDir,
+	})
+}
+
 // addOrUpdateEnvVars adds or updates environment variables
 func (d *ServiceCustomDefaulter) addOrUpdateEnvVars(service *servingknativedevv1.Service, containerIdx int, decoReleaseValue string) {
 	// Add DECO_RELEASE environment variable
@@ -347,6 +517,11 @@ func (d *ServiceCustomDefaulter) Default(ctx context.Context, obj runtime.Object
 	if !exists || injectAnnotation != "true" {
 		return nil
 	}
+	if service.Annotations[decofileInjectBypassAnnot] == "true" {
+		servicelog.Info("Skipping Decofile injection: bypass annotation set",
+			"name", service.GetName(), "annotation", decofileInjectBypassAnnot)
+		return nil
+	}
 
 	// Get deploymentId from Service labels
 	deploymentId, err := d.getDeploymentId(service)
@@ -371,12 +546,24 @@ func (d *ServiceCustomDefaulter) Default(ctx context.Context, obj runtime.Object
 		return nil // Allow Service creation (non-blocking)
 	}
 
+	// Extra Decofiles are plain volume mounts next to the primary one
+	if len(extraDecofileRefs(service, deploymentId)) > 0 &&
+		(decofile.Spec.Target == decositesv1alpha1.TargetS3 || service.Annotations[decofileInjectModeAnnot] == injectModeSidecar) {
+		return fmt.Errorf("%s requires the primary Decofile to be mounted as a volume", decofileInjectExtraAnnot)
+	}
+
 	// s3 target: point the runtime at the HTTP URL instead of mounting a
 	// ConfigMap volume (the decofile lives in S3, not etcd).
 	if decofile.Spec.Target == decositesv1alpha1.TargetS3 {
 		if err := d.injectDecofileHTTP(service, decofile); err != nil {
 			return err
 		}
+	} else if mode := service.Annotations[decofileInjectModeAnnot]; mode == injectModeSidecar {
+		if err := d.injectDecofileSidecar(service, decofile); err != nil {
+			return err
+		}
+	} else if mode != "" && mode != injectModeVolume {
+		return fmt.Errorf("invalid %s %q (must be %q or %q)", decofileInjectModeAnnot, mode, injectModeVolume, injectModeSidecar)
 	} else {
 		// Get mount path from annotation or use default directory
 		mountDir := "/app/decofile"
@@ -388,6 +575,15 @@ func (d *ServiceCustomDefaulter) Default(ctx context.Context, obj runtime.Object
 		if err := d.injectDecofileVolume(ctx, service, decofile, mountDir); err != nil {
 			return err
 		}
+
+		// Mount any further Decofiles the runtime reads next to the primary
+		if err := d.injectExtraDecofiles(ctx, service, deploymentId, mountDir); err != nil {
+			return err
+		}
+	}
+
+	if scratchPath := service.Annotations[decofileScratchAnnot]; scratchPath != "" {
+		d.addOrUpdateScratchVolume(service, d.findTargetContainer(service), scratchPath)
 	}
 
 	// Explicitly add deploymentId label to pod template for notification
@@ -395,7 +591,7 @@ func (d *ServiceCustomDefaulter) Default(ctx context.Context, obj runtime.Object
 	if service.Spec.Template.Labels == nil {
 		service.Spec.Template.Labels = make(map[string]string)
 	}
-	service.Spec.Template.Labels[deploymentIdLabel] = deploymentId
+	service.Spec.Template.Labels[deploymentIdLabelOrDefault(d.DeploymentIdLabel)] = deploymentId
 
 	// Inject valkey-acl Secret as envFrom so pods receive per-tenant Valkey credentials.
 	// optional=true ensures pods start even before the Secret is provisioned by the operator,
@@ -442,35 +638,80 @@ func (d *ServiceCustomDefaulter) addOrUpdateValkeyEnvFrom(service *servingknativ
 // NOTE: The +kubebuilder:object:generate=false marker prevents controller-gen from generating DeepCopy methods,
 // as this struct is used only for temporary operations and does not need to be deeply copied.
 type ServiceCustomValidator struct {
-	// TODO(user): Add more fields as needed for validation
+	// Client looks up the Decofiles an injecting Service refers to. Nil
+	// skips the check.
+	Client client.Client
+	// DeploymentIdLabel is as on ServiceCustomDefaulter.
+	DeploymentIdLabel string
 }
 
 var _ webhook.CustomValidator = &ServiceCustomValidator{}
 
 // ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type Service.
-func (v *ServiceCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+func (v *ServiceCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
 	service, ok := obj.(*servingknativedevv1.Service)
 	if !ok {
 		return nil, fmt.Errorf("expected a Service object but got %T", obj)
 	}
 	servicelog.Info("Validation for Service upon creation", "name", service.GetName())
 
-	// TODO(user): fill in your validation logic upon object creation.
-
-	return nil, nil
+	return v.injectionWarnings(ctx, service), nil
 }
 
 // ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type Service.
-func (v *ServiceCustomValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+func (v *ServiceCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
 	service, ok := newObj.(*servingknativedevv1.Service)
 	if !ok {
 		return nil, fmt.Errorf("expected a Service object for the newObj but got %T", newObj)
 	}
 	servicelog.Info("Validation for Service upon update", "name", service.GetName())
 
-	// TODO(user): fill in your validation logic upon object update.
+	return v.injectionWarnings(ctx, service), nil
+}
 
-	return nil, nil
+// injectionWarnings warns, without rejecting, when a Service requests
+// decofile injection but a Decofile it names doesn't exist, so that
+// `kubectl apply` shows why the pods come up without a decofile. The
+// mutating webhook admits such Services on purpose (the Decofile may still
+// be on its way), leaving only a log line otherwise.
+func (v *ServiceCustomValidator) injectionWarnings(ctx context.Context, service *servingknativedevv1.Service) admission.Warnings {
+	if v.Client == nil || service.Annotations[decofileInjectAnnot] != "true" ||
+		service.Annotations[decofileInjectBypassAnnot] == "true" {
+		return nil
+	}
+	deploymentId := serviceDeploymentId(service, v.DeploymentIdLabel)
+	if deploymentId == "" {
+		return nil // the mutating webhook rejects this already
+	}
+
+	decofiles := &decositesv1alpha1.DecofileList{}
+	if err := v.Client.List(ctx, decofiles, client.InNamespace(service.Namespace)); err != nil {
+		servicelog.Error(err, "Failed to list Decofiles during Service validation, skipping injection warnings")
+		return nil
+	}
+	exists := func(ref string, byName bool) bool {
+		for i := range decofiles.Items {
+			if df := &decofiles.Items[i]; df.DeploymentIdOrName() == ref || (byName && df.Name == ref) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var warnings admission.Warnings
+	if !exists(deploymentId, false) {
+		warnings = append(warnings, fmt.Sprintf(
+			"%s is set but no Decofile in namespace %s has deploymentId %s: the decofile will not be injected and "+
+				"the pods can't be hot-reloaded until the Service is redeployed after the Decofile exists",
+			decofileInjectAnnot, service.Namespace, deploymentId))
+	}
+	for _, ref := range extraDecofileRefs(service, deploymentId) {
+		if !exists(ref, true) {
+			warnings = append(warnings, fmt.Sprintf("%s lists %s but no Decofile in namespace %s has that deploymentId or name: it will not be mounted",
+				decofileInjectExtraAnnot, ref, service.Namespace))
+		}
+	}
+	return warnings
 }
 
 // ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type Service.
@@ -0,0 +1,75 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+*/
+
+package v1
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	servingknativedevv1 "knative.dev/serving/pkg/apis/serving/v1"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+// Run without envtest: go test -run TestInjectDecofileSidecar ./internal/webhook/v1/
+func TestInjectDecofileSidecar(t *testing.T) {
+	t.Setenv("DECOFILE_SIDECAR_IMAGE", "ghcr.io/deco-sites/decofile-server:v1")
+	t.Setenv("DECOFILE_SIDECAR_PORT", "")
+
+	df := &decositesv1alpha1.Decofile{ObjectMeta: metav1.ObjectMeta{Name: "site", Namespace: "sites-foo"}}
+	svc := &servingknativedevv1.Service{}
+	svc.Spec.Template.Spec.Containers = []corev1.Container{{Name: appContainerName}}
+	d := &ServiceCustomDefaulter{}
+
+	// Applying twice (e.g. on Service update) must stay idempotent
+	for range 2 {
+		if err := d.injectDecofileSidecar(svc, df); err != nil {
+			t.Fatalf("injectDecofileSidecar: %v", err)
+		}
+	}
+
+	containers := svc.Spec.Template.Spec.Containers
+	if len(containers) != 2 || containers[1].Name != sidecarContainerName {
+		t.Fatalf("containers = %+v, want app + %s", containers, sidecarContainerName)
+	}
+	sidecar := containers[1]
+	if sidecar.Image != "ghcr.io/deco-sites/decofile-server:v1" || len(sidecar.Ports) != 0 {
+		t.Errorf("sidecar = %+v", sidecar)
+	}
+	if len(sidecar.VolumeMounts) != 1 || sidecar.VolumeMounts[0].MountPath != sidecarMountDir || !sidecar.VolumeMounts[0].ReadOnly {
+		t.Errorf("sidecar mounts = %+v", sidecar.VolumeMounts)
+	}
+	if len(containers[0].VolumeMounts) != 0 {
+		t.Errorf("app container mounts = %+v, want none", containers[0].VolumeMounts)
+	}
+	if vols := svc.Spec.Template.Spec.Volumes; len(vols) != 1 || vols[0].ConfigMap == nil || vols[0].ConfigMap.Name != df.ConfigMapName() {
+		t.Errorf("volumes = %+v", vols)
+	}
+
+	env := map[string]string{}
+	for _, e := range containers[0].Env {
+		env[e.Name] = e.Value
+	}
+	if env[decoReleaseEnvVar] != "http://localhost:8099/decofile.json" {
+		t.Errorf("DECO_RELEASE = %q", env[decoReleaseEnvVar])
+	}
+	if env[reloadTokenEnvVar] == "" {
+		t.Error("reload token not injected")
+	}
+}
+
+func TestInjectDecofileSidecar_RequiresImage(t *testing.T) {
+	t.Setenv("DECOFILE_SIDECAR_IMAGE", "")
+	svc := &servingknativedevv1.Service{}
+	svc.Spec.Template.Spec.Containers = []corev1.Container{{Name: appContainerName}}
+	df := &decositesv1alpha1.Decofile{ObjectMeta: metav1.ObjectMeta{Name: "site"}}
+	if err := (&ServiceCustomDefaulter{}).injectDecofileSidecar(svc, df); err == nil {
+		t.Fatal("injectDecofileSidecar() succeeded without DECOFILE_SIDECAR_IMAGE")
+	}
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+	"github.com/deco-sites/decofile-operator/internal/controller"
+	"github.com/deco-sites/decofile-operator/internal/github"
+)
+
+const (
+	// verifyCommitAnnot on a Decofile makes updates that change its GitHub
+	// commit check with GitHub that the commit exists before they're
+	// admitted, so a typo'd SHA or ref never reaches reconcile.
+	verifyCommitAnnot = "deco.sites/verify-commit"
+
+	// verifyCommitTimeout bounds the GitHub request, well within the
+	// admission webhook's own timeout.
+	verifyCommitTimeout = 5 * time.Second
+)
+
+// verifyCommit rejects an update of a verifyCommitAnnot Decofile whose new
+// spec.github commit (or org/repo) GitHub doesn't know. When GitHub can't
+// be asked, the update is admitted with a warning: an outage must not
+// block every update.
+func (v *DecofileCustomValidator) verifyCommit(ctx context.Context, oldDecofile, decofile *decositesv1alpha1.Decofile) (admission.Warnings, error) {
+	gh := decofile.Spec.GitHub
+	if decofile.Annotations[verifyCommitAnnot] != "true" || decofile.Spec.Source != decositesv1alpha1.SourceGitHub || gh == nil {
+		return nil, nil
+	}
+	if old := oldDecofile.Spec.GitHub; old != nil && old.Org == gh.Org && old.Repo == gh.Repo && old.Commit == gh.Commit {
+		return nil, nil
+	}
+
+	token, err := controller.GitHubToken(ctx, v.Client, decofile.Namespace, gh.Secret, v.DefaultGitHubSecret)
+	if err != nil {
+		return admission.Warnings{fmt.Sprintf("%s: commit %q not verified: %v", verifyCommitAnnot, gh.Commit, err)}, nil
+	}
+	commitExists := v.CommitExists
+	if commitExists == nil {
+		commitExists = github.CommitExists
+	}
+	checkCtx, cancel := context.WithTimeout(ctx, verifyCommitTimeout)
+	defer cancel()
+	exists, err := commitExists(checkCtx, token, gh.Org, gh.Repo, gh.Commit)
+	if err != nil {
+		decofilelog.Info("Could not verify commit", "name", decofile.Name, "namespace", decofile.Namespace,
+			"commit", gh.Commit, "error", err.Error())
+		return admission.Warnings{fmt.Sprintf("%s: commit %q not verified: %v", verifyCommitAnnot, gh.Commit, err)}, nil
+	}
+	if !exists {
+		return nil, fmt.Errorf("spec.github.commit %q not found in %s/%s (or the repository isn't readable with the Decofile's GitHub token)",
+			gh.Commit, gh.Org, gh.Repo)
+	}
+	return nil, nil
+}
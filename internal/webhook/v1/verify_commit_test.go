@@ -0,0 +1,105 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	decositesv1alpha1 "github.com/deco-sites/decofile-operator/api/v1alpha1"
+)
+
+// Run without envtest: go test -run TestDecofileValidateUpdate_VerifyCommit ./internal/webhook/v1/
+func TestDecofileValidateUpdate_VerifyCommit(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := corev1.AddToScheme(s); err != nil {
+		t.Fatalf("add core scheme: %v", err)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "gh", Namespace: "sites-foo"},
+		Data:       map[string][]byte{"token": []byte("secret")},
+	}
+	decofile := func(commit string, verify bool) *decositesv1alpha1.Decofile {
+		df := &decositesv1alpha1.Decofile{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "sites-foo"},
+			Spec: decositesv1alpha1.DecofileSpec{
+				Source: decositesv1alpha1.SourceGitHub,
+				GitHub: &decositesv1alpha1.GitHubSource{
+					Org: "deco-sites", Repo: "storefront", Commit: commit, Path: ".deco/blocks", Secret: "gh",
+				},
+			},
+		}
+		if verify {
+			df.Annotations = map[string]string{verifyCommitAnnot: "true"}
+		}
+		return df
+	}
+
+	var checked []string
+	v := &DecofileCustomValidator{
+		Client: fake.NewClientBuilder().WithScheme(s).WithObjects(secret).Build(),
+		CommitExists: func(_ context.Context, token, _, _, ref string) (bool, error) {
+			if token != "secret" {
+				t.Errorf("token = %q, want the one from spec.github.secret", token)
+			}
+			checked = append(checked, ref)
+			switch ref {
+			case "main":
+				return true, nil
+			case "unreachable":
+				return false, errors.New("status 502")
+			}
+			return false, nil
+		},
+	}
+
+	cases := []struct {
+		name         string
+		old, new     *decositesv1alpha1.Decofile
+		wantErr      bool
+		wantWarnings bool
+		wantChecked  bool
+	}{
+		{name: "annotation not set", old: decofile("main", false), new: decofile("typo", false)},
+		{name: "commit unchanged", old: decofile("typo", true), new: decofile("typo", true)},
+		{name: "existing commit", old: decofile("v1", true), new: decofile("main", true), wantChecked: true},
+		{name: "missing commit", old: decofile("main", true), new: decofile("typo", true), wantErr: true, wantChecked: true},
+		{name: "GitHub unreachable", old: decofile("main", true), new: decofile("unreachable", true),
+			wantWarnings: true, wantChecked: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			checked = nil
+			warnings, err := v.ValidateUpdate(context.Background(), tc.old, tc.new)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("ValidateUpdate() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if (len(warnings) > 0) != tc.wantWarnings {
+				t.Errorf("ValidateUpdate() warnings = %v, want warnings %v", warnings, tc.wantWarnings)
+			}
+			if (len(checked) > 0) != tc.wantChecked {
+				t.Errorf("checked commits = %v, want a check %v", checked, tc.wantChecked)
+			}
+		})
+	}
+}